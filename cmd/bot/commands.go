@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+)
+
+const commandsUsage = `commands:
+  serve                             run the bot (default)
+  worker                            run only background jobs, see -jobs
+  migrate                           apply database migrations and exit
+  backup PATH                       copy database into new file PATH
+  export PROJECT_ID                 write project tasks as JSON to stdout
+  import PROJECT_ID [FILE]          create tasks from JSON written by export, stdin if FILE is omitted
+  user grant TG_USER_ID PROJECT_ID  make project member a manager
+  user revoke TG_USER_ID PROJECT_ID make project manager a member
+  project list                      list all projects
+  project delete PROJECT_ID         export project tasks to file and delete project with them after confirmation
+  outbox list                       list notifications delivery of which was given up
+  outbox requeue ID|all             send given up notifications again`
+
+// runCommand runs operational command against database, output goes to stdout.
+func runCommand(ctx context.Context, db *sql.DB, command string, args []string) error {
+	projectStorage := sqliteStorage.NewProjectStorage(db, db)
+	userStorage := sqliteStorage.NewUserStorage(db, db)
+	taskStorage := sqliteStorage.NewTaskStorage(db, db)
+
+	switch {
+	case command == "backup" && len(args) == 1:
+		if err := sqliteStorage.NewMaintenanceStorage(db).CopyTo(ctx, args[0]); err != nil {
+			return fmt.Errorf("could not copy database: %w", err)
+		}
+		log.Printf("INFO database copied to %s", args[0])
+		return nil
+	case command == "export" && len(args) == 1:
+		projectID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("could not parse project id %q: %w", args[0], err)
+		}
+		return exportTasks(ctx, taskStorage, projectID, os.Stdout)
+	case command == "import" && (len(args) == 1 || len(args) == 2):
+		projectID, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("could not parse project id %q: %w", args[0], err)
+		}
+		if _, err = projectStorage.FetchProjectByID(ctx, projectID); err != nil {
+			return fmt.Errorf("could not fetch project: %w", err)
+		}
+		var r io.Reader = os.Stdin
+		if len(args) == 2 {
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+		return importTasks(ctx, taskStorage, projectID, r)
+	case command == "user" && len(args) == 3 && (args[0] == "grant" || args[0] == "revoke"):
+		role := model.UserProjectRoleManager
+		if args[0] == "revoke" {
+			role = model.UserProjectRoleMember
+		}
+		return setUserRole(ctx, userStorage, args[1], args[2], role)
+	case command == "project" && len(args) == 1 && args[0] == "list":
+		return listProjects(ctx, projectStorage, userStorage, os.Stdout)
+	case command == "project" && len(args) == 2 && args[0] == "delete":
+		return deleteProject(ctx, projectStorage, taskStorage, args[1], os.Stdin, os.Stdout)
+	case command == "outbox" && len(args) == 1 && args[0] == "list":
+		return listDeadNotifications(ctx, sqliteStorage.NewOutboxStorage(db), os.Stdout)
+	case command == "outbox" && len(args) == 2 && args[0] == "requeue":
+		return requeueDeadNotifications(ctx, sqliteStorage.NewOutboxStorage(db), args[1])
+	default:
+		return fmt.Errorf("unknown command or arguments\n\n%s", commandsUsage)
+	}
+}
+
+// exportedTask is task as written by export, assignees and authors are not exported
+// because users are known only to the instance.
+type exportedTask struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority,omitempty"`
+	Estimate    int      `json:"estimate,omitempty"`
+	Deadline    string   `json:"deadline,omitempty"`
+	Muted       bool     `json:"muted,omitempty"`
+	Epic        bool     `json:"epic,omitempty"`
+	ParentID    int      `json:"parent_id,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+func exportTasks(ctx context.Context, taskStorage model.TaskRepository, projectID int, w io.Writer) error {
+	tasks, err := taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: projectID})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	exported := make([]exportedTask, 0, len(tasks))
+	for _, task := range tasks {
+		e := exportedTask{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      string(task.Status),
+			Priority:    string(task.Priority),
+			Estimate:    task.Estimate,
+			Muted:       task.Muted,
+			Epic:        task.Epic,
+			ParentID:    task.ParentID,
+			Labels:      task.Labels,
+		}
+		if !task.Deadline.IsZero() {
+			e.Deadline = task.Deadline.Format(time.DateOnly)
+		}
+		exported = append(exported, e)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exported)
+}
+
+// importTasks creates tasks with new ids, children are linked to imported epics afterwards.
+func importTasks(ctx context.Context, taskStorage model.TaskRepository, projectID int, r io.Reader) error {
+	var exported []exportedTask
+	if err := json.NewDecoder(r).Decode(&exported); err != nil {
+		return fmt.Errorf("could not decode tasks: %w", err)
+	}
+
+	tasks := make([]*model.Task, 0, len(exported))
+	for _, e := range exported {
+		if strings.TrimSpace(e.Title) == "" {
+			return fmt.Errorf("task %d has no title", e.ID)
+		}
+		status := model.TaskStatus(e.Status)
+		if !slices.Contains(model.TaskStatuses, status) {
+			return fmt.Errorf("task %d has unknown status %q", e.ID, e.Status)
+		}
+		task := model.NewTask(projectID, e.Title, 0)
+		if e.Priority != "" {
+			task.Priority = model.TaskPriority(e.Priority)
+			if !slices.Contains(model.TaskPriorities, task.Priority) {
+				return fmt.Errorf("task %d has unknown priority %q", e.ID, e.Priority)
+			}
+		}
+		if e.Estimate != 0 && !slices.Contains(model.TaskEstimates, e.Estimate) {
+			return fmt.Errorf("task %d has unknown estimate %d", e.ID, e.Estimate)
+		}
+		task.Estimate = e.Estimate
+		task.Description = e.Description
+		task.Status = status
+		task.Muted = e.Muted
+		for _, label := range e.Labels {
+			if label == "" || strings.ContainsFunc(label, unicode.IsSpace) {
+				return fmt.Errorf("task %d has invalid label %q", e.ID, label)
+			}
+		}
+		task.Labels = e.Labels
+		task.Epic = e.Epic
+		if e.Deadline != "" {
+			deadline, err := time.ParseInLocation(time.DateOnly, e.Deadline, time.Local)
+			if err != nil {
+				return fmt.Errorf("could not parse deadline of task %d: %w", e.ID, err)
+			}
+			task.Deadline = deadline
+		}
+		tasks = append(tasks, task)
+	}
+	if err := taskStorage.CreateTasks(ctx, tasks); err != nil {
+		return fmt.Errorf("could not create tasks: %w", err)
+	}
+
+	newIDs := make(map[int]int, len(exported))
+	for i, e := range exported {
+		newIDs[e.ID] = tasks[i].ID
+	}
+	var children []*model.Task
+	for i, e := range exported {
+		if parentID, ok := newIDs[e.ParentID]; ok && e.ParentID != 0 {
+			tasks[i].ParentID = parentID
+			children = append(children, tasks[i])
+		}
+	}
+	if len(children) > 0 {
+		if err := taskStorage.UpdateTasks(ctx, children); err != nil {
+			return fmt.Errorf("could not link tasks to epics: %w", err)
+		}
+	}
+	log.Printf("INFO imported %d tasks into project id=%d", len(tasks), projectID)
+	return nil
+}
+
+func setUserRole(ctx context.Context, userStorage model.UserRepository, rawTgID, rawProjectID string, role model.UserProjectRole) error {
+	tgUserID, err := strconv.ParseInt(rawTgID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse user id %q: %w", rawTgID, err)
+	}
+	projectID, err := strconv.Atoi(rawProjectID)
+	if err != nil {
+		return fmt.Errorf("could not parse project id %q: %w", rawProjectID, err)
+	}
+	user, err := userStorage.FetchUserByTgID(ctx, tgUserID)
+	if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	err = userStorage.SetUserRoleInProject(ctx, projectID, user.ID, role)
+	if errors.Is(err, model.ErrUserNotFound) {
+		return fmt.Errorf("user tg_id=%d is not a member of project id=%d", tgUserID, projectID)
+	} else if err != nil {
+		return fmt.Errorf("could not set role: %w", err)
+	}
+	log.Printf("INFO user id=%d is now %s in project id=%d", user.ID, role, projectID)
+	return nil
+}
+
+func listProjects(ctx context.Context, projectStorage model.ProjectRepository, userStorage model.UserRepository, w io.Writer) error {
+	projects, err := projectStorage.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch projects: %w", err)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCHAT\tMEMBERS\tSTATE\tTITLE")
+	for _, prj := range projects {
+		members, err := userStorage.CountUsersInProject(ctx, prj.ID)
+		if err != nil {
+			return fmt.Errorf("could not count members: %w", err)
+		}
+		var state []string
+		if prj.Archived {
+			state = append(state, "archived")
+		}
+		if prj.Frozen {
+			state = append(state, "frozen")
+		}
+		if len(state) == 0 {
+			state = append(state, "active")
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\n", prj.ID, prj.TgChatID, members, strings.Join(state, ","), prj.Title)
+	}
+	return tw.Flush()
+}
+
+// deleteProject shows what is deleted with project, exports its tasks to file in current directory, so they
+// can be imported back, and deletes project once id of the project is typed to confirm. Users who were members
+// only of it are cleaned up by maintenance after grace period.
+func deleteProject(ctx context.Context, projectStorage model.ProjectRepository, taskStorage model.TaskRepository,
+	rawID string, in io.Reader, out io.Writer) error {
+	projectID, err := strconv.Atoi(rawID)
+	if err != nil {
+		return fmt.Errorf("could not parse project id %q: %w", rawID, err)
+	}
+	prj, err := projectStorage.FetchProjectByID(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	contents, err := projectStorage.CountProjectContents(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not count project contents: %w", err)
+	}
+
+	path := fmt.Sprintf("project-%d-%s.json", prj.ID, time.Now().Format("20060102-150405"))
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not create export file: %w", err)
+	}
+	err = exportTasks(ctx, taskStorage, prj.ID, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("could not export tasks: %w", err)
+	}
+
+	fmt.Fprintf(out, "project id=%d %q has %d tasks, %d members and %d comments\n",
+		prj.ID, prj.Title, contents.Tasks, contents.Members, contents.Comments)
+	fmt.Fprintf(out, "tasks are exported to %s, restore them with: import PROJECT_ID %s\n", path, path)
+	fmt.Fprint(out, "type project id to delete it: ")
+	var answer string
+	fmt.Fscanln(in, &answer)
+	if answer != strconv.Itoa(prj.ID) {
+		return errors.New("deletion is not confirmed")
+	}
+
+	if err = projectStorage.DeleteProject(ctx, prj.ID); err != nil {
+		return fmt.Errorf("could not delete project: %w", err)
+	}
+	log.Printf("INFO project id=%d %q deleted", prj.ID, prj.Title)
+	return nil
+}
+
+func listDeadNotifications(ctx context.Context, outbox model.OutboxRepository, w io.Writer) error {
+	notifications, err := outbox.ListDeadNotifications(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch notifications: %w", err)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSER\tCREATED\tATTEMPTS\tLAST ERROR\tTEXT")
+	for _, n := range notifications {
+		text := strings.Join(strings.Fields(n.Text), " ")
+		if runes := []rune(text); len(runes) > 40 {
+			text = string(runes[:40]) + "…"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%s\t%s\n", n.ID, n.UserID, n.CreatedAt.Format(time.DateTime), n.Attempts, n.LastError, text)
+	}
+	return tw.Flush()
+}
+
+// requeueDeadNotifications makes given up notifications due now, they are sent by process running jobs.
+func requeueDeadNotifications(ctx context.Context, outbox model.OutboxRepository, rawID string) error {
+	var ids []int
+	if rawID == "all" {
+		notifications, err := outbox.ListDeadNotifications(ctx)
+		if err != nil {
+			return fmt.Errorf("could not fetch notifications: %w", err)
+		}
+		for _, n := range notifications {
+			ids = append(ids, n.ID)
+		}
+	} else {
+		id, err := strconv.Atoi(rawID)
+		if err != nil {
+			return fmt.Errorf("could not parse notification id %q: %w", rawID, err)
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		err := outbox.RequeueDeadNotification(ctx, id, time.Now())
+		if errors.Is(err, model.ErrNotificationNotFound) {
+			return fmt.Errorf("notification id=%d is not given up", id)
+		} else if err != nil {
+			return fmt.Errorf("could not requeue notification: %w", err)
+		}
+	}
+	log.Printf("INFO requeued %d notifications", len(ids))
+	return nil
+}
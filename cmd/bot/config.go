@@ -5,20 +5,82 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/agalitsyn/flagutils"
 	"github.com/agalitsyn/secret"
 
 	"github.com/fatih/color"
 	"github.com/go-pkgz/lgr"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/app"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
 )
 
 const EnvPrefix = "TG_TASKS_BOT"
 
+var commandNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
 type Config struct {
 	Debug      bool
 	InlineMode bool
 	Token      secret.String
+	HTTPAddr   string
+	PublicURL  string
+
+	// DBKey encrypts database with SQLCipher, binary has to be built with "sqlcipher" tag.
+	DBKey secret.String
+	// DBReaders is size of pool of read-only connections, reads go through the single writer connection if 0.
+	DBReaders int
+
+	MaintenanceInterval time.Duration
+	Vacuum              bool
+
+	GroomingInterval time.Duration
+	GroomingTasks    int
+
+	OverdueCheckInterval time.Duration
+	RecurrenceInterval   time.Duration
+	SnapshotInterval     time.Duration
+
+	// OutboxInterval is how often notifications left in outbox are sent, they are sent without outbox if 0.
+	OutboxInterval time.Duration
+
+	// Jobs runs background jobs in the process handling updates, off when they run in worker.
+	Jobs bool
+
+	DryRun       bool
+	DryRunChatID int64
+
+	RecordUpdates string
+
+	// StatusLabels overrides status emojis and names, e.g. "done=🎉|сделано,todo=📝".
+	StatusLabels string
+
+	SlowQueryThreshold   time.Duration
+	StorageStatsInterval time.Duration
+
+	LLMEndpoint string
+	LLMAPIKey   secret.String
+	LLMModel    string
+
+	SummarizeCooldown time.Duration
+
+	NameFormat string
+
+	AnnounceSettings bool
+
+	// CommandAliases are comma separated "alias=command" pairs, e.g. "т=create_task,з=projects".
+	CommandAliases string
+
+	// Operators are comma separated Telegram IDs of users allowed to run /admin_sql.
+	Operators string
+
+	TelemetryEndpoint string
+	TelemetryInterval time.Duration
 
 	runPrintVersion bool
 	runMigrate      bool
@@ -39,17 +101,118 @@ func ParseFlags() Config {
 	flag.BoolVar(&cfg.Debug, "debug", false, "Debug mode.")
 	token := flag.String("token", "", "Telegram bot token.")
 	flag.BoolVar(&cfg.InlineMode, "inline-mode", false, "Enable bot inline mode.")
+	dbKey := flag.String("db-key", "", "Key of database encrypted with SQLCipher, requires binary built with '-tags sqlcipher'. Disabled if empty.")
+	flag.IntVar(&cfg.DBReaders, "db-readers", 4, "Number of read-only database connections, database is switched to WAL mode. Reads share writer connection if 0.")
+	flag.StringVar(&cfg.HTTPAddr, "http-addr", "", "HTTP server listen address, e.g. ':8080'. Disabled if empty.")
+	flag.StringVar(&cfg.PublicURL, "public-url", "", "Public base URL of HTTP server used in links, e.g. 'https://bot.example.com'.")
+	flag.DurationVar(&cfg.MaintenanceInterval, "maintenance-interval", 24*time.Hour, "Interval of stale data cleanup. Disabled if 0.")
+	flag.BoolVar(&cfg.Vacuum, "vacuum", false, "Compact database file during cleanup.")
+	flag.DurationVar(&cfg.GroomingInterval, "grooming-interval", 7*24*time.Hour, "Interval of posting the oldest backlog tasks for review. Disabled if 0.")
+	flag.IntVar(&cfg.GroomingTasks, "grooming-tasks", 5, "Number of backlog tasks posted for review.")
+	flag.DurationVar(&cfg.OverdueCheckInterval, "overdue-check-interval", 5*time.Minute, "Interval of checking deadlines to announce overdue tasks and escalate priorities. Disabled if 0.")
+	flag.DurationVar(&cfg.RecurrenceInterval, "recurrence-interval", time.Minute, "Interval of creating next instances of recurring tasks. Disabled if 0.")
+	flag.DurationVar(&cfg.SnapshotInterval, "snapshot-interval", time.Hour, "Interval of saving daily task counts of projects, the last save of the day is kept. Disabled if 0.")
+	flag.DurationVar(&cfg.OutboxInterval, "outbox-interval", 10*time.Second, "Interval of retrying notifications kept in outbox. Notifications are sent without outbox if 0.")
+	flag.BoolVar(&cfg.Jobs, "jobs", true, "Run background jobs when serving, disable when they run in separate worker.")
+	flag.BoolVar(&cfg.DryRun, "dry-run", false, "Log outgoing messages instead of sending them and work with database copy.")
+	flag.Int64Var(&cfg.DryRunChatID, "dry-run-chat-id", 0, "Chat receiving copies of messages in dry run. Disabled if 0.")
+	flag.StringVar(&cfg.RecordUpdates, "record-updates", "", "File to append incoming updates to for replay. Disabled if empty.")
+	flag.StringVar(&cfg.StatusLabels, "status-labels", "", "Status emojis and names overrides, e.g. 'done=🎉|сделано,todo=📝'.")
+	flag.DurationVar(&cfg.SlowQueryThreshold, "slow-query-threshold", 200*time.Millisecond, "Log storage calls taking longer. Disabled if 0.")
+	flag.DurationVar(&cfg.StorageStatsInterval, "storage-stats-interval", time.Hour, "Interval of logging storage calls statistics. Disabled if 0.")
+	flag.StringVar(&cfg.LLMEndpoint, "llm-endpoint", "", "Base URL of OpenAI-compatible API for assistant features, e.g. 'https://api.openai.com/v1'. Disabled if empty.")
+	llmAPIKey := flag.String("llm-api-key", "", "API key of language model endpoint.")
+	flag.StringVar(&cfg.LLMModel, "llm-model", "gpt-4o-mini", "Language model name.")
+	flag.DurationVar(&cfg.SummarizeCooldown, "summarize-cooldown", time.Minute, "Interval /summarize answers with the previous summary of the chat. Disabled if 0.")
+	flag.StringVar(&cfg.NameFormat, "name-format", string(app.NameFormatLastFirst), "How user names are shown: 'last-first', 'first-last' or 'username'.")
+	flag.BoolVar(&cfg.AnnounceSettings, "announce-settings", true, "Post changelog line to project chat when manager changes project settings.")
+	flag.StringVar(&cfg.CommandAliases, "command-aliases", "", "Comma separated command aliases, e.g. 'т=create_task,з=projects'. Disabled if empty.")
+	flag.StringVar(&cfg.Operators, "operators", "", "Comma separated Telegram user IDs allowed to run read-only SQL with /admin_sql. Disabled if empty.")
+	flag.StringVar(&cfg.TelemetryEndpoint, "telemetry-endpoint", "", "URL receiving anonymous usage counters of the instance as JSON. Disabled if empty.")
+	flag.DurationVar(&cfg.TelemetryInterval, "telemetry-interval", time.Hour, "Interval of sending usage counters.")
 	flag.BoolVar(&cfg.runPrintVersion, "version", false, "Show version.")
 	flag.BoolVar(&cfg.runMigrate, "migrate", false, "Migrate.")
 
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [command]\n\n%s\n\nflags:\n", os.Args[0], commandsUsage)
+		flag.PrintDefaults()
+	}
 	flagutils.Prefix = EnvPrefix
 	flagutils.Parse()
 	flag.Parse()
 
 	cfg.Token = secret.NewString(*token)
+	cfg.LLMAPIKey = secret.NewString(*llmAPIKey)
+	cfg.DBKey = secret.NewString(*dbKey)
 	return cfg
 }
 
+// parseStatusLabels parses comma separated "status=emoji|name" pairs, emoji or name may be empty.
+func parseStatusLabels(s string) (map[model.TaskStatus]model.StatusLabel, error) {
+	labels := make(map[model.TaskStatus]model.StatusLabel)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		status, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected status=emoji|name, got %q", pair)
+		}
+		emoji, name, _ := strings.Cut(value, "|")
+		labels[model.TaskStatus(strings.TrimSpace(status))] = model.StatusLabel{
+			Emoji: strings.TrimSpace(emoji),
+			Name:  strings.TrimSpace(name),
+		}
+	}
+	return labels, nil
+}
+
+// parseCommandAliases parses comma separated "alias=command" pairs, leading slashes are optional.
+func parseCommandAliases(s string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		alias, command, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected alias=command, got %q", pair)
+		}
+		alias = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(alias), "/"))
+		command = strings.TrimPrefix(strings.TrimSpace(command), "/")
+		if alias == "" || strings.ContainsAny(alias, " @") {
+			return nil, fmt.Errorf("invalid alias in %q", pair)
+		}
+		if !commandNamePattern.MatchString(command) {
+			return nil, fmt.Errorf("invalid command in %q", pair)
+		}
+		if _, ok = aliases[alias]; ok {
+			return nil, fmt.Errorf("duplicate alias %q", alias)
+		}
+		aliases[alias] = command
+	}
+	return aliases, nil
+}
+
+// parseOperators parses comma separated Telegram user IDs.
+func parseOperators(s string) ([]int64, error) {
+	var ids []int64
+	for _, raw := range strings.Split(s, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse user id %q: %w", raw, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func setupLogger(debug bool) {
 	colorizer := lgr.Mapper{
 		ErrorFunc:  func(s string) string { return color.New(color.FgHiRed).Sprint(s) },
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+)
+
+const (
+	dbPath = "db.sqlite3"
+	// dryRunDBPath is recreated from main database on every dry run, so real data is never changed.
+	dryRunDBPath = "db.dry-run.sqlite3"
+)
+
+// switchToCopy copies database into dst path, closes db and returns connection to the copy,
+// copy of encrypted database is opened with the same key.
+func switchToCopy(ctx context.Context, db *sql.DB, dst, key string) (*sql.DB, error) {
+	defer db.Close()
+
+	if err := sqliteStorage.NewMaintenanceStorage(db).CopyTo(ctx, dst); err != nil {
+		return nil, fmt.Errorf("could not copy database: %w", err)
+	}
+	return sqliteStorage.ConnectEncrypted(dst, key)
+}
+
+// leaseHolder identifies process in job leases, replicas on different hosts or on the same one differ.
+func leaseHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
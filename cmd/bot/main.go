@@ -2,15 +2,28 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
+	"time"
 
 	"github.com/agalitsyn/sqlite"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/api"
 	"github.com/agalitsyn/telegram-tasks-bot/internal/app"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/caldav"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/llm"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/publicboard"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/storage/metered"
 	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/taskstream"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/telemetry"
 	"github.com/agalitsyn/telegram-tasks-bot/migrations"
 	"github.com/agalitsyn/telegram-tasks-bot/version"
 )
@@ -31,28 +44,173 @@ func main() {
 		log.Printf("DEBUG running with config %v", cfg.String())
 	}
 
-	db, err := sqlite.Connect("db.sqlite3")
+	command, args := "serve", flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	// -migrate flag is kept for existing deployments
+	if cfg.runMigrate {
+		command = "migrate"
+	}
+
+	if !slices.Contains(app.NameFormats, app.NameFormat(cfg.NameFormat)) {
+		log.Printf("ERROR unknown name format %q", cfg.NameFormat)
+		return
+	}
+
+	path := dbPath
+	db, err := sqliteStorage.ConnectEncrypted(path, cfg.DBKey.Unmask())
 	if err != nil {
 		log.Fatal(err)
 	}
+	if cfg.DryRun {
+		path = dryRunDBPath
+		if db, err = switchToCopy(ctx, db, path, cfg.DBKey.Unmask()); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("INFO dry run: using database copy %s", path)
+	}
 	defer db.Close()
 
 	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
 		log.Printf("ERROR could not apply migrations: %s", err)
 		return
 	}
-	if cfg.runMigrate {
-		os.Exit(0)
+	if err = sqliteStorage.ValidateSchema(ctx, db, migrations.FS); err != nil {
+		log.Printf("ERROR unexpected database schema: %s", err)
+		return
+	}
+
+	// Bot reads through separate pool, so reports and boards don't wait for writes, commands use single connection
+	reader := db
+	if (command == "serve" || command == "worker") && cfg.DBReaders > 0 {
+		if reader, err = sqliteStorage.ConnectReader(db, path, cfg.DBKey.Unmask(), cfg.DBReaders); err != nil {
+			log.Printf("ERROR could not open database readers: %s", err)
+			return
+		}
+		defer reader.Close()
+	}
+	// Operator console never runs on the writer, its pragmas could be switched by the query
+	console := reader
+	if (command == "serve" || command == "worker") && cfg.Operators != "" && reader == db {
+		if console, err = sqliteStorage.ConnectReader(db, path, cfg.DBKey.Unmask(), 1); err != nil {
+			log.Printf("ERROR could not open database reader for console: %s", err)
+			return
+		}
+		defer console.Close()
+	}
+
+	switch command {
+	case "serve":
+		runBot(ctx, cfg, db, reader, console, false)
+	case "worker":
+		runBot(ctx, cfg, db, reader, console, true)
+	case "migrate":
+		log.Printf("INFO database schema is up to date")
+	default:
+		if err = runCommand(ctx, db, command, args); err != nil {
+			log.Printf("ERROR %s: %s", command, err)
+			db.Close()
+			os.Exit(1)
+		}
 	}
+}
 
+// runBot handles updates and runs background jobs until ctx is cancelled,
+// worker only runs the jobs, so they don't delay answers in large deployments.
+// Storages write through db and read through reader, which may be db itself,
+// operator console reads through read-only console.
+func runBot(ctx context.Context, cfg Config, db, reader, console *sql.DB, worker bool) {
+	var err error
 	log.Printf("version: %s", version.String())
 
-	projectStorage := sqliteStorage.NewProjectStorage(db)
-	userStorage := sqliteStorage.NewUserStorage(db)
+	if cfg.StatusLabels != "" {
+		labels, err := parseStatusLabels(cfg.StatusLabels)
+		if err == nil {
+			err = model.OverrideStatusLabels(labels)
+		}
+		if err != nil {
+			log.Printf("ERROR could not set status labels: %s", err)
+			return
+		}
+	}
+
+	storageMetrics := metered.NewMetrics(cfg.SlowQueryThreshold)
+	projectStorage := metered.NewProjects(sqliteStorage.NewProjectStorage(db, reader), storageMetrics)
+	userStorage := metered.NewUsers(sqliteStorage.NewUserStorage(db, reader), storageMetrics)
+	taskStorage := metered.NewTasks(sqliteStorage.NewTaskStorage(db, reader), storageMetrics)
+	if cfg.StorageStatsInterval > 0 {
+		go storageMetrics.StartReporting(ctx, cfg.StorageStatsInterval)
+	}
+
+	apiTokenStorage := metered.NewAPITokens(sqliteStorage.NewAPITokenStorage(db), storageMetrics)
+	// Every process logs task events, so streams see changes made by worker too
+	eventLog := metered.NewTaskEventLog(sqliteStorage.NewTaskEventLogStorage(db, reader), storageMetrics)
+	// API handler creates tasks with bot, so server is started once bot is ready
+	var mux *http.ServeMux
+	if cfg.HTTPAddr != "" && !worker {
+		taskStream := taskstream.NewHandler(projectStorage, eventLog)
+		context.AfterFunc(ctx, taskStream.Close)
+		go taskStream.Start(ctx)
+
+		mux = http.NewServeMux()
+		mux.Handle(caldav.PathPrefix, caldav.NewHandler(userStorage, taskStorage))
+		mux.Handle(publicboard.PathPrefix, publicboard.NewHandler(projectStorage, taskStorage, userStorage))
+		mux.Handle(taskstream.PathPrefix, taskStream)
+	}
 
 	botCfg := app.BotConfig{
 		UpdateTimeout:      60,
 		InlineQueryEnabled: cfg.InlineMode,
+		DryRun:             cfg.DryRun,
+		DryRunChatID:       cfg.DryRunChatID,
+		NameFormat:         app.NameFormat(cfg.NameFormat),
+		AnnounceSettings:   cfg.AnnounceSettings,
+		Plugins:            plugins,
+		Leases:             metered.NewLeases(sqliteStorage.NewLeaseStorage(db), storageMetrics),
+		LeaseHolder:        leaseHolder(),
+		EventLog:           eventLog,
+	}
+	if cfg.OutboxInterval > 0 {
+		botCfg.Outbox = metered.NewOutbox(sqliteStorage.NewOutboxStorage(db), storageMetrics)
+	}
+
+	if cfg.HTTPAddr != "" {
+		botCfg.PublicURL = cfg.PublicURL
+		botCfg.APITokens = apiTokenStorage
+	}
+	if cfg.LLMEndpoint != "" {
+		botCfg.Completer = llm.NewClient(cfg.LLMEndpoint, cfg.LLMAPIKey.Unmask(), cfg.LLMModel)
+		botCfg.SummarizeCooldown = cfg.SummarizeCooldown
+	}
+	if cfg.CommandAliases != "" {
+		if botCfg.CommandAliases, err = parseCommandAliases(cfg.CommandAliases); err != nil {
+			log.Printf("ERROR could not parse command aliases: %s", err)
+			return
+		}
+	}
+	if cfg.Operators != "" {
+		if botCfg.Operators, err = parseOperators(cfg.Operators); err != nil {
+			log.Printf("ERROR could not parse operators: %s", err)
+			return
+		}
+		botCfg.Console = sqliteStorage.NewConsoleStorage(console)
+	}
+	if cfg.TelemetryEndpoint != "" && cfg.TelemetryInterval > 0 {
+		tracker := telemetry.NewClient(cfg.TelemetryEndpoint, version.String())
+		botCfg.Tracker = tracker
+		go tracker.StartReporting(ctx, cfg.TelemetryInterval)
+		log.Printf("INFO sending anonymous usage counters to %s", cfg.TelemetryEndpoint)
+	}
+	if cfg.RecordUpdates != "" {
+		// Updates contain personal data of users.
+		updatesLog, err := os.OpenFile(cfg.RecordUpdates, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Printf("ERROR could not open updates log: %s", err)
+			return
+		}
+		defer updatesLog.Close()
+		botCfg.UpdatesLog = updatesLog
 	}
 	bot, err := app.NewBot(
 		botCfg,
@@ -60,6 +218,7 @@ func main() {
 		log.Default(),
 		projectStorage,
 		userStorage,
+		taskStorage,
 	)
 	if err != nil {
 		log.Printf("ERROR could not init bot: %s", err)
@@ -68,7 +227,62 @@ func main() {
 	if cfg.Debug {
 		bot.Debug = true
 	}
+	if mux != nil {
+		mux.Handle(api.PathPrefix, api.NewHandler(apiTokenStorage, projectStorage, taskStorage, userStorage, bot))
+		go runHTTPServer(ctx, cfg.HTTPAddr, mux)
+	}
+	if !worker {
+		if err = bot.RegisterCommands(); err != nil {
+			log.Printf("WARN could not register commands: %s", err)
+		}
+	}
 
+	// Jobs keep their state in database, so either process may run them, leases keep them from running twice
+	jobs := worker || cfg.Jobs
+	if cfg.MaintenanceInterval > 0 {
+		maintenanceCfg := app.MaintenanceConfig{
+			Interval:   cfg.MaintenanceInterval,
+			PendingTTL: time.Hour,
+			Vacuum:     cfg.Vacuum,
+		}
+		// Pending confirmations are kept in memory of process handling updates, so they are pruned there anyway
+		var maintenanceStorage model.MaintenanceRepository
+		if jobs {
+			maintenanceStorage = metered.NewMaintenance(sqliteStorage.NewMaintenanceStorage(db), storageMetrics)
+		}
+		go bot.StartMaintenance(ctx, maintenanceStorage, maintenanceCfg)
+	}
+
+	if jobs && cfg.GroomingInterval > 0 && cfg.GroomingTasks > 0 {
+		groomingCfg := app.GroomingConfig{
+			Interval: cfg.GroomingInterval,
+			Tasks:    cfg.GroomingTasks,
+		}
+		go bot.StartGrooming(ctx, groomingCfg)
+	}
+
+	if jobs && cfg.OverdueCheckInterval > 0 {
+		go bot.StartOverdueAnnouncements(ctx, cfg.OverdueCheckInterval)
+		go bot.StartPriorityEscalation(ctx, cfg.OverdueCheckInterval)
+	}
+
+	if jobs && cfg.RecurrenceInterval > 0 {
+		go bot.StartRecurrence(ctx, cfg.RecurrenceInterval)
+	}
+
+	if jobs && cfg.SnapshotInterval > 0 {
+		go bot.StartSnapshots(ctx, cfg.SnapshotInterval)
+	}
+
+	if jobs && cfg.OutboxInterval > 0 {
+		go bot.StartOutboxDelivery(ctx, cfg.OutboxInterval)
+	}
+
+	if worker {
+		log.Printf("INFO running background jobs with authorized account %s", bot.Self.UserName)
+		<-ctx.Done()
+		return
+	}
 	log.Printf("INFO starting with authorized account %s", bot.Self.UserName)
 	bot.Start(ctx)
 }
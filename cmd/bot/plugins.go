@@ -0,0 +1,8 @@
+package main
+
+import "github.com/agalitsyn/telegram-tasks-bot/internal/app"
+
+// plugins are compiled into the bot, deployer adds own ones from separate file of this package:
+//
+//	func init() { plugins = append(plugins, hrsync.New()) }
+var plugins []app.Plugin
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/app"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/telegramtest"
+)
+
+// Words of generated task titles, small vocabulary makes some titles similar enough to trigger duplicate warnings.
+var titleWords = []string{
+	"подготовить", "обновить", "проверить", "согласовать", "отчёт", "сайт", "договор",
+	"презентацию", "бюджет", "релиз", "макет", "счёт", "клиента", "сервер", "документацию",
+}
+
+const firstChatID = -1000000
+
+func chatID(i int) int64 {
+	return int64(firstChatID - i)
+}
+
+func newRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// generator feeds updates of its chats to bot one by one, callbacks press buttons bot has really sent.
+type generator struct {
+	bot     *app.Bot
+	tg      *telegramtest.Server
+	stats   *latencies
+	seq     *atomic.Int64
+	handled *atomic.Int64
+	rnd     *rand.Rand
+	chats   []int64
+	users   int
+}
+
+func (g *generator) run(ctx context.Context, n int) {
+	// Everybody joins projects first, the first member becomes manager and enables quick capture.
+	for _, chatID := range g.chats {
+		for user := 0; user < g.users; user++ {
+			g.handle(ctx, "start", g.command(chatID, user, "/start"))
+		}
+		g.handle(ctx, "quick_capture", g.command(chatID, 0, "/quick_capture"))
+	}
+
+	for i := 0; i < n; i++ {
+		chatID := g.chats[g.rnd.Intn(len(g.chats))]
+		user := g.rnd.Intn(g.users)
+		switch p := g.rnd.Intn(100); {
+		case p < 35:
+			g.handle(ctx, "capture", g.message(chatID, user, "todo: "+g.title()))
+		case p < 45:
+			g.handle(ctx, "chatter", g.message(chatID, user, "коллеги, напоминаю про созвон"))
+		case p < 60:
+			g.handle(ctx, "help", g.command(chatID, user, "/help"))
+		case p < 70:
+			list := fmt.Sprintf("/import_tasks\n- %s\n- %s @%s\n- %s 01.01",
+				g.title(), g.title(), username(chatID, g.users, 1), g.title())
+			g.handle(ctx, "import", g.command(chatID, 0, list))
+		default:
+			buttons := g.tg.ChatButtons(chatID)
+			if len(buttons) == 0 {
+				g.handle(ctx, "help", g.command(chatID, user, "/help"))
+				continue
+			}
+			button := buttons[g.rnd.Intn(len(buttons))]
+			g.handle(ctx, "callback", g.callback(chatID, user, *button.CallbackData))
+		}
+	}
+}
+
+func (g *generator) handle(ctx context.Context, kind string, update tgbotapi.Update) {
+	defer g.stats.observe(kind, time.Now())
+	g.bot.HandleUpdate(ctx, update)
+	g.handled.Add(1)
+}
+
+func (g *generator) title() string {
+	words := make([]string, 3)
+	for i := range words {
+		words[i] = titleWords[g.rnd.Intn(len(titleWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+func (g *generator) message(chatID int64, user int, text string) tgbotapi.Update {
+	id := int(g.seq.Add(1))
+	return tgbotapi.Update{
+		UpdateID: id,
+		Message: &tgbotapi.Message{
+			MessageID: id,
+			From:      g.user(chatID, user),
+			Date:      int(time.Now().Unix()),
+			Chat:      &tgbotapi.Chat{ID: chatID, Type: "supergroup", Title: fmt.Sprintf("Chat %d", chatID)},
+			Text:      text,
+		},
+	}
+}
+
+func (g *generator) command(chatID int64, user int, text string) tgbotapi.Update {
+	update := g.message(chatID, user, text)
+	name, _, _ := strings.Cut(strings.Fields(text)[0], "@")
+	update.Message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name)}}
+	return update
+}
+
+func (g *generator) callback(chatID int64, user int, data string) tgbotapi.Update {
+	id := int(g.seq.Add(1))
+	return tgbotapi.Update{
+		UpdateID: id,
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:   fmt.Sprintf("%d", id),
+			From: g.user(chatID, user),
+			Message: &tgbotapi.Message{
+				MessageID: id,
+				Chat:      &tgbotapi.Chat{ID: chatID, Type: "supergroup"},
+			},
+			Data: data,
+		},
+	}
+}
+
+// user returns member of chat, members of different chats do not overlap.
+func (g *generator) user(chatID int64, user int) *tgbotapi.User {
+	return &tgbotapi.User{
+		ID:        tgUserID(chatID, g.users, user),
+		FirstName: "User",
+		LastName:  fmt.Sprintf("%d", user),
+		UserName:  username(chatID, g.users, user),
+	}
+}
+
+func tgUserID(chatID int64, users int, user int) int64 {
+	return (firstChatID-chatID)*int64(users) + int64(user) + 1
+}
+
+func username(chatID int64, users int, user int) string {
+	return fmt.Sprintf("user%d", tgUserID(chatID, users, user))
+}
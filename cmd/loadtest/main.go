@@ -0,0 +1,179 @@
+// Command loadtest replays synthetic update stream of busy group chats through bot handlers
+// with fake Telegram API and reports throughput with handler and storage latencies.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agalitsyn/sqlite"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/app"
+	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/telegramtest"
+	"github.com/agalitsyn/telegram-tasks-bot/migrations"
+)
+
+type config struct {
+	chats   int
+	users   int
+	updates int
+	workers int
+	readers int
+	seed    int64
+	dbPath  string
+	verbose bool
+}
+
+func main() {
+	var cfg config
+	flag.IntVar(&cfg.chats, "chats", 50, "Number of group chats.")
+	flag.IntVar(&cfg.users, "users", 5, "Number of members in each chat.")
+	flag.IntVar(&cfg.updates, "updates", 5000, "Number of updates to replay after members join chats.")
+	flag.IntVar(&cfg.workers, "workers", 1, "Number of goroutines handling updates, each owns own share of chats.")
+	flag.IntVar(&cfg.readers, "readers", 0, "Number of read-only database connections like -db-readers of bot. Reads share writer connection if 0.")
+	flag.Int64Var(&cfg.seed, "seed", 1, "Random seed of update stream.")
+	flag.StringVar(&cfg.dbPath, "db", "", "Database path. Temporary database is used if empty.")
+	flag.BoolVar(&cfg.verbose, "verbose", false, "Show bot logs.")
+	flag.Parse()
+
+	if cfg.chats < cfg.workers || cfg.users < 1 || cfg.workers < 1 {
+		fmt.Fprintln(os.Stderr, "need at least one user, one worker and one chat per worker")
+		os.Exit(2)
+	}
+	logs := &errorCounter{w: io.Discard}
+	if cfg.verbose {
+		logs.w = os.Stderr
+	}
+	log.SetOutput(logs)
+
+	if err := run(cfg, logs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg config, logs *errorCounter) error {
+	dbPath := cfg.dbPath
+	if dbPath == "" {
+		dir, err := os.MkdirTemp("", "loadtest")
+		if err != nil {
+			return fmt.Errorf("could not create temp dir: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		dbPath = filepath.Join(dir, "db.sqlite3")
+	}
+
+	db, err := sqliteStorage.Connect(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+		return fmt.Errorf("could not apply migrations: %w", err)
+	}
+	reader := db
+	if cfg.readers > 0 {
+		if reader, err = sqliteStorage.ConnectReader(db, dbPath, "", cfg.readers); err != nil {
+			return err
+		}
+		defer reader.Close()
+	}
+
+	tg := telegramtest.NewServer()
+	defer tg.Close()
+
+	storageStats := newLatencies()
+	bot, err := app.NewBot(
+		app.BotConfig{APIEndpoint: tg.Endpoint()},
+		"loadtest",
+		log.Default(),
+		timedProjects{sqliteStorage.NewProjectStorage(db, reader), storageStats},
+		timedUsers{sqliteStorage.NewUserStorage(db, reader), storageStats},
+		timedTasks{sqliteStorage.NewTaskStorage(db, reader), storageStats},
+	)
+	if err != nil {
+		return fmt.Errorf("could not init bot: %w", err)
+	}
+
+	var (
+		ctx         = context.Background()
+		updateStats = newLatencies()
+		seq         atomic.Int64
+		handled     atomic.Int64
+		wg          sync.WaitGroup
+	)
+	start := time.Now()
+	for w := 0; w < cfg.workers; w++ {
+		g := &generator{
+			bot:     bot,
+			tg:      tg,
+			stats:   updateStats,
+			seq:     &seq,
+			handled: &handled,
+			rnd:     newRand(cfg.seed + int64(w)),
+			users:   cfg.users,
+		}
+		for i := w; i < cfg.chats; i += cfg.workers {
+			g.chats = append(g.chats, chatID(i))
+		}
+		n := cfg.updates / cfg.workers
+		if w < cfg.updates%cfg.workers {
+			n++
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.run(ctx, n)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	total := handled.Load()
+	fmt.Printf("handled %d updates in %s: %.0f updates/s\n", total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	fmt.Printf("errors logged: %d\n", logs.count.Load())
+	fmt.Println("\nupdates:")
+	updateStats.report(os.Stdout)
+	fmt.Println("\nstorage:")
+	storageStats.report(os.Stdout)
+	fmt.Println("\ntelegram requests:")
+	printRequests(tg.RequestCounts())
+	return nil
+}
+
+// errorCounter counts error lines of bot log, since handlers do not return errors.
+type errorCounter struct {
+	w     io.Writer
+	count atomic.Int64
+}
+
+func (c *errorCounter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte("ERROR")) {
+		c.count.Add(1)
+	}
+	return c.w.Write(p)
+}
+
+func printRequests(counts map[string]int) {
+	methods := make([]string, 0, len(counts))
+	for method := range counts {
+		methods = append(methods, method)
+	}
+	slices.Sort(methods)
+	for _, method := range methods {
+		fmt.Printf("  %s: %d\n", method, counts[method])
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// latencies collects durations of named operations.
+type latencies struct {
+	mu      sync.Mutex
+	samples map[string][]time.Duration
+}
+
+func newLatencies() *latencies {
+	return &latencies{samples: make(map[string][]time.Duration)}
+}
+
+// observe records time passed since start, intended to be deferred.
+func (l *latencies) observe(name string, start time.Time) {
+	d := time.Since(start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples[name] = append(l.samples[name], d)
+}
+
+func (l *latencies) report(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.samples))
+	for name := range l.samples {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operation\tcount\tavg\tp50\tp95\tp99\tmax\t")
+	for _, name := range names {
+		samples := slices.Clone(l.samples[name])
+		slices.Sort(samples)
+
+		var total time.Duration
+		for _, d := range samples {
+			total += d
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t\n",
+			name,
+			len(samples),
+			round(total/time.Duration(len(samples))),
+			round(percentile(samples, 50)),
+			round(percentile(samples, 95)),
+			round(percentile(samples, 99)),
+			round(samples[len(samples)-1]),
+		)
+	}
+	tw.Flush()
+}
+
+// percentile expects sorted samples.
+func percentile(samples []time.Duration, p int) time.Duration {
+	return samples[(len(samples)-1)*p/100]
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
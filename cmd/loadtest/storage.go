@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// Storages below measure latency of every repository call.
+
+type timedProjects struct {
+	model.ProjectRepository
+	stats *latencies
+}
+
+func (s timedProjects) FetchProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	defer s.stats.observe("FetchProjectByID", time.Now())
+	return s.ProjectRepository.FetchProjectByID(ctx, id)
+}
+
+func (s timedProjects) FetchProjectByChatID(ctx context.Context, tgChatID int64) (*model.Project, error) {
+	defer s.stats.observe("FetchProjectByChatID", time.Now())
+	return s.ProjectRepository.FetchProjectByChatID(ctx, tgChatID)
+}
+
+func (s timedProjects) FetchProjectByPublicToken(ctx context.Context, token string) (*model.Project, error) {
+	defer s.stats.observe("FetchProjectByPublicToken", time.Now())
+	return s.ProjectRepository.FetchProjectByPublicToken(ctx, token)
+}
+
+func (s timedProjects) FetchProjectsByUser(ctx context.Context, userID int, role model.UserProjectRole) ([]model.Project, error) {
+	defer s.stats.observe("FetchProjectsByUser", time.Now())
+	return s.ProjectRepository.FetchProjectsByUser(ctx, userID, role)
+}
+
+func (s timedProjects) ListProjects(ctx context.Context) ([]model.Project, error) {
+	defer s.stats.observe("ListProjects", time.Now())
+	return s.ProjectRepository.ListProjects(ctx)
+}
+
+func (s timedProjects) FetchProjectHolidays(ctx context.Context, projectID int) ([]time.Time, error) {
+	defer s.stats.observe("FetchProjectHolidays", time.Now())
+	return s.ProjectRepository.FetchProjectHolidays(ctx, projectID)
+}
+
+func (s timedProjects) SetProjectHolidays(ctx context.Context, projectID int, days []time.Time) error {
+	defer s.stats.observe("SetProjectHolidays", time.Now())
+	return s.ProjectRepository.SetProjectHolidays(ctx, projectID, days)
+}
+
+func (s timedProjects) FetchProjectLinks(ctx context.Context, projectID int) ([]model.ProjectLink, error) {
+	defer s.stats.observe("FetchProjectLinks", time.Now())
+	return s.ProjectRepository.FetchProjectLinks(ctx, projectID)
+}
+
+func (s timedProjects) SaveProjectLink(ctx context.Context, link *model.ProjectLink) error {
+	defer s.stats.observe("SaveProjectLink", time.Now())
+	return s.ProjectRepository.SaveProjectLink(ctx, link)
+}
+
+func (s timedProjects) RemoveProjectLink(ctx context.Context, id int) error {
+	defer s.stats.observe("RemoveProjectLink", time.Now())
+	return s.ProjectRepository.RemoveProjectLink(ctx, id)
+}
+
+func (s timedProjects) CreateProject(ctx context.Context, project *model.Project) error {
+	defer s.stats.observe("CreateProject", time.Now())
+	return s.ProjectRepository.CreateProject(ctx, project)
+}
+
+func (s timedProjects) UpdateProject(ctx context.Context, project *model.Project) error {
+	defer s.stats.observe("UpdateProject", time.Now())
+	return s.ProjectRepository.UpdateProject(ctx, project)
+}
+
+func (s timedProjects) DeleteProject(ctx context.Context, id int) error {
+	defer s.stats.observe("DeleteProject", time.Now())
+	return s.ProjectRepository.DeleteProject(ctx, id)
+}
+
+func (s timedProjects) CountProjectContents(ctx context.Context, id int) (model.ProjectContents, error) {
+	defer s.stats.observe("CountProjectContents", time.Now())
+	return s.ProjectRepository.CountProjectContents(ctx, id)
+}
+
+type timedUsers struct {
+	model.UserRepository
+	stats *latencies
+}
+
+func (s timedUsers) FetchUserByID(ctx context.Context, id int) (*model.User, error) {
+	defer s.stats.observe("FetchUserByID", time.Now())
+	return s.UserRepository.FetchUserByID(ctx, id)
+}
+
+func (s timedUsers) FetchUserByTgID(ctx context.Context, tgUserID int64) (*model.User, error) {
+	defer s.stats.observe("FetchUserByTgID", time.Now())
+	return s.UserRepository.FetchUserByTgID(ctx, tgUserID)
+}
+
+func (s timedUsers) FetchUserByCalDAVToken(ctx context.Context, token string) (*model.User, error) {
+	defer s.stats.observe("FetchUserByCalDAVToken", time.Now())
+	return s.UserRepository.FetchUserByCalDAVToken(ctx, token)
+}
+
+func (s timedUsers) UpdateUserCalDAVToken(ctx context.Context, userID int, token string) error {
+	defer s.stats.observe("UpdateUserCalDAVToken", time.Now())
+	return s.UserRepository.UpdateUserCalDAVToken(ctx, userID, token)
+}
+
+func (s timedUsers) SetUserUnreachable(ctx context.Context, tgUserID int64, unreachable bool) error {
+	defer s.stats.observe("SetUserUnreachable", time.Now())
+	return s.UserRepository.SetUserUnreachable(ctx, tgUserID, unreachable)
+}
+
+func (s timedUsers) FetchProjectUserByUsername(ctx context.Context, projectID int, username string) (*model.User, error) {
+	defer s.stats.observe("FetchProjectUserByUsername", time.Now())
+	return s.UserRepository.FetchProjectUserByUsername(ctx, projectID, username)
+}
+
+func (s timedUsers) FetchProjectUserByPreviousUsername(ctx context.Context, projectID int, username string) (*model.User, error) {
+	defer s.stats.observe("FetchProjectUserByPreviousUsername", time.Now())
+	return s.UserRepository.FetchProjectUserByPreviousUsername(ctx, projectID, username)
+}
+
+func (s timedUsers) CreateUser(ctx context.Context, user *model.User) error {
+	defer s.stats.observe("CreateUser", time.Now())
+	return s.UserRepository.CreateUser(ctx, user)
+}
+
+func (s timedUsers) UpdateUser(ctx context.Context, user *model.User) error {
+	defer s.stats.observe("UpdateUser", time.Now())
+	return s.UserRepository.UpdateUser(ctx, user)
+}
+
+func (s timedUsers) AddUserToProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) error {
+	defer s.stats.observe("AddUserToProject", time.Now())
+	return s.UserRepository.AddUserToProject(ctx, projectID, userID, role)
+}
+
+func (s timedUsers) SetUserRoleInProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) error {
+	defer s.stats.observe("SetUserRoleInProject", time.Now())
+	return s.UserRepository.SetUserRoleInProject(ctx, projectID, userID, role)
+}
+
+func (s timedUsers) FetchUserRoleInProject(ctx context.Context, projectID int, user *model.User) error {
+	defer s.stats.observe("FetchUserRoleInProject", time.Now())
+	return s.UserRepository.FetchUserRoleInProject(ctx, projectID, user)
+}
+
+func (s timedUsers) CountUsersInProject(ctx context.Context, projectID int) (int, error) {
+	defer s.stats.observe("CountUsersInProject", time.Now())
+	return s.UserRepository.CountUsersInProject(ctx, projectID)
+}
+
+func (s timedUsers) FetchProjectUsers(ctx context.Context, projectID int) ([]model.User, error) {
+	defer s.stats.observe("FetchProjectUsers", time.Now())
+	return s.UserRepository.FetchProjectUsers(ctx, projectID)
+}
+
+type timedTasks struct {
+	model.TaskRepository
+	stats *latencies
+}
+
+func (s timedTasks) FetchTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	defer s.stats.observe("FetchTaskByID", time.Now())
+	return s.TaskRepository.FetchTaskByID(ctx, id)
+}
+
+func (s timedTasks) FilterTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error) {
+	defer s.stats.observe("FilterTasks", time.Now())
+	return s.TaskRepository.FilterTasks(ctx, filter)
+}
+
+func (s timedTasks) CreateTask(ctx context.Context, task *model.Task) error {
+	defer s.stats.observe("CreateTask", time.Now())
+	return s.TaskRepository.CreateTask(ctx, task)
+}
+
+func (s timedTasks) CreateTasks(ctx context.Context, tasks []*model.Task) error {
+	defer s.stats.observe("CreateTasks", time.Now())
+	return s.TaskRepository.CreateTasks(ctx, tasks)
+}
+
+func (s timedTasks) UpdateTask(ctx context.Context, task *model.Task) error {
+	defer s.stats.observe("UpdateTask", time.Now())
+	return s.TaskRepository.UpdateTask(ctx, task)
+}
+
+func (s timedTasks) UpdateTasks(ctx context.Context, tasks []*model.Task) error {
+	defer s.stats.observe("UpdateTasks", time.Now())
+	return s.TaskRepository.UpdateTasks(ctx, tasks)
+}
+
+func (s timedTasks) UpdateTasksWithNotifications(ctx context.Context, tasks []*model.Task, notifications []model.Notification) error {
+	defer s.stats.observe("UpdateTasksWithNotifications", time.Now())
+	return s.TaskRepository.UpdateTasksWithNotifications(ctx, tasks, notifications)
+}
+
+func (s timedTasks) SetTaskOverdueNotified(ctx context.Context, id int, notified bool) error {
+	defer s.stats.observe("SetTaskOverdueNotified", time.Now())
+	return s.TaskRepository.SetTaskOverdueNotified(ctx, id, notified)
+}
+
+func (s timedTasks) SetTaskEscalated(ctx context.Context, id int, escalated bool) error {
+	defer s.stats.observe("SetTaskEscalated", time.Now())
+	return s.TaskRepository.SetTaskEscalated(ctx, id, escalated)
+}
+
+func (s timedTasks) RemoveTask(ctx context.Context, id int) error {
+	defer s.stats.observe("RemoveTask", time.Now())
+	return s.TaskRepository.RemoveTask(ctx, id)
+}
+
+func (s timedTasks) RestoreTask(ctx context.Context, id int) error {
+	defer s.stats.observe("RestoreTask", time.Now())
+	return s.TaskRepository.RestoreTask(ctx, id)
+}
+
+func (s timedTasks) FetchDeletedTasks(ctx context.Context, projectID int) ([]model.Task, error) {
+	defer s.stats.observe("FetchDeletedTasks", time.Now())
+	return s.TaskRepository.FetchDeletedTasks(ctx, projectID)
+}
+
+func (s timedTasks) PurgeTask(ctx context.Context, id int) error {
+	defer s.stats.observe("PurgeTask", time.Now())
+	return s.TaskRepository.PurgeTask(ctx, id)
+}
+
+func (s timedTasks) PurgeDeletedTasks(ctx context.Context, before time.Time) (int, error) {
+	defer s.stats.observe("PurgeDeletedTasks", time.Now())
+	return s.TaskRepository.PurgeDeletedTasks(ctx, before)
+}
+
+func (s timedTasks) CountTasksByStatus(ctx context.Context, projectID int) (map[model.TaskStatus]int, error) {
+	defer s.stats.observe("CountTasksByStatus", time.Now())
+	return s.TaskRepository.CountTasksByStatus(ctx, projectID)
+}
+
+func (s timedTasks) FetchTaskCounters(ctx context.Context, projectID int, now time.Time) (model.TaskCounters, error) {
+	defer s.stats.observe("FetchTaskCounters", time.Now())
+	return s.TaskRepository.FetchTaskCounters(ctx, projectID, now)
+}
+
+func (s timedTasks) FetchUpcomingDeadlines(ctx context.Context, projectID int, limit int) ([]model.Task, error) {
+	defer s.stats.observe("FetchUpcomingDeadlines", time.Now())
+	return s.TaskRepository.FetchUpcomingDeadlines(ctx, projectID, limit)
+}
+
+func (s timedTasks) FetchChecklist(ctx context.Context, taskID int) ([]model.ChecklistItem, error) {
+	defer s.stats.observe("FetchChecklist", time.Now())
+	return s.TaskRepository.FetchChecklist(ctx, taskID)
+}
+
+func (s timedTasks) FetchChecklistItem(ctx context.Context, id int) (*model.ChecklistItem, error) {
+	defer s.stats.observe("FetchChecklistItem", time.Now())
+	return s.TaskRepository.FetchChecklistItem(ctx, id)
+}
+
+func (s timedTasks) AddChecklistItems(ctx context.Context, items []*model.ChecklistItem) error {
+	defer s.stats.observe("AddChecklistItems", time.Now())
+	return s.TaskRepository.AddChecklistItems(ctx, items)
+}
+
+func (s timedTasks) SetChecklistItemDone(ctx context.Context, id int, done bool) error {
+	defer s.stats.observe("SetChecklistItemDone", time.Now())
+	return s.TaskRepository.SetChecklistItemDone(ctx, id, done)
+}
+
+func (s timedTasks) RemoveChecklistItem(ctx context.Context, id int) error {
+	defer s.stats.observe("RemoveChecklistItem", time.Now())
+	return s.TaskRepository.RemoveChecklistItem(ctx, id)
+}
+
+func (s timedTasks) AddTaskComment(ctx context.Context, comment *model.TaskComment) error {
+	defer s.stats.observe("AddTaskComment", time.Now())
+	return s.TaskRepository.AddTaskComment(ctx, comment)
+}
+
+func (s timedTasks) FetchTaskComments(ctx context.Context, taskID int, limit int) ([]model.TaskComment, int, error) {
+	defer s.stats.observe("FetchTaskComments", time.Now())
+	return s.TaskRepository.FetchTaskComments(ctx, taskID, limit)
+}
+
+func (s timedTasks) AddTaskAttachment(ctx context.Context, attachment *model.TaskAttachment) error {
+	defer s.stats.observe("AddTaskAttachment", time.Now())
+	return s.TaskRepository.AddTaskAttachment(ctx, attachment)
+}
+
+func (s timedTasks) FetchTaskAttachments(ctx context.Context, taskID int) ([]model.TaskAttachment, error) {
+	defer s.stats.observe("FetchTaskAttachments", time.Now())
+	return s.TaskRepository.FetchTaskAttachments(ctx, taskID)
+}
+
+func (s timedTasks) FetchTaskAttachment(ctx context.Context, id int) (*model.TaskAttachment, error) {
+	defer s.stats.observe("FetchTaskAttachment", time.Now())
+	return s.TaskRepository.FetchTaskAttachment(ctx, id)
+}
+
+func (s timedTasks) SetTaskWatcher(ctx context.Context, taskID int, userID int64, watching bool) error {
+	defer s.stats.observe("SetTaskWatcher", time.Now())
+	return s.TaskRepository.SetTaskWatcher(ctx, taskID, userID, watching)
+}
+
+func (s timedTasks) FetchTaskWatchers(ctx context.Context, taskID int) ([]int64, error) {
+	defer s.stats.observe("FetchTaskWatchers", time.Now())
+	return s.TaskRepository.FetchTaskWatchers(ctx, taskID)
+}
+
+func (s timedTasks) CountTaskLabels(ctx context.Context, projectID int) ([]model.LabelCount, error) {
+	defer s.stats.observe("CountTaskLabels", time.Now())
+	return s.TaskRepository.CountTaskLabels(ctx, projectID)
+}
+
+func (s timedTasks) CreateAlertTask(ctx context.Context, task *model.Task, fingerprint string) error {
+	defer s.stats.observe("CreateAlertTask", time.Now())
+	return s.TaskRepository.CreateAlertTask(ctx, task, fingerprint)
+}
+
+func (s timedTasks) FetchAlertTask(ctx context.Context, projectID int, fingerprint string) (*model.Task, error) {
+	defer s.stats.observe("FetchAlertTask", time.Now())
+	return s.TaskRepository.FetchAlertTask(ctx, projectID, fingerprint)
+}
+
+func (s timedTasks) SetTaskBlockers(ctx context.Context, taskID int, blockerIDs []int) error {
+	defer s.stats.observe("SetTaskBlockers", time.Now())
+	return s.TaskRepository.SetTaskBlockers(ctx, taskID, blockerIDs)
+}
+
+func (s timedTasks) FetchTaskBlockers(ctx context.Context, taskID int) ([]model.Task, error) {
+	defer s.stats.observe("FetchTaskBlockers", time.Now())
+	return s.TaskRepository.FetchTaskBlockers(ctx, taskID)
+}
+
+func (s timedTasks) FetchBlockedTasks(ctx context.Context, blockerID int) ([]model.Task, error) {
+	defer s.stats.observe("FetchBlockedTasks", time.Now())
+	return s.TaskRepository.FetchBlockedTasks(ctx, blockerID)
+}
+
+func (s timedTasks) SetTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence) error {
+	defer s.stats.observe("SetTaskRecurrence", time.Now())
+	return s.TaskRepository.SetTaskRecurrence(ctx, rec)
+}
+
+func (s timedTasks) RemoveTaskRecurrence(ctx context.Context, taskID int) error {
+	defer s.stats.observe("RemoveTaskRecurrence", time.Now())
+	return s.TaskRepository.RemoveTaskRecurrence(ctx, taskID)
+}
+
+func (s timedTasks) FetchTaskRecurrence(ctx context.Context, taskID int) (*model.TaskRecurrence, error) {
+	defer s.stats.observe("FetchTaskRecurrence", time.Now())
+	return s.TaskRepository.FetchTaskRecurrence(ctx, taskID)
+}
+
+func (s timedTasks) FetchProjectRecurrences(ctx context.Context, projectID int) ([]model.TaskRecurrence, error) {
+	defer s.stats.observe("FetchProjectRecurrences", time.Now())
+	return s.TaskRepository.FetchProjectRecurrences(ctx, projectID)
+}
+
+func (s timedTasks) FetchDueRecurrences(ctx context.Context, now time.Time) ([]model.TaskRecurrence, error) {
+	defer s.stats.observe("FetchDueRecurrences", time.Now())
+	return s.TaskRepository.FetchDueRecurrences(ctx, now)
+}
+
+func (s timedTasks) RenewTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence, next *model.Task) error {
+	defer s.stats.observe("RenewTaskRecurrence", time.Now())
+	return s.TaskRepository.RenewTaskRecurrence(ctx, rec, next)
+}
+
+func (s timedTasks) SaveTaskTemplate(ctx context.Context, tpl *model.TaskTemplate) error {
+	defer s.stats.observe("SaveTaskTemplate", time.Now())
+	return s.TaskRepository.SaveTaskTemplate(ctx, tpl)
+}
+
+func (s timedTasks) FetchTaskTemplates(ctx context.Context, projectID int) ([]model.TaskTemplate, error) {
+	defer s.stats.observe("FetchTaskTemplates", time.Now())
+	return s.TaskRepository.FetchTaskTemplates(ctx, projectID)
+}
+
+func (s timedTasks) FetchTaskTemplate(ctx context.Context, id int) (*model.TaskTemplate, error) {
+	defer s.stats.observe("FetchTaskTemplate", time.Now())
+	return s.TaskRepository.FetchTaskTemplate(ctx, id)
+}
+
+func (s timedTasks) RemoveTaskTemplate(ctx context.Context, id int) error {
+	defer s.stats.observe("RemoveTaskTemplate", time.Now())
+	return s.TaskRepository.RemoveTaskTemplate(ctx, id)
+}
+
+func (s timedTasks) SnapshotTaskCounts(ctx context.Context, day time.Time) error {
+	defer s.stats.observe("SnapshotTaskCounts", time.Now())
+	return s.TaskRepository.SnapshotTaskCounts(ctx, day)
+}
+
+func (s timedTasks) FetchTaskSnapshots(ctx context.Context, projectID int, from, to time.Time) ([]model.TaskSnapshot, error) {
+	defer s.stats.observe("FetchTaskSnapshots", time.Now())
+	return s.TaskRepository.FetchTaskSnapshots(ctx, projectID, from, to)
+}
+
+func (s timedTasks) FetchTaskHistory(ctx context.Context, taskID int, limit int) ([]model.TaskChange, int, error) {
+	defer s.stats.observe("FetchTaskHistory", time.Now())
+	return s.TaskRepository.FetchTaskHistory(ctx, taskID, limit)
+}
+
+func (s timedTasks) MoveTask(ctx context.Context, id int, move model.TaskMove) error {
+	defer s.stats.observe("MoveTask", time.Now())
+	return s.TaskRepository.MoveTask(ctx, id, move)
+}
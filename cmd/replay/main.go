@@ -0,0 +1,114 @@
+// Command replay feeds updates recorded with -record-updates through bot handlers against scratch database
+// and fake Telegram API, printing every request bot makes.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/agalitsyn/sqlite"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/app"
+	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/telegramtest"
+	"github.com/agalitsyn/telegram-tasks-bot/migrations"
+)
+
+func main() {
+	updatesPath := flag.String("updates", "", "File with recorded updates.")
+	dbPath := flag.String("db", "", "Database to start from, it is copied and never changed. Empty database is used if not set.")
+	dbKey := flag.String("db-key", "", "Key of database encrypted with SQLCipher, scratch copy is encrypted with it too.")
+	botUsername := flag.String("bot-username", "", "Username of recorded bot, needed for commands addressed to it by mention.")
+	flag.Parse()
+
+	if *updatesPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*updatesPath, *dbPath, *dbKey, *botUsername); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(updatesPath, srcDBPath, dbKey, botUsername string) error {
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "replay")
+	if err != nil {
+		return fmt.Errorf("could not create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	dbPath := filepath.Join(dir, "db.sqlite3")
+
+	if srcDBPath != "" {
+		src, err := sqliteStorage.ConnectEncrypted(srcDBPath, dbKey)
+		if err != nil {
+			return err
+		}
+		err = sqliteStorage.NewMaintenanceStorage(src).CopyTo(ctx, dbPath)
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("could not copy database: %w", err)
+		}
+	}
+
+	db, err := sqliteStorage.ConnectEncrypted(dbPath, dbKey)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+		return fmt.Errorf("could not apply migrations: %w", err)
+	}
+	if err = sqliteStorage.ValidateSchema(ctx, db, migrations.FS); err != nil {
+		return fmt.Errorf("unexpected database schema: %w", err)
+	}
+
+	tg := telegramtest.NewServer()
+	defer tg.Close()
+	if botUsername != "" {
+		tg.Username = botUsername
+	}
+	tg.OnRequest = func(method string, params url.Values) {
+		fmt.Printf("→ %s %v\n", method, params)
+	}
+
+	bot, err := app.NewBot(
+		app.BotConfig{APIEndpoint: tg.Endpoint()},
+		"replay",
+		log.Default(),
+		sqliteStorage.NewProjectStorage(db, db),
+		sqliteStorage.NewUserStorage(db, db),
+		sqliteStorage.NewTaskStorage(db, db),
+	)
+	if err != nil {
+		return fmt.Errorf("could not init bot: %w", err)
+	}
+
+	f, err := os.Open(updatesPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var update tgbotapi.Update
+		if err = json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			return fmt.Errorf("could not decode update: %w", err)
+		}
+		fmt.Printf("# update %d\n", update.UpdateID)
+		bot.HandleUpdate(ctx, update)
+	}
+	return scanner.Err()
+}
@@ -9,6 +9,7 @@ require (
 	github.com/fatih/color v1.17.0
 	github.com/go-pkgz/lgr v0.11.1
 	github.com/go-telegram-bot-api/telegram-bot-api/v5 v5.5.1
+	github.com/mutecomm/go-sqlcipher/v4 v4.4.2
 )
 
 require (
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// alertLabel is added to tasks created for alerts, so they are found with "label=alert".
+const alertLabel = "alert"
+
+// alertmanagerPayload is body of Prometheus Alertmanager webhook, only fields used for tasks are decoded.
+type alertmanagerPayload struct {
+	Alerts []alert `json:"alerts"`
+}
+
+type alert struct {
+	// Status is "firing" or "resolved".
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// alertPriorities maps "severity" label of Prometheus and Zabbix alerts to task priority, other values are normal.
+var alertPriorities = map[string]model.TaskPriority{
+	"critical":    model.TaskPriorityUrgent,
+	"disaster":    model.TaskPriorityUrgent,
+	"high":        model.TaskPriorityHigh,
+	"average":     model.TaskPriorityHigh,
+	"error":       model.TaskPriorityHigh,
+	"info":        model.TaskPriorityLow,
+	"information": model.TaskPriorityLow,
+}
+
+// receiveAlerts creates task for every firing alert which has no open task yet and marks task done when its alert is resolved.
+func (h *Handler) receiveAlerts(w http.ResponseWriter, r *http.Request, prj *model.Project, token *model.APIToken) {
+	var payload alertmanagerPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("could not decode request: %s", err))
+		return
+	}
+	if prj.Frozen {
+		writeError(w, http.StatusConflict, "project is frozen")
+		return
+	}
+
+	ctx := r.Context()
+	var created, resolved int
+	for _, a := range payload.Alerts {
+		fingerprint := a.fingerprint()
+		task, err := h.taskStorage.FetchAlertTask(ctx, prj.ID, fingerprint)
+		if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+			internalError(w, "could not fetch alert task", err)
+			return
+		}
+		open := task != nil && task.Status.IsOpen()
+
+		switch {
+		case a.Status == "resolved" && open:
+			if err = h.tasks.CloseTaskFromAPI(ctx, prj, task, token.CreatedBy, "алерт больше не активен"); err != nil {
+				internalError(w, "could not close alert task", err)
+				return
+			}
+			resolved++
+		case a.Status == "firing" && !open:
+			task = a.task(prj.ID, token.CreatedBy)
+			err = h.tasks.CreateAlertTaskFromAPI(ctx, prj, task, fingerprint)
+			if errors.Is(err, model.ErrAlertTaskExists) {
+				// Concurrent delivery of the same alert has created task
+				continue
+			} else if err != nil {
+				internalError(w, "could not create alert task", err)
+				return
+			}
+			created++
+		}
+	}
+	if created > 0 || resolved > 0 {
+		log.Printf("INFO api: token id=%d created %d and resolved %d alert tasks in project id=%d", token.ID, created, resolved, prj.ID)
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"created": created, "resolved": resolved})
+}
+
+// fingerprint identifies alert between notifications, it is computed from labels if sender doesn't provide it.
+func (a alert) fingerprint() string {
+	if a.Fingerprint != "" {
+		return a.Fingerprint
+	}
+	hash := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(a.Labels)) {
+		fmt.Fprintf(hash, "%s=%s\n", name, a.Labels[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (a alert) task(projectID int, createdBy int64) *model.Task {
+	title := a.Labels["alertname"]
+	if summary := a.Annotations["summary"]; summary != "" {
+		title = strings.TrimSpace(title + ": " + summary)
+	} else if instance := a.Labels["instance"]; instance != "" {
+		title += " " + instance
+	}
+	title = strings.TrimPrefix(title, ": ")
+	if title == "" {
+		title = "алерт без названия"
+	}
+
+	var sb strings.Builder
+	if description := a.Annotations["description"]; description != "" {
+		sb.WriteString(description + "\n\n")
+	}
+	sb.WriteString("Метки алерта:\n")
+	for _, name := range slices.Sorted(maps.Keys(a.Labels)) {
+		fmt.Fprintf(&sb, "%s=%s\n", name, a.Labels[name])
+	}
+	if a.GeneratorURL != "" {
+		fmt.Fprintf(&sb, "\nИсточник: %s", a.GeneratorURL)
+	}
+
+	task := model.NewTask(projectID, title, createdBy)
+	task.Description = strings.TrimSpace(sb.String())
+	task.Labels = []string{alertLabel}
+	task.Priority = model.TaskPriorityNormal
+	if priority, ok := alertPriorities[strings.ToLower(a.Labels["severity"])]; ok {
+		task.Priority = priority
+	}
+	return task
+}
@@ -0,0 +1,272 @@
+// Package api serves project tasks as JSON to automation authorized with project API tokens.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// PathPrefix is the root of API, requests carry token in "Authorization: Bearer" header.
+const PathPrefix = "/api/"
+
+const (
+	// touchInterval limits how often use of token is saved, so reads don't turn into writes.
+	touchInterval = time.Minute
+	maxBodySize   = 64 << 10
+)
+
+// TaskManager changes tasks on behalf of user issued the token and tells project chat about it.
+type TaskManager interface {
+	// CreateTaskFromAPI returns model.ErrUserNotFound if assignee is not a project member.
+	CreateTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, assignee string) error
+	// CreateAlertTaskFromAPI returns model.ErrAlertTaskExists if alert with fingerprint already has open task.
+	CreateAlertTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, fingerprint string) error
+	// CloseTaskFromAPI marks task done, reason is shown in project chat.
+	CloseTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, actorID int64, reason string) error
+}
+
+type Handler struct {
+	mux            *http.ServeMux
+	tokenStorage   model.APITokenRepository
+	projectStorage model.ProjectRepository
+	taskStorage    model.TaskRepository
+	userStorage    model.UserRepository
+	tasks          TaskManager
+}
+
+func NewHandler(
+	tokenStorage model.APITokenRepository,
+	projectStorage model.ProjectRepository,
+	taskStorage model.TaskRepository,
+	userStorage model.UserRepository,
+	tasks TaskManager,
+) *Handler {
+	h := &Handler{
+		mux:            http.NewServeMux(),
+		tokenStorage:   tokenStorage,
+		projectStorage: projectStorage,
+		taskStorage:    taskStorage,
+		userStorage:    userStorage,
+		tasks:          tasks,
+	}
+	h.mux.HandleFunc("GET "+PathPrefix+"v1/tasks", h.authorized(model.APITokenScopeRead, h.listTasks))
+	h.mux.HandleFunc("POST "+PathPrefix+"v1/tasks", h.authorized(model.APITokenScopeReadWrite, h.createTask))
+	h.mux.HandleFunc("GET "+PathPrefix+"v1/stats", h.authorized(model.APITokenScopeRead, h.listStats))
+	h.mux.HandleFunc("POST "+PathPrefix+"v1/alertmanager", h.authorized(model.APITokenScopeReadWrite, h.receiveAlerts))
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// projectHandlerFunc handles request to project token is issued for.
+type projectHandlerFunc func(w http.ResponseWriter, r *http.Request, prj *model.Project, token *model.APIToken)
+
+// authorized passes request to next if it carries token with scope allowing the request.
+func (h *Handler) authorized(scope model.APITokenScope, next projectHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || raw == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+		token, err := h.tokenStorage.FetchAPITokenByHash(ctx, model.HashAPIToken(strings.TrimSpace(raw)))
+		if err != nil && errors.Is(err, model.ErrAPITokenNotFound) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "invalid or revoked token")
+			return
+		} else if err != nil {
+			internalError(w, "could not fetch token", err)
+			return
+		}
+		if !token.Scope.Allows(scope) {
+			writeError(w, http.StatusForbidden, "token scope does not allow this request")
+			return
+		}
+
+		prj, err := h.projectStorage.FetchProjectByID(ctx, token.ProjectID)
+		if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+			writeError(w, http.StatusUnauthorized, "project of token is deleted")
+			return
+		} else if err != nil {
+			internalError(w, "could not fetch project", err)
+			return
+		}
+
+		if now := time.Now(); now.Sub(token.LastUsedAt) >= touchInterval {
+			if err = h.tokenStorage.TouchAPIToken(ctx, token.ID, now); err != nil {
+				log.Printf("WARN api: could not record use of token id=%d: %s", token.ID, err)
+			}
+		}
+		next(w, r, prj, token)
+	}
+}
+
+type taskView struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status"`
+	Priority    string   `json:"priority"`
+	Estimate    int      `json:"estimate,omitempty"`
+	Deadline    string   `json:"deadline,omitempty"`
+	Assignee    string   `json:"assignee,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Epic        bool     `json:"epic,omitempty"`
+	ParentID    int      `json:"parent_id,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+// listTasks returns tasks of project, they are filtered by "status" and "label" parameters,
+// "open=true" excludes done and cancelled tasks.
+func (h *Handler) listTasks(w http.ResponseWriter, r *http.Request, prj *model.Project, _ *model.APIToken) {
+	query := r.URL.Query()
+	filter := model.TaskFilter{
+		ProjectID: prj.ID,
+		Status:    model.TaskStatus(query.Get("status")),
+		Label:     strings.ToLower(strings.TrimPrefix(query.Get("label"), "#")),
+		OnlyOpen:  query.Get("open") == "true",
+	}
+	if filter.Status != "" && !slices.Contains(model.TaskStatuses, filter.Status) {
+		writeError(w, http.StatusBadRequest, "unknown status")
+		return
+	}
+	tasks, err := h.taskStorage.FilterTasks(r.Context(), filter)
+	if err != nil {
+		internalError(w, "could not fetch tasks", err)
+		return
+	}
+
+	views := make([]taskView, 0, len(tasks))
+	assignees := make(map[int64]string)
+	for _, task := range tasks {
+		view, err := h.taskView(r.Context(), task, assignees)
+		if err != nil {
+			internalError(w, "could not fetch assignee", err)
+			return
+		}
+		views = append(views, view)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": views})
+}
+
+type createTaskRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Assignee is username of project member, leading @ is optional.
+	Assignee string `json:"assignee"`
+	// Deadline is date in YYYY-MM-DD format, task is due by the end of the day.
+	Deadline string `json:"deadline"`
+}
+
+// createTask creates task from JSON, e.g. sent by monitoring alert or form, and answers with created task.
+func (h *Handler) createTask(w http.ResponseWriter, r *http.Request, prj *model.Project, token *model.APIToken) {
+	var req createTaskRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("could not decode request: %s", err))
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		writeError(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+	if prj.Frozen {
+		writeError(w, http.StatusConflict, "project is frozen")
+		return
+	}
+
+	task := model.NewTask(prj.ID, req.Title, token.CreatedBy)
+	task.Description = strings.TrimSpace(req.Description)
+	if req.Deadline != "" {
+		deadline, err := time.ParseInLocation(time.DateOnly, req.Deadline, time.Local)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "deadline must be date in YYYY-MM-DD format")
+			return
+		}
+		task.Deadline = deadline
+	}
+
+	err := h.tasks.CreateTaskFromAPI(r.Context(), prj, task, strings.TrimPrefix(strings.TrimSpace(req.Assignee), "@"))
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		writeError(w, http.StatusUnprocessableEntity, "assignee is not a project member")
+		return
+	} else if err != nil {
+		internalError(w, "could not create task", err)
+		return
+	}
+	log.Printf("INFO api: token id=%d created task id=%d in project id=%d", token.ID, task.ID, prj.ID)
+
+	view, err := h.taskView(r.Context(), *task, make(map[int64]string))
+	if err != nil {
+		internalError(w, "could not fetch assignee", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"task": view})
+}
+
+// taskView converts task to JSON view, assignees caches usernames by user id.
+func (h *Handler) taskView(ctx context.Context, task model.Task, assignees map[int64]string) (taskView, error) {
+	view := taskView{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Priority:    string(task.Priority),
+		Estimate:    task.Estimate,
+		Labels:      task.Labels,
+		Epic:        task.Epic,
+		ParentID:    task.ParentID,
+	}
+	if !task.Deadline.IsZero() {
+		view.Deadline = task.Deadline.Format(time.DateOnly)
+	}
+	if !task.UpdatedAt.IsZero() {
+		view.UpdatedAt = task.UpdatedAt.Format(time.RFC3339)
+	}
+	if task.Assignee != 0 {
+		username, ok := assignees[task.Assignee]
+		if !ok {
+			user, err := h.userStorage.FetchUserByID(ctx, int(task.Assignee))
+			if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+				return taskView{}, err
+			}
+			if user != nil {
+				username = user.Username
+			}
+			assignees[task.Assignee] = username
+		}
+		view.Assignee = username
+	}
+	return view, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("WARN api: could not write response: %s", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func internalError(w http.ResponseWriter, msg string, err error) {
+	log.Printf("ERROR api: %s: %s", msg, err)
+	writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+type snapshotView struct {
+	Date     string         `json:"date"`
+	Open     int            `json:"open"`
+	Closed   int            `json:"closed"`
+	Statuses map[string]int `json:"statuses"`
+}
+
+// listStats returns daily counts of project's tasks by status for the last "days" days, e.g. for burndown chart.
+// Days without snapshot are omitted.
+func (h *Handler) listStats(w http.ResponseWriter, r *http.Request, prj *model.Project, _ *model.APIToken) {
+	days := defaultStatsDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		var err error
+		if days, err = strconv.Atoi(raw); err != nil || days <= 0 || days > maxStatsDays {
+			writeError(w, http.StatusBadRequest, "days must be number from 1 to "+strconv.Itoa(maxStatsDays))
+			return
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	snapshots, err := h.taskStorage.FetchTaskSnapshots(r.Context(), prj.ID, today.AddDate(0, 0, 1-days), today)
+	if err != nil {
+		internalError(w, "could not fetch snapshots", err)
+		return
+	}
+
+	views := make([]snapshotView, len(snapshots))
+	for i, snapshot := range snapshots {
+		statuses := make(map[string]int, len(snapshot.Counts))
+		for status, count := range snapshot.Counts {
+			statuses[string(status)] = count
+		}
+		views[i] = snapshotView{
+			Date:     snapshot.Day.Format(time.DateOnly),
+			Open:     snapshot.Open(),
+			Closed:   snapshot.Closed(),
+			Statuses: statuses,
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"days": views})
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// menuCommandPattern is command name Telegram accepts in command menu and highlights in messages,
+// other aliases, e.g. Cyrillic ones, work only when typed.
+var menuCommandPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// resolveCommandAlias rewrites message starting with alias, e.g. "/т отчёт", to the command it stands for.
+// Aliases addressed to other bots are left as is.
+func (b *Bot) resolveCommandAlias(message *tgbotapi.Message) {
+	if len(b.cfg.CommandAliases) == 0 || !strings.HasPrefix(message.Text, "/") {
+		return
+	}
+	word := message.Text
+	if i := strings.IndexFunc(word, unicode.IsSpace); i >= 0 {
+		word = word[:i]
+	}
+	alias, mention, _ := strings.Cut(word[1:], "@")
+	if mention != "" && !strings.EqualFold(mention, b.Self.UserName) {
+		return
+	}
+	command, ok := b.cfg.CommandAliases[strings.ToLower(alias)]
+	if !ok {
+		return
+	}
+
+	resolved := "/" + command
+	shift := utf16Len(resolved) - utf16Len(word)
+	entities := []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: utf16Len(resolved)}}
+	for _, entity := range message.Entities {
+		if entity.Offset == 0 && entity.Type == "bot_command" {
+			continue
+		}
+		entity.Offset += shift
+		entities = append(entities, entity)
+	}
+	message.Text = resolved + message.Text[len(word):]
+	message.Entities = entities
+}
+
+// withAliases appends aliases of listed commands which Telegram accepts in command menu,
+// they are described as commands they stand for.
+func (b *Bot) withAliases(commands []tgbotapi.BotCommand) []tgbotapi.BotCommand {
+	res := slices.Clone(commands)
+	for _, alias := range slices.Sorted(maps.Keys(b.cfg.CommandAliases)) {
+		command := b.cfg.CommandAliases[alias]
+		i := slices.IndexFunc(commands, func(cmd tgbotapi.BotCommand) bool { return cmd.Command == command })
+		if i < 0 || !menuCommandPattern.MatchString(alias) {
+			continue
+		}
+		res = append(res, tgbotapi.BotCommand{
+			Command:     alias,
+			Description: fmt.Sprintf("%s (/%s)", commands[i].Description, command),
+		})
+	}
+	return res
+}
+
+// renderAliases returns line listing aliases for help, empty if there are none.
+func (b *Bot) renderAliases() string {
+	aliases := slices.Sorted(maps.Keys(b.cfg.CommandAliases))
+	if len(aliases) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(aliases))
+	for i, alias := range aliases {
+		pairs[i] = fmt.Sprintf("/%s → /%s", alias, b.cfg.CommandAliases[alias])
+	}
+	return "\tСокращения: " + strings.Join(pairs, ", ") + "\n"
+}
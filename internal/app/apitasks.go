@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CreateTaskFromAPI creates task sent by external system, e.g. monitoring alert, and posts its card to project chat.
+// Long title is shortened and kept whole in description, deadline date is moved to the end of the day.
+func (b *Bot) CreateTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, assignee string) error {
+	if assignee != "" {
+		user, err := b.fetchProjectUserByUsername(ctx, prj.ID, assignee)
+		if err != nil && errors.Is(err, model.ErrUserNotFound) {
+			return fmt.Errorf("%w: @%s", err, assignee)
+		} else if err != nil {
+			return fmt.Errorf("could not fetch user by username: %w", err)
+		}
+		task.Assignee = int64(user.ID)
+	}
+	prepareTaskFromAPI(prj, task)
+	if err := b.createTask(ctx, task, int(task.CreatedBy)); err != nil {
+		return err
+	}
+	return b.postTaskFromAPI(ctx, prj, task)
+}
+
+// CreateAlertTaskFromAPI creates task for monitoring alert with fingerprint like CreateTaskFromAPI,
+// model.ErrAlertTaskExists is returned if the alert already has open task.
+func (b *Bot) CreateAlertTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, fingerprint string) error {
+	prepareTaskFromAPI(prj, task)
+	if err := b.taskStorage.CreateAlertTask(ctx, task, fingerprint); err != nil {
+		return fmt.Errorf("could not create task: %w", err)
+	}
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventCreated, Task: *task, ActorID: int(task.CreatedBy)})
+	return b.postTaskFromAPI(ctx, prj, task)
+}
+
+func prepareTaskFromAPI(prj *model.Project, task *model.Task) {
+	if utf8.RuneCountInString(task.Title) > titleMaxLen {
+		task.Description = strings.TrimSpace(task.Title + "\n" + task.Description)
+		task.Title = shortenTitle(task.Title, titleMaxLen)
+	}
+	task.Status = model.TaskStatusTODO
+	if task.Deadline.IsZero() {
+		task.Deadline = defaultDeadline(prj, time.Now())
+	} else {
+		task.Deadline = endOfDay(task.Deadline)
+	}
+}
+
+// postTaskFromAPI posts card of created task to project chat.
+func (b *Bot) postTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task) error {
+	card, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(prj.TgChatID, "🔌 задача из внешней системы\n\n"+card)
+	msg.ReplyMarkup = taskCardKeyboard(task)
+	if _, err = b.sendMessage(msg); err != nil {
+		// Task is saved, so chat will see it on the board anyway
+		log.Printf("WARN could not post task id=%d created via api: %s", task.ID, err)
+	}
+	return nil
+}
+
+// CloseTaskFromAPI marks task done on behalf of user with actorID, e.g. when monitoring alert is resolved,
+// and tells project chat about it with the reason.
+func (b *Bot) CloseTaskFromAPI(ctx context.Context, prj *model.Project, task *model.Task, actorID int64, reason string) error {
+	prev := task.Clone()
+	task.Status = model.TaskStatusDone
+	task.UpdatedBy = actorID
+	if err := b.updateWatchedTask(ctx, prev, task, int(actorID)); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG task id=%d is closed via api: %s", task.ID, reason)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: int(actorID)})
+
+	text := fmt.Sprintf("🔌 задача #%d %s закрыта: %s", task.ID, task.Title, reason)
+	if _, err := b.sendMessage(tgbotapi.NewMessage(prj.TgChatID, text)); err != nil {
+		log.Printf("WARN could not post closing of task id=%d via api: %s", task.ID, err)
+	}
+	return nil
+}
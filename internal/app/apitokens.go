@@ -0,0 +1,210 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/api"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// callbackIssueAPIToken is followed by scope, e.g. "issue_api_token_read".
+	callbackIssueAPIToken  = "issue_api_token_"
+	callbackRevokeAPIToken = "revoke_api_token_"
+
+	maxProjectAPITokens = 10
+	apiTokenPrefixLen   = 6
+)
+
+// apiTokensCommand shows API tokens of project with buttons issuing and revoking them, allowed for managers.
+func (b *Bot) apiTokensCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, _, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+	if b.cfg.APITokens == nil || b.cfg.PublicURL == "" {
+		return b.reply(update.Message, "API не настроен на этом сервере")
+	}
+
+	tokens, err := b.cfg.APITokens.ListAPITokens(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not list api tokens: %w", err)
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, b.renderAPITokens(tokens))
+	msg.ReplyMarkup = apiTokensKeyboard(tokens)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+func (b *Bot) renderAPITokens(tokens []model.APIToken) string {
+	var sb strings.Builder
+	sb.WriteString("🔑 API-токены проекта\n\n")
+	if len(tokens) == 0 {
+		sb.WriteString("Токенов нет.\n")
+	}
+	for _, token := range tokens {
+		used := "не использовался"
+		if !token.LastUsedAt.IsZero() {
+			used = "использован " + token.LastUsedAt.Format(format.DateTimeLayout)
+		}
+		fmt.Fprintf(&sb, "• %s… — %s, выпущен %s, %s\n",
+			token.Prefix, token.Scope.StringLocalized(), token.CreatedAt.Format(format.DateLayout), used)
+	}
+	fmt.Fprintf(&sb, "\nТокен передаётся в заголовке «Authorization: Bearer <токен>» запросов к %s%s. "+
+		"Новый токен бот пришлёт менеджеру в личные сообщения.",
+		strings.TrimSuffix(b.cfg.PublicURL, "/"), api.PathPrefix)
+	return sb.String()
+}
+
+func apiTokensKeyboard(tokens []model.APIToken) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, token := range tokens {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🗑 Отозвать %s…", token.Prefix), fmt.Sprintf("%s%d", callbackRevokeAPIToken, token.ID),
+		)))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Только чтение", callbackIssueAPIToken+string(model.APITokenScopeRead)),
+		tgbotapi.NewInlineKeyboardButtonData("➕ Чтение и запись", callbackIssueAPIToken+string(model.APITokenScopeReadWrite)),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// issueAPITokenCallback sends new token to manager in private chat, so it is not seen by other chat members.
+func (b *Bot) issueAPITokenCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	scope := model.APITokenScope(strings.TrimPrefix(query.Data, callbackIssueAPIToken))
+	if scope != model.APITokenScopeRead && scope != model.APITokenScopeReadWrite {
+		return fmt.Errorf("could not parse callback data %q", query.Data)
+	}
+	prj, user, ok, err := b.fetchAPITokensManager(ctx, query)
+	if err != nil || !ok {
+		return err
+	}
+	if user.Unreachable {
+		return b.answerCallback(query.ID, "токен приходит в личные сообщения: сначала напишите боту /start в личном чате")
+	}
+
+	tokens, err := b.cfg.APITokens.ListAPITokens(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not list api tokens: %w", err)
+	}
+	if len(tokens) >= maxProjectAPITokens {
+		return b.answerCallback(query.ID, fmt.Sprintf("у проекта может быть не больше %d токенов, отзовите ненужные", maxProjectAPITokens))
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("could not generate token: %w", err)
+	}
+	token := &model.APIToken{
+		ProjectID: prj.ID,
+		Hash:      model.HashAPIToken(secret),
+		Prefix:    secret[:apiTokenPrefixLen],
+		Scope:     scope,
+		CreatedBy: int64(user.ID),
+	}
+	if err = b.cfg.APITokens.CreateAPIToken(ctx, token); err != nil {
+		return fmt.Errorf("could not create api token: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(b.cfg.PublicURL, "/") + api.PathPrefix + "v1/tasks"
+	text := fmt.Sprintf(
+		"🔑 API-токен проекта «%s» (%s):\n\n%s\n\n"+
+			"Пример запроса:\ncurl -H 'Authorization: Bearer %s' %s\n\n"+
+			"Токен показывается один раз, храните его как пароль. Отозвать токен можно командой /api_tokens в чате проекта.",
+		prj.Title, scope.StringLocalized(), secret, secret, endpoint,
+	)
+	if _, err = b.sendMessage(tgbotapi.NewMessage(query.From.ID, text)); err != nil {
+		// Token nobody has seen is useless
+		if revokeErr := b.cfg.APITokens.RevokeAPIToken(ctx, prj.ID, token.ID); revokeErr != nil {
+			log.Printf("ERROR could not revoke undelivered api token id=%d: %s", token.ID, revokeErr)
+		}
+		if classifyTelegramError(err) == telegramErrorUnreachable {
+			return b.answerCallback(query.ID, "токен приходит в личные сообщения: сначала напишите боту /start в личном чате")
+		}
+		return fmt.Errorf("could not send api token: %w", err)
+	}
+	log.Printf("INFO user id=%d issued api token id=%d with scope %s for project id=%d", user.ID, token.ID, scope, prj.ID)
+	b.publishProjectChange(ctx, prj, user, fmt.Sprintf("выпущен API-токен %s… (%s)", token.Prefix, scope.StringLocalized()))
+
+	if err = b.refreshAPITokens(ctx, query, prj); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "🔑 токен отправлен вам в личные сообщения")
+}
+
+// revokeAPITokenCallback deletes token, requests with it are refused right away.
+func (b *Bot) revokeAPITokenCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	tokenID, err := parseCallbackID(query.Data, callbackRevokeAPIToken)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	prj, user, ok, err := b.fetchAPITokensManager(ctx, query)
+	if err != nil || !ok {
+		return err
+	}
+
+	var prefix string
+	tokens, err := b.cfg.APITokens.ListAPITokens(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not list api tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if token.ID == tokenID {
+			prefix = token.Prefix
+		}
+	}
+	err = b.cfg.APITokens.RevokeAPIToken(ctx, prj.ID, tokenID)
+	if err != nil && errors.Is(err, model.ErrAPITokenNotFound) {
+		return b.answerCallback(query.ID, "токен уже отозван")
+	} else if err != nil {
+		return fmt.Errorf("could not revoke api token: %w", err)
+	}
+	log.Printf("INFO user id=%d revoked api token id=%d of project id=%d", user.ID, tokenID, prj.ID)
+	b.publishProjectChange(ctx, prj, user, fmt.Sprintf("отозван API-токен %s…", prefix))
+
+	if err = b.refreshAPITokens(ctx, query, prj); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "токен отозван")
+}
+
+func (b *Bot) refreshAPITokens(ctx context.Context, query *tgbotapi.CallbackQuery, prj *model.Project) error {
+	tokens, err := b.cfg.APITokens.ListAPITokens(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not list api tokens: %w", err)
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(
+		query.Message.Chat.ID, query.Message.MessageID, b.renderAPITokens(tokens), apiTokensKeyboard(tokens),
+	)
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return nil
+}
+
+// fetchAPITokensManager returns chat's project if callback is pressed by its manager,
+// otherwise it answers callback with explanation.
+func (b *Bot) fetchAPITokensManager(ctx context.Context, query *tgbotapi.CallbackQuery) (*model.Project, *model.User, bool, error) {
+	if query.Message == nil || b.cfg.APITokens == nil {
+		return nil, nil, false, b.answerCallback(query.ID, "")
+	}
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, false, b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if user.Role != model.UserProjectRoleManager {
+		return nil, nil, false, b.answerCallback(query.ID, "токенами управляет менеджер проекта")
+	}
+	return prj, user, true, nil
+}
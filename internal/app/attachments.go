@@ -0,0 +1,203 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackShowAttachments = "show_attachments_"
+	callbackAddAttachment   = "add_attachment_"
+	callbackSendAttachment  = "send_attachment_"
+
+	maxTaskAttachments      = 10
+	attachmentButtonNameLen = 40
+)
+
+// attachmentPromptRe matches bot's prompt for attachment, reply to it carries photo or document.
+var attachmentPromptRe = regexp.MustCompile(`фото или документом для задачи #(\d+)`)
+
+func attachmentsButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📎 Вложения", fmt.Sprintf("%s%d", callbackShowAttachments, task.ID))
+}
+
+// renderAttachments returns list of attachments with buttons sending them to chat.
+func renderAttachments(task *model.Task, attachments []model.TaskAttachment) (string, tgbotapi.InlineKeyboardMarkup) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	photos := 0
+	for _, attachment := range attachments {
+		name := attachment.FileName
+		if runes := []rune(name); len(runes) > attachmentButtonNameLen {
+			name = string(runes[:attachmentButtonNameLen-1]) + "…"
+		}
+		name = "📄 " + name
+		if attachment.Kind == model.AttachmentKindPhoto {
+			photos++
+			name = fmt.Sprintf("🖼 Фото %d", photos)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(name, fmt.Sprintf("%s%d", callbackSendAttachment, attachment.ID)),
+		))
+	}
+	if len(attachments) < maxTaskAttachments {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Прикрепить файл", fmt.Sprintf("%s%d", callbackAddAttachment, task.ID)),
+		))
+	}
+
+	text := fmt.Sprintf("📎 у задачи #%d %s нет вложений", task.ID, task.Title)
+	if len(attachments) > 0 {
+		text = fmt.Sprintf("📎 вложения задачи #%d %s: %d, нажмите, чтобы открыть", task.ID, task.Title, len(attachments))
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// showAttachmentsCallback replies to task card with attachments of the task, allowed for project members.
+func (b *Bot) showAttachmentsCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackShowAttachments)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	attachments, err := b.taskStorage.FetchTaskAttachments(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch attachments: %w", err)
+	}
+	text, keyboard := renderAttachments(task, attachments)
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = keyboard
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send attachments: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// addAttachmentCallback asks member who pressed the button to reply with photo or document.
+func (b *Bot) addAttachmentCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackAddAttachment)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"📎 %s, ответьте на это сообщение фото или документом для задачи #%d", mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send attachment prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleAttachmentReply attaches photo or document from reply of any project member to the task,
+// it reports false if message is not a reply to attachment prompt.
+func (b *Bot) handleAttachmentReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	anyMember := func(*model.Task, *model.User) bool { return true }
+	task, user, handled, err := b.fetchPromptedTaskFor(ctx, message, attachmentPromptRe, anyMember)
+	if err != nil || task == nil {
+		return handled, err
+	}
+	if !task.Status.IsOpen() {
+		return true, b.reply(message, "задача уже закрыта")
+	}
+
+	attachment := &model.TaskAttachment{TaskID: task.ID, AddedBy: int64(user.ID)}
+	switch {
+	case len(message.Photo) > 0:
+		// Sizes are sorted from the smallest
+		attachment.Kind = model.AttachmentKindPhoto
+		attachment.FileID = message.Photo[len(message.Photo)-1].FileID
+	case message.Document != nil:
+		attachment.Kind = model.AttachmentKindDocument
+		attachment.FileID = message.Document.FileID
+		attachment.FileName = message.Document.FileName
+		if attachment.FileName == "" {
+			attachment.FileName = "документ"
+		}
+	default:
+		return true, b.reply(message, "ответьте на сообщение бота фото или документом")
+	}
+
+	attachments, err := b.taskStorage.FetchTaskAttachments(ctx, task.ID)
+	if err != nil {
+		return true, fmt.Errorf("could not fetch attachments: %w", err)
+	}
+	if len(attachments) >= maxTaskAttachments {
+		return true, b.reply(message, fmt.Sprintf("у задачи может быть не больше %d вложений", maxTaskAttachments))
+	}
+	if err = b.taskStorage.AddTaskAttachment(ctx, attachment); err != nil {
+		return true, fmt.Errorf("could not add attachment: %w", err)
+	}
+	log.Printf("DEBUG user id=%d attached %s id=%d to task id=%d", user.ID, attachment.Kind, attachment.ID, task.ID)
+
+	text, keyboard := renderAttachments(task, append(attachments, *attachment))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = keyboard
+	_, err = b.sendMessage(msg)
+	return true, err
+}
+
+// sendAttachmentCallback sends attachment to chat again, allowed for project members.
+func (b *Bot) sendAttachmentCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	attachmentID, err := parseCallbackID(query.Data, callbackSendAttachment)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	attachment, err := b.taskStorage.FetchTaskAttachment(ctx, attachmentID)
+	if err != nil && errors.Is(err, model.ErrAttachmentNotFound) {
+		return b.answerCallback(query.ID, "вложение удалено вместе с задачей")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch attachment: %w", err)
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, attachment.TaskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	caption := fmt.Sprintf("📎 к задаче #%d %s", task.ID, task.Title)
+	var file tgbotapi.Chattable
+	if attachment.Kind == model.AttachmentKindPhoto {
+		photo := tgbotapi.NewPhoto(query.Message.Chat.ID, tgbotapi.FileID(attachment.FileID))
+		photo.Caption = caption
+		photo.ReplyToMessageID = query.Message.MessageID
+		file = photo
+	} else {
+		doc := tgbotapi.NewDocument(query.Message.Chat.ID, tgbotapi.FileID(attachment.FileID))
+		doc.Caption = caption
+		doc.ReplyToMessageID = query.Message.MessageID
+		file = doc
+	}
+	if _, err = b.Send(file); err != nil {
+		return fmt.Errorf("could not send attachment id=%d: %w", attachment.ID, err)
+	}
+	return b.answerCallback(query.ID, "")
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	editFieldBlockers = "blockers"
+	maxTaskBlockers   = 10
+)
+
+// blockersPromptRe matches bot's prompt for blockers, reply to it carries numbers of blocking tasks.
+var blockersPromptRe = regexp.MustCompile(`блокирующих задачу #(\d+)`)
+
+func blockersButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("⛓ Блокеры", fmt.Sprintf("%s%d_%s", callbackEditField, task.ID, editFieldBlockers))
+}
+
+// formatTaskRefs returns tasks as "#12 Title (status)" separated by commas.
+func formatTaskRefs(tasks []model.Task) string {
+	refs := make([]string, len(tasks))
+	for i, task := range tasks {
+		refs[i] = fmt.Sprintf("#%d %s (%s)", task.ID, task.Title, task.Status.StringLocalized())
+	}
+	return strings.Join(refs, ", ")
+}
+
+// formatTaskIDs returns numbers of tasks like "#12, #15".
+func formatTaskIDs(tasks []model.Task) string {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = fmt.Sprintf("#%d", task.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// openTasks drops done and cancelled tasks.
+func openTasks(tasks []model.Task) []model.Task {
+	return slices.DeleteFunc(tasks, func(t model.Task) bool { return !t.Status.IsOpen() })
+}
+
+// openBlockers returns blockers of the task which are not done or cancelled yet.
+func (b *Bot) openBlockers(ctx context.Context, taskID int) ([]model.Task, error) {
+	blockers, err := b.taskStorage.FetchTaskBlockers(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch blockers: %w", err)
+	}
+	return openTasks(blockers), nil
+}
+
+// blockersPrompt asks member to reply with numbers of tasks blocking the task.
+func (b *Bot) blockersPrompt(ctx context.Context, task *model.Task, user *model.User) (string, error) {
+	blockers, err := b.taskStorage.FetchTaskBlockers(ctx, task.ID)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch blockers: %w", err)
+	}
+	text := fmt.Sprintf("⛓ %s, ответьте на это сообщение номерами задач, блокирующих задачу #%d, через пробел или «-», чтобы убрать все",
+		mention(user), task.ID)
+	if len(blockers) > 0 {
+		text += fmt.Sprintf("\nСейчас: %s", formatTaskRefs(blockers))
+	}
+	return text, nil
+}
+
+// handleBlockersReply replaces blockers of the task with tasks listed in reply of any project member,
+// it reports false if message is not a reply to blockers prompt.
+func (b *Bot) handleBlockersReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	anyMember := func(*model.Task, *model.User) bool { return true }
+	task, user, handled, err := b.fetchPromptedTaskFor(ctx, message, blockersPromptRe, anyMember)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	var blockers []model.Task
+	if fields := strings.Fields(message.Text); len(fields) == 0 || fields[0] != "-" {
+		var ids []int
+		for _, raw := range strings.FieldsFunc(message.Text, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' }) {
+			id, err := strconv.Atoi(strings.TrimPrefix(raw, "#"))
+			if err != nil || id <= 0 {
+				return true, b.reply(message, fmt.Sprintf("%s не номер задачи, ответьте номерами через пробел, например «#12 #15»", raw))
+			}
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+		switch {
+		case len(ids) == 0:
+			return true, b.reply(message, "ответьте на сообщение бота номерами задач через пробел или «-»")
+		case len(ids) > maxTaskBlockers:
+			return true, b.reply(message, fmt.Sprintf("у задачи может быть не больше %d блокеров", maxTaskBlockers))
+		case slices.Contains(ids, task.ID):
+			return true, b.reply(message, "задача не может блокировать сама себя")
+		}
+
+		for _, id := range ids {
+			blocker, err := b.taskStorage.FetchTaskByID(ctx, id)
+			if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+				return true, fmt.Errorf("could not fetch task: %w", err)
+			}
+			if blocker == nil || blocker.ProjectID != task.ProjectID {
+				return true, b.reply(message, fmt.Sprintf("задачи #%d нет в проекте", id))
+			}
+			blocked, err := b.blockedBy(ctx, blocker.ID, task.ID)
+			if err != nil {
+				return true, err
+			}
+			if blocked {
+				return true, b.reply(message, fmt.Sprintf("задача #%d уже ждёт задачу #%d, блокеры не могут идти по кругу", blocker.ID, task.ID))
+			}
+			blockers = append(blockers, *blocker)
+		}
+	}
+
+	ids := make([]int, len(blockers))
+	for i, blocker := range blockers {
+		ids[i] = blocker.ID
+	}
+	if err = b.taskStorage.SetTaskBlockers(ctx, task.ID, ids); err != nil {
+		return true, fmt.Errorf("could not set blockers: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set blockers of task id=%d to %v", user.ID, task.ID, ids)
+
+	if len(blockers) == 0 {
+		return true, b.reply(message, fmt.Sprintf("⛓ задачу #%d больше ничего не блокирует", task.ID))
+	}
+	return true, b.reply(message, fmt.Sprintf("⛓ задачу #%d блокируют: %s", task.ID, formatTaskRefs(blockers)))
+}
+
+// blockedBy reports whether task waits for blocker directly or through other blockers.
+func (b *Bot) blockedBy(ctx context.Context, taskID, blockerID int) (bool, error) {
+	seen := map[int]bool{taskID: true}
+	queue := []int{taskID}
+	for len(queue) > 0 {
+		blockers, err := b.taskStorage.FetchTaskBlockers(ctx, queue[0])
+		if err != nil {
+			return false, fmt.Errorf("could not fetch blockers: %w", err)
+		}
+		queue = queue[1:]
+		for _, blocker := range blockers {
+			if blocker.ID == blockerID {
+				return true, nil
+			}
+			if !seen[blocker.ID] {
+				seen[blocker.ID] = true
+				queue = append(queue, blocker.ID)
+			}
+		}
+	}
+	return false, nil
+}
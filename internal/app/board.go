@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// boardRefreshDelay collects bursts of task changes into single edit of board message.
+	boardRefreshDelay = 5 * time.Second
+	boardDeadlinesNum = 5
+)
+
+// pinBoardCommand toggles pinned message with live board summary.
+func (b *Bot) pinBoardCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, _, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	if prj.BoardMessageID != 0 {
+		unpin := tgbotapi.UnpinChatMessageConfig{ChatID: prj.TgChatID, MessageID: prj.BoardMessageID}
+		if _, err = b.Request(unpin); err != nil {
+			log.Printf("WARN could not unpin board message: %s", err)
+		}
+		prj.BoardMessageID = 0
+		if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+			return fmt.Errorf("could not update project: %w", err)
+		}
+		log.Printf("DEBUG project id=%d board unpinned", prj.ID)
+		return b.reply(update.Message, "📌 доска откреплена")
+	}
+
+	text, err := b.renderBoard(ctx, prj)
+	if err != nil {
+		return err
+	}
+	sent, err := b.sendMessage(tgbotapi.NewMessage(prj.TgChatID, text))
+	if err != nil {
+		return fmt.Errorf("could not send board message: %w", err)
+	}
+	pin := tgbotapi.PinChatMessageConfig{ChatID: prj.TgChatID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err = b.Request(pin); err != nil {
+		log.Printf("WARN could not pin board message: %s", err)
+		return b.reply(update.Message, "не удалось закрепить доску: дайте боту права администратора на закрепление сообщений")
+	}
+
+	prj.BoardMessageID = sent.MessageID
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d board pinned as message id=%d", prj.ID, sent.MessageID)
+	return nil
+}
+
+func (b *Bot) refreshBoardOnTaskEvent(_ context.Context, event model.TaskEvent) {
+	b.scheduleBoardRefresh(event.Task.ProjectID)
+}
+
+func (b *Bot) scheduleBoardRefresh(projectID int) {
+	b.boardMu.Lock()
+	defer b.boardMu.Unlock()
+
+	// Already scheduled refresh will render this change too
+	if _, ok := b.boardTimers[projectID]; ok {
+		return
+	}
+	b.boardTimers[projectID] = time.AfterFunc(boardRefreshDelay, func() {
+		b.boardMu.Lock()
+		delete(b.boardTimers, projectID)
+		b.boardMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := b.refreshBoard(ctx, projectID); err != nil {
+			log.Printf("ERROR could not refresh board of project id=%d: %s", projectID, err)
+		}
+	})
+}
+
+func (b *Bot) refreshBoard(ctx context.Context, projectID int) error {
+	prj, err := b.projectStorage.FetchProjectByID(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if prj.BoardMessageID == 0 {
+		return nil
+	}
+
+	text, err := b.renderBoard(ctx, prj)
+	if err != nil {
+		return err
+	}
+	_, err = b.Send(tgbotapi.NewEditMessageText(prj.TgChatID, prj.BoardMessageID, text))
+	switch classifyTelegramError(err) {
+	case telegramErrorNotModified:
+		return nil
+	case telegramErrorMessageNotFound:
+		// Board message was deleted from chat
+		prj.BoardMessageID = 0
+		return b.projectStorage.UpdateProject(ctx, prj)
+	}
+	return err
+}
+
+func (b *Bot) renderBoard(ctx context.Context, prj *model.Project) (string, error) {
+	counts, err := b.taskStorage.CountTasksByStatus(ctx, prj.ID)
+	if err != nil {
+		return "", fmt.Errorf("could not count tasks: %w", err)
+	}
+	deadlines, err := b.taskStorage.FetchUpcomingDeadlines(ctx, prj.ID, boardDeadlinesNum)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch upcoming deadlines: %w", err)
+	}
+
+	links, err := b.renderProjectLinks(ctx, prj.ID)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📌 Доска проекта «%s»\n%s\n", prj.Title, links)
+	for _, status := range model.TaskStatuses {
+		fmt.Fprintf(&sb, "%s %s: %d\n", status.Emoji(), format.Capitalize(status.StringLocalized()), counts[status])
+	}
+
+	sb.WriteString("\n⏰ Ближайшие дедлайны:\n")
+	if len(deadlines) == 0 {
+		sb.WriteString("нет задач со сроком\n")
+	}
+	for _, task := range deadlines {
+		fmt.Fprintf(&sb, "• %s — %s#%d %s\n", task.Deadline.Format(format.DateLayout), task.Priority.Marker(), task.ID, task.Title)
+	}
+
+	fmt.Fprintf(&sb, "\nобновлено %s", time.Now().Format(format.DateTimeLayout))
+	return sb.String(), nil
+}
@@ -2,10 +2,16 @@ package app
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
 	"github.com/agalitsyn/telegram-tasks-bot/version"
@@ -15,6 +21,43 @@ import (
 type BotConfig struct {
 	UpdateTimeout      int
 	InlineQueryEnabled bool
+	// PublicURL is base URL of bot's HTTP server used in links given to users, empty if server is disabled.
+	PublicURL string
+	// APIEndpoint overrides Telegram Bot API endpoint format, tgbotapi.APIEndpoint is used if empty.
+	APIEndpoint string
+	// DryRun makes bot only log changing Bot API requests, DryRunChatID receives copies of messages if set.
+	DryRun       bool
+	DryRunChatID int64
+	// UpdatesLog receives incoming updates as JSON lines for replay, disabled if nil.
+	UpdatesLog io.Writer
+	// Completer enables language model in optional assistant features, simple heuristics are used if nil.
+	Completer Completer
+	// SummarizeCooldown is how long /summarize answers with the previous summary of the chat, disabled if 0.
+	SummarizeCooldown time.Duration
+	// NameFormat is how names of users are built from their Telegram profiles, last-first if empty.
+	NameFormat NameFormat
+	// AnnounceSettings posts line to project chat when manager changes project settings.
+	AnnounceSettings bool
+	// CommandAliases maps alias to command it runs, e.g. "т" to "create_task".
+	CommandAliases map[string]string
+	// Console enables /admin_sql for Operators, Telegram IDs of users running the instance.
+	Console   model.ConsoleRepository
+	Operators []int64
+	// Tracker receives anonymous usage events, disabled if nil.
+	Tracker Tracker
+	// Plugins extend bot with custom commands and subscribers.
+	Plugins []Plugin
+	// Leases let only one of processes sharing database run each background job, LeaseHolder identifies
+	// this process. Jobs run unconditionally if nil.
+	Leases      model.LeaseRepository
+	LeaseHolder string
+	// Outbox keeps notifications about task changes until they are delivered, they are sent right away if nil.
+	Outbox model.OutboxRepository
+	// APITokens let managers issue tokens for project API, /api_tokens is disabled if nil.
+	APITokens model.APITokenRepository
+	// EventLog receives task events for other processes, e.g. event streams served by bot
+	// for changes made by worker, events stay in memory if nil.
+	EventLog model.TaskEventLogRepository
 }
 
 type Bot struct {
@@ -23,6 +66,33 @@ type Bot struct {
 	cfg            BotConfig
 	projectStorage model.ProjectRepository
 	userStorage    model.UserRepository
+	taskStorage    model.TaskRepository
+
+	events        eventBus[model.TaskEvent]
+	projectEvents eventBus[model.ProjectEvent]
+	pendingTasks  pendingStore[capturedTask]
+	imports       pendingStore[importBatch]
+	// failedUpdates keeps updates whose handler panicked until user retries them.
+	failedUpdates pendingStore[tgbotapi.Update]
+	postpones     pendingStore[postponeRequest]
+	handovers     pendingStore[handoverRequest]
+	// titleSuggestions keeps titles written by user for tasks created with suggested title.
+	titleSuggestions pendingStore[titleSuggestion]
+	deadlineShifts   pendingStore[deadlineShift]
+	taskSendings     pendingStore[taskSending]
+	// commandResults keeps results of expensive commands for cooldowns.
+	commandResults resultCache[commandKey, string]
+	// taskCounters keeps counters of projects until their tasks change.
+	taskCounters resultCache[int, model.TaskCounters]
+
+	boardMu     sync.Mutex
+	boardTimers map[int]*time.Timer
+
+	remindersMu          sync.Mutex
+	unreachableReminders map[int64]time.Time
+
+	// outboxWake triggers delivery of notifications saved by this process.
+	outboxWake chan struct{}
 }
 
 func NewBot(
@@ -31,72 +101,134 @@ func NewBot(
 	logger tgbotapi.BotLogger,
 	projectStorage model.ProjectRepository,
 	userStorage model.UserRepository,
+	taskStorage model.TaskRepository,
 ) (*Bot, error) {
-	bot, err := tgbotapi.NewBotAPI(token)
+	endpoint := cfg.APIEndpoint
+	if endpoint == "" {
+		endpoint = tgbotapi.APIEndpoint
+	}
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(token, endpoint)
 	if err != nil {
 		return nil, err
 	}
 	tgbotapi.SetLogger(logger)
-	return &Bot{
+	if cfg.DryRun {
+		bot.Client = &dryRunClient{client: bot.Client, echoChatID: cfg.DryRunChatID}
+	}
+	b := &Bot{
 		cfg:            cfg,
 		projectStorage: projectStorage,
 		userStorage:    userStorage,
+		taskStorage:    taskStorage,
 		BotAPI:         bot,
-	}, nil
+		boardTimers:    make(map[int]*time.Timer),
+
+		unreachableReminders: make(map[int64]time.Time),
+		outboxWake:           make(chan struct{}, 1),
+	}
+	b.events.Subscribe(b.refreshBoardOnTaskEvent)
+	b.events.Subscribe(b.resetTaskCountersOnTaskEvent)
+	if cfg.AnnounceSettings {
+		b.projectEvents.Subscribe(b.announceProjectChange)
+	}
+	if cfg.Tracker != nil {
+		b.events.Subscribe(b.trackTaskEvent)
+	}
+	if cfg.EventLog != nil {
+		b.events.Subscribe(b.logTaskEvent)
+	}
+	b.subscribePlugins()
+	return b, nil
 }
 
 func (b *Bot) Start(ctx context.Context) {
+	b.startPlugins(ctx)
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = b.cfg.UpdateTimeout
 	updates := b.GetUpdatesChan(u)
 	for {
 		select {
 		case update := <-updates:
-			if update.InlineQuery != nil && b.cfg.InlineQueryEnabled {
-				if err := b.handleInlineQuery(update); err != nil {
-					log.Printf("ERROR handling inline query: %s", err)
-				}
-				continue
+			if b.cfg.UpdatesLog != nil {
+				b.recordUpdate(update)
 			}
+			b.HandleUpdate(ctx, update)
 
-			if update.Message == nil { // ignore any non-Message updates
-				continue
-			}
+		case <-ctx.Done():
+			log.Printf("DEBUG stopped: %s", ctx.Err())
+			return
+		}
+	}
+}
 
-			if !update.Message.IsCommand() {
-				command, ok := parseCommand(update.Message.Text, b.Self.UserName)
-				if ok {
-					// Create a new update with the parsed command
-					cmdUpdate := update
-					cmdUpdate.Message.Text = "/" + command
-					cmdUpdate.Message.Entities = []tgbotapi.MessageEntity{
-						{
-							Type:   "bot_command",
-							Offset: 0,
-							Length: len(command) + 1,
-						},
-					}
-					if err := b.handleCommand(ctx, cmdUpdate); err != nil {
-						log.Printf("ERROR handling command: %s", err)
-					}
-
-					continue
-				}
-			}
+// HandleUpdate dispatches single update to its handler, errors are logged and panics are recovered.
+func (b *Bot) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	defer b.recoverUpdate(update)
+
+	b.checkReachability(ctx, update)
+	b.refreshProfile(ctx, update)
+
+	if update.InlineQuery != nil && b.cfg.InlineQueryEnabled {
+		if err := b.handleInlineQuery(update); err != nil {
+			log.Printf("ERROR handling inline query: %s", err)
+		}
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		if err := b.handleCallbackQuery(ctx, update); err != nil {
+			log.Printf("ERROR handling callback query: %s", err)
+		}
+		return
+	}
 
-			if err := b.handleCommand(ctx, update); err != nil {
+	if update.Message == nil { // ignore any non-Message updates
+		return
+	}
+	b.resolveCommandAlias(update.Message)
+
+	if !update.Message.IsCommand() {
+		command, ok := parseCommand(update.Message.Text, b.Self.UserName)
+		if ok {
+			// Create a new update with the parsed command
+			cmdUpdate := update
+			cmdUpdate.Message.Text = "/" + command
+			cmdUpdate.Message.Entities = []tgbotapi.MessageEntity{
+				{
+					Type:   "bot_command",
+					Offset: 0,
+					Length: len(command) + 1,
+				},
+			}
+			b.resolveCommandAlias(cmdUpdate.Message)
+			if err := b.handleCommand(ctx, cmdUpdate); err != nil {
 				log.Printf("ERROR handling command: %s", err)
 			}
+			return
+		}
 
-		case <-ctx.Done():
-			log.Printf("DEBUG stopped: %s", ctx.Err())
+		// Plain chat messages in groups are not addressed to bot
+		if !update.Message.Chat.IsPrivate() {
+			if err := b.handleMessage(ctx, update); err != nil {
+				log.Printf("ERROR handling message: %s", err)
+			}
 			return
 		}
 	}
+
+	if err := b.handleCommand(ctx, update); err != nil {
+		log.Printf("ERROR handling command: %s", err)
+	}
 }
 
 func (b *Bot) handleCommand(ctx context.Context, update tgbotapi.Update) error {
+	if refused, err := b.refuseFrozenCommand(ctx, update.Message); refused || err != nil {
+		return err
+	}
+
 	command := update.Message.Command()
+	b.trackCommand(command)
 	switch command {
 	case "start":
 		return b.startCommand(ctx, update)
@@ -105,67 +237,188 @@ func (b *Bot) handleCommand(ctx context.Context, update tgbotapi.Update) error {
 	case "status":
 		return b.statusCommand(update)
 	case "help":
-		return b.helpCommand(update)
+		return b.helpCommand(ctx, update)
+	case "caldav":
+		return b.caldavCommand(ctx, update)
+	case "quick_capture":
+		return b.quickCaptureCommand(ctx, update)
+	case "pin_board":
+		return b.pinBoardCommand(ctx, update)
+	case "import_tasks":
+		return b.importTasksCommand(ctx, update)
+	case "share_board":
+		return b.shareBoardCommand(ctx, update)
+	case "api_tokens":
+		return b.apiTokensCommand(ctx, update)
+	case "default_deadline":
+		return b.defaultDeadlineCommand(ctx, update)
+	case "escalation":
+		return b.escalationCommand(ctx, update)
+	case "no_deadline":
+		return b.noDeadlineCommand(ctx, update)
+	case "holidays":
+		return b.holidaysCommand(ctx, update)
+	case "links":
+		return b.linksCommand(ctx, update)
+	case "trash":
+		return b.trashCommand(ctx, update)
+	case "welcome":
+		return b.welcomeCommand(ctx, update)
+	case "shift_deadlines":
+		return b.shiftDeadlinesCommand(ctx, update)
+	case "epic":
+		return b.epicCommand(ctx, update)
+	case "labels":
+		return b.labelsCommand(ctx, update)
+	case "repeat":
+		return b.repeatCommand(ctx, update)
+	case "create_task":
+		return b.createTaskCommand(ctx, update)
+	case "template":
+		return b.templateCommand(ctx, update)
+	case "freeze":
+		return b.freezeCommand(ctx, update)
+	case "projects":
+		return b.projectsCommand(ctx, update)
+	case "summarize":
+		return b.summarizeCommand(ctx, update)
+	case "calendar":
+		return b.calendarCommand(ctx, update)
+	case "admin_sql":
+		return b.adminSQLCommand(ctx, update)
 	default:
+		if handled, err := b.pluginCommand(ctx, update); handled || err != nil {
+			return err
+		}
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Незнакомая команда.")
-		_, err := b.Send(msg)
+		_, err := b.sendMessage(msg)
 		return err
 	}
 }
 
-func (b *Bot) helpCommand(update tgbotapi.Update) error {
+func (b *Bot) helpCommand(ctx context.Context, update tgbotapi.Update) error {
 	tpl := `Трекер задач
-
+%s
 	Создать проект /start
 	Создать задачу /create_task
 	Статус /status
+	Быстрое создание задач из сообщений "todo:" /quick_capture
+	Закрепить доску проекта /pin_board
+	Создать задачи из списка /import_tasks
+	Ссылка на доску для тех, кого нет в чате /share_board
+	Токены для доступа к задачам через API /api_tokens
+	Срок по умолчанию для новых задач /default_deadline
+	Повышение приоритета перед сроком /escalation
+	Задачи без срока /no_deadline
+	Праздники проекта /holidays
+	Ссылки на ресурсы проекта /links
+	Корзина удалённых задач /trash
+	Приветствие новых участников /welcome
+	Сдвинуть сроки задач /shift_deadlines
+	Эпики и их прогресс /epic
+	Метки задач и задачи с меткой /labels
+	Повторяющиеся задачи /repeat
+	Шаблоны задач /template
+	Заморозить проект на время проверки /freeze
+	Календарь дедлайнов /calendar
+	Сводка по открытым задачам от ассистента /summarize
+	Подключить задачи к календарю /caldav
+	Сводка по вашим проектам /projects
 	Помощь /help
-
+%s
 	---
 	Версия: %s`
 
-	text := fmt.Sprintf(tpl, version.String())
+	header, err := b.renderTaskCounters(ctx, update.Message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	text := fmt.Sprintf(tpl, header, b.renderAliases(), version.String())
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
-	_, err := b.Send(msg)
+	_, err = b.sendMessage(msg)
 	return err
 }
 
+// renderTaskCounters returns line with project's work in progress summary, empty if chat is not a project.
+func (b *Bot) renderTaskCounters(ctx context.Context, tgChatID int64) (string, error) {
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, tgChatID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not fetch project: %w", err)
+	}
+
+	counters, err := b.fetchTaskCounters(ctx, prj.ID)
+	if err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf(
+		"%s %d в работе, 🔥 %d просрочено, %s %d в очереди",
+		model.TaskStatusInProgress.Emoji(), counters.InProgress,
+		counters.Overdue,
+		model.TaskStatusTODO.Emoji(), counters.Queued,
+	)
+	if counters.OpenPoints > 0 {
+		header += fmt.Sprintf(", 🎯 %s в открытых задачах", formatEstimate(counters.OpenPoints))
+	}
+	return header + "\n", nil
+}
+
 func (b *Bot) statusCommand(update tgbotapi.Update) error {
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Работаю.")
-	_, err := b.Send(msg)
+	_, err := b.sendMessage(msg)
 	return err
 }
 
 func (b *Bot) startCommand(ctx context.Context, update tgbotapi.Update) error {
-	tgChatID := update.Message.Chat.ID
-	prj, err := b.projectStorage.FetchProjectByChatID(ctx, update.Message.Chat.ID)
+	prj, user, userAdded, err := b.ensureProjectMember(ctx, update.Message)
+	if err != nil {
+		return err
+	}
+
+	var text string
+	if userAdded {
+		text = fmt.Sprintf(
+			"✨ вы добавлены в проект \"%s\" с ролью %s",
+			prj.Title, strings.Title(user.Role.StringLocalized()),
+		)
+	} else {
+		text = fmt.Sprintf(
+			"🚀 вы уже состоите в проекте \"%s\" с ролью %s",
+			prj.Title, strings.Title(user.Role.StringLocalized()),
+		)
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// ensureProjectMember registers chat as project and message author as its member if they are not yet known.
+func (b *Bot) ensureProjectMember(ctx context.Context, message *tgbotapi.Message) (*model.Project, *model.User, bool, error) {
+	tgChatID := message.Chat.ID
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, tgChatID)
 	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
-		prj = model.NewProject(update.Message.Chat.Title, tgChatID)
+		prj = model.NewProject(message.Chat.Title, tgChatID)
 		if err = b.projectStorage.CreateProject(ctx, prj); err != nil {
-			return fmt.Errorf("could not create project: %w", err)
+			return nil, nil, false, fmt.Errorf("could not create project: %w", err)
 		}
 		log.Printf("DEBUG created project id=%d", prj.ID)
 	} else if err != nil {
-		return fmt.Errorf("could not fetch project: %w", err)
+		return nil, nil, false, fmt.Errorf("could not fetch project: %w", err)
 	} else {
 		log.Printf("DEBUG fetch project id=%d", prj.ID)
 	}
 
-	user, err := b.userStorage.FetchUserByTgID(ctx, update.Message.From.ID)
+	user, err := b.userStorage.FetchUserByTgID(ctx, message.From.ID)
 	if err != nil && errors.Is(err, model.ErrUserNotFound) {
-		user = model.NewUser(update.Message.From.ID)
-		if update.Message.From.LastName != "" && update.Message.From.FirstName != "" {
-			user.FullName = fmt.Sprintf("%s %s", update.Message.From.LastName, update.Message.From.FirstName)
-		} else if update.Message.From.UserName != "" {
-			// TODO: update.Message.From.UserName always set?
-			user.FullName = update.Message.From.UserName
-		}
+		user = b.newTgUser(message.From)
 		if err = b.userStorage.CreateUser(ctx, user); err != nil {
-			return fmt.Errorf("could not create user: %w", err)
+			return nil, nil, false, fmt.Errorf("could not create user: %w", err)
 		}
 		log.Printf("DEBUG created user id=%d", user.ID)
 	} else if err != nil {
-		return fmt.Errorf("could not fetch user: %w", err)
+		return nil, nil, false, fmt.Errorf("could not fetch user: %w", err)
 	} else {
 		log.Printf("DEBUG fetch user id=%d", user.ID)
 	}
@@ -175,7 +428,7 @@ func (b *Bot) startCommand(ctx context.Context, update tgbotapi.Update) error {
 	if err != nil && errors.Is(err, model.ErrUserNotFound) {
 		usersInPrjNum, err := b.userStorage.CountUsersInProject(ctx, prj.ID)
 		if err != nil {
-			return fmt.Errorf("could not count users in project: %w", err)
+			return nil, nil, false, fmt.Errorf("could not count users in project: %w", err)
 		}
 
 		user.Role = model.UserProjectRoleMember
@@ -185,31 +438,184 @@ func (b *Bot) startCommand(ctx context.Context, update tgbotapi.Update) error {
 		}
 
 		if err = b.userStorage.AddUserToProject(ctx, prj.ID, user.ID, user.Role); err != nil {
-			return fmt.Errorf("could not add user to project: %w", err)
+			return nil, nil, false, fmt.Errorf("could not add user to project: %w", err)
 		}
 		log.Printf("DEBUG user id=%d assigned with role '%s' to project id=%d", user.ID, user.Role, prj.ID)
 
 		userAdded = true
+		// Members of new project are known before they talk to bot, so tasks can be assigned to them
+		if usersInPrjNum == 0 && !message.Chat.IsPrivate() {
+			b.importChatAdministrators(ctx, prj)
+		}
 	} else if err != nil {
-		return fmt.Errorf("could not fetch user role for project: %w", err)
+		return nil, nil, false, fmt.Errorf("could not fetch user role for project: %w", err)
 	} else {
 		log.Printf("DEBUG user id=%d has role '%s' in project id=%d", user.ID, user.Role, prj.ID)
 	}
 
-	var text string
-	if userAdded {
-		text = fmt.Sprintf(
-			"✨ вы добавлены в проект \"%s\" с ролью %s",
-			prj.Title, strings.Title(user.Role.StringLocalized()),
-		)
-	} else {
-		text = fmt.Sprintf(
-			"🚀 вы уже состоите в проекте \"%s\" с ролью %s",
-			prj.Title, strings.Title(user.Role.StringLocalized()),
-		)
+	return prj, user, userAdded, nil
+}
+
+// fetchProjectMember returns chat's project and user with their role in it.
+func (b *Bot) fetchProjectMember(ctx context.Context, tgChatID, tgUserID int64) (*model.Project, *model.User, error) {
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, tgChatID)
+	if err != nil {
+		return nil, nil, err
 	}
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
-	_, err = b.Send(msg)
+	user, err := b.userStorage.FetchUserByTgID(ctx, tgUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = b.userStorage.FetchUserRoleInProject(ctx, prj.ID, user); err != nil {
+		return nil, nil, err
+	}
+	return prj, user, nil
+}
+
+// fetchManagedProject returns chat's project and its manager who wrote the message,
+// if author is not a manager it explains the refusal in chat and returns nil project.
+func (b *Bot) fetchManagedProject(ctx context.Context, message *tgbotapi.Message) (*model.Project, *model.User, error) {
+	if message.Chat.IsPrivate() {
+		return nil, nil, b.reply(message, "команда доступна только в чате проекта")
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, b.reply(message, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if user.Role != model.UserProjectRoleManager {
+		return nil, nil, b.reply(message, "🚫 эта команда доступна только менеджеру проекта")
+	}
+	return prj, user, nil
+}
+
+func (b *Bot) handleCallbackQuery(ctx context.Context, update tgbotapi.Update) error {
+	if refused, err := b.refuseFrozenCallback(ctx, update.CallbackQuery); refused || err != nil {
+		return err
+	}
+
+	data := update.CallbackQuery.Data
+	b.trackCallback(data)
+	switch {
+	case strings.HasPrefix(data, callbackUndoTask):
+		return b.undoTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackCreateDuplicate):
+		return b.createDuplicateCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShowTask):
+		return b.showTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackImportConfirm):
+		return b.importConfirmCallback(ctx, update)
+	case strings.HasPrefix(data, callbackImportCancel):
+		return b.importCancelCallback(update)
+	case strings.HasPrefix(data, callbackShiftConfirm):
+		return b.shiftConfirmCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShiftCancel):
+		return b.shiftCancelCallback(update)
+	case strings.HasPrefix(data, callbackRetryUpdate):
+		return b.retryUpdateCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPostponeRequest):
+		return b.postponeRequestCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPostponeApprove):
+		return b.postponeApproveCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPostponeDeny):
+		return b.postponeDenyCallback(ctx, update)
+	case strings.HasPrefix(data, callbackHandoverRequest):
+		return b.handoverRequestCallback(ctx, update)
+	case strings.HasPrefix(data, callbackHandoverAccept):
+		return b.handoverAcceptCallback(ctx, update)
+	case strings.HasPrefix(data, callbackHandoverDecline):
+		return b.handoverDeclineCallback(update)
+	case strings.HasPrefix(data, callbackSetReviewer):
+		return b.setReviewerCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSetCoAssignees):
+		return b.setCoAssigneesCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSendTask):
+		return b.sendTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSendTaskTo):
+		return b.sendTaskToCallback(ctx, update)
+	case strings.HasPrefix(data, callbackStartTask),
+		strings.HasPrefix(data, callbackFinishTask),
+		strings.HasPrefix(data, callbackHoldTask),
+		strings.HasPrefix(data, callbackAcceptReview),
+		strings.HasPrefix(data, callbackReturnReview):
+		return b.statusCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPickPriority):
+		return b.pickPriorityCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSetPriority):
+		return b.setPriorityCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPickEstimate):
+		return b.pickEstimateCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSetEstimate):
+		return b.setEstimateCallback(ctx, update)
+	case strings.HasPrefix(data, callbackMoveTask):
+		return b.moveTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackRestoreTask):
+		return b.restoreTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackPurgeTask):
+		return b.purgeTaskCallback(ctx, update)
+	case strings.HasPrefix(data, callbackIssueAPIToken):
+		return b.issueAPITokenCallback(ctx, update)
+	case strings.HasPrefix(data, callbackRevokeAPIToken):
+		return b.revokeAPITokenCallback(ctx, update)
+	case strings.HasPrefix(data, callbackToggleWatch):
+		return b.toggleWatchCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShowAttachments):
+		return b.showAttachmentsCallback(ctx, update)
+	case strings.HasPrefix(data, callbackAddAttachment):
+		return b.addAttachmentCallback(ctx, update)
+	case strings.HasPrefix(data, callbackSendAttachment):
+		return b.sendAttachmentCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShowHistory):
+		return b.showHistoryCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShowComments):
+		return b.showCommentsCallback(ctx, update)
+	case strings.HasPrefix(data, callbackAddComment):
+		return b.addCommentCallback(ctx, update)
+	case strings.HasPrefix(data, callbackShowChecklist):
+		return b.showChecklistCallback(ctx, update)
+	case strings.HasPrefix(data, callbackAddChecklistItems):
+		return b.addChecklistItemsCallback(ctx, update)
+	case strings.HasPrefix(data, callbackToggleChecklistItem):
+		return b.toggleChecklistItemCallback(ctx, update)
+	case strings.HasPrefix(data, callbackRemoveChecklistItem):
+		return b.removeChecklistItemCallback(ctx, update)
+	case strings.HasPrefix(data, callbackEditField):
+		return b.editFieldCallback(ctx, update)
+	case strings.HasPrefix(data, callbackToggleMute):
+		return b.toggleMuteCallback(ctx, update)
+	case strings.HasPrefix(data, callbackOverdueSnooze):
+		return b.overdueSnoozeCallback(ctx, update)
+	case strings.HasPrefix(data, callbackKeepTitle):
+		return b.keepTitleCallback(ctx, update)
+	case strings.HasPrefix(data, callbackFullTitle):
+		return b.fullTitleCallback(ctx, update)
+	case strings.HasPrefix(data, callbackGroomPromote),
+		strings.HasPrefix(data, callbackGroomCancel),
+		strings.HasPrefix(data, callbackGroomKeep):
+		return b.groomCallback(ctx, update)
+	case strings.HasPrefix(data, callbackCalendarMonth):
+		return b.calendarMonthCallback(ctx, update)
+	case strings.HasPrefix(data, callbackCalendarDay):
+		return b.calendarDayCallback(ctx, update)
+	case strings.HasPrefix(data, callbackListTemplates):
+		return b.listTemplatesCallback(ctx, update)
+	case strings.HasPrefix(data, callbackCreateFromTemplate):
+		return b.createFromTemplateCallback(ctx, update)
+	default:
+		return b.answerCallback(update.CallbackQuery.ID, "")
+	}
+}
+
+func (b *Bot) reply(message *tgbotapi.Message, text string) error {
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	_, err := b.sendMessage(msg)
+	return err
+}
+
+func (b *Bot) answerCallback(callbackID string, text string) error {
+	_, err := b.Request(tgbotapi.NewCallback(callbackID, text))
 	return err
 }
 
@@ -243,3 +649,15 @@ func parseCommand(text string, botUsername string) (string, bool) {
 	}
 	return "", false
 }
+
+func parseCallbackID(data string, prefix string) (int, error) {
+	return strconv.Atoi(strings.TrimPrefix(data, prefix))
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
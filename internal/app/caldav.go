@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/caldav"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// caldavCommand issues personal CalDAV link, each call revokes previously issued link.
+func (b *Bot) caldavCommand(ctx context.Context, update tgbotapi.Update) error {
+	var text string
+	switch {
+	case !update.Message.Chat.IsPrivate():
+		text = "🔒 команда доступна только в личных сообщениях с ботом"
+	case b.cfg.PublicURL == "":
+		text = "CalDAV не настроен на этом сервере."
+	default:
+		user, err := b.userStorage.FetchUserByTgID(ctx, update.Message.From.ID)
+		if err != nil && errors.Is(err, model.ErrUserNotFound) {
+			text = "сначала присоединитесь к проекту командой /start в чате проекта"
+			break
+		} else if err != nil {
+			return fmt.Errorf("could not fetch user: %w", err)
+		}
+
+		token, err := generateToken()
+		if err != nil {
+			return fmt.Errorf("could not generate token: %w", err)
+		}
+		if err = b.userStorage.UpdateUserCalDAVToken(ctx, user.ID, token); err != nil {
+			return fmt.Errorf("could not update caldav token: %w", err)
+		}
+		log.Printf("DEBUG issued caldav token for user id=%d", user.ID)
+
+		link := strings.TrimSuffix(b.cfg.PublicURL, "/") + caldav.PathPrefix + token + "/"
+		text = fmt.Sprintf(
+			"📅 адрес календаря с вашими задачами (только чтение):\n%s\n\n"+
+				"Добавьте его как CalDAV-аккаунт в Tasks.org, DAVx⁵ или Apple Reminders. "+
+				"Повторный вызов /caldav выдаст новый адрес, а старый перестанет работать.",
+			link,
+		)
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, text)
+	_, err := b.sendMessage(msg)
+	return err
+}
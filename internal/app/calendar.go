@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackCalendarMonth = "cal_month_"
+	callbackCalendarDay   = "cal_day_"
+	callbackCalendarNoop  = "cal_noop"
+
+	calendarMonthLayout = "2006-01"
+	calendarDayLayout   = "2006-01-02"
+)
+
+var (
+	monthNames   = [...]string{"январь", "февраль", "март", "апрель", "май", "июнь", "июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь"}
+	weekdayNames = [...]string{"пн", "вт", "ср", "чт", "пт", "сб", "вс"}
+)
+
+// calendarCommand shows deadlines calendar of current month in project chat.
+func (b *Bot) calendarCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(message, "команда доступна только в чате проекта")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	keyboard, err := b.calendarKeyboard(ctx, prj.ID, month)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, "📅 календарь дедлайнов: рядом с числом — открытые задачи со сроком в этот день")
+	msg.ReplyMarkup = keyboard
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// calendarMonthCallback switches calendar to another month.
+func (b *Bot) calendarMonthCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	month, err := time.ParseInLocation(calendarMonthLayout, strings.TrimPrefix(query.Data, callbackCalendarMonth), time.Local)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	prj, ok, err := b.fetchCallbackProject(ctx, query)
+	if err != nil || !ok {
+		return err
+	}
+
+	keyboard, err := b.calendarKeyboard(ctx, prj.ID, month)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, keyboard)
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// calendarDayCallback lists open tasks due on the day.
+func (b *Bot) calendarDayCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	day, err := time.ParseInLocation(calendarDayLayout, strings.TrimPrefix(query.Data, callbackCalendarDay), time.Local)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	prj, ok, err := b.fetchCallbackProject(ctx, query)
+	if err != nil || !ok {
+		return err
+	}
+
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		ProjectID:    prj.ID,
+		DeadlineFrom: day,
+		Deadline:     endOfDay(day),
+		OnlyOpen:     true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return b.answerCallback(query.ID, fmt.Sprintf("на %s задач нет", day.Format(format.DateLayout)))
+	}
+
+	var (
+		sb   strings.Builder
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+	fmt.Fprintf(&sb, "📅 срок %s:\n", day.Format(format.DateLayout))
+	for _, task := range tasks {
+		fmt.Fprintf(&sb, "• %s#%d %s (%s)\n", task.Priority.Marker(), task.ID, task.Title, task.Status.StringLocalized())
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📂 Открыть #%d", task.ID),
+				fmt.Sprintf("%s%d", callbackShowTask, task.ID),
+			),
+		))
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, sb.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send tasks of the day: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// fetchCallbackProject returns project of chat where button is pressed, otherwise it answers callback.
+func (b *Bot) fetchCallbackProject(ctx context.Context, query *tgbotapi.CallbackQuery) (*model.Project, bool, error) {
+	if query.Message == nil {
+		return nil, false, b.answerCallback(query.ID, "")
+	}
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, query.Message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return nil, false, b.answerCallback(query.ID, "проект не найден")
+	} else if err != nil {
+		return nil, false, fmt.Errorf("could not fetch project: %w", err)
+	}
+	return prj, true, nil
+}
+
+// calendarKeyboard renders month starting on Monday, days with deadlines show number of open tasks.
+func (b *Bot) calendarKeyboard(ctx context.Context, projectID int, month time.Time) (tgbotapi.InlineKeyboardMarkup, error) {
+	next := month.AddDate(0, 1, 0)
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		ProjectID:    projectID,
+		DeadlineFrom: month,
+		Deadline:     next.Add(-time.Second),
+		OnlyOpen:     true,
+	})
+	if err != nil {
+		return tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	counts := make(map[int]int)
+	for _, task := range tasks {
+		counts[task.Deadline.In(month.Location()).Day()]++
+	}
+
+	noop := func(text string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, callbackCalendarNoop)
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️", callbackCalendarMonth+month.AddDate(0, -1, 0).Format(calendarMonthLayout)),
+			noop(fmt.Sprintf("%s %d", monthNames[month.Month()-1], month.Year())),
+			tgbotapi.NewInlineKeyboardButtonData("▶️", callbackCalendarMonth+next.Format(calendarMonthLayout)),
+		),
+	}
+	var header []tgbotapi.InlineKeyboardButton
+	for _, name := range weekdayNames {
+		header = append(header, noop(name))
+	}
+	rows = append(rows, header)
+
+	// Monday is the first day of week
+	week := make([]tgbotapi.InlineKeyboardButton, (int(month.Weekday())+6)%7)
+	for i := range week {
+		week[i] = noop(" ")
+	}
+	for day := month; day.Before(next); day = day.AddDate(0, 0, 1) {
+		text := fmt.Sprintf("%d", day.Day())
+		if n := counts[day.Day()]; n > 0 {
+			text = fmt.Sprintf("%d·%d", day.Day(), n)
+		}
+		week = append(week, tgbotapi.NewInlineKeyboardButtonData(text, callbackCalendarDay+day.Format(calendarDayLayout)))
+		if len(week) == 7 {
+			rows = append(rows, week)
+			week = nil
+		}
+	}
+	if len(week) > 0 {
+		for len(week) < 7 {
+			week = append(week, noop(" "))
+		}
+		rows = append(rows, week)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
@@ -0,0 +1,352 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackUndoTask        = "undo_task_"
+	callbackCreateDuplicate = "dup_create_"
+)
+
+var quickCapturePrefixes = []string{"todo:", "задача:"}
+
+func (b *Bot) quickCaptureCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	prj.QuickCapture = !prj.QuickCapture
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d quick capture set to %t", prj.ID, prj.QuickCapture)
+
+	text, change := "быстрое создание задач выключено", "быстрое создание задач выключено"
+	if prj.QuickCapture {
+		text = "⚡️ быстрое создание задач включено: сообщения, начинающиеся с «todo:» или «задача:», станут задачами.\n\n" +
+			"Чтобы бот видел сообщения чата, отключите ему режим Group Privacy в @BotFather."
+		change = "быстрое создание задач включено"
+	}
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+// handleMessage handles plain group messages which are not commands.
+func (b *Bot) handleMessage(ctx context.Context, update tgbotapi.Update) error {
+	if len(update.Message.NewChatMembers) > 0 {
+		return b.welcomeNewMembers(ctx, update.Message)
+	}
+	if handled, err := b.handlePostponeReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleHandoverReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleReviewerReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleCoAssigneesReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleLabelsReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleBlockersReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleChecklistReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleCommentReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if handled, err := b.handleAttachmentReply(ctx, update.Message); handled || err != nil {
+		return err
+	}
+	if text, ok := parseBotMention(update.Message.Text, b.Self.UserName); ok {
+		return b.captureMentionTask(ctx, update.Message, text)
+	}
+
+	title, description, ok := parseQuickCapture(update.Message.Text)
+	if !ok {
+		return nil
+	}
+
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, update.Message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if !prj.QuickCapture {
+		return nil
+	}
+	if prj.Frozen {
+		return b.reply(update.Message, frozenProjectText)
+	}
+	return b.captureTask(ctx, update.Message, title, description, time.Time{}, false)
+}
+
+// captureMentionTask creates task from message addressed to bot as "@bot text",
+// deadline may end the first line, e.g. "сделать отчёт к пятнице".
+func (b *Bot) captureMentionTask(ctx context.Context, message *tgbotapi.Message, text string) error {
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(message, "сначала создайте проект командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if prj.Frozen {
+		return b.reply(message, frozenProjectText)
+	}
+
+	firstLine, rest, _ := strings.Cut(text, "\n")
+	title, deadline, _ := parseDeadlinePhrase(firstLine, time.Now())
+	title, rest = strings.TrimSpace(title), strings.TrimSpace(rest)
+	if title == "" && rest == "" {
+		return b.reply(message, fmt.Sprintf("напишите задачу после упоминания, например: @%s сделать отчёт к пятнице", b.Self.UserName))
+	}
+	return b.captureTask(ctx, message, title, rest, deadline, true)
+}
+
+// captureTask creates task written in chat message and confirms it with short line or with task card,
+// task without deadline gets project's default one.
+func (b *Bot) captureTask(ctx context.Context, message *tgbotapi.Message, title, description string, deadline time.Time, card bool) error {
+	prj, user, _, err := b.ensureProjectMember(ctx, message)
+	if err != nil {
+		return err
+	}
+
+	// Missing or too long title is replaced with suggested one, whole text is kept in description.
+	original := title
+	shortened := title == "" || utf8.RuneCountInString(title) > titleMaxLen
+	if shortened {
+		description = strings.TrimSpace(title + "\n" + description)
+		title = b.suggestTitle(ctx, description)
+	}
+
+	task := model.NewTask(prj.ID, title, int64(user.ID))
+	task.Description = description
+	task.Status = model.TaskStatusTODO
+	task.Deadline = deadline
+	if task.Deadline.IsZero() {
+		task.Deadline = defaultDeadline(prj, time.Now())
+	}
+
+	projectTasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID})
+	if err != nil {
+		return fmt.Errorf("could not fetch project tasks: %w", err)
+	}
+	if duplicates := findSimilarTasks(title, projectTasks); len(duplicates) > 0 {
+		return b.warnDuplicates(message, task, duplicates)
+	}
+
+	if err = b.createTask(ctx, task, user.ID); err != nil {
+		return err
+	}
+	log.Printf("DEBUG user id=%d captured task id=%d in project id=%d", user.ID, task.ID, prj.ID)
+
+	text := taskCapturedText(task)
+	if card {
+		if text, err = b.renderTaskCard(ctx, task); err != nil {
+			return err
+		}
+		text = "✅ задача создана\n\n" + text
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = undoTaskKeyboard(task)
+	if shortened {
+		suggestionID := b.titleSuggestions.Put(titleSuggestion{
+			taskID:     task.ID,
+			original:   original,
+			authorTgID: message.From.ID,
+		})
+		msg.Text += "\n\n✂️ название предложено ботом, полный текст сохранён в описании"
+		msg.ReplyMarkup = titleSuggestionKeyboard(task, suggestionID, original != "")
+	}
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+type capturedTask struct {
+	task       model.Task
+	authorTgID int64
+}
+
+// warnDuplicates holds captured task until author confirms it is not a duplicate of listed tasks.
+func (b *Bot) warnDuplicates(message *tgbotapi.Message, task *model.Task, duplicates []model.Task) error {
+	pendingID := b.pendingTasks.Put(capturedTask{task: *task, authorTgID: message.From.ID})
+
+	var sb strings.Builder
+	sb.WriteString("⚠️ похожие задачи уже есть:\n")
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, dup := range duplicates {
+		fmt.Fprintf(&sb, "• #%d %s (%s)\n", dup.ID, dup.Title, dup.Status.StringLocalized())
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📂 Открыть #%d", dup.ID),
+				fmt.Sprintf("%s%d", callbackShowTask, dup.ID),
+			),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Создать всё равно", fmt.Sprintf("%s%d", callbackCreateDuplicate, pendingID)),
+	))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, sb.String())
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err := b.sendMessage(msg)
+	return err
+}
+
+// createDuplicateCallback creates held task after author confirmed it.
+func (b *Bot) createDuplicateCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	pendingID, err := parseCallbackID(query.Data, callbackCreateDuplicate)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	captured, ok := b.pendingTasks.Get(pendingID)
+	if !ok {
+		return b.answerCallback(query.ID, "черновик задачи устарел, отправьте сообщение заново")
+	}
+	if captured.authorTgID != query.From.ID {
+		return b.answerCallback(query.ID, "создать задачу может только её автор")
+	}
+	b.pendingTasks.Delete(pendingID)
+
+	task := captured.task
+	if err = b.createTask(ctx, &task, int(task.CreatedBy)); err != nil {
+		return err
+	}
+	log.Printf("DEBUG user id=%d captured task id=%d in project id=%d despite duplicates", task.CreatedBy, task.ID, task.ProjectID)
+
+	if query.Message != nil {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(
+			query.Message.Chat.ID,
+			query.Message.MessageID,
+			taskCapturedText(&task),
+			undoTaskKeyboard(&task),
+		)
+		if _, err = b.Send(edit); err != nil {
+			return fmt.Errorf("could not edit message: %w", err)
+		}
+	}
+	return b.answerCallback(query.ID, "задача создана")
+}
+
+func taskCapturedText(task *model.Task) string {
+	return fmt.Sprintf("✅ задача #%d создана: %s", task.ID, task.Title)
+}
+
+func undoTaskKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Отменить", fmt.Sprintf("%s%d", callbackUndoTask, task.ID)),
+		),
+	)
+}
+
+// undoTaskCallback removes just captured task, allowed for task author and project managers.
+func (b *Bot) undoTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackUndoTask)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача уже удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+
+	user, err := b.userStorage.FetchUserByTgID(ctx, query.From.ID)
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return b.answerCallback(query.ID, "отменить может только автор задачи")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	if task.CreatedBy != int64(user.ID) {
+		err = b.userStorage.FetchUserRoleInProject(ctx, task.ProjectID, user)
+		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+			return fmt.Errorf("could not fetch user role for project: %w", err)
+		}
+		if user.Role != model.UserProjectRoleManager {
+			return b.answerCallback(query.ID, "отменить может только автор задачи")
+		}
+	}
+
+	if err = b.taskStorage.RemoveTask(ctx, task.ID); err != nil {
+		return fmt.Errorf("could not remove task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d removed task id=%d", user.ID, task.ID)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventRemoved, Task: *task, ActorID: user.ID})
+
+	if query.Message != nil {
+		edit := tgbotapi.NewEditMessageText(
+			query.Message.Chat.ID,
+			query.Message.MessageID,
+			fmt.Sprintf("↩️ задача #%d отменена: %s", task.ID, task.Title),
+		)
+		if _, err = b.Send(edit); err != nil {
+			return fmt.Errorf("could not edit message: %w", err)
+		}
+	}
+	return b.answerCallback(query.ID, "задача отменена")
+}
+
+// parseBotMention returns text of message addressed to bot as "@bot text",
+// commands in "@bot /command" form are left to parseCommand.
+func parseBotMention(text string, botUsername string) (string, bool) {
+	prefix := "@" + botUsername
+	if len(text) <= len(prefix) || !strings.EqualFold(text[:len(prefix)], prefix) {
+		return "", false
+	}
+	rest := text[len(prefix):]
+	// Other bot with longer username starting the same
+	if r, _ := utf8.DecodeRuneInString(rest); !unicode.IsSpace(r) {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" || strings.HasPrefix(rest, "/") {
+		return "", false
+	}
+	return rest, true
+}
+
+// parseQuickCapture extracts task from message with capture prefix,
+// title is the rest of the first line, it may be empty, and following lines become description.
+func parseQuickCapture(text string) (string, string, bool) {
+	firstLine, rest, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	for _, prefix := range quickCapturePrefixes {
+		if len(firstLine) < len(prefix) || !strings.EqualFold(firstLine[:len(prefix)], prefix) {
+			continue
+		}
+		title, description := strings.TrimSpace(firstLine[len(prefix):]), strings.TrimSpace(rest)
+		if title == "" && description == "" {
+			return "", "", false
+		}
+		return title, description, true
+	}
+	return "", "", false
+}
@@ -0,0 +1,63 @@
+package app
+
+import "testing"
+
+func TestParseBotMention(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+		ok   bool
+	}{
+		{"@tasks_bot сделать отчёт", "сделать отчёт", true},
+		{"@Tasks_Bot сделать отчёт", "сделать отчёт", true},
+		{"@tasks_bot   сделать отчёт  ", "сделать отчёт", true},
+		{"@tasks_bot\nсделать отчёт\nподробности", "сделать отчёт\nподробности", true},
+		{"@tasks_bot", "", false},
+		{"@tasks_bot   ", "", false},
+		{"@tasks_bot /list", "", false},
+		{"@tasks_bot_dev сделать отчёт", "", false},
+		{"@tasks_botсделать отчёт", "", false},
+		{"@other_bot сделать отчёт", "", false},
+		{"сделать отчёт @tasks_bot", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseBotMention(tt.text, "tasks_bot")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseBotMention(%q): got %q, %t, want %q, %t", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseQuickCapture(t *testing.T) {
+	tests := []struct {
+		text        string
+		title       string
+		description string
+		ok          bool
+	}{
+		{"todo: купить молоко", "купить молоко", "", true},
+		{"TODO:купить молоко", "купить молоко", "", true},
+		{"задача: купить молоко", "купить молоко", "", true},
+		{"Задача: купить молоко", "купить молоко", "", true},
+		{"ЗАДАЧА: купить молоко", "купить молоко", "", true},
+		{"  todo: купить молоко  ", "купить молоко", "", true},
+		{"todo: купить молоко\n2 литра\nобезжиренное", "купить молоко", "2 литра\nобезжиренное", true},
+		// Empty title is suggested later from description
+		{"todo:\nкупить молоко", "", "купить молоко", true},
+		{"todo:", "", "", false},
+		{"todo:   \n  ", "", "", false},
+		{"todo купить молоко", "", "", false},
+		{"надо todo: купить молоко", "", "", false},
+		{"купить молоко", "", "", false},
+		{"зад", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		title, description, ok := parseQuickCapture(tt.text)
+		if title != tt.title || description != tt.description || ok != tt.ok {
+			t.Errorf("parseQuickCapture(%q): got %q, %q, %t, want %q, %q, %t",
+				tt.text, title, description, ok, tt.title, tt.description, tt.ok)
+		}
+	}
+}
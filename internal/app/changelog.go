@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// publishProjectChange tells subscribers that manager changed project settings.
+func (b *Bot) publishProjectChange(ctx context.Context, prj *model.Project, user *model.User, change string) {
+	b.projectEvents.Publish(ctx, model.ProjectEvent{Project: *prj, ActorID: user.ID, Change: change})
+}
+
+// announceProjectChange posts changelog line to project chat, so members are not confused by silent changes.
+func (b *Bot) announceProjectChange(ctx context.Context, event model.ProjectEvent) {
+	actor := "кто-то"
+	user, err := b.userStorage.FetchUserByID(ctx, event.ActorID)
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		log.Printf("ERROR could not fetch user id=%d: %s", event.ActorID, err)
+	} else if user != nil {
+		actor = user.FullName
+	}
+
+	text := fmt.Sprintf("📝 настройки проекта: %s (%s)", event.Change, actor)
+	if _, err = b.sendMessage(tgbotapi.NewMessage(event.Project.TgChatID, text)); err != nil {
+		log.Printf("ERROR could not announce change of project id=%d: %s", event.Project.ID, err)
+	}
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackShowChecklist       = "show_checklist_"
+	callbackAddChecklistItems   = "add_checklist_"
+	callbackToggleChecklistItem = "toggle_check_"
+	callbackRemoveChecklistItem = "remove_check_"
+
+	maxChecklistItems      = 20
+	maxChecklistItemLength = 100
+)
+
+// checklistPromptRe matches bot's prompt for checklist items, reply to it carries items one per line.
+var checklistPromptRe = regexp.MustCompile(`пунктами чек-листа задачи #(\d+)`)
+
+func checklistButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("☑️ Чек-лист", fmt.Sprintf("%s%d", callbackShowChecklist, task.ID))
+}
+
+// renderChecklist returns checklist message with buttons toggling and removing items,
+// when all items are done assignee is offered to finish the task.
+func renderChecklist(task *model.Task, items []model.ChecklistItem) (string, tgbotapi.InlineKeyboardMarkup) {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, item := range items {
+		mark := "⬜"
+		if item.Done {
+			mark = "✅"
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%s %s", mark, item.Text), fmt.Sprintf("%s%d", callbackToggleChecklistItem, item.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑", fmt.Sprintf("%s%d", callbackRemoveChecklistItem, item.ID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Добавить пункты", fmt.Sprintf("%s%d", callbackAddChecklistItems, task.ID)),
+	))
+
+	done, total := model.ChecklistProgress(items)
+	if total == 0 {
+		return fmt.Sprintf("☑️ чек-лист задачи #%d %s пуст", task.ID, task.Title), tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	text := fmt.Sprintf("☑️ чек-лист задачи #%d %s: %d/%d", task.ID, task.Title, done, total)
+	if done == total {
+		text += "\n\n🎉 все пункты выполнены"
+		if button, ok := finishButton(task); ok {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+		}
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// finishButton returns status button finishing assigned task if it is available in current status.
+func finishButton(task *model.Task) (tgbotapi.InlineKeyboardButton, bool) {
+	if task.Assignee == 0 {
+		return tgbotapi.InlineKeyboardButton{}, false
+	}
+	buttons := statusButtons(task)
+	i := slices.IndexFunc(buttons, func(button tgbotapi.InlineKeyboardButton) bool {
+		return strings.HasPrefix(*button.CallbackData, callbackFinishTask)
+	})
+	if i < 0 {
+		return tgbotapi.InlineKeyboardButton{}, false
+	}
+	return buttons[i], true
+}
+
+// showChecklistCallback replies to task card with checklist of the task, allowed for project members.
+func (b *Bot) showChecklistCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackShowChecklist)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	items, err := b.taskStorage.FetchChecklist(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch checklist: %w", err)
+	}
+	text, keyboard := renderChecklist(task, items)
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = keyboard
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send checklist: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// addChecklistItemsCallback asks member who pressed the button to reply with new checklist items.
+func (b *Bot) addChecklistItemsCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackAddChecklistItems)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"☑️ %s, ответьте на это сообщение пунктами чек-листа задачи #%d, каждый с новой строки", mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "обновить changelog"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send checklist prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleChecklistReply adds items listed in reply of any project member to checklist and sends it,
+// it reports false if message is not a reply to checklist prompt.
+func (b *Bot) handleChecklistReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	anyMember := func(*model.Task, *model.User) bool { return true }
+	task, user, handled, err := b.fetchPromptedTaskFor(ctx, message, checklistPromptRe, anyMember)
+	if err != nil || task == nil {
+		return handled, err
+	}
+	if !task.Status.IsOpen() {
+		return true, b.reply(message, "задача уже закрыта")
+	}
+
+	var texts []string
+	for _, line := range strings.Split(message.Text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-•*"))
+		if line == "" {
+			continue
+		}
+		if len([]rune(line)) > maxChecklistItemLength {
+			return true, b.reply(message, fmt.Sprintf("пункт чек-листа должен быть не длиннее %d символов", maxChecklistItemLength))
+		}
+		texts = append(texts, line)
+	}
+	if len(texts) == 0 {
+		return true, b.reply(message, "ответьте на сообщение бота пунктами чек-листа, каждый с новой строки")
+	}
+
+	items, err := b.taskStorage.FetchChecklist(ctx, task.ID)
+	if err != nil {
+		return true, fmt.Errorf("could not fetch checklist: %w", err)
+	}
+	if len(items)+len(texts) > maxChecklistItems {
+		return true, b.reply(message, fmt.Sprintf("в чек-листе может быть не больше %d пунктов", maxChecklistItems))
+	}
+	added := make([]*model.ChecklistItem, len(texts))
+	for i, text := range texts {
+		added[i] = &model.ChecklistItem{TaskID: task.ID, Text: text}
+	}
+	if err = b.taskStorage.AddChecklistItems(ctx, added); err != nil {
+		return true, fmt.Errorf("could not add checklist items: %w", err)
+	}
+	log.Printf("DEBUG user id=%d added %d checklist items to task id=%d", user.ID, len(added), task.ID)
+	for _, item := range added {
+		items = append(items, *item)
+	}
+
+	text, keyboard := renderChecklist(task, items)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = keyboard
+	_, err = b.sendMessage(msg)
+	return true, err
+}
+
+// toggleChecklistItemCallback checks or unchecks item and refreshes checklist message, allowed for project members.
+func (b *Bot) toggleChecklistItemCallback(ctx context.Context, update tgbotapi.Update) error {
+	return b.changeChecklistItem(ctx, update, callbackToggleChecklistItem, func(item *model.ChecklistItem) (string, error) {
+		if err := b.taskStorage.SetChecklistItemDone(ctx, item.ID, !item.Done); err != nil {
+			return "", err
+		}
+		if item.Done {
+			return "отметка снята", nil
+		}
+		return "пункт выполнен", nil
+	})
+}
+
+// removeChecklistItemCallback removes item and refreshes checklist message, allowed for project members.
+func (b *Bot) removeChecklistItemCallback(ctx context.Context, update tgbotapi.Update) error {
+	return b.changeChecklistItem(ctx, update, callbackRemoveChecklistItem, func(item *model.ChecklistItem) (string, error) {
+		if err := b.taskStorage.RemoveChecklistItem(ctx, item.ID); err != nil {
+			return "", err
+		}
+		return "пункт удалён", nil
+	})
+}
+
+// changeChecklistItem applies change to item pressed in checklist message and refreshes the message,
+// change returns answer to callback.
+func (b *Bot) changeChecklistItem(
+	ctx context.Context,
+	update tgbotapi.Update,
+	prefix string,
+	change func(item *model.ChecklistItem) (string, error),
+) error {
+	query := update.CallbackQuery
+	itemID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	item, err := b.taskStorage.FetchChecklistItem(ctx, itemID)
+	if err != nil && errors.Is(err, model.ErrChecklistItemNotFound) {
+		return b.answerCallback(query.ID, "пункт уже удалён")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch checklist item: %w", err)
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, item.TaskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	answer, err := change(item)
+	if err != nil && errors.Is(err, model.ErrChecklistItemNotFound) {
+		return b.answerCallback(query.ID, "пункт уже удалён")
+	} else if err != nil {
+		return fmt.Errorf("could not change checklist item: %w", err)
+	}
+	log.Printf("DEBUG user id=%d changed checklist item id=%d of task id=%d: %s", user.ID, item.ID, task.ID, answer)
+
+	items, err := b.taskStorage.FetchChecklist(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch checklist: %w", err)
+	}
+	if done, total := model.ChecklistProgress(items); total > 0 && done == total && !item.Done {
+		answer = "🎉 все пункты выполнены"
+	}
+	text, keyboard := renderChecklist(task, items)
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, keyboard)
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, answer)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackSetCoAssignees = "set_coassignees_"
+
+	maxCoAssignees = 5
+)
+
+// coAssigneesPromptRe matches bot's prompt for co-assignees, reply to it carries usernames.
+var coAssigneesPromptRe = regexp.MustCompile(`помогут с задачей #(\d+)`)
+
+// setCoAssigneesCallback asks assignee of the task to reply with usernames of co-assignees.
+func (b *Bot) setCoAssigneesCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackSetCoAssignees)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, user, ok, err := b.fetchAssignedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"👥 %s, ответьте на это сообщение @username или упоминаниями участников, которые помогут с задачей #%d, или «-», чтобы работать одному",
+		mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "@username @username"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send co-assignees prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleCoAssigneesReply replaces co-assignees with members named in reply and notifies new ones privately,
+// it reports false if message is not a reply to co-assignees prompt.
+func (b *Bot) handleCoAssigneesReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	task, user, handled, err := b.fetchPromptedTask(ctx, message, coAssigneesPromptRe)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	var coAssignees []*model.User
+	fields := strings.Fields(message.Text)
+	refs, ok := parseMemberRefs(message)
+	switch {
+	case len(fields) > 0 && fields[0] == "-":
+		if len(task.CoAssignees) == 0 {
+			return true, b.reply(message, "у задачи нет соисполнителей")
+		}
+	case ok:
+		if len(refs) > maxCoAssignees {
+			return true, b.reply(message, fmt.Sprintf("у задачи может быть не больше %d соисполнителей", maxCoAssignees))
+		}
+		for _, ref := range refs {
+			coAssignee, err := b.fetchProjectMemberByRef(ctx, task.ProjectID, ref)
+			if err != nil && errors.Is(err, model.ErrUserNotFound) {
+				return true, b.reply(message, fmt.Sprintf("%s не состоит в проекте", ref))
+			} else if err != nil {
+				return true, fmt.Errorf("could not fetch project member: %w", err)
+			}
+			switch int64(coAssignee.ID) {
+			case task.Assignee:
+				return true, b.reply(message, "исполнитель уже работает над задачей")
+			case task.Reviewer:
+				return true, b.reply(message, fmt.Sprintf("%s проверяет задачу и не может быть соисполнителем", coAssignee.FullName))
+			}
+			if !slices.ContainsFunc(coAssignees, func(u *model.User) bool { return u.ID == coAssignee.ID }) {
+				coAssignees = append(coAssignees, coAssignee)
+			}
+		}
+	default:
+		return true, b.reply(message, "ответьте на сообщение бота @username или упоминаниями участников проекта через пробел или «-»")
+	}
+
+	previous := task.CoAssignees
+	task.CoAssignees = nil
+	names := make([]string, len(coAssignees))
+	for i, coAssignee := range coAssignees {
+		task.CoAssignees = append(task.CoAssignees, int64(coAssignee.ID))
+		names[i] = coAssignee.FullName
+	}
+	slices.Sort(task.CoAssignees)
+	task.UpdatedBy = int64(user.ID)
+	text := fmt.Sprintf("👥 %s просит вас помочь с задачей #%d %s", user.FullName, task.ID, task.Title)
+	var notifications []model.Notification
+	for _, coAssignee := range coAssignees {
+		if !slices.Contains(previous, int64(coAssignee.ID)) {
+			notifications = append(notifications, model.NewNotification(int64(coAssignee.ID), text))
+		}
+	}
+	if err = b.updateTaskNotifying(ctx, task, notifications...); err != nil {
+		return true, fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set co-assignees of task id=%d to %v", user.ID, task.ID, task.CoAssignees)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+
+	if len(coAssignees) == 0 {
+		return true, b.reply(message, fmt.Sprintf("👥 у задачи #%d больше нет соисполнителей", task.ID))
+	}
+	return true, b.reply(message, fmt.Sprintf("👥 с задачей #%d помогут: %s", task.ID, strings.Join(names, ", ")))
+}
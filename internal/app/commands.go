@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var (
+	privateCommands = []tgbotapi.BotCommand{
+		{Command: "caldav", Description: "подключить задачи к календарю"},
+		{Command: "projects", Description: "сводка по проектам, которыми вы управляете"},
+		{Command: "help", Description: "помощь"},
+	}
+	groupCommands = []tgbotapi.BotCommand{
+		{Command: "start", Description: "создать проект или вступить в него"},
+		{Command: "create_task", Description: "создать задачу, в том числе из шаблона"},
+		{Command: "status", Description: "статус бота"},
+		{Command: "no_deadline", Description: "задачи без срока"},
+		{Command: "calendar", Description: "календарь дедлайнов"},
+		{Command: "epic", Description: "эпики проекта и их прогресс"},
+		{Command: "labels", Description: "метки задач и задачи с меткой"},
+		{Command: "repeat", Description: "повторяющиеся задачи"},
+		{Command: "template", Description: "шаблоны задач"},
+		{Command: "links", Description: "ссылки на ресурсы проекта"},
+		{Command: "summarize", Description: "сводка по открытым задачам от ассистента"},
+		{Command: "help", Description: "помощь и сводка по задачам"},
+	}
+	// adminCommands are shown to chat administrators, who usually manage projects.
+	adminCommands = slices.Concat(groupCommands, []tgbotapi.BotCommand{
+		{Command: "import_tasks", Description: "создать задачи из списка"},
+		{Command: "quick_capture", Description: "задачи из сообщений «todo:»"},
+		{Command: "pin_board", Description: "закрепить доску проекта"},
+		{Command: "share_board", Description: "ссылка на доску для тех, кого нет в чате"},
+		{Command: "api_tokens", Description: "токены для доступа через API"},
+		{Command: "default_deadline", Description: "срок по умолчанию для новых задач"},
+		{Command: "escalation", Description: "повышение приоритета перед сроком"},
+		{Command: "holidays", Description: "праздники проекта"},
+		{Command: "trash", Description: "корзина удалённых задач"},
+		{Command: "welcome", Description: "приветствие новых участников"},
+		{Command: "shift_deadlines", Description: "сдвинуть сроки задач"},
+		{Command: "freeze", Description: "заморозить или разморозить проект"},
+	})
+
+	// commandTranslations holds descriptions for clients with other language than default Russian.
+	commandTranslations = map[string]map[string]string{
+		"en": {
+			"caldav":           "connect tasks to calendar",
+			"projects":         "summary of projects you manage",
+			"help":             "help and tasks summary",
+			"start":            "create project or join it",
+			"status":           "bot status",
+			"no_deadline":      "tasks without deadline",
+			"calendar":         "deadlines calendar",
+			"epic":             "project epics and progress",
+			"labels":           "task labels and tasks by label",
+			"repeat":           "recurring tasks",
+			"create_task":      "create task, also from template",
+			"template":         "task templates",
+			"links":            "project resources links",
+			"summarize":        "open tasks summary by assistant",
+			"import_tasks":     "create tasks from list",
+			"quick_capture":    "tasks from \"todo:\" messages",
+			"pin_board":        "pin project board",
+			"share_board":      "board link for those outside chat",
+			"api_tokens":       "tokens for API access",
+			"default_deadline": "default deadline for new tasks",
+			"escalation":       "priority escalation before deadline",
+			"holidays":         "project holidays",
+			"trash":            "trash of removed tasks",
+			"welcome":          "welcome message for new members",
+			"shift_deadlines":  "shift tasks deadlines",
+			"freeze":           "freeze or unfreeze project",
+		},
+	}
+)
+
+// RegisterCommands sets command lists shown by Telegram clients in private chats, group chats
+// and to group administrators, menu button opens the list.
+func (b *Bot) RegisterCommands() error {
+	scopes := []struct {
+		scope    tgbotapi.BotCommandScope
+		commands []tgbotapi.BotCommand
+	}{
+		{tgbotapi.NewBotCommandScopeAllPrivateChats(), privateCommands},
+		{tgbotapi.NewBotCommandScopeAllGroupChats(), groupCommands},
+		{tgbotapi.NewBotCommandScopeAllChatAdministrators(), adminCommands},
+	}
+	for _, s := range scopes {
+		if _, err := b.Request(tgbotapi.NewSetMyCommandsWithScope(s.scope, b.withAliases(s.commands)...)); err != nil {
+			return fmt.Errorf("could not set commands for %s scope: %w", s.scope.Type, err)
+		}
+		for lang, translations := range commandTranslations {
+			cfg := tgbotapi.NewSetMyCommandsWithScopeAndLanguage(s.scope, lang, b.withAliases(translateCommands(s.commands, translations))...)
+			if _, err := b.Request(cfg); err != nil {
+				return fmt.Errorf("could not set %s commands for %s scope: %w", lang, s.scope.Type, err)
+			}
+		}
+	}
+
+	// Library has no config for setChatMenuButton yet.
+	params := tgbotapi.Params{"menu_button": `{"type":"commands"}`}
+	if _, err := b.MakeRequest("setChatMenuButton", params); err != nil {
+		return fmt.Errorf("could not set menu button: %w", err)
+	}
+	return nil
+}
+
+// translateCommands keeps default description of commands missing in translations.
+func translateCommands(commands []tgbotapi.BotCommand, translations map[string]string) []tgbotapi.BotCommand {
+	res := make([]tgbotapi.BotCommand, len(commands))
+	for i, cmd := range commands {
+		res[i] = cmd
+		if description, ok := translations[cmd.Command]; ok {
+			res[i].Description = description
+		}
+	}
+	return res
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackShowComments = "show_comments_"
+	callbackAddComment   = "add_comment_"
+
+	// shownComments is how many latest comments are shown in thread.
+	shownComments    = 10
+	maxCommentLength = 1000
+)
+
+// commentPromptRe matches bot's prompt for comment, reply to it is the comment.
+var commentPromptRe = regexp.MustCompile(`комментарием к задаче #(\d+)`)
+
+func commentsButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("💬 Комментарии", fmt.Sprintf("%s%d", callbackShowComments, task.ID))
+}
+
+func addCommentKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✍️ Добавить комментарий", fmt.Sprintf("%s%d", callbackAddComment, task.ID)),
+	))
+}
+
+// renderComments returns the latest comments of the task with their authors and time.
+func (b *Bot) renderComments(ctx context.Context, task *model.Task) (string, error) {
+	comments, total, err := b.taskStorage.FetchTaskComments(ctx, task.ID, shownComments)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch comments: %w", err)
+	}
+	if total == 0 {
+		return fmt.Sprintf("💬 у задачи #%d %s пока нет комментариев", task.ID, task.Title), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "💬 комментарии к задаче #%d %s", task.ID, task.Title)
+	if total > len(comments) {
+		fmt.Fprintf(&sb, " (последние %d из %d)", len(comments), total)
+	}
+	sb.WriteString(":\n")
+	for _, comment := range comments {
+		name, err := b.userName(ctx, int(comment.AuthorID))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "\n%s, %s:\n%s\n", name, comment.CreatedAt.Format(format.DateTimeLayout), comment.Text)
+	}
+	return sb.String(), nil
+}
+
+// showCommentsCallback replies to task card with the latest comments, allowed for project members.
+func (b *Bot) showCommentsCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackShowComments)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	text, err := b.renderComments(ctx, task)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = addCommentKeyboard(task)
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send comments: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// addCommentCallback asks member who pressed the button to reply with comment.
+func (b *Bot) addCommentCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackAddComment)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"💬 %s, ответьте на это сообщение комментарием к задаче #%d", mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "проверил на стенде"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send comment prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleCommentReply saves reply of any project member as comment to the task,
+// it reports false if message is not a reply to comment prompt.
+func (b *Bot) handleCommentReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	anyMember := func(*model.Task, *model.User) bool { return true }
+	task, user, handled, err := b.fetchPromptedTaskFor(ctx, message, commentPromptRe, anyMember)
+	if err != nil || task == nil {
+		return handled, err
+	}
+	if !task.Status.IsOpen() {
+		return true, b.reply(message, "задача уже закрыта")
+	}
+
+	text := strings.TrimSpace(message.Text)
+	switch {
+	case text == "":
+		return true, b.reply(message, "ответьте на сообщение бота текстом комментария")
+	case len([]rune(text)) > maxCommentLength:
+		return true, b.reply(message, fmt.Sprintf("комментарий должен быть не длиннее %d символов", maxCommentLength))
+	}
+
+	comment := &model.TaskComment{TaskID: task.ID, AuthorID: int64(user.ID), Text: text}
+	if err = b.taskStorage.AddTaskComment(ctx, comment); err != nil {
+		return true, fmt.Errorf("could not add comment: %w", err)
+	}
+	log.Printf("DEBUG user id=%d commented task id=%d", user.ID, task.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("💬 комментарий к задаче #%d %s добавлен", task.ID, task.Title))
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(commentsButton(task)))
+	_, err = b.sendMessage(msg)
+	return true, err
+}
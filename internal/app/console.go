@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const (
+	// consoleMaxRows limits rows of /admin_sql answer.
+	consoleMaxRows = 50
+	// consoleTimeout stops /admin_sql query running too long, so it doesn't hold reader connection.
+	consoleTimeout = 5 * time.Second
+)
+
+// adminSQLCommand runs read-only query for operator in private chat,
+// other users get the same answer as for unknown command.
+func (b *Bot) adminSQLCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	if b.cfg.Console == nil || !message.Chat.IsPrivate() || !slices.Contains(b.cfg.Operators, message.From.ID) {
+		return b.reply(message, "Незнакомая команда.")
+	}
+
+	query := strings.TrimSpace(message.CommandArguments())
+	if query == "" {
+		return b.reply(message, fmt.Sprintf("укажите запрос: /admin_sql SELECT ..., вернётся не больше %d строк", consoleMaxRows))
+	}
+	if keyword := strings.ToUpper(strings.Fields(query)[0]); keyword != "SELECT" && keyword != "WITH" {
+		return b.reply(message, "можно выполнять только SELECT")
+	}
+
+	log.Printf("INFO operator tg_id=%d runs query: %s", message.From.ID, query)
+	queryCtx, cancel := context.WithTimeout(ctx, consoleTimeout)
+	defer cancel()
+	res, err := b.cfg.Console.Query(queryCtx, query, consoleMaxRows)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return b.reply(message, fmt.Sprintf("⚠️ запрос прерван, он выполнялся дольше %s", consoleTimeout))
+	} else if err != nil {
+		return b.reply(message, "⚠️ "+err.Error())
+	}
+
+	return b.reply(message, formatQueryResult(res, telegramMessageLimit))
+}
+
+// formatQueryResult formats rows which fit into limit of UTF-16 code units with count of rows,
+// the first row is cut if even it doesn't fit.
+func formatQueryResult(res *model.QueryResult, limit int) string {
+	count := strconv.Itoa(len(res.Rows))
+	if res.Truncated {
+		count = "больше " + count
+	}
+	footer := func(shown int) string {
+		if shown == len(res.Rows) && !res.Truncated {
+			return fmt.Sprintf("\n\nстрок: %s", count)
+		}
+		return fmt.Sprintf("\n\nстрок: %s, показаны первые %d", count, shown)
+	}
+	// Room is left for the longest footer, which is written when some rows are not shown
+	room := limit - utf16Len(fmt.Sprintf("\n\nстрок: %s, показаны первые %d", count, len(res.Rows)))
+
+	var sb strings.Builder
+	sb.WriteString(cutText(strings.Join(res.Columns, " | "), room))
+	size := utf16Len(sb.String())
+	shown := 0
+	for _, row := range res.Rows {
+		line := "\n" + strings.Join(row, " | ")
+		lineSize := utf16Len(line)
+		if size+lineSize > room {
+			if shown == 0 && size+1 < room {
+				sb.WriteString(cutText(line, room-size))
+				shown++
+			}
+			break
+		}
+		sb.WriteString(line)
+		size += lineSize
+		shown++
+	}
+	sb.WriteString(footer(shown))
+	return sb.String()
+}
+
+// cutText cuts text to limit of UTF-16 code units marking the cut with ellipsis.
+func cutText(text string, limit int) string {
+	if utf16Len(text) <= limit {
+		return text
+	}
+	var (
+		sb   strings.Builder
+		size int
+	)
+	for _, r := range text {
+		if size+utf16.RuneLen(r)+1 > limit {
+			break
+		}
+		sb.WriteRune(r)
+		size += utf16.RuneLen(r)
+	}
+	return sb.String() + "…"
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func TestFormatQueryResult(t *testing.T) {
+	tests := []struct {
+		name  string
+		res   *model.QueryResult
+		limit int
+		want  string
+	}{
+		{
+			name:  "all rows",
+			res:   &model.QueryResult{Columns: []string{"id", "title"}, Rows: [][]string{{"1", "a"}, {"2", "b"}}},
+			limit: telegramMessageLimit,
+			want:  "id | title\n1 | a\n2 | b\n\nстрок: 2",
+		},
+		{
+			name:  "rows over query limit",
+			res:   &model.QueryResult{Columns: []string{"id"}, Rows: [][]string{{"1"}, {"2"}}, Truncated: true},
+			limit: telegramMessageLimit,
+			want:  "id\n1\n2\n\nстрок: больше 2, показаны первые 2",
+		},
+		{
+			name:  "rows over message limit",
+			res:   &model.QueryResult{Columns: []string{"id"}, Rows: [][]string{{"1"}, {"2"}, {"3"}}},
+			limit: 36,
+			want:  "id\n1\n2\n\nстрок: 3, показаны первые 2",
+		},
+		{
+			name:  "first row over message limit",
+			res:   &model.QueryResult{Columns: []string{"text"}, Rows: [][]string{{"😀😀😀😀😀😀"}, {"b"}}},
+			limit: 40,
+			want:  "text\n😀😀…\n\nстрок: 2, показаны первые 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatQueryResult(tt.res, tt.limit)
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			if size := utf16Len(got); size > tt.limit {
+				t.Fatalf("got %d UTF-16 code units, limit %d", size, tt.limit)
+			}
+		})
+	}
+
+	rows := make([][]string, consoleMaxRows)
+	for i := range rows {
+		rows[i] = []string{strings.Repeat("x", 200)}
+	}
+	got := formatQueryResult(&model.QueryResult{Columns: []string{"text"}, Rows: rows}, telegramMessageLimit)
+	if size := utf16Len(got); size > telegramMessageLimit {
+		t.Fatalf("long result: got %d UTF-16 code units, limit %d", size, telegramMessageLimit)
+	}
+	if !strings.HasSuffix(got, "показаны первые 20") {
+		t.Fatalf("long result: got footer %q", got[strings.LastIndex(got, "\n"):])
+	}
+}
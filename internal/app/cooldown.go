@@ -0,0 +1,68 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// resultCache keeps results of expensive queries and commands, so repeated calls within ttl reuse them.
+type resultCache[K comparable, V any] struct {
+	mu    sync.Mutex
+	items map[K]cachedResult[V]
+}
+
+// commandKey identifies result of command in chat.
+type commandKey struct {
+	command string
+	chatID  int64
+}
+
+type cachedResult[V any] struct {
+	value     V
+	createdAt time.Time
+}
+
+// Get returns result if it was put less than ttl ago.
+func (c *resultCache[K, V]) Get(key K, ttl time.Duration) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok || time.Since(item.createdAt) >= ttl {
+		var zero V
+		return zero, false
+	}
+	return item.value, true
+}
+
+func (c *resultCache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.items == nil {
+		c.items = make(map[K]cachedResult[V])
+	}
+	c.items[key] = cachedResult[V]{value: value, createdAt: time.Now()}
+}
+
+func (c *resultCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, key)
+}
+
+// Prune removes results created before given time and returns number of removed results.
+func (c *resultCache[K, V]) Prune(before time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key, item := range c.items {
+		if item.createdAt.Before(before) {
+			delete(c.items, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// taskCountersTTL limits how long cached counters are used, overdue tasks are counted without task events.
+const taskCountersTTL = time.Minute
+
+// fetchTaskCounters returns counters of project's tasks, cached until tasks of the project change.
+func (b *Bot) fetchTaskCounters(ctx context.Context, projectID int) (model.TaskCounters, error) {
+	if counters, ok := b.taskCounters.Get(projectID, taskCountersTTL); ok {
+		return counters, nil
+	}
+	counters, err := b.taskStorage.FetchTaskCounters(ctx, projectID, time.Now())
+	if err != nil {
+		return model.TaskCounters{}, fmt.Errorf("could not fetch task counters: %w", err)
+	}
+	b.taskCounters.Put(projectID, counters)
+	return counters, nil
+}
+
+func (b *Bot) resetTaskCountersOnTaskEvent(_ context.Context, event model.TaskEvent) {
+	b.taskCounters.Delete(event.Task.ProjectID)
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// parseDate parses date in "02.01.2006" or "02.01" form, date without year is the nearest such date from now.
+// Result is the end of the day, so task due today is not overdue until the day ends.
+func parseDate(s string, now time.Time) (time.Time, bool) {
+	if t, err := time.ParseInLocation(format.DateLayout, s, now.Location()); err == nil {
+		return endOfDay(t), true
+	}
+
+	t, err := time.ParseInLocation("02.01", s, now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+	t = time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+	if endOfDay(t).Before(now) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return endOfDay(t), true
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// defaultDeadline returns deadline for project's new task created without one, zero if project has no default.
+func defaultDeadline(prj *model.Project, now time.Time) time.Time {
+	if prj.DefaultDeadlineDays <= 0 {
+		return time.Time{}
+	}
+	return endOfDay(now.AddDate(0, 0, prj.DefaultDeadlineDays))
+}
+
+// deadlinePrepositions may precede deadline phrase, e.g. "к пятнице" or "до 25.10".
+var deadlinePrepositions = []string{"к", "до", "на", "в", "во"}
+
+var weekdayForms = map[string]time.Weekday{
+	"понедельник": time.Monday, "понедельнику": time.Monday, "понедельника": time.Monday,
+	"вторник": time.Tuesday, "вторнику": time.Tuesday, "вторника": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "среде": time.Wednesday, "среды": time.Wednesday,
+	"четверг": time.Thursday, "четвергу": time.Thursday, "четверга": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пятнице": time.Friday, "пятницы": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "субботе": time.Saturday, "субботы": time.Saturday,
+	"воскресенье": time.Sunday, "воскресенью": time.Sunday, "воскресенья": time.Sunday,
+}
+
+// parseDeadlinePhrase finds deadline written in words at the end of text, e.g. "к пятнице", "до завтра",
+// "через 3 дня" or "к 25.10", and returns text without it. Weekday means the nearest one after today.
+func parseDeadlinePhrase(text string, now time.Time) (string, time.Time, bool) {
+	words := strings.Fields(text)
+	if len(words) < 2 {
+		return text, time.Time{}, false
+	}
+	last := strings.ToLower(strings.TrimRight(words[len(words)-1], ".,!?"))
+
+	// Number of trailing words making the phrase
+	n := 1
+	var day time.Time
+	if t, ok := parseDate(last, now); ok {
+		day = t
+	} else if weekday, ok := weekdayForms[last]; ok {
+		day = now.AddDate(0, 0, (int(weekday)-int(now.Weekday())+6)%7+1)
+	} else {
+		switch last {
+		case "сегодня":
+			day = now
+		case "завтра":
+			day = now.AddDate(0, 0, 1)
+		case "послезавтра":
+			day = now.AddDate(0, 0, 2)
+		case "день", "дня", "дней", "неделю", "недели", "недель":
+			unit, count := 1, 1
+			if strings.HasPrefix(last, "недел") {
+				unit = 7
+			}
+			if c, err := strconv.Atoi(words[len(words)-2]); err == nil {
+				count, n = c, 2
+			}
+			if len(words) < n+2 || !strings.EqualFold(words[len(words)-n-1], "через") || count <= 0 || count*unit > 365 {
+				return text, time.Time{}, false
+			}
+			return strings.Join(words[:len(words)-n-1], " "), endOfDay(now.AddDate(0, 0, count*unit)), true
+		default:
+			return text, time.Time{}, false
+		}
+	}
+
+	if len(words) > n && slices.Contains(deadlinePrepositions, strings.ToLower(words[len(words)-n-1])) {
+		n++
+	}
+	return strings.Join(words[:len(words)-n], " "), endOfDay(day), true
+}
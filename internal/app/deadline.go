@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxDefaultDeadlineDays = 365
+
+// defaultDeadlineCommand sets number of days given to new tasks without deadline, "/default_deadline 0" disables it.
+func (b *Bot) defaultDeadlineCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	args := strings.TrimSpace(update.Message.CommandArguments())
+	if args == "" {
+		text := "новые задачи без срока остаются без дедлайна"
+		if prj.DefaultDeadlineDays > 0 {
+			text = "новые задачи без срока получают дедлайн через " + daysText(prj.DefaultDeadlineDays)
+		}
+		return b.reply(update.Message, text+"\n\nИзменить: /default_deadline N, отключить: /default_deadline 0")
+	}
+
+	days, err := strconv.Atoi(args)
+	if err != nil || days < 0 || days > maxDefaultDeadlineDays {
+		return b.reply(update.Message, fmt.Sprintf("укажите число дней от 0 до %d", maxDefaultDeadlineDays))
+	}
+
+	prj.DefaultDeadlineDays = days
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d default deadline set to %d days", prj.ID, days)
+
+	text, change := "⏰ срок по умолчанию отключён", "срок по умолчанию отключён"
+	if days > 0 {
+		text = "⏰ новые задачи без срока получат дедлайн через " + daysText(days)
+		change = "срок по умолчанию — " + daysText(days)
+	}
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+func daysText(n int) string {
+	return format.Count(n, "день", "дня", "дней")
+}
+
+// noDeadlineCommand lists project's open tasks which still lack deadline.
+func (b *Bot) noDeadlineCommand(ctx context.Context, update tgbotapi.Update) error {
+	if update.Message.Chat.IsPrivate() {
+		return b.reply(update.Message, "команда доступна только в чате проекта")
+	}
+
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, update.Message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(update.Message, "сначала создайте проект командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		ProjectID:       prj.ID,
+		WithoutDeadline: true,
+		OnlyOpen:        true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return b.reply(update.Message, "👍 у всех открытых задач есть срок")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📭 задачи без срока: %d\n\n", len(tasks))
+	for _, task := range tasks {
+		fmt.Fprintf(&sb, "• %s#%d %s (%s)\n", task.Priority.Marker(), task.ID, task.Title, task.Status.StringLocalized())
+	}
+	return b.reply(update.Message, sb.String())
+}
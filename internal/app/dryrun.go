@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dryRunClient delivers only reading Bot API requests, other methods are logged and answered with fake success.
+type dryRunClient struct {
+	client tgbotapi.HTTPClient
+	// echoChatID receives copies of sent messages, disabled if 0.
+	echoChatID int64
+	messageID  atomic.Int64
+}
+
+func (c *dryRunClient) Do(req *http.Request) (*http.Response, error) {
+	method := path.Base(req.URL.Path)
+	if strings.HasPrefix(method, "get") {
+		return c.client.Do(req)
+	}
+
+	var params url.Values
+	if req.Body != nil && req.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if params, err = url.ParseQuery(string(body)); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("INFO dry run: %s %v", method, params)
+
+	chatID, _ := strconv.ParseInt(params.Get("chat_id"), 10, 64)
+	if method == "sendMessage" && c.echoChatID != 0 && chatID != c.echoChatID {
+		c.echo(req, chatID, params.Get("text"))
+	}
+	return c.fakeResponse(chatID)
+}
+
+// echo sends copy of message to echo chat for review.
+func (c *dryRunClient) echo(orig *http.Request, chatID int64, text string) {
+	params := url.Values{}
+	params.Set("chat_id", strconv.FormatInt(c.echoChatID, 10))
+	params.Set("text", fmt.Sprintf("[dry run → %d]\n%s", chatID, text))
+
+	req, err := http.NewRequestWithContext(orig.Context(), http.MethodPost, orig.URL.String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		log.Printf("ERROR dry run: could not create echo request: %s", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		log.Printf("ERROR dry run: could not echo message: %s", err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// fakeResponse returns message as result, which suits callers expecting both message and boolean.
+func (c *dryRunClient) fakeResponse(chatID int64) (*http.Response, error) {
+	msg := tgbotapi.Message{
+		MessageID: int(c.messageID.Add(1)),
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: chatID},
+	}
+	result, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(tgbotapi.APIResponse{Ok: true, Result: result})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
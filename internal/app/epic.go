@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const epicUsage = "Сделать задачу эпиком: /epic 12, добавить в эпик задачи: /epic 12 #15 #16"
+
+// epicCommand lists project's epics with progress, "/epic N #child..." promotes task N into epic
+// and moves listed tasks into it, changes are allowed for managers.
+func (b *Bot) epicCommand(ctx context.Context, update tgbotapi.Update) error {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return b.listEpics(ctx, update.Message)
+	}
+
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil {
+			return b.reply(update.Message, fmt.Sprintf("не понял номер задачи %q\n\n%s", arg, epicUsage))
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	var tasks []*model.Task
+	for _, id := range ids {
+		task, err := b.taskStorage.FetchTaskByID(ctx, id)
+		if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+			return fmt.Errorf("could not fetch task: %w", err)
+		}
+		if task == nil || task.ProjectID != prj.ID {
+			return b.reply(update.Message, fmt.Sprintf("задачи #%d нет в проекте", id))
+		}
+		tasks = append(tasks, task)
+	}
+
+	epic, children := tasks[0], tasks[1:]
+	if epic.ParentID != 0 {
+		return b.reply(update.Message, fmt.Sprintf("задача #%d входит в эпик #%d, эпики не вкладываются друг в друга", epic.ID, epic.ParentID))
+	}
+	for _, child := range children {
+		if child.Epic {
+			return b.reply(update.Message, fmt.Sprintf("задача #%d — эпик, эпики не вкладываются друг в друга", child.ID))
+		}
+	}
+
+	var changed []*model.Task
+	if !epic.Epic {
+		epic.Epic = true
+		changed = append(changed, epic)
+	}
+	for _, child := range children {
+		if child.ParentID != epic.ID {
+			child.ParentID = epic.ID
+			changed = append(changed, child)
+		}
+	}
+	if len(changed) > 0 {
+		for _, task := range changed {
+			task.UpdatedBy = int64(user.ID)
+		}
+		if err = b.taskStorage.UpdateTasks(ctx, changed); err != nil {
+			return fmt.Errorf("could not update tasks: %w", err)
+		}
+		for _, task := range changed {
+			b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+		}
+		log.Printf("DEBUG user id=%d made task id=%d epic of %d tasks", user.ID, epic.ID, len(children))
+	}
+
+	progress, err := b.renderEpicProgress(ctx, epic)
+	if err != nil {
+		return err
+	}
+	return b.reply(update.Message, fmt.Sprintf("🗂 эпик #%d %s: %s", epic.ID, epic.Title, progress))
+}
+
+func (b *Bot) listEpics(ctx context.Context, message *tgbotapi.Message) error {
+	if message.Chat.IsPrivate() {
+		return b.reply(message, "команда доступна только в чате проекта")
+	}
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(message, "сначала создайте проект командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID})
+	if err != nil {
+		return fmt.Errorf("could not fetch project tasks: %w", err)
+	}
+	var sb strings.Builder
+	for _, task := range tasks {
+		if !task.Epic {
+			continue
+		}
+		done, total := model.EpicProgress(task.ID, tasks)
+		fmt.Fprintf(&sb, "%s %s#%d %s — %s\n", task.Status.Emoji(), task.Priority.Marker(), task.ID, task.Title, epicProgressText(done, total))
+	}
+	if sb.Len() == 0 {
+		return b.reply(message, "в проекте нет эпиков\n\n"+epicUsage)
+	}
+	return b.reply(message, "🗂 эпики проекта:\n\n"+sb.String()+"\n"+epicUsage)
+}
+
+func (b *Bot) renderEpicProgress(ctx context.Context, epic *model.Task) (string, error) {
+	children, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ParentID: epic.ID})
+	if err != nil {
+		return "", fmt.Errorf("could not fetch epic tasks: %w", err)
+	}
+	return epicProgressText(model.EpicProgress(epic.ID, children)), nil
+}
+
+func epicProgressText(done, total int) string {
+	if total == 0 {
+		return "задач пока нет"
+	}
+	return fmt.Sprintf("выполнено %d из %d", done, total)
+}
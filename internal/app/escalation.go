@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxEscalationDays = 30
+
+// escalationCommand sets number of days before deadline when open tasks get higher priority,
+// "/escalation 0" disables it.
+func (b *Bot) escalationCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	args := strings.TrimSpace(update.Message.CommandArguments())
+	if args == "" {
+		text := "приоритет задач перед сроком не повышается"
+		if prj.EscalationDays > 0 {
+			text = "приоритет открытых задач повышается за " + daysText(prj.EscalationDays) + " до срока"
+		}
+		return b.reply(update.Message, text+"\n\nИзменить: /escalation N, отключить: /escalation 0")
+	}
+
+	days, err := strconv.Atoi(args)
+	if err != nil || days < 0 || days > maxEscalationDays {
+		return b.reply(update.Message, fmt.Sprintf("укажите число дней от 0 до %d", maxEscalationDays))
+	}
+
+	prj.EscalationDays = days
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d escalation set to %d days", prj.ID, days)
+
+	text, change := "⏫ повышение приоритета перед сроком отключено", "повышение приоритета отключено"
+	if days > 0 {
+		text = "⏫ приоритет открытых задач будет повышаться за " + daysText(days) + " до срока"
+		change = "повышение приоритета за " + daysText(days) + " до срока"
+	}
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+// StartPriorityEscalation raises priority of open tasks by one level once their deadline comes within escalation days
+// of their project, moves them to the top of lists and tells project chat. It checks deadlines every interval
+// until context is cancelled.
+func (b *Bot) StartPriorityEscalation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !b.holdsLease(ctx, "escalation", interval) {
+				continue
+			}
+			if err := b.escalateTasks(ctx, time.Now()); err != nil {
+				log.Printf("ERROR priority escalation: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) escalateTasks(ctx context.Context, now time.Time) error {
+	projects, err := b.projectStorage.ListProjects(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch projects: %w", err)
+	}
+	for _, prj := range projects {
+		if prj.EscalationDays == 0 || prj.Archived || prj.Frozen {
+			continue
+		}
+		if err = b.escalateProjectTasks(ctx, &prj, now); err != nil {
+			log.Printf("ERROR priority escalation: project id=%d: %s", prj.ID, err)
+		}
+	}
+	return nil
+}
+
+// escalateProjectTasks marks every task due within escalation days escalated, so priority is raised once
+// and manager can lower it back. Urgent tasks are only marked.
+func (b *Bot) escalateProjectTasks(ctx context.Context, prj *model.Project, now time.Time) error {
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		ProjectID:    prj.ID,
+		Deadline:     endOfDay(now.AddDate(0, 0, prj.EscalationDays)),
+		OnlyOpen:     true,
+		NotEscalated: true,
+		WithoutMuted: true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+
+	var escalated []*model.Task
+	for i := range tasks {
+		task := &tasks[i]
+		if priority, ok := raisedPriority(task.Priority); ok {
+			prev := task.Clone()
+			task.Priority = priority
+			// Bot raises priority on its own, so change has no author
+			task.UpdatedBy = 0
+			if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+				return fmt.Errorf("could not update task: %w", err)
+			}
+			b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev})
+			escalated = append(escalated, task)
+		}
+		if err = b.taskStorage.SetTaskEscalated(ctx, task.ID, true); err != nil {
+			return fmt.Errorf("could not mark task escalated: %w", err)
+		}
+	}
+	if len(escalated) == 0 {
+		return nil
+	}
+
+	// Task moved to the top last ends up first, so the nearest deadline leads lists
+	slices.SortStableFunc(escalated, func(a, b *model.Task) int { return b.Deadline.Compare(a.Deadline) })
+	for _, task := range escalated {
+		if err = b.taskStorage.MoveTask(ctx, task.ID, model.TaskMoveTop); err != nil {
+			return fmt.Errorf("could not move task to top: %w", err)
+		}
+	}
+	log.Printf("DEBUG priority escalation: raised priority of %d tasks of project id=%d", len(escalated), prj.ID)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "⏫ приблизился срок, приоритет повышен: %d\n\n", len(escalated))
+	for i := len(escalated) - 1; i >= 0; i-- {
+		task := escalated[i]
+		fmt.Fprintf(&sb, "• %s — %s#%d %s\n", task.Deadline.Format(format.DateLayout), task.Priority.Marker(), task.ID, task.Title)
+	}
+	_, err = b.sendMessage(tgbotapi.NewMessage(prj.TgChatID, sb.String()))
+	return err
+}
+
+// raisedPriority returns priority one level higher, false for urgent.
+func raisedPriority(priority model.TaskPriority) (model.TaskPriority, bool) {
+	i := slices.Index(model.TaskPriorities, priority)
+	if i < 0 {
+		i = slices.Index(model.TaskPriorities, model.TaskPriorityNormal)
+	}
+	if i == len(model.TaskPriorities)-1 {
+		return priority, false
+	}
+	return model.TaskPriorities[i+1], true
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func TestRaisedPriority(t *testing.T) {
+	tests := []struct {
+		priority model.TaskPriority
+		want     model.TaskPriority
+		ok       bool
+	}{
+		{model.TaskPriorityLow, model.TaskPriorityNormal, true},
+		{model.TaskPriorityNormal, model.TaskPriorityHigh, true},
+		{model.TaskPriorityHigh, model.TaskPriorityUrgent, true},
+		{model.TaskPriorityUrgent, model.TaskPriorityUrgent, false},
+		// Tasks saved before priorities had none, which means normal
+		{"", model.TaskPriorityHigh, true},
+	}
+	for _, tt := range tests {
+		got, ok := raisedPriority(tt.priority)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("raisedPriority(%q): got %q, %t, want %q, %t", tt.priority, got, ok, tt.want, tt.ok)
+		}
+	}
+}
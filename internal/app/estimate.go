@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackPickEstimate = "pick_estimate_"
+	// callbackSetEstimate is followed by story points and task id, e.g. "estimate_5_12", 0 clears estimate.
+	callbackSetEstimate = "estimate_"
+)
+
+func estimateButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	text := "🎯 Оценка"
+	if task.Estimate != 0 {
+		text = "🎯 " + formatEstimate(task.Estimate)
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%d", callbackPickEstimate, task.ID))
+}
+
+// estimateKeyboard replaces card buttons while member picks estimate,
+// "back" keeps current estimate and brings card buttons back.
+func estimateKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, points := range model.TaskEstimates {
+		text := strconv.Itoa(points)
+		if points == task.Estimate {
+			text = "• " + text
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%d_%d", callbackSetEstimate, points, task.ID)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row[:3], row[3:], tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✖️ Без оценки", fmt.Sprintf("%s0_%d", callbackSetEstimate, task.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("%s%d_%d", callbackSetEstimate, task.Estimate, task.ID)),
+	))
+}
+
+// pickEstimateCallback shows estimate picker on task card, allowed for project members.
+func (b *Bot) pickEstimateCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackPickEstimate)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, estimateKeyboard(task))
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, "оцените задачу в стори-поинтах")
+}
+
+// setEstimateCallback saves chosen estimate and refreshes task card, allowed for project members.
+func (b *Bot) setEstimateCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	rawPoints, rawID, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackSetEstimate), "_")
+	points, err := strconv.Atoi(rawPoints)
+	if err != nil || (points != 0 && !slices.Contains(model.TaskEstimates, points)) {
+		return fmt.Errorf("could not parse callback data %q", query.Data)
+	}
+	taskID, err := strconv.Atoi(rawID)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	if task.Estimate != points {
+		from := task.Estimate
+		task.Estimate = points
+		task.UpdatedBy = int64(user.ID)
+		if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+			return fmt.Errorf("could not update task: %w", err)
+		}
+		log.Printf("DEBUG user id=%d changed estimate of task id=%d from %d to %d", user.ID, task.ID, from, points)
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+	}
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, taskCardKeyboard(task))
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	if points == 0 {
+		return b.answerCallback(query.ID, "оценка снята")
+	}
+	return b.answerCallback(query.ID, "оценка: "+formatEstimate(points))
+}
+
+// formatEstimate shows story points, e.g. "5 SP".
+func formatEstimate(points int) string {
+	return fmt.Sprintf("%d SP", points)
+}
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// eventLogTTL is how long task events are logged, readers of the log poll it far more often.
+const eventLogTTL = 24 * time.Hour
+
+// logTaskEvent saves event for other processes, e.g. event streams of the bot when change is made by worker.
+func (b *Bot) logTaskEvent(ctx context.Context, event model.TaskEvent) {
+	if err := b.cfg.EventLog.AppendTaskEvent(ctx, event); err != nil {
+		log.Printf("ERROR could not log event of task id=%d: %s", event.Task.ID, err)
+	}
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// eventBus delivers events to subscribers synchronously in order of subscription.
+type eventBus[E any] struct {
+	mu       sync.RWMutex
+	handlers []func(ctx context.Context, event E)
+}
+
+func (e *eventBus[E]) Subscribe(h func(ctx context.Context, event E)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.handlers = append(e.handlers, h)
+}
+
+func (e *eventBus[E]) Publish(ctx context.Context, event E) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, h := range e.handlers {
+		h(ctx, event)
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const frozenProjectText = "🧊 проект заморожен, изменения недоступны. Менеджер может разморозить его командой /freeze"
+
+var (
+	// frozenCommands change project when run, commands mapped to false change it only when given arguments.
+	frozenCommands = map[string]bool{
+		"quick_capture":    true,
+		"import_tasks":     true,
+		"share_board":      true,
+		"default_deadline": false,
+		"escalation":       false,
+		"holidays":         false,
+		"shift_deadlines":  false,
+		"epic":             false,
+		"repeat":           false,
+		"create_task":      false,
+		"template":         false,
+		"links":            false,
+		"welcome":          false,
+	}
+	// frozenCallbacks are prefixes of buttons which change tasks.
+	frozenCallbacks = []string{
+		callbackUndoTask,
+		callbackCreateDuplicate,
+		callbackCreateFromTemplate,
+		callbackImportConfirm,
+		callbackShiftConfirm,
+		callbackPostponeRequest,
+		callbackPostponeApprove,
+		callbackHandoverRequest,
+		callbackHandoverAccept,
+		callbackSetReviewer,
+		callbackSetCoAssignees,
+		callbackToggleMute,
+		callbackFullTitle,
+		callbackGroomPromote,
+		callbackGroomCancel,
+		callbackStartTask,
+		callbackFinishTask,
+		callbackHoldTask,
+		callbackAcceptReview,
+		callbackReturnReview,
+		callbackPickPriority,
+		callbackSetPriority,
+		callbackPickEstimate,
+		callbackSetEstimate,
+		callbackMoveTask,
+		callbackRestoreTask,
+		callbackPurgeTask,
+		callbackEditField,
+		callbackAddChecklistItems,
+		callbackToggleChecklistItem,
+		callbackRemoveChecklistItem,
+		callbackAddComment,
+		callbackAddAttachment,
+		// Tokens can still be revoked
+		callbackIssueAPIToken,
+	}
+)
+
+// freezeCommand toggles read-only mode of project, e.g. for an audit, allowed for managers.
+func (b *Bot) freezeCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	prj.Frozen = !prj.Frozen
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("INFO user id=%d set project id=%d frozen to %t", user.ID, prj.ID, prj.Frozen)
+
+	text, change := "🌤 проект разморожен, задачи снова можно менять", "проект разморожен"
+	if prj.Frozen {
+		text = "🧊 проект заморожен: задачи и настройки нельзя менять, просмотр доступен. Разморозить: /freeze"
+		change = "проект заморожен"
+	}
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+// refuseFrozenCommand replies with explanation when command would change frozen project.
+func (b *Bot) refuseFrozenCommand(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	always, ok := frozenCommands[message.Command()]
+	if !ok || (!always && strings.TrimSpace(message.CommandArguments()) == "") || message.Chat.IsPrivate() {
+		return false, nil
+	}
+	frozen, err := b.projectFrozen(ctx, message.Chat.ID)
+	if err != nil || !frozen {
+		return false, err
+	}
+	return true, b.reply(message, frozenProjectText)
+}
+
+// refuseFrozenCallback answers with explanation when button would change frozen project.
+// Buttons in private chats are checked by their handlers, which know the project.
+func (b *Bot) refuseFrozenCallback(ctx context.Context, query *tgbotapi.CallbackQuery) (bool, error) {
+	if query.Message == nil || query.Message.Chat.IsPrivate() {
+		return false, nil
+	}
+	mutating := slices.ContainsFunc(frozenCallbacks, func(prefix string) bool {
+		return strings.HasPrefix(query.Data, prefix)
+	})
+	if !mutating {
+		return false, nil
+	}
+	frozen, err := b.projectFrozen(ctx, query.Message.Chat.ID)
+	if err != nil || !frozen {
+		return false, err
+	}
+	return true, b.answerCallback(query.ID, frozenProjectText)
+}
+
+func (b *Bot) projectFrozen(ctx context.Context, tgChatID int64) (bool, error) {
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, tgChatID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not fetch project: %w", err)
+	}
+	return prj.Frozen, nil
+}
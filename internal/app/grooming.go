@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackGroomPromote = "groom_promote_"
+	callbackGroomCancel  = "groom_cancel_"
+	callbackGroomKeep    = "groom_keep_"
+)
+
+type GroomingConfig struct {
+	Interval time.Duration
+	// Tasks is number of the oldest backlog tasks posted to each project.
+	Tasks int
+}
+
+// StartGrooming periodically asks projects to review their oldest backlog tasks until context is cancelled.
+func (b *Bot) StartGrooming(ctx context.Context, cfg GroomingConfig) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !b.holdsLease(ctx, "grooming", cfg.Interval) {
+				continue
+			}
+			if err := b.postGroomingPrompts(ctx, cfg.Tasks); err != nil {
+				log.Printf("ERROR grooming: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) postGroomingPrompts(ctx context.Context, limit int) error {
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{Status: model.TaskStatusBacklog, WithoutMuted: true})
+	if err != nil {
+		return fmt.Errorf("could not fetch backlog tasks: %w", err)
+	}
+
+	// Tasks are ordered by id, so the first ones of each project are the oldest.
+	var projectIDs []int
+	byProject := make(map[int][]model.Task)
+	for _, task := range tasks {
+		if _, ok := byProject[task.ProjectID]; !ok {
+			projectIDs = append(projectIDs, task.ProjectID)
+		}
+		if len(byProject[task.ProjectID]) < limit {
+			byProject[task.ProjectID] = append(byProject[task.ProjectID], task)
+		}
+	}
+
+	for _, projectID := range projectIDs {
+		prj, err := b.projectStorage.FetchProjectByID(ctx, projectID)
+		if err != nil {
+			log.Printf("ERROR grooming: could not fetch project id=%d: %s", projectID, err)
+			continue
+		}
+		if prj.Archived || prj.Frozen {
+			continue
+		}
+		if err = b.sendGroomingPrompt(prj, byProject[projectID]); err != nil {
+			log.Printf("ERROR grooming: could not send prompt to project id=%d: %s", projectID, err)
+			continue
+		}
+		log.Printf("DEBUG grooming: posted %d backlog tasks to project id=%d", len(byProject[projectID]), projectID)
+	}
+	return nil
+}
+
+func (b *Bot) sendGroomingPrompt(prj *model.Project, tasks []model.Task) error {
+	var (
+		sb   strings.Builder
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+	sb.WriteString("🧹 разбор бэклога: эти задачи давно ждут решения\n\n")
+	for _, task := range tasks {
+		fmt.Fprintf(&sb, "%s#%d %s\n", task.Priority.Marker(), task.ID, task.Title)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s #%d", model.TaskStatusTODO.Emoji(), task.ID),
+				fmt.Sprintf("%s%d", callbackGroomPromote, task.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s #%d", model.TaskStatusCancelled.Emoji(), task.ID),
+				fmt.Sprintf("%s%d", callbackGroomCancel, task.ID),
+			),
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("👌 #%d", task.ID),
+				fmt.Sprintf("%s%d", callbackGroomKeep, task.ID),
+			),
+		))
+	}
+	fmt.Fprintf(&sb, "\n%s — к выполнению, %s — отменить, 👌 — оставить в бэклоге",
+		model.TaskStatusTODO.Emoji(), model.TaskStatusCancelled.Emoji())
+
+	msg := tgbotapi.NewMessage(prj.TgChatID, sb.String())
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err := b.sendMessage(msg)
+	return err
+}
+
+// groomCallback applies decision about backlog task and removes its buttons, allowed for project members.
+func (b *Bot) groomCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	var (
+		prefix string
+		status model.TaskStatus
+	)
+	switch {
+	case strings.HasPrefix(query.Data, callbackGroomPromote):
+		prefix, status = callbackGroomPromote, model.TaskStatusTODO
+	case strings.HasPrefix(query.Data, callbackGroomCancel):
+		prefix, status = callbackGroomCancel, model.TaskStatusCancelled
+	default:
+		prefix, status = callbackGroomKeep, model.TaskStatusBacklog
+	}
+	taskID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.finishGrooming(query, taskID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if task.Status != model.TaskStatusBacklog {
+		return b.finishGrooming(query, taskID, "задача уже не в бэклоге")
+	}
+
+	if status != task.Status {
+		prev := task.Clone()
+		task.Status = status
+		task.UpdatedBy = int64(user.ID)
+		if err = b.updateWatchedTask(ctx, prev, task, user.ID); err != nil {
+			return fmt.Errorf("could not update task: %w", err)
+		}
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: user.ID})
+	}
+	log.Printf("DEBUG grooming: user id=%d left task id=%d with status %s", user.ID, task.ID, status)
+	return b.finishGrooming(query, taskID, fmt.Sprintf("#%d: %s %s", task.ID, status.Emoji(), status.StringLocalized()))
+}
+
+// finishGrooming removes buttons of the task from grooming prompt.
+func (b *Bot) finishGrooming(query *tgbotapi.CallbackQuery, taskID int, answer string) error {
+	if query.Message.ReplyMarkup != nil {
+		suffix := fmt.Sprintf("_%d", taskID)
+		// Empty keyboard must be sent as empty array to remove the last buttons.
+		rows := [][]tgbotapi.InlineKeyboardButton{}
+		for _, row := range query.Message.ReplyMarkup.InlineKeyboard {
+			if len(row) > 0 && row[0].CallbackData != nil && strings.HasSuffix(*row[0].CallbackData, suffix) {
+				continue
+			}
+			rows = append(rows, row)
+		}
+		edit := tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: rows})
+		if _, err := b.Send(edit); err != nil {
+			return fmt.Errorf("could not edit message: %w", err)
+		}
+	}
+	return b.answerCallback(query.ID, answer)
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackHandoverRequest = "handover_request_"
+	callbackHandoverAccept  = "handover_accept_"
+	callbackHandoverDecline = "handover_decline_"
+)
+
+// handoverPromptRe matches bot's prompt for new assignee, reply to it carries username.
+var handoverPromptRe = regexp.MustCompile(`передаёте задачу #(\d+)`)
+
+type handoverRequest struct {
+	taskID int
+	from   *model.User
+	to     *model.User
+	// Proposal message in project chat, it shows the outcome whichever copy of proposal was answered.
+	chatID    int64
+	messageID int
+}
+
+// handoverRequestCallback asks assignee of the task to reply with username of member taking it over.
+func (b *Bot) handoverRequestCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackHandoverRequest)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, user, ok, err := b.fetchAssignedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"🤝 %s, ответьте на это сообщение @username или упоминанием участника, которому передаёте задачу #%d", mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "@username"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send handover prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleHandoverReply offers task to member named in reply, both in project chat and privately,
+// it reports false if message is not a reply to handover prompt.
+func (b *Bot) handleHandoverReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	task, user, handled, err := b.fetchPromptedTask(ctx, message, handoverPromptRe)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	refs, _ := parseMemberRefs(message)
+	if len(refs) == 0 {
+		return true, b.reply(message, "ответьте на сообщение бота @username или упоминанием участника проекта")
+	}
+	target, err := b.fetchProjectMemberByRef(ctx, task.ProjectID, refs[0])
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return true, b.reply(message, fmt.Sprintf("%s не состоит в проекте", refs[0]))
+	} else if err != nil {
+		return true, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if target.ID == user.ID {
+		return true, b.reply(message, "вы уже исполнитель этой задачи")
+	}
+
+	text := fmt.Sprintf("🤝 %s предлагает %s взять задачу #%d %s", user.FullName, mention(target), task.ID, task.Title)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ReplyToMessageID = message.MessageID
+	proposal, err := b.sendMessage(msg)
+	if err != nil {
+		return true, fmt.Errorf("could not send handover proposal: %w", err)
+	}
+
+	requestID := b.handovers.Put(handoverRequest{
+		taskID:    task.ID,
+		from:      user,
+		to:        target,
+		chatID:    proposal.Chat.ID,
+		messageID: proposal.MessageID,
+	})
+	log.Printf("INFO user id=%d offered task id=%d to user id=%d", user.ID, task.ID, target.ID)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Принять", fmt.Sprintf("%s%d", callbackHandoverAccept, requestID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отказаться", fmt.Sprintf("%s%d", callbackHandoverDecline, requestID)),
+		),
+	)
+	edit := tgbotapi.NewEditMessageReplyMarkup(proposal.Chat.ID, proposal.MessageID, keyboard)
+	if _, err = b.Send(edit); err != nil {
+		return true, fmt.Errorf("could not edit message: %w", err)
+	}
+
+	private := tgbotapi.NewMessage(target.TgUserID, text)
+	private.ReplyMarkup = keyboard
+	if _, err = b.sendMessage(private); err != nil {
+		log.Printf("WARN could not offer task id=%d to user id=%d privately: %s", task.ID, target.ID, err)
+	}
+	return true, nil
+}
+
+func (b *Bot) handoverAcceptCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	request, ok, err := b.takeHandoverRequest(query, callbackHandoverAccept)
+	if err != nil || !ok {
+		return err
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, request.taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.finishHandover(query, request, fmt.Sprintf("задача #%d удалена", request.taskID))
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.Assignee != int64(request.from.ID) || !task.Status.IsOpen() {
+		return b.finishHandover(query, request, fmt.Sprintf("🤝 задача #%d уже не у %s, передача отменена", task.ID, request.from.FullName))
+	}
+	// Offer may be accepted in private chat, so frozen project is not checked before handler
+	prj, err := b.projectStorage.FetchProjectByID(ctx, task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if prj.Frozen {
+		return b.finishHandover(query, request, fmt.Sprintf("🧊 проект заморожен, передача задачи #%d отменена", task.ID))
+	}
+
+	prev := task.Clone()
+	task.Assignee = int64(request.to.ID)
+	task.CoAssignees = slices.DeleteFunc(task.CoAssignees, func(userID int64) bool { return userID == task.Assignee })
+	// Nobody reviews their own work
+	if task.Reviewer == task.Assignee {
+		task.Reviewer = 0
+	}
+	task.UpdatedBy = int64(request.to.ID)
+	text := fmt.Sprintf("🤝 %s принимает задачу #%d %s от %s", request.to.FullName, task.ID, task.Title, request.from.FullName)
+	if err = b.updateWatchedTask(ctx, prev, task, request.to.ID, model.NewNotification(int64(request.from.ID), text)); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("INFO user id=%d took over task id=%d from user id=%d", request.to.ID, task.ID, request.from.ID)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: request.to.ID})
+
+	return b.finishHandover(query, request, text)
+}
+
+func (b *Bot) handoverDeclineCallback(update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	request, ok, err := b.takeHandoverRequest(query, callbackHandoverDecline)
+	if err != nil || !ok {
+		return err
+	}
+	log.Printf("INFO user id=%d declined task id=%d offered by user id=%d", request.to.ID, request.taskID, request.from.ID)
+
+	text := fmt.Sprintf("🙅 %s не может взять задачу #%d, она остаётся у %s", request.to.FullName, request.taskID, request.from.FullName)
+	if _, err = b.sendMessage(tgbotapi.NewMessage(request.from.TgUserID, text)); err != nil {
+		log.Printf("WARN could not notify user id=%d about handover: %s", request.from.ID, err)
+	}
+	return b.finishHandover(query, request, text)
+}
+
+// takeHandoverRequest removes request from pending ones if callback is pressed by member task is offered to,
+// otherwise it answers callback with explanation.
+func (b *Bot) takeHandoverRequest(query *tgbotapi.CallbackQuery, prefix string) (handoverRequest, bool, error) {
+	requestID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return handoverRequest{}, false, fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	request, ok := b.handovers.Get(requestID)
+	if !ok {
+		return handoverRequest{}, false, b.answerCallback(query.ID, "предложение устарело или уже принято")
+	}
+	if request.to.TgUserID != query.From.ID {
+		return handoverRequest{}, false, b.answerCallback(query.ID, "ответить может только тот, кому предложена задача")
+	}
+	b.handovers.Delete(requestID)
+	return request, true, nil
+}
+
+// finishHandover shows outcome in place of proposal in project chat and in private copy if it was answered there.
+func (b *Bot) finishHandover(query *tgbotapi.CallbackQuery, request handoverRequest, text string) error {
+	edit := tgbotapi.NewEditMessageText(request.chatID, request.messageID, text)
+	if _, err := b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	if query.Message != nil && query.Message.Chat.ID != request.chatID {
+		if err := b.editCallbackMessage(query, text); err != nil {
+			return err
+		}
+	}
+	return b.answerCallback(query.ID, "")
+}
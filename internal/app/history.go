@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackShowHistory = "show_history_"
+
+	// shownChanges is how many latest changes are shown in history.
+	shownChanges = 20
+)
+
+var taskFieldNames = map[model.TaskField]string{
+	model.TaskFieldTitle:       "название",
+	model.TaskFieldDescription: "описание",
+	model.TaskFieldStatus:      "статус",
+	model.TaskFieldDeadline:    "срок",
+	model.TaskFieldAssignee:    "исполнитель",
+	model.TaskFieldCoAssignees: "соисполнители",
+	model.TaskFieldReviewer:    "ревьюер",
+	model.TaskFieldMuted:       "напоминания",
+	model.TaskFieldEpic:        "эпик",
+	model.TaskFieldParent:      "входит в эпик",
+	model.TaskFieldPriority:    "приоритет",
+	model.TaskFieldLabels:      "метки",
+	model.TaskFieldEstimate:    "оценка",
+}
+
+func historyButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🕓 История", fmt.Sprintf("%s%d", callbackShowHistory, task.ID))
+}
+
+// showHistoryCallback replies to task card with the latest changes of the task, allowed for project members.
+func (b *Bot) showHistoryCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackShowHistory)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, _, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	text, err := b.renderHistory(ctx, task)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ReplyToMessageID = query.Message.MessageID
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send history: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// renderHistory returns the latest changes of the task, changes saved together are grouped under their author and time.
+func (b *Bot) renderHistory(ctx context.Context, task *model.Task) (string, error) {
+	changes, total, err := b.taskStorage.FetchTaskHistory(ctx, task.ID, shownChanges)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch history: %w", err)
+	}
+	if total == 0 {
+		return fmt.Sprintf("🕓 задача #%d %s ещё не менялась", task.ID, task.Title), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🕓 история задачи #%d %s", task.ID, task.Title)
+	if total > len(changes) {
+		fmt.Fprintf(&sb, " (последние %d из %d)", len(changes), total)
+	}
+	sb.WriteString(":\n")
+	for i, change := range changes {
+		if i == 0 || change.UserID != changes[i-1].UserID || !change.ChangedAt.Equal(changes[i-1].ChangedAt) {
+			name, err := b.userName(ctx, int(change.UserID))
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&sb, "\n%s, %s:\n", change.ChangedAt.Local().Format(format.DateTimeLayout), name)
+		}
+		line, err := b.renderChange(ctx, change)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String(), nil
+}
+
+func (b *Bot) renderChange(ctx context.Context, change model.TaskChange) (string, error) {
+	name := taskFieldNames[change.Field]
+	if name == "" {
+		name = string(change.Field)
+	}
+	switch change.Field {
+	case model.TaskFieldDescription:
+		if change.OldValue == "" {
+			return "описание добавлено", nil
+		} else if change.NewValue == "" {
+			return "описание удалено", nil
+		}
+		return "описание изменено", nil
+	case model.TaskFieldMuted:
+		if change.NewValue == "true" {
+			return "напоминания: 🔕 отключены", nil
+		}
+		return "напоминания: 🔔 включены", nil
+	case model.TaskFieldEpic:
+		if change.NewValue == "true" {
+			return "стала эпиком", nil
+		}
+		return "больше не эпик", nil
+	}
+
+	oldValue, err := b.renderChangeValue(ctx, change.Field, change.OldValue)
+	if err != nil {
+		return "", err
+	}
+	newValue, err := b.renderChangeValue(ctx, change.Field, change.NewValue)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s: %s → %s", name, oldValue, newValue), nil
+}
+
+// renderChangeValue shows value of task field saved in history, users are shown by their names.
+func (b *Bot) renderChangeValue(ctx context.Context, field model.TaskField, value string) (string, error) {
+	if value == "" {
+		return "—", nil
+	}
+	switch field {
+	case model.TaskFieldTitle:
+		return "«" + value + "»", nil
+	case model.TaskFieldStatus:
+		status := model.TaskStatus(value)
+		return status.Emoji() + " " + status.StringLocalized(), nil
+	case model.TaskFieldPriority:
+		priority := model.TaskPriority(value)
+		return priority.Emoji() + " " + priority.StringLocalized(), nil
+	case model.TaskFieldDeadline:
+		deadline, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return value, nil
+		}
+		return deadline.Local().Format(format.DateLayout), nil
+	case model.TaskFieldParent:
+		return "#" + value, nil
+	case model.TaskFieldEstimate:
+		return value + " SP", nil
+	case model.TaskFieldLabels:
+		return formatLabels(strings.Split(value, ",")), nil
+	case model.TaskFieldAssignee, model.TaskFieldReviewer, model.TaskFieldCoAssignees:
+		var names []string
+		for _, raw := range strings.Split(value, ",") {
+			userID, err := strconv.Atoi(raw)
+			if err != nil {
+				return value, nil
+			}
+			name, err := b.userName(ctx, userID)
+			if err != nil {
+				return "", err
+			}
+			names = append(names, name)
+		}
+		return strings.Join(names, ", "), nil
+	}
+	return value, nil
+}
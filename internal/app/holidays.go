@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxProjectHolidays = 100
+
+// holidaysCommand shows or replaces project's days off besides weekends, "/holidays off" clears them.
+func (b *Bot) holidaysCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		days, err := b.projectStorage.FetchProjectHolidays(ctx, prj.ID)
+		if err != nil {
+			return fmt.Errorf("could not fetch holidays: %w", err)
+		}
+		text := "выходные проекта — суббота и воскресенье, праздников нет"
+		if len(days) > 0 {
+			text = "выходные проекта — суббота, воскресенье и праздники: " + holidaysText(days)
+		}
+		return b.reply(update.Message, text+"\n\nЗадать праздники: /holidays 01.01 07.01 09.05, очистить: /holidays off")
+	}
+
+	var days []time.Time
+	if len(args) != 1 || args[0] != "off" {
+		if len(args) > maxProjectHolidays {
+			return b.reply(update.Message, fmt.Sprintf("можно задать не больше %d праздников", maxProjectHolidays))
+		}
+		now := time.Now()
+		for _, arg := range args {
+			day, ok := parseDate(arg, now)
+			if !ok {
+				return b.reply(update.Message, fmt.Sprintf("не понял дату %q, укажите даты в формате ДД.ММ или ДД.ММ.ГГГГ", arg))
+			}
+			days = append(days, startOfDay(day))
+		}
+		slices.SortFunc(days, time.Time.Compare)
+	}
+
+	if err = b.projectStorage.SetProjectHolidays(ctx, prj.ID, days); err != nil {
+		return fmt.Errorf("could not set holidays: %w", err)
+	}
+	log.Printf("DEBUG project id=%d holidays set to %d days", prj.ID, len(days))
+
+	text, change := "🗓 праздники очищены, выходные — суббота и воскресенье", "праздники очищены"
+	if len(days) > 0 {
+		text = "🗓 праздники проекта: " + holidaysText(days)
+		change = "праздники — " + holidaysText(days)
+	}
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+func holidaysText(days []time.Time) string {
+	dates := make([]string, len(days))
+	for i, day := range days {
+		dates[i] = day.Format(format.DateLayout)
+	}
+	return strings.Join(dates, ", ")
+}
+
+// dayOffChecker reports whether date is weekend or one of project's holidays.
+type dayOffChecker func(t time.Time) bool
+
+func (b *Bot) projectDaysOff(ctx context.Context, projectID int) (dayOffChecker, error) {
+	days, err := b.projectStorage.FetchProjectHolidays(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch holidays: %w", err)
+	}
+	return func(t time.Time) bool {
+		if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+			return true
+		}
+		day := startOfDay(t)
+		return slices.ContainsFunc(days, day.Equal)
+	}, nil
+}
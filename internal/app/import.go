@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackImportConfirm = "import_confirm_"
+	callbackImportCancel  = "import_cancel_"
+
+	importMaxTasks = 50
+)
+
+// Number must be followed by space, so line starting with date like "25.10" keeps it
+var listBulletRe = regexp.MustCompile(`^(?:[-*•–—]\s*|\d+[.)](?:\s+|$))`)
+
+type importBatch struct {
+	authorTgID int64
+	tasks      []model.Task
+}
+
+type importLine struct {
+	title    string
+	username string
+	deadline time.Time
+}
+
+// importTasksCommand previews tasks parsed from list pasted after command and waits for confirmation.
+func (b *Bot) importTasksCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	now := time.Now()
+	lines := parseTaskList(update.Message.CommandArguments(), now)
+	if len(lines) == 0 {
+		return b.reply(update.Message, "вставьте список задач после команды, по одной на строку, например:\n\n"+
+			"/import_tasks\n- подготовить отчёт @ivanov 25.10\n- обновить сайт")
+	}
+	if len(lines) > importMaxTasks {
+		return b.reply(update.Message, fmt.Sprintf("за один раз можно создать не больше %d задач", importMaxTasks))
+	}
+
+	isDayOff, err := b.projectDaysOff(ctx, prj.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		preview  strings.Builder
+		warnings strings.Builder
+		batch    = importBatch{authorTgID: update.Message.From.ID}
+	)
+	fmt.Fprintf(&preview, "📋 будет создано задач: %d\n\n", len(lines))
+	for i, line := range lines {
+		task := model.NewTask(prj.ID, line.title, int64(user.ID))
+		task.Status = model.TaskStatusTODO
+		task.Deadline = line.deadline
+		if task.Deadline.IsZero() {
+			task.Deadline = defaultDeadline(prj, now)
+		}
+
+		var details []string
+		if line.username != "" {
+			assignee, err := b.fetchProjectUserByUsername(ctx, prj.ID, line.username)
+			if err != nil && errors.Is(err, model.ErrUserNotFound) {
+				fmt.Fprintf(&warnings, "⚠️ @%s не состоит в проекте, задача %d останется без исполнителя\n", line.username, i+1)
+			} else if err != nil {
+				return fmt.Errorf("could not fetch user by username: %w", err)
+			} else {
+				task.Assignee = int64(assignee.ID)
+				details = append(details, "@"+assignee.Username)
+			}
+		}
+		if !task.Deadline.IsZero() {
+			details = append(details, "до "+task.Deadline.Format(format.DateLayout))
+			if !line.deadline.IsZero() && isDayOff(task.Deadline) {
+				fmt.Fprintf(&warnings, "⚠️ срок задачи %d выпадает на выходной\n", i+1)
+			}
+		}
+
+		fmt.Fprintf(&preview, "%d. %s", i+1, task.Title)
+		if len(details) > 0 {
+			fmt.Fprintf(&preview, " — %s", strings.Join(details, ", "))
+		}
+		preview.WriteString("\n")
+		batch.tasks = append(batch.tasks, *task)
+	}
+	if warnings.Len() > 0 {
+		preview.WriteString("\n")
+		preview.WriteString(warnings.String())
+	}
+
+	batchID := b.imports.Put(batch)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, preview.String())
+	msg.ReplyToMessageID = update.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Создать", fmt.Sprintf("%s%d", callbackImportConfirm, batchID)),
+			tgbotapi.NewInlineKeyboardButtonData("✖️ Отмена", fmt.Sprintf("%s%d", callbackImportCancel, batchID)),
+		),
+	)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+func (b *Bot) importConfirmCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	batchID, batch, ok, err := b.takeImportBatch(query, callbackImportConfirm)
+	if err != nil || !ok {
+		return err
+	}
+
+	tasks := make([]*model.Task, len(batch.tasks))
+	for i := range batch.tasks {
+		tasks[i] = &batch.tasks[i]
+	}
+	if err = b.taskStorage.CreateTasks(ctx, tasks); err != nil {
+		return fmt.Errorf("could not create tasks: %w", err)
+	}
+	for _, task := range tasks {
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventCreated, Task: *task, ActorID: int(task.CreatedBy)})
+	}
+	log.Printf("DEBUG imported %d tasks from batch id=%d", len(tasks), batchID)
+
+	text := fmt.Sprintf("✅ создана задача #%d", tasks[0].ID)
+	if len(tasks) > 1 {
+		text = fmt.Sprintf("✅ создано задач: %d (#%d–#%d)", len(tasks), tasks[0].ID, tasks[len(tasks)-1].ID)
+	}
+	if err = b.editCallbackMessage(query, text); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "задачи созданы")
+}
+
+func (b *Bot) importCancelCallback(update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	_, _, ok, err := b.takeImportBatch(query, callbackImportCancel)
+	if err != nil || !ok {
+		return err
+	}
+	if err = b.editCallbackMessage(query, "✖️ создание задач из списка отменено"); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// takeImportBatch removes batch from pending imports if callback is pressed by its author,
+// otherwise it answers callback with explanation.
+func (b *Bot) takeImportBatch(query *tgbotapi.CallbackQuery, prefix string) (int, importBatch, bool, error) {
+	batchID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return 0, importBatch{}, false, fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	batch, ok := b.imports.Get(batchID)
+	if !ok {
+		return 0, importBatch{}, false, b.answerCallback(query.ID, "список устарел, отправьте его заново")
+	}
+	if batch.authorTgID != query.From.ID {
+		return 0, importBatch{}, false, b.answerCallback(query.ID, "подтвердить может только автор списка")
+	}
+	b.imports.Delete(batchID)
+	return batchID, batch, true, nil
+}
+
+func (b *Bot) editCallbackMessage(query *tgbotapi.CallbackQuery, text string) error {
+	if query.Message == nil {
+		return nil
+	}
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	if _, err := b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return nil
+}
+
+// parseTaskList parses one task per non-empty line, lines may start with bullet or number
+// and end with "@username" and deadline date in any order.
+func parseTaskList(text string, now time.Time) []importLine {
+	var res []importLine
+	for _, raw := range strings.Split(text, "\n") {
+		fields := strings.Fields(listBulletRe.ReplaceAllString(strings.TrimSpace(raw), ""))
+
+		var line importLine
+		for len(fields) > 1 {
+			last := fields[len(fields)-1]
+			if strings.HasPrefix(last, "@") && len(last) > 1 && line.username == "" {
+				line.username = strings.TrimPrefix(last, "@")
+				fields = fields[:len(fields)-1]
+				continue
+			}
+			if deadline, ok := parseDate(last, now); ok && line.deadline.IsZero() {
+				line.deadline = deadline
+				fields = fields[:len(fields)-1]
+				// Allow "до 25.10" form
+				if len(fields) > 1 && strings.EqualFold(fields[len(fields)-1], "до") {
+					fields = fields[:len(fields)-1]
+				}
+				continue
+			}
+			break
+		}
+
+		line.title = strings.Join(fields, " ")
+		if line.title != "" {
+			res = append(res, line)
+		}
+	}
+	return res
+}
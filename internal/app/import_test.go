@@ -0,0 +1,94 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTaskList(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	oct25 := time.Date(2026, time.October, 25, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+		want []importLine
+	}{
+		{
+			name: "plain lines",
+			text: "Купить молоко\nПозвонить клиенту",
+			want: []importLine{{title: "Купить молоко"}, {title: "Позвонить клиенту"}},
+		},
+		{
+			name: "bullets and numbers",
+			text: "- первая\n* вторая\n• третья\n— четвёртая\n1. пятая\n2) шестая\n10.  седьмая",
+			want: []importLine{
+				{title: "первая"}, {title: "вторая"}, {title: "третья"}, {title: "четвёртая"},
+				{title: "пятая"}, {title: "шестая"}, {title: "седьмая"},
+			},
+		},
+		{
+			name: "empty lines and bare bullets skipped",
+			text: "\n  \n- \nКупить молоко\n\n",
+			want: []importLine{{title: "Купить молоко"}},
+		},
+		{
+			name: "username and deadline",
+			text: "Сделать отчёт @ivan 25.10",
+			want: []importLine{{title: "Сделать отчёт", username: "ivan", deadline: oct25}},
+		},
+		{
+			name: "deadline and username in other order",
+			text: "Сделать отчёт 25.10 @ivan",
+			want: []importLine{{title: "Сделать отчёт", username: "ivan", deadline: oct25}},
+		},
+		{
+			name: "deadline with preposition",
+			text: "Сделать отчёт до 25.10.2026",
+			want: []importLine{{title: "Сделать отчёт", deadline: oct25}},
+		},
+		{
+			name: "past date means next year",
+			text: "Сдать декларацию 01.03",
+			want: []importLine{{title: "Сдать декларацию", deadline: time.Date(2027, time.March, 1, 23, 59, 59, 0, time.UTC)}},
+		},
+		{
+			name: "only the last username and date are taken",
+			text: "Созвон @anna @ivan 20.10 25.10",
+			want: []importLine{{title: "Созвон @anna @ivan 20.10", deadline: oct25}},
+		},
+		{
+			name: "line starting with date",
+			text: "25.10 сдать отчёт\n1.5 часа на ревью",
+			want: []importLine{{title: "25.10 сдать отчёт"}, {title: "1.5 часа на ревью"}},
+		},
+		{
+			name: "title is never empty",
+			text: "@ivan\n25.10\nдо 25.10",
+			want: []importLine{{title: "@ivan"}, {title: "25.10"}, {title: "до", deadline: oct25}},
+		},
+		{
+			name: "invalid date kept in title",
+			text: "Сделать отчёт 32.10\nПочинить @",
+			want: []importLine{{title: "Сделать отчёт 32.10"}, {title: "Починить @"}},
+		},
+		{
+			name: "empty",
+			text: "",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTaskList(tt.text, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d lines %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i].title != tt.want[i].title || got[i].username != tt.want[i].username || !got[i].deadline.Equal(tt.want[i].deadline) {
+					t.Errorf("line %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
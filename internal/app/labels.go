@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// callbackEditField is followed by task id and field, e.g. "edit_field_12_labels".
+	callbackEditField = "edit_field_"
+	editFieldLabels   = "labels"
+
+	maxTaskLabels  = 10
+	maxLabelLength = 32
+)
+
+var (
+	// labelsPromptRe matches bot's prompt for labels, reply to it carries labels.
+	labelsPromptRe = regexp.MustCompile(`метками задачи #(\d+)`)
+	labelRe        = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+)
+
+func labelsButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🏷 Метки", fmt.Sprintf("%s%d_%s", callbackEditField, task.ID, editFieldLabels))
+}
+
+// formatLabels returns labels as hashtags, so they are searchable in chat.
+func formatLabels(labels []string) string {
+	tags := make([]string, len(labels))
+	for i, label := range labels {
+		tags[i] = "#" + label
+	}
+	return strings.Join(tags, " ")
+}
+
+// parseLabels parses labels separated by spaces or commas, leading # is optional and case is ignored.
+// It returns the first invalid label if there is one.
+func parseLabels(text string) ([]string, string) {
+	var labels []string
+	for _, raw := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' }) {
+		label := strings.ToLower(strings.TrimPrefix(raw, "#"))
+		if !labelRe.MatchString(label) || len([]rune(label)) > maxLabelLength {
+			return nil, raw
+		}
+		labels = append(labels, label)
+	}
+	slices.Sort(labels)
+	return slices.Compact(labels), ""
+}
+
+// editFieldCallback asks member who pressed the button to reply with new value of task field.
+func (b *Bot) editFieldCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	rawID, field, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackEditField), "_")
+	taskID, err := strconv.Atoi(rawID)
+	if err != nil || field != editFieldLabels && field != editFieldBlockers {
+		return fmt.Errorf("could not parse callback data %q", query.Data)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	text := fmt.Sprintf("🏷 %s, ответьте на это сообщение метками задачи #%d через пробел или «-», чтобы убрать все", mention(user), task.ID)
+	placeholder := "баг срочно"
+	if len(task.Labels) > 0 {
+		text += fmt.Sprintf("\nСейчас: %s", formatLabels(task.Labels))
+	}
+	if field == editFieldBlockers {
+		if text, err = b.blockersPrompt(ctx, task, user); err != nil {
+			return err
+		}
+		placeholder = "#12 #15"
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: placeholder}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send %s prompt: %w", field, err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleLabelsReply replaces labels of the task with ones listed in reply of any project member,
+// it reports false if message is not a reply to labels prompt.
+func (b *Bot) handleLabelsReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	anyMember := func(*model.Task, *model.User) bool { return true }
+	task, user, handled, err := b.fetchPromptedTaskFor(ctx, message, labelsPromptRe, anyMember)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	var labels []string
+	if fields := strings.Fields(message.Text); len(fields) == 0 || fields[0] != "-" {
+		var invalid string
+		labels, invalid = parseLabels(message.Text)
+		switch {
+		case invalid != "":
+			return true, b.reply(message, fmt.Sprintf("метка %s не подходит: только буквы, цифры, «_» и «-», до %d символов", invalid, maxLabelLength))
+		case len(labels) == 0:
+			return true, b.reply(message, "ответьте на сообщение бота метками через пробел или «-»")
+		case len(labels) > maxTaskLabels:
+			return true, b.reply(message, fmt.Sprintf("у задачи может быть не больше %d меток", maxTaskLabels))
+		}
+	} else if len(task.Labels) == 0 {
+		return true, b.reply(message, "у задачи нет меток")
+	}
+
+	task.Labels = labels
+	task.UpdatedBy = int64(user.ID)
+	if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+		return true, fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set labels of task id=%d to %v", user.ID, task.ID, task.Labels)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+
+	if len(labels) == 0 {
+		return true, b.reply(message, fmt.Sprintf("🏷 у задачи #%d больше нет меток", task.ID))
+	}
+	return true, b.reply(message, fmt.Sprintf("🏷 метки задачи #%d: %s", task.ID, formatLabels(labels)))
+}
+
+// labelsCommand lists labels of project's open tasks, with label given it lists open tasks having it.
+func (b *Bot) labelsCommand(ctx context.Context, update tgbotapi.Update) error {
+	if update.Message.Chat.IsPrivate() {
+		return b.reply(update.Message, "команда доступна только в чате проекта")
+	}
+
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, update.Message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(update.Message, "сначала создайте проект командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+
+	var sb strings.Builder
+	if label := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(update.Message.CommandArguments()), "#")); label != "" {
+		tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, Label: label, OnlyOpen: true})
+		if err != nil {
+			return fmt.Errorf("could not fetch tasks: %w", err)
+		}
+		if len(tasks) == 0 {
+			return b.reply(update.Message, fmt.Sprintf("🏷 нет открытых задач с меткой #%s", label))
+		}
+		fmt.Fprintf(&sb, "🏷 задачи с меткой #%s: %d\n\n", label, len(tasks))
+		for _, task := range tasks {
+			fmt.Fprintf(&sb, "• %s#%d %s (%s)\n", task.Priority.Marker(), task.ID, task.Title, task.Status.StringLocalized())
+		}
+		return b.reply(update.Message, sb.String())
+	}
+
+	counts, err := b.taskStorage.CountTaskLabels(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not count labels: %w", err)
+	}
+	if len(counts) == 0 {
+		return b.reply(update.Message, "🏷 у открытых задач нет меток, добавьте их кнопкой «🏷 Метки» на карточке задачи")
+	}
+	sb.WriteString("🏷 метки открытых задач:\n\n")
+	for _, c := range counts {
+		fmt.Fprintf(&sb, "• #%s — %d\n", c.Name, c.Tasks)
+	}
+	sb.WriteString("\nЗадачи с меткой: /labels метка")
+	return b.reply(update.Message, sb.String())
+}
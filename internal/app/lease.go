@@ -0,0 +1,26 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// holdsLease reports whether this process runs job scheduled every interval, it is always true without leases.
+// Lease outlives interval by half, so holder keeps it between runs and another process takes
+// the job over only after holder missed a run.
+func (b *Bot) holdsLease(ctx context.Context, job string, interval time.Duration) bool {
+	if b.cfg.Leases == nil {
+		return true
+	}
+	ok, err := b.cfg.Leases.AcquireLease(ctx, job, b.cfg.LeaseHolder, interval+interval/2)
+	if err != nil {
+		// Skipped run is better than duplicated posts
+		log.Printf("ERROR could not acquire lease of %s: %s", job, err)
+		return false
+	}
+	if !ok {
+		log.Printf("DEBUG %s is run by another process", job)
+	}
+	return ok
+}
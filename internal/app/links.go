@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// maxProjectLinks keeps resources keyboard short.
+	maxProjectLinks     = 10
+	projectLinkTitleLen = 32
+)
+
+const linksUsage = "Добавить ссылку: /links Макеты https://example.com, удалить: /links - Макеты"
+
+// linksCommand shows project's resources as buttons, managers add link with "/links title URL"
+// and remove it with "/links - title".
+func (b *Bot) linksCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.listProjectLinks(ctx, message)
+	}
+	if len(args) < 2 {
+		return b.reply(message, linksUsage)
+	}
+
+	prj, user, err := b.fetchManagedProject(ctx, message)
+	if err != nil || prj == nil {
+		return err
+	}
+	links, err := b.projectStorage.FetchProjectLinks(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch links: %w", err)
+	}
+	findLink := func(title string) *model.ProjectLink {
+		for i := range links {
+			if strings.EqualFold(links[i].Title, title) {
+				return &links[i]
+			}
+		}
+		return nil
+	}
+
+	if args[0] == "-" {
+		title := strings.Join(args[1:], " ")
+		existing := findLink(title)
+		if existing == nil {
+			return b.reply(message, fmt.Sprintf("ссылки «%s» нет в проекте", title))
+		}
+		if err = b.projectStorage.RemoveProjectLink(ctx, existing.ID); err != nil {
+			return fmt.Errorf("could not remove link: %w", err)
+		}
+		log.Printf("DEBUG user id=%d removed link id=%d of project id=%d", user.ID, existing.ID, prj.ID)
+		if err = b.reply(message, fmt.Sprintf("🔗 ссылка «%s» удалена", existing.Title)); err != nil {
+			return err
+		}
+		b.scheduleBoardRefresh(prj.ID)
+		b.publishProjectChange(ctx, prj, user, fmt.Sprintf("ссылка «%s» удалена", existing.Title))
+		return nil
+	}
+
+	title, rawURL := strings.Join(args[:len(args)-1], " "), args[len(args)-1]
+	existing := findLink(title)
+	switch {
+	case !isWebLink(rawURL):
+		return b.reply(message, "ссылка должна начинаться с http:// или https://\n\n"+linksUsage)
+	case utf8.RuneCountInString(title) > projectLinkTitleLen:
+		return b.reply(message, fmt.Sprintf("название ссылки должно быть не длиннее %d символов", projectLinkTitleLen))
+	case existing == nil && len(links) >= maxProjectLinks:
+		return b.reply(message, fmt.Sprintf("в проекте может быть не больше %d ссылок, удалите ненужные: /links - название", maxProjectLinks))
+	}
+
+	link := &model.ProjectLink{ProjectID: prj.ID, Title: title, URL: rawURL, CreatedBy: int64(user.ID)}
+	if err = b.projectStorage.SaveProjectLink(ctx, link); err != nil {
+		return fmt.Errorf("could not save link: %w", err)
+	}
+	log.Printf("DEBUG user id=%d saved link id=%d of project id=%d", user.ID, link.ID, prj.ID)
+
+	text := fmt.Sprintf("🔗 ссылка «%s» добавлена", link.Title)
+	if existing != nil {
+		text = fmt.Sprintf("🔗 ссылка «%s» заменена", link.Title)
+	}
+	if err = b.reply(message, text+"\n\nВсе ресурсы проекта: /links"); err != nil {
+		return err
+	}
+	b.scheduleBoardRefresh(prj.ID)
+	b.publishProjectChange(ctx, prj, user, fmt.Sprintf("ссылка «%s» — %s", link.Title, link.URL))
+	return nil
+}
+
+func (b *Bot) listProjectLinks(ctx context.Context, message *tgbotapi.Message) error {
+	prj, _, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.reply(message, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	links, err := b.projectStorage.FetchProjectLinks(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch links: %w", err)
+	}
+	if len(links) == 0 {
+		return b.reply(message, "🔗 в проекте нет ссылок на ресурсы\n\n"+linksUsage)
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, len(links))
+	for i, link := range links {
+		rows[i] = tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL(link.Title, link.URL))
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("🔗 Ресурсы проекта «%s»\n\n%s", prj.Title, linksUsage))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// renderProjectLinks returns line per project's link for headers of project messages, empty if there are no links.
+func (b *Bot) renderProjectLinks(ctx context.Context, projectID int) (string, error) {
+	links, err := b.projectStorage.FetchProjectLinks(ctx, projectID)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch links: %w", err)
+	}
+	var sb strings.Builder
+	for _, link := range links {
+		fmt.Fprintf(&sb, "🔗 %s: %s\n", link.Title, link.URL)
+	}
+	return sb.String(), nil
+}
+
+// isWebLink reports whether s is absolute http or https URL, Telegram rejects buttons with other links.
+func isWebLink(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
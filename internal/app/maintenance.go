@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// orphanUserTTL is how long users left without projects keep personal data, so members removed
+// by mistake or moving between chats don't lose it.
+const orphanUserTTL = 30 * 24 * time.Hour
+
+type MaintenanceConfig struct {
+	Interval time.Duration
+	// PendingTTL is how long unconfirmed user input is kept.
+	PendingTTL time.Duration
+	Vacuum     bool
+}
+
+// StartMaintenance periodically removes stale data until context is cancelled,
+// database is not cleaned if storage is nil.
+func (b *Bot) StartMaintenance(ctx context.Context, storage model.MaintenanceRepository, cfg MaintenanceConfig) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.runMaintenance(ctx, storage, cfg)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) runMaintenance(ctx context.Context, storage model.MaintenanceRepository, cfg MaintenanceConfig) {
+	before := time.Now().Add(-cfg.PendingTTL)
+	pruned := b.pendingTasks.Prune(before) + b.imports.Prune(before) + b.failedUpdates.Prune(before) +
+		b.postpones.Prune(before) + b.handovers.Prune(before) + b.titleSuggestions.Prune(before) +
+		b.deadlineShifts.Prune(before) + b.taskSendings.Prune(before)
+	log.Printf("DEBUG maintenance: pruned %d pending confirmations", pruned)
+	b.commandResults.Prune(before)
+	b.taskCounters.Prune(before)
+	if storage == nil || !b.holdsLease(ctx, "maintenance", cfg.Interval) {
+		return
+	}
+
+	// Purged tasks may leave users without anything, so they are removed first
+	purged, err := b.taskStorage.PurgeDeletedTasks(ctx, time.Now().Add(-trashTTL))
+	if err != nil {
+		log.Printf("ERROR maintenance: could not purge deleted tasks: %s", err)
+	} else {
+		log.Printf("DEBUG maintenance: purged %d tasks from trash", purged)
+	}
+
+	if b.cfg.EventLog != nil {
+		events, err := b.cfg.EventLog.DeleteTaskEvents(ctx, time.Now().Add(-eventLogTTL))
+		if err != nil {
+			log.Printf("ERROR maintenance: could not delete old task events: %s", err)
+		} else {
+			log.Printf("DEBUG maintenance: deleted %d old task events", events)
+		}
+	}
+
+	users, err := storage.DeleteOrphanUsers(ctx, time.Now().Add(-orphanUserTTL))
+	if err != nil {
+		log.Printf("ERROR maintenance: could not delete orphan users: %s", err)
+	} else {
+		log.Printf("DEBUG maintenance: deleted or anonymized %d users without projects", users)
+	}
+
+	if cfg.Vacuum {
+		if err = storage.Compact(ctx); err != nil {
+			log.Printf("ERROR maintenance: could not compact database: %s", err)
+		} else {
+			log.Printf("DEBUG maintenance: database compacted")
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// memberRef is member named in message either by @username or by text mention, which carries
+// Telegram user and is the only way to name users without username.
+type memberRef struct {
+	username string
+	user     *tgbotapi.User
+}
+
+func (r memberRef) String() string {
+	if r.user != nil {
+		return strings.TrimSpace(r.user.FirstName + " " + r.user.LastName)
+	}
+	return "@" + r.username
+}
+
+// parseMemberRefs returns members named in message in order of appearance,
+// it reports false if message has anything besides @usernames and text mentions.
+func parseMemberRefs(message *tgbotapi.Message) ([]memberRef, bool) {
+	var mentions []tgbotapi.MessageEntity
+	for _, entity := range message.Entities {
+		if entity.Type == "text_mention" && entity.User != nil {
+			mentions = append(mentions, entity)
+		}
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].Offset < mentions[j].Offset })
+
+	// Entity offsets are counted in UTF-16 code units.
+	text := utf16.Encode([]rune(message.Text))
+	var refs []memberRef
+	ok := true
+	parseUsernames := func(part []uint16) {
+		for _, field := range strings.Fields(string(utf16.Decode(part))) {
+			username := strings.TrimPrefix(field, "@")
+			if !strings.HasPrefix(field, "@") || username == "" {
+				ok = false
+				continue
+			}
+			refs = append(refs, memberRef{username: username})
+		}
+	}
+	pos := 0
+	for _, entity := range mentions {
+		if entity.Offset < pos || entity.Offset+entity.Length > len(text) {
+			return nil, false
+		}
+		parseUsernames(text[pos:entity.Offset])
+		refs = append(refs, memberRef{user: entity.User})
+		pos = entity.Offset + entity.Length
+	}
+	parseUsernames(text[pos:])
+	return refs, ok && len(refs) > 0
+}
+
+// fetchProjectMemberByRef returns project member named in message, model.ErrUserNotFound if they are not a member.
+func (b *Bot) fetchProjectMemberByRef(ctx context.Context, projectID int, ref memberRef) (*model.User, error) {
+	if ref.user == nil {
+		return b.fetchProjectUserByUsername(ctx, projectID, ref.username)
+	}
+	user, err := b.userStorage.FetchUserByTgID(ctx, ref.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.userStorage.FetchUserRoleInProject(ctx, projectID, user); err != nil {
+		return nil, err
+	}
+	b.saveProfile(ctx, user, ref.user)
+	return user, nil
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackToggleMute = "toggle_mute_"
+
+// muteButton switches reminders and escalations of the task off or back on.
+func muteButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	text := "🔕 Не уведомлять"
+	if task.Muted {
+		text = "🔔 Уведомлять"
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%d", callbackToggleMute, task.ID))
+}
+
+// toggleMuteCallback mutes or unmutes task and refreshes its card, allowed for project members.
+func (b *Bot) toggleMuteCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackToggleMute)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if !task.Status.IsOpen() {
+		return b.answerCallback(query.ID, "задача уже закрыта")
+	}
+
+	task.Muted = !task.Muted
+	task.UpdatedBy = int64(user.ID)
+	if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set muted of task id=%d to %t", user.ID, task.ID, task.Muted)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, taskCardKeyboard(task))
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	if task.Muted {
+		return b.answerCallback(query.ID, "напоминания по задаче отключены")
+	}
+	return b.answerCallback(query.ID, "напоминания по задаче включены")
+}
@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	outboxBatch = 100
+	// outboxMaxAttempts is how many times notification is sent before it is dead-lettered,
+	// with backoff it is retried for about 4 hours.
+	outboxMaxAttempts = 10
+	outboxMaxBackoff  = time.Hour
+)
+
+// errUndeliverable marks notifications which can't be delivered by retrying.
+var errUndeliverable = errors.New("undeliverable")
+
+// updateTaskNotifying saves task and notifies users about the change, see updateTasksNotifying.
+func (b *Bot) updateTaskNotifying(ctx context.Context, task *model.Task, notifications ...model.Notification) error {
+	return b.updateTasksNotifying(ctx, []*model.Task{task}, notifications...)
+}
+
+// updateTasksNotifying saves tasks and notifies users about the change. With outbox notifications are saved
+// along with the tasks and sent by StartOutboxDelivery, so they are not lost if sending fails or process stops,
+// otherwise they are sent right away.
+func (b *Bot) updateTasksNotifying(ctx context.Context, tasks []*model.Task, notifications ...model.Notification) error {
+	var recipients []model.Notification
+	for _, n := range notifications {
+		if n.UserID != 0 {
+			recipients = append(recipients, n)
+		}
+	}
+
+	if b.cfg.Outbox == nil {
+		if err := b.taskStorage.UpdateTasks(ctx, tasks); err != nil {
+			return err
+		}
+		for _, n := range recipients {
+			b.notifyUser(ctx, n.UserID, n.Text)
+		}
+		return nil
+	}
+
+	if err := b.taskStorage.UpdateTasksWithNotifications(ctx, tasks, recipients); err != nil {
+		return err
+	}
+	if len(recipients) > 0 {
+		b.wakeOutbox()
+	}
+	return nil
+}
+
+// dispatchNotifications sends notifications which are not saved along with a change,
+// through outbox if it is configured. Failures are only logged.
+func (b *Bot) dispatchNotifications(ctx context.Context, notifications []model.Notification) {
+	if b.cfg.Outbox == nil {
+		for _, n := range notifications {
+			b.notifyUser(ctx, n.UserID, n.Text)
+		}
+		return
+	}
+	if err := b.cfg.Outbox.EnqueueNotifications(ctx, notifications); err != nil {
+		log.Printf("ERROR outbox: could not enqueue %d notifications: %s", len(notifications), err)
+		return
+	}
+	b.wakeOutbox()
+}
+
+// wakeOutbox makes StartOutboxDelivery send new notifications without waiting for the next tick.
+func (b *Bot) wakeOutbox() {
+	select {
+	case b.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartOutboxDelivery sends notifications from outbox every interval and right after they are saved
+// by this process until context is cancelled.
+func (b *Bot) StartOutboxDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.outboxWake:
+		case <-ctx.Done():
+			return
+		}
+		if !b.holdsLease(ctx, "outbox", interval) {
+			continue
+		}
+		if err := b.deliverNotifications(ctx); err != nil {
+			log.Printf("ERROR outbox: %s", err)
+		}
+	}
+}
+
+func (b *Bot) deliverNotifications(ctx context.Context) error {
+	notifications, err := b.cfg.Outbox.FetchDueNotifications(ctx, time.Now(), outboxBatch)
+	if err != nil {
+		return fmt.Errorf("could not fetch notifications: %w", err)
+	}
+	for i := range notifications {
+		n := &notifications[i]
+		err = b.deliverNotification(ctx, n)
+		if err == nil {
+			if err = b.cfg.Outbox.DeleteNotification(ctx, n.ID); err != nil {
+				return fmt.Errorf("could not delete notification: %w", err)
+			}
+			continue
+		}
+
+		n.Attempts++
+		n.LastError = err.Error()
+		if errors.Is(err, errUndeliverable) || n.Attempts >= outboxMaxAttempts {
+			n.Dead = true
+			log.Printf("ERROR outbox: gave up notification id=%d to user id=%d after %d attempts: %s", n.ID, n.UserID, n.Attempts, err)
+		} else {
+			n.NextAttemptAt = time.Now().Add(outboxBackoff(n.Attempts))
+			log.Printf("WARN outbox: could not send notification id=%d to user id=%d, retry at %s: %s",
+				n.ID, n.UserID, n.NextAttemptAt.Format(time.DateTime), err)
+		}
+		if err = b.cfg.Outbox.UpdateNotification(ctx, n); err != nil {
+			return fmt.Errorf("could not update notification: %w", err)
+		}
+	}
+	return nil
+}
+
+func (b *Bot) deliverNotification(ctx context.Context, n *model.Notification) error {
+	user, err := b.userStorage.FetchUserByID(ctx, int(n.UserID))
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return fmt.Errorf("%w: user is deleted", errUndeliverable)
+	} else if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	// Message to unreachable user is skipped without error, so it would be lost
+	if user.Unreachable {
+		return fmt.Errorf("%w: user blocked the bot", errUndeliverable)
+	}
+	_, err = b.sendMessage(tgbotapi.NewMessage(user.TgUserID, n.Text))
+	if classifyTelegramError(err) == telegramErrorUnreachable {
+		return fmt.Errorf("%w: %s", errUndeliverable, err)
+	}
+	return err
+}
+
+// outboxBackoff doubles delay from a minute with every failed attempt.
+func outboxBackoff(attempts int) time.Duration {
+	if attempts > 7 {
+		return outboxMaxBackoff
+	}
+	return min(time.Minute<<(attempts-1), outboxMaxBackoff)
+}
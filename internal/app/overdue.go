@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackOverdueSnooze = "overdue_snooze_"
+
+	overdueSnoozeDelay = 24 * time.Hour
+)
+
+// StartOverdueAnnouncements posts once to project chat about every not muted task whose deadline passed,
+// it checks deadlines every interval until context is cancelled.
+func (b *Bot) StartOverdueAnnouncements(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !b.holdsLease(ctx, "overdue", interval) {
+				continue
+			}
+			if err := b.announceOverdueTasks(ctx); err != nil {
+				log.Printf("ERROR overdue announcements: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) announceOverdueTasks(ctx context.Context) error {
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		Deadline:           time.Now(),
+		OnlyOpen:           true,
+		OverdueNotNotified: true,
+		WithoutMuted:       true,
+	})
+	if err != nil {
+		return fmt.Errorf("could not fetch overdue tasks: %w", err)
+	}
+
+	projects := make(map[int]*model.Project)
+	for _, task := range tasks {
+		prj, ok := projects[task.ProjectID]
+		if !ok {
+			if prj, err = b.projectStorage.FetchProjectByID(ctx, task.ProjectID); err != nil {
+				log.Printf("ERROR overdue announcements: could not fetch project id=%d: %s", task.ProjectID, err)
+				continue
+			}
+			projects[task.ProjectID] = prj
+		}
+
+		// Tasks of archived projects are marked too, so they are not announced after unarchiving
+		if !prj.Archived {
+			if err = b.sendOverdueAnnouncement(ctx, prj, &task); err != nil {
+				log.Printf("ERROR overdue announcements: could not announce task id=%d: %s", task.ID, err)
+				continue
+			}
+		}
+		if err = b.taskStorage.SetTaskOverdueNotified(ctx, task.ID, true); err != nil {
+			return fmt.Errorf("could not mark task announced: %w", err)
+		}
+		log.Printf("DEBUG overdue announcements: task id=%d announced", task.ID)
+	}
+	return nil
+}
+
+func (b *Bot) sendOverdueAnnouncement(ctx context.Context, prj *model.Project, task *model.Task) error {
+	text := fmt.Sprintf("🔥 просрочено: #%d %s, срок был %s", task.ID, task.Title, task.Deadline.Format(format.DateLayout))
+	buttons := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("💤 Напомнить завтра", fmt.Sprintf("%s%d", callbackOverdueSnooze, task.ID)),
+	)
+	if task.Assignee != 0 {
+		assignee, err := b.userStorage.FetchUserByID(ctx, int(task.Assignee))
+		if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+			return fmt.Errorf("could not fetch assignee: %w", err)
+		}
+		if assignee != nil {
+			text += "\n\n" + mention(assignee) + ", запросите перенос, если не успеваете"
+			buttons = append(tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("⏰ Запросить перенос", fmt.Sprintf("%s%d", callbackPostponeRequest, task.ID)),
+			), buttons...)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(prj.TgChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons)
+	_, err := b.sendMessage(msg)
+	return err
+}
+
+// overdueSnoozeCallback announces the task again a day later if it is still overdue, allowed for project members.
+// Snoozes are kept in memory, so restart drops them and the task is not announced again.
+func (b *Bot) overdueSnoozeCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackOverdueSnooze)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+
+	time.AfterFunc(overdueSnoozeDelay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := b.taskStorage.SetTaskOverdueNotified(ctx, taskID, false); err != nil {
+			log.Printf("ERROR could not reset overdue announcement of task id=%d: %s", taskID, err)
+		}
+	})
+	log.Printf("DEBUG user id=%d snoozed overdue task id=%d", user.ID, task.ID)
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID,
+		fmt.Sprintf("💤 %s: напомню про просроченную задачу #%d %s завтра", user.FullName, task.ID, task.Title))
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// pendingStore keeps user input awaiting confirmation through inline buttons.
+type pendingStore[T any] struct {
+	mu    sync.Mutex
+	seq   int
+	items map[int]pendingItem[T]
+}
+
+type pendingItem[T any] struct {
+	value     T
+	createdAt time.Time
+}
+
+func (s *pendingStore[T]) Put(value T) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.items == nil {
+		s.items = make(map[int]pendingItem[T])
+	}
+	s.seq++
+	s.items[s.seq] = pendingItem[T]{value: value, createdAt: time.Now()}
+	return s.seq
+}
+
+func (s *pendingStore[T]) Get(id int) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	return item.value, ok
+}
+
+func (s *pendingStore[T]) Delete(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, id)
+}
+
+// Prune removes items created before given time and returns number of removed items.
+func (s *pendingStore[T]) Prune(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, item := range s.items {
+		if item.createdAt.Before(before) {
+			delete(s.items, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Plugin is extension compiled into the bot by deployer, e.g. sync with internal HR system.
+// It implements any of StartHook, TaskEventHook and CommandHook.
+type Plugin interface {
+	Name() string
+}
+
+// StartHook is called once before bot starts receiving updates.
+type StartHook interface {
+	OnStart(ctx context.Context, bot *Bot) error
+}
+
+// TaskEventHook receives every change of tasks after bot's own subscribers.
+type TaskEventHook interface {
+	OnTaskEvent(ctx context.Context, event model.TaskEvent)
+}
+
+// CommandHook handles commands unknown to the bot, it reports false if command is not its own.
+// Built-in commands can't be overridden.
+type CommandHook interface {
+	OnCommand(ctx context.Context, bot *Bot, update tgbotapi.Update) (bool, error)
+}
+
+func (b *Bot) subscribePlugins() {
+	for _, p := range b.cfg.Plugins {
+		if hook, ok := p.(TaskEventHook); ok {
+			b.events.Subscribe(hook.OnTaskEvent)
+		}
+	}
+}
+
+// startPlugins runs start hooks, failed plugin is logged and the bot keeps working.
+func (b *Bot) startPlugins(ctx context.Context) {
+	for _, p := range b.cfg.Plugins {
+		hook, ok := p.(StartHook)
+		if !ok {
+			continue
+		}
+		if err := hook.OnStart(ctx, b); err != nil {
+			log.Printf("ERROR could not start plugin %s: %s", p.Name(), err)
+			continue
+		}
+		log.Printf("INFO started plugin %s", p.Name())
+	}
+}
+
+// pluginCommand offers command to plugins in order of registration until one handles it.
+func (b *Bot) pluginCommand(ctx context.Context, update tgbotapi.Update) (bool, error) {
+	for _, p := range b.cfg.Plugins {
+		hook, ok := p.(CommandHook)
+		if !ok {
+			continue
+		}
+		handled, err := hook.OnCommand(ctx, b, update)
+		if err != nil {
+			return true, fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+		if handled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Projects gives plugins access to project storage.
+func (b *Bot) Projects() model.ProjectRepository { return b.projectStorage }
+
+// Users gives plugins access to user storage.
+func (b *Bot) Users() model.UserRepository { return b.userStorage }
+
+// Tasks gives plugins access to task storage, changes made through it are not published as task events.
+func (b *Bot) Tasks() model.TaskRepository { return b.taskStorage }
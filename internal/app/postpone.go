@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackPostponeRequest = "postpone_request_"
+	callbackPostponeApprove = "postpone_approve_"
+	callbackPostponeDeny    = "postpone_deny_"
+)
+
+// postponePromptRe matches bot's prompt for new deadline, reply to it carries the date.
+var postponePromptRe = regexp.MustCompile(`новым сроком задачи #(\d+)`)
+
+type postponeRequest struct {
+	taskID      int
+	requesterID int
+	oldDeadline time.Time
+	newDeadline time.Time
+}
+
+// postponeRequestCallback asks assignee of the task to reply with new deadline.
+func (b *Bot) postponeRequestCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackPostponeRequest)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, user, ok, err := b.fetchAssignedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"⏰ %s, ответьте на это сообщение новым сроком задачи #%d в формате ДД.ММ", mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "25.10"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send postpone prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handlePostponeReply sends proposed deadline to managers for approval,
+// it reports false if message is not a reply to postpone prompt.
+func (b *Bot) handlePostponeReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	task, user, handled, err := b.fetchPromptedTask(ctx, message, postponePromptRe)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	now := time.Now()
+	deadline, ok := parseDate(strings.TrimSpace(message.Text), now)
+	if !ok {
+		return true, b.reply(message, "не понял дату, ответьте на сообщение бота сроком в формате ДД.ММ или ДД.ММ.ГГГГ")
+	}
+	if deadline.Equal(task.Deadline) {
+		return true, b.reply(message, "это текущий срок задачи")
+	}
+
+	requestID := b.postpones.Put(postponeRequest{
+		taskID:      task.ID,
+		requesterID: user.ID,
+		oldDeadline: task.Deadline,
+		newDeadline: deadline,
+	})
+	log.Printf("INFO user id=%d requested to postpone task id=%d to %s", user.ID, task.ID, deadline.Format(format.DateLayout))
+
+	isDayOff, err := b.projectDaysOff(ctx, task.ProjectID)
+	if err != nil {
+		return true, err
+	}
+	var warning string
+	if isDayOff(deadline) {
+		warning = "\n⚠️ новый срок выпадает на выходной"
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf(
+		"⏰ %s просит перенести срок задачи #%d %s: %s → %s (%s)%s\n\nрешение за менеджером проекта",
+		user.FullName, task.ID, task.Title, deadlineText(task.Deadline), deadline.Format(format.DateLayout),
+		format.Days(deadline, now), warning,
+	))
+	msg.ReplyToMessageID = message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Одобрить", fmt.Sprintf("%s%d", callbackPostponeApprove, requestID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("%s%d", callbackPostponeDeny, requestID)),
+		),
+	)
+	_, err = b.sendMessage(msg)
+	return true, err
+}
+
+func (b *Bot) postponeApproveCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	request, manager, ok, err := b.takePostponeRequest(ctx, query, callbackPostponeApprove)
+	if err != nil || !ok {
+		return err
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, request.taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		if err = b.editCallbackMessage(query, fmt.Sprintf("задача #%d удалена", request.taskID)); err != nil {
+			return err
+		}
+		return b.answerCallback(query.ID, "")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+
+	prev := task.Clone()
+	task.Deadline = request.newDeadline
+	task.UpdatedBy = int64(manager.ID)
+	if err = b.updateWatchedTask(ctx, prev, task, manager.ID); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	// Passing of the new deadline is announced again
+	if err = b.taskStorage.SetTaskOverdueNotified(ctx, task.ID, false); err != nil {
+		return fmt.Errorf("could not reset overdue announcement: %w", err)
+	}
+	log.Printf("INFO user id=%d approved postponing task id=%d from %s to %s requested by user id=%d",
+		manager.ID, task.ID, deadlineText(request.oldDeadline), deadlineText(request.newDeadline), request.requesterID)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: manager.ID})
+
+	text := fmt.Sprintf("✅ срок задачи #%d %s перенесён: %s → %s (одобрил %s)",
+		task.ID, task.Title, deadlineText(request.oldDeadline), deadlineText(request.newDeadline), manager.FullName)
+	if err = b.editCallbackMessage(query, text); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "срок перенесён")
+}
+
+func (b *Bot) postponeDenyCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	request, manager, ok, err := b.takePostponeRequest(ctx, query, callbackPostponeDeny)
+	if err != nil || !ok {
+		return err
+	}
+	log.Printf("INFO user id=%d denied postponing task id=%d to %s requested by user id=%d",
+		manager.ID, request.taskID, deadlineText(request.newDeadline), request.requesterID)
+
+	text := fmt.Sprintf("❌ перенос срока задачи #%d на %s отклонён (%s), срок остаётся %s",
+		request.taskID, deadlineText(request.newDeadline), manager.FullName, deadlineText(request.oldDeadline))
+	if err = b.editCallbackMessage(query, text); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// takePostponeRequest removes request from pending ones if callback is pressed by project manager,
+// otherwise it answers callback with explanation.
+func (b *Bot) takePostponeRequest(ctx context.Context, query *tgbotapi.CallbackQuery, prefix string) (postponeRequest, *model.User, bool, error) {
+	requestID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return postponeRequest{}, nil, false, fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return postponeRequest{}, nil, false, b.answerCallback(query.ID, "")
+	}
+
+	request, ok := b.postpones.Get(requestID)
+	if !ok {
+		return postponeRequest{}, nil, false, b.answerCallback(query.ID, "запрос устарел, исполнителю нужно отправить его заново")
+	}
+	_, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && !errors.Is(err, model.ErrProjectNotFound) && !errors.Is(err, model.ErrUserNotFound) {
+		return postponeRequest{}, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if err != nil || user.Role != model.UserProjectRoleManager {
+		return postponeRequest{}, nil, false, b.answerCallback(query.ID, "решение о переносе принимает менеджер проекта")
+	}
+	b.postpones.Delete(requestID)
+	return request, user, true, nil
+}
+
+func deadlineText(deadline time.Time) string {
+	if deadline.IsZero() {
+		return "без срока"
+	}
+	return deadline.Format(format.DateLayout)
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackPickPriority = "pick_priority_"
+	// callbackSetPriority is followed by priority and task id, e.g. "priority_urgent_12".
+	callbackSetPriority = "priority_"
+
+	priorityRefusal = "приоритет задачи меняет менеджер проекта"
+)
+
+func priorityButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(
+		fmt.Sprintf("%s Приоритет", task.Priority.Emoji()), fmt.Sprintf("%s%d", callbackPickPriority, task.ID))
+}
+
+// priorityKeyboard replaces card buttons while manager picks priority,
+// "back" keeps current priority and brings card buttons back.
+func priorityKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, p := range model.TaskPriorities {
+		text := fmt.Sprintf("%s %s", p.Emoji(), p.StringLocalized())
+		if p == task.Priority {
+			text = "• " + text
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%s_%d", callbackSetPriority, p, task.ID)))
+	}
+	back := tgbotapi.NewInlineKeyboardButtonData("↩️ Назад", fmt.Sprintf("%s%s_%d", callbackSetPriority, task.Priority, task.ID))
+	return tgbotapi.NewInlineKeyboardMarkup(row[:2], row[2:], tgbotapi.NewInlineKeyboardRow(back))
+}
+
+// pickPriorityCallback shows priority picker on task card, allowed for managers.
+func (b *Bot) pickPriorityCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackPickPriority)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	task, _, ok, err := b.fetchTriagedTask(ctx, query, taskID, priorityRefusal)
+	if err != nil || !ok {
+		return err
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, priorityKeyboard(task))
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, "выберите приоритет задачи")
+}
+
+// setPriorityCallback saves chosen priority and refreshes task card, allowed for managers.
+func (b *Bot) setPriorityCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	rawPriority, rawID, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackSetPriority), "_")
+	priority := model.TaskPriority(rawPriority)
+	taskID, err := strconv.Atoi(rawID)
+	if err != nil || !slices.Contains(model.TaskPriorities, priority) {
+		return fmt.Errorf("could not parse callback data %q", query.Data)
+	}
+	task, user, ok, err := b.fetchTriagedTask(ctx, query, taskID, priorityRefusal)
+	if err != nil || !ok {
+		return err
+	}
+
+	if task.Priority != priority {
+		from := task.Priority
+		task.Priority = priority
+		task.UpdatedBy = int64(user.ID)
+		if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+			return fmt.Errorf("could not update task: %w", err)
+		}
+		log.Printf("DEBUG user id=%d changed priority of task id=%d from %s to %s", user.ID, task.ID, from, priority)
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+	}
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, taskCardKeyboard(task))
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, fmt.Sprintf("приоритет: %s %s", priority.Emoji(), priority.StringLocalized()))
+}
+
+// fetchTriagedTask returns open task of chat's project if callback is pressed by manager,
+// otherwise it answers callback with explanation, refusal is shown to other members.
+func (b *Bot) fetchTriagedTask(ctx context.Context, query *tgbotapi.CallbackQuery, taskID int, refusal string) (*model.Task, *model.User, bool, error) {
+	if query.Message == nil {
+		return nil, nil, false, b.answerCallback(query.ID, "")
+	}
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, false, b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if user.Role != model.UserProjectRoleManager {
+		return nil, nil, false, b.answerCallback(query.ID, refusal)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return nil, nil, false, b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return nil, nil, false, b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if !task.Status.IsOpen() {
+		return nil, nil, false, b.answerCallback(query.ID, "задача уже закрыта")
+	}
+	return task, user, true, nil
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Supergroup chat IDs are internal IDs with this prefix, only their messages have t.me links.
+const supergroupChatIDOffset = -1000000000000
+
+const rollupDueWindow = 7 * 24 * time.Hour
+
+// projectsCommand shows manager health of all their projects in private chat.
+func (b *Bot) projectsCommand(ctx context.Context, update tgbotapi.Update) error {
+	if !update.Message.Chat.IsPrivate() {
+		return b.reply(update.Message, "🔒 команда доступна только в личных сообщениях с ботом")
+	}
+
+	user, err := b.userStorage.FetchUserByTgID(ctx, update.Message.From.ID)
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return b.reply(update.Message, "вы пока не управляете ни одним проектом")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	projects, err := b.projectStorage.FetchProjectsByUser(ctx, user.ID, model.UserProjectRoleManager)
+	if err != nil {
+		return fmt.Errorf("could not fetch projects: %w", err)
+	}
+	projects = slices.DeleteFunc(projects, func(prj model.Project) bool { return prj.Archived })
+	if len(projects) == 0 {
+		return b.reply(update.Message, "вы пока не управляете ни одним проектом")
+	}
+
+	now := time.Now()
+	var (
+		sb   strings.Builder
+		rows [][]tgbotapi.InlineKeyboardButton
+	)
+	sb.WriteString("📊 ваши проекты\n")
+	for _, prj := range projects {
+		line, err := b.renderProjectHealth(ctx, &prj, now)
+		if err != nil {
+			return err
+		}
+		trend, err := b.renderProjectTrend(ctx, prj.ID, now)
+		if err != nil {
+			return err
+		}
+		if trend != "" {
+			line += "\n" + trend
+		}
+		fmt.Fprintf(&sb, "\n%s\n%s\n", prj.Title, line)
+
+		if link := boardLink(&prj); link != "" {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonURL("📌 "+prj.Title, link),
+			))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, sb.String())
+	if len(rows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// renderProjectHealth returns line with project's overdue tasks, tasks due soon and work in progress.
+func (b *Bot) renderProjectHealth(ctx context.Context, prj *model.Project, now time.Time) (string, error) {
+	counters, err := b.fetchTaskCounters(ctx, prj.ID)
+	if err != nil {
+		return "", err
+	}
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{
+		ProjectID: prj.ID,
+		Deadline:  now.Add(rollupDueWindow),
+		OnlyOpen:  true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	dueSoon := 0
+	for _, task := range tasks {
+		if !task.Deadline.Before(now) {
+			dueSoon++
+		}
+	}
+	return fmt.Sprintf(
+		"🔥 %d просрочено, ⏳ %d в ближайшие 7 дней, %s %d в работе",
+		counters.Overdue, dueSoon, model.TaskStatusInProgress.Emoji(), counters.InProgress,
+	), nil
+}
+
+// boardLink returns link to pinned board of the project, empty if board is not pinned or chat has no links.
+func boardLink(prj *model.Project) string {
+	if prj.BoardMessageID == 0 || prj.TgChatID > supergroupChatIDOffset {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/c/%d/%d", supergroupChatIDOffset-prj.TgChatID, prj.BoardMessageID)
+}
+
+// importChatAdministrators adds administrators of project's chat to the project as managers,
+// failures are only logged since users still can join with /start.
+func (b *Bot) importChatAdministrators(ctx context.Context, prj *model.Project) {
+	admins, err := b.GetChatAdministrators(tgbotapi.ChatAdministratorsConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: prj.TgChatID},
+	})
+	if err != nil {
+		log.Printf("WARN could not fetch administrators of chat id=%d: %s", prj.TgChatID, err)
+		return
+	}
+
+	added := 0
+	for _, admin := range admins {
+		if admin.User == nil || admin.User.IsBot {
+			continue
+		}
+		user, err := b.userStorage.FetchUserByTgID(ctx, admin.User.ID)
+		if err != nil && errors.Is(err, model.ErrUserNotFound) {
+			user = b.newTgUser(admin.User)
+			if err = b.userStorage.CreateUser(ctx, user); err != nil {
+				log.Printf("ERROR could not create user tg_id=%d: %s", admin.User.ID, err)
+				continue
+			}
+		} else if err != nil {
+			log.Printf("ERROR could not fetch user tg_id=%d: %s", admin.User.ID, err)
+			continue
+		}
+
+		// Author of /start is already a member
+		err = b.userStorage.FetchUserRoleInProject(ctx, prj.ID, user)
+		if err == nil {
+			continue
+		} else if !errors.Is(err, model.ErrUserNotFound) {
+			log.Printf("ERROR could not fetch role of user id=%d: %s", user.ID, err)
+			continue
+		}
+		if err = b.userStorage.AddUserToProject(ctx, prj.ID, user.ID, model.UserProjectRoleManager); err != nil {
+			log.Printf("ERROR could not add user id=%d to project: %s", user.ID, err)
+			continue
+		}
+		added++
+	}
+	log.Printf("DEBUG added %d chat administrators to project id=%d", added, prj.ID)
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// callbackMoveTask is followed by task id and move, e.g. "move_task_12_up".
+const callbackMoveTask = "move_task_"
+
+const rankRefusal = "порядок задач меняет менеджер проекта"
+
+// rankButtons are shown on cards of tasks waiting to be started.
+func rankButtons(task *model.Task) []tgbotapi.InlineKeyboardButton {
+	if task.Status != model.TaskStatusTODO && task.Status != model.TaskStatusBacklog {
+		return nil
+	}
+	button := func(text string, move model.TaskMove) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("%s%d_%s", callbackMoveTask, task.ID, move))
+	}
+	return tgbotapi.NewInlineKeyboardRow(
+		button("⬆️", model.TaskMoveUp), button("⬇️", model.TaskMoveDown), button("⏫ В начало", model.TaskMoveTop),
+	)
+}
+
+// moveTaskCallback changes place of task among project's tasks with the same status, allowed for managers.
+func (b *Bot) moveTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	rawID, rawMove, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackMoveTask), "_")
+	move := model.TaskMove(rawMove)
+	taskID, err := strconv.Atoi(rawID)
+	if err != nil || !slices.Contains([]model.TaskMove{model.TaskMoveUp, model.TaskMoveDown, model.TaskMoveTop}, move) {
+		return fmt.Errorf("could not parse callback data %q", query.Data)
+	}
+	task, user, ok, err := b.fetchTriagedTask(ctx, query, taskID, rankRefusal)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = b.taskStorage.MoveTask(ctx, task.ID, move); err != nil {
+		return fmt.Errorf("could not move task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d moved task id=%d %s", user.ID, task.ID, move)
+
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: task.ProjectID, Status: task.Status})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	place := slices.IndexFunc(tasks, func(t model.Task) bool { return t.ID == task.ID }) + 1
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, taskCardKeyboard(task))
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, fmt.Sprintf("место в «%s»: %d из %d", task.Status.StringLocalized(), place, len(tasks)))
+}
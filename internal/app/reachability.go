@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// unreachableReminderInterval limits reminders in group chats to user who blocked bot.
+const unreachableReminderInterval = 24 * time.Hour
+
+// isUnreachable reports whether user blocked bot, private chat ID equals user ID.
+func (b *Bot) isUnreachable(ctx context.Context, tgUserID int64) bool {
+	user, err := b.userStorage.FetchUserByTgID(ctx, tgUserID)
+	if err != nil {
+		if !errors.Is(err, model.ErrUserNotFound) {
+			log.Printf("ERROR could not fetch user: %s", err)
+		}
+		return false
+	}
+	return user.Unreachable
+}
+
+func (b *Bot) setUnreachable(ctx context.Context, tgUserID int64, unreachable bool) {
+	if err := b.userStorage.SetUserUnreachable(ctx, tgUserID, unreachable); err != nil {
+		log.Printf("ERROR could not update user reachability: %s", err)
+		return
+	}
+	log.Printf("DEBUG user tg_id=%d unreachable set to %t", tgUserID, unreachable)
+}
+
+// checkReachability handles every update: private message means user has unblocked bot,
+// user who still blocks it is reminded in group chat.
+func (b *Bot) checkReachability(ctx context.Context, update tgbotapi.Update) {
+	from, chat := update.SentFrom(), update.FromChat()
+	if from == nil || chat == nil || !b.isUnreachable(ctx, from.ID) {
+		return
+	}
+	if chat.IsPrivate() {
+		b.setUnreachable(ctx, from.ID, false)
+		return
+	}
+
+	b.remindersMu.Lock()
+	remindedAt, ok := b.unreachableReminders[from.ID]
+	if ok && time.Since(remindedAt) < unreachableReminderInterval {
+		b.remindersMu.Unlock()
+		return
+	}
+	b.unreachableReminders[from.ID] = time.Now()
+	b.remindersMu.Unlock()
+
+	mention := from.FirstName
+	if from.UserName != "" {
+		mention = "@" + from.UserName
+	}
+	text := fmt.Sprintf("⚠️ %s, бот не может написать вам в личные сообщения. "+
+		"Откройте чат с @%s и нажмите «Перезапустить» или /start.", mention, b.Self.UserName)
+	if _, err := b.sendMessage(tgbotapi.NewMessage(chat.ID, text)); err != nil {
+		log.Printf("ERROR could not remind unreachable user: %s", err)
+	}
+}
@@ -0,0 +1,15 @@
+package app
+
+import (
+	"encoding/json"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// recordUpdate appends update to updates log before it is handled, handlers may modify it.
+func (b *Bot) recordUpdate(update tgbotapi.Update) {
+	if err := json.NewEncoder(b.cfg.UpdatesLog).Encode(update); err != nil {
+		log.Printf("ERROR could not record update id=%d: %s", update.UpdateID, err)
+	}
+}
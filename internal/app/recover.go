@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackRetryUpdate = "retry_update_"
+
+// recoverUpdate keeps bot running when update handler panics and offers user to retry,
+// must be deferred directly by HandleUpdate.
+func (b *Bot) recoverUpdate(update tgbotapi.Update) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	log.Printf("ERROR panic while handling update id=%d: %v\n%s", update.UpdateID, r, debug.Stack())
+
+	chat := update.FromChat()
+	if chat == nil {
+		return
+	}
+	retryID := b.failedUpdates.Put(update)
+	msg := tgbotapi.NewMessage(chat.ID, "😵 что-то пошло не так, попробуйте ещё раз")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 повторить", fmt.Sprintf("%s%d", callbackRetryUpdate, retryID)),
+		),
+	)
+	if _, err := b.sendMessage(msg); err != nil {
+		log.Printf("ERROR could not send apology: %s", err)
+	}
+}
+
+// retryUpdateCallback handles failed update again on behalf of its author.
+func (b *Bot) retryUpdateCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	retryID, err := parseCallbackID(query.Data, callbackRetryUpdate)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	failed, ok := b.failedUpdates.Get(retryID)
+	if !ok {
+		return b.answerCallback(query.ID, "повторить уже нельзя, отправьте команду заново")
+	}
+	if from := failed.SentFrom(); from == nil || from.ID != query.From.ID {
+		return b.answerCallback(query.ID, "повторить может только автор запроса")
+	}
+	b.failedUpdates.Delete(retryID)
+
+	if err = b.editCallbackMessage(query, "🔁 повторяю…"); err != nil {
+		return err
+	}
+	if err = b.answerCallback(query.ID, ""); err != nil {
+		return err
+	}
+	b.HandleUpdate(ctx, failed)
+	return nil
+}
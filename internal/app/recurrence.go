@@ -0,0 +1,249 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const repeatUsage = "Повторять задачу: /repeat 12 ежедневно, еженедельно, ежемесячно или по расписанию cron, " +
+	"например /repeat 12 0 9 * * 1 — по понедельникам в 9:00. Перестать повторять: /repeat 12 -"
+
+// repeatCommand lists project's recurring tasks, "/repeat N rule" makes task N recur and "/repeat N -" stops it.
+func (b *Bot) repeatCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	prj, user, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.reply(message, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.listRecurringTasks(ctx, message, prj)
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || len(args) < 2 {
+		return b.reply(message, repeatUsage)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, id)
+	if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task == nil || task.ProjectID != prj.ID {
+		return b.reply(message, fmt.Sprintf("задачи #%d нет в проекте", id))
+	}
+
+	if args[1] == "-" {
+		if err = b.taskStorage.RemoveTaskRecurrence(ctx, task.ID); err != nil {
+			return fmt.Errorf("could not remove recurrence: %w", err)
+		}
+		log.Printf("DEBUG user id=%d stopped recurrence of task id=%d", user.ID, task.ID)
+		return b.reply(message, fmt.Sprintf("🔁 задача #%d больше не повторяется", task.ID))
+	}
+
+	raw := strings.Join(args[1:], " ")
+	rule, err := model.ParseRecurrenceRule(raw)
+	if err != nil {
+		log.Printf("DEBUG could not parse recurrence rule %q: %s", raw, err)
+		return b.reply(message, fmt.Sprintf("не понял правило повтора «%s»\n\n%s", raw, repeatUsage))
+	}
+	switch {
+	case !task.Status.IsOpen():
+		return b.reply(message, fmt.Sprintf("задача #%d закрыта, повторять можно открытую задачу", task.ID))
+	case task.Epic:
+		return b.reply(message, "эпик нельзя повторять, повторяйте задачи внутри него")
+	}
+
+	// Period of the task ends at its deadline, task without one gets deadline of the first period
+	now := time.Now()
+	rec := &model.TaskRecurrence{TaskID: task.ID, Rule: rule, NextAt: task.Deadline}
+	if !rec.NextAt.After(now) {
+		rec.NextAt = rule.Next(now)
+	}
+	if err = b.taskStorage.SetTaskRecurrence(ctx, rec); err != nil {
+		return fmt.Errorf("could not set recurrence: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set recurrence of task id=%d to %q", user.ID, task.ID, rule)
+
+	if task.Deadline.IsZero() {
+		prev := task.Clone()
+		task.Deadline = rec.NextAt
+		task.UpdatedBy = int64(user.ID)
+		if err = b.updateWatchedTask(ctx, prev, task, user.ID); err != nil {
+			return fmt.Errorf("could not update task: %w", err)
+		}
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: user.ID})
+	}
+	return b.reply(message, fmt.Sprintf("🔁 задача #%d повторяется %s: следующая появится, когда эту закроют, или %s",
+		task.ID, rule.StringLocalized(), recurrenceTime(rec.NextAt)))
+}
+
+func (b *Bot) listRecurringTasks(ctx context.Context, message *tgbotapi.Message, prj *model.Project) error {
+	recs, err := b.taskStorage.FetchProjectRecurrences(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch recurrences: %w", err)
+	}
+	if len(recs) == 0 {
+		return b.reply(message, "🔁 в проекте нет повторяющихся задач\n\n"+repeatUsage)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔁 повторяющиеся задачи:\n\n")
+	for _, rec := range recs {
+		task, err := b.taskStorage.FetchTaskByID(ctx, rec.TaskID)
+		if err != nil {
+			return fmt.Errorf("could not fetch task: %w", err)
+		}
+		fmt.Fprintf(&sb, "• %s#%d %s — %s, следующая %s\n",
+			task.Priority.Marker(), task.ID, task.Title, rec.Rule.StringLocalized(), recurrenceTime(rec.NextAt))
+	}
+	sb.WriteString("\n" + repeatUsage)
+	return b.reply(message, sb.String())
+}
+
+// recurrenceTime shows time of cron rules, other rules end periods at the end of the day.
+func recurrenceTime(t time.Time) string {
+	if t.Equal(endOfDay(t)) {
+		return t.Format(format.DateLayout)
+	}
+	return t.Format(format.DateTimeLayout)
+}
+
+// StartRecurrence creates next instances of recurring tasks whose period ended or whose latest instance
+// is closed, it checks them every interval until context is cancelled.
+func (b *Bot) StartRecurrence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !b.holdsLease(ctx, "recurrence", interval) {
+				continue
+			}
+			if err := b.renewRecurringTasks(ctx); err != nil {
+				log.Printf("ERROR recurrence: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bot) renewRecurringTasks(ctx context.Context) error {
+	now := time.Now()
+	recs, err := b.taskStorage.FetchDueRecurrences(ctx, now)
+	if err != nil {
+		return fmt.Errorf("could not fetch due recurrences: %w", err)
+	}
+
+	projects := make(map[int]*model.Project)
+	for _, rec := range recs {
+		task, err := b.taskStorage.FetchTaskByID(ctx, rec.TaskID)
+		if err != nil {
+			log.Printf("ERROR recurrence: could not fetch task id=%d: %s", rec.TaskID, err)
+			continue
+		}
+		prj, ok := projects[task.ProjectID]
+		if !ok {
+			if prj, err = b.projectStorage.FetchProjectByID(ctx, task.ProjectID); err != nil {
+				log.Printf("ERROR recurrence: could not fetch project id=%d: %s", task.ProjectID, err)
+				continue
+			}
+			projects[task.ProjectID] = prj
+		}
+		// Tasks of frozen projects are created once they are unfrozen
+		if prj.Archived || prj.Frozen {
+			continue
+		}
+		if err = b.renewRecurringTask(ctx, prj, task, &rec, now); err != nil {
+			log.Printf("ERROR recurrence: could not renew task id=%d: %s", task.ID, err)
+		}
+	}
+	return nil
+}
+
+// renewRecurringTask creates the next instance of the task with the same content and unchecked checklist,
+// its watchers keep watching it. Periods missed while bot was stopped are skipped.
+func (b *Bot) renewRecurringTask(ctx context.Context, prj *model.Project, prev *model.Task, rec *model.TaskRecurrence, now time.Time) error {
+	nextAt := rec.Rule.Next(rec.NextAt)
+	for !nextAt.IsZero() && !nextAt.After(now) {
+		nextAt = rec.Rule.Next(nextAt)
+	}
+	if nextAt.IsZero() {
+		if err := b.taskStorage.RemoveTaskRecurrence(ctx, prev.ID); err != nil {
+			return fmt.Errorf("could not remove recurrence: %w", err)
+		}
+		return fmt.Errorf("rule %q doesn't match any time, recurrence is removed", rec.Rule)
+	}
+
+	next := model.NewTask(prev.ProjectID, prev.Title, prev.CreatedBy)
+	next.Description = prev.Description
+	next.Status = model.TaskStatusTODO
+	next.Deadline = nextAt
+	next.Assignee = prev.Assignee
+	next.CoAssignees = slices.Clone(prev.CoAssignees)
+	next.Reviewer = prev.Reviewer
+	next.Muted = prev.Muted
+	next.ParentID = prev.ParentID
+	next.Priority = prev.Priority
+	next.Estimate = prev.Estimate
+	next.Labels = slices.Clone(prev.Labels)
+	rec.NextAt = nextAt
+	if err := b.taskStorage.RenewTaskRecurrence(ctx, rec, next); err != nil {
+		return fmt.Errorf("could not renew recurrence: %w", err)
+	}
+	log.Printf("INFO recurrence: created task id=%d repeating task id=%d", next.ID, prev.ID)
+
+	if err := b.copyRecurringTaskDetails(ctx, prev, next); err != nil {
+		// Task is created, so it is better to post it without details than to repeat it twice
+		log.Printf("WARN recurrence: could not copy details of task id=%d: %s", prev.ID, err)
+	}
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventCreated, Task: *next, ActorID: int(prev.CreatedBy)})
+
+	card, err := b.renderTaskCard(ctx, next)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(prj.TgChatID, fmt.Sprintf("🔁 повтор задачи #%d\n\n%s", prev.ID, card))
+	msg.ReplyMarkup = taskCardKeyboard(next)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+func (b *Bot) copyRecurringTaskDetails(ctx context.Context, prev, next *model.Task) error {
+	items, err := b.taskStorage.FetchChecklist(ctx, prev.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch checklist: %w", err)
+	}
+	if len(items) > 0 {
+		copies := make([]*model.ChecklistItem, len(items))
+		for i, item := range items {
+			copies[i] = &model.ChecklistItem{TaskID: next.ID, Text: item.Text}
+		}
+		if err = b.taskStorage.AddChecklistItems(ctx, copies); err != nil {
+			return fmt.Errorf("could not add checklist items: %w", err)
+		}
+	}
+
+	watchers, err := b.taskStorage.FetchTaskWatchers(ctx, prev.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch watchers: %w", err)
+	}
+	for _, userID := range watchers {
+		if err = b.taskStorage.SetTaskWatcher(ctx, next.ID, userID, true); err != nil {
+			return fmt.Errorf("could not set watcher: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackSetReviewer = "set_reviewer_"
+
+// reviewerPromptRe matches bot's prompt for reviewer, reply to it carries username.
+var reviewerPromptRe = regexp.MustCompile(`проверит задачу #(\d+)`)
+
+// setReviewerCallback asks assignee of the task to reply with username of reviewer.
+func (b *Bot) setReviewerCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackSetReviewer)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, user, ok, err := b.fetchAssignedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf(
+		"👀 %s, ответьте на это сообщение @username или упоминанием участника, который проверит задачу #%d, или «-», чтобы убрать ревьюера",
+		mention(user), task.ID,
+	))
+	msg.ReplyToMessageID = query.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true, InputFieldPlaceholder: "@username"}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send reviewer prompt: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// handleReviewerReply sets reviewer named in reply and notifies them privately,
+// it reports false if message is not a reply to reviewer prompt.
+func (b *Bot) handleReviewerReply(ctx context.Context, message *tgbotapi.Message) (bool, error) {
+	task, user, handled, err := b.fetchPromptedTask(ctx, message, reviewerPromptRe)
+	if err != nil || task == nil {
+		return handled, err
+	}
+
+	var reviewer *model.User
+	fields := strings.Fields(message.Text)
+	refs, _ := parseMemberRefs(message)
+	switch {
+	case len(fields) > 0 && fields[0] == "-":
+		if task.Reviewer == 0 {
+			return true, b.reply(message, "у задачи нет ревьюера")
+		}
+	case len(refs) > 0:
+		reviewer, err = b.fetchProjectMemberByRef(ctx, task.ProjectID, refs[0])
+		if err != nil && errors.Is(err, model.ErrUserNotFound) {
+			return true, b.reply(message, fmt.Sprintf("%s не состоит в проекте", refs[0]))
+		} else if err != nil {
+			return true, fmt.Errorf("could not fetch project member: %w", err)
+		}
+		if task.IsAssignee(int64(reviewer.ID)) {
+			return true, b.reply(message, "исполнитель не может проверять свою задачу")
+		}
+	default:
+		return true, b.reply(message, "ответьте на сообщение бота @username или упоминанием участника проекта или «-»")
+	}
+
+	task.Reviewer = 0
+	if reviewer != nil {
+		task.Reviewer = int64(reviewer.ID)
+	}
+	task.UpdatedBy = int64(user.ID)
+	notification := model.NewNotification(task.Reviewer,
+		fmt.Sprintf("👀 %s просит вас проверить задачу #%d %s", user.FullName, task.ID, task.Title))
+	if err = b.updateTaskNotifying(ctx, task, notification); err != nil {
+		return true, fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set reviewer of task id=%d to user id=%d", user.ID, task.ID, task.Reviewer)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: user.ID})
+
+	if reviewer == nil {
+		return true, b.reply(message, fmt.Sprintf("👀 у задачи #%d больше нет ревьюера", task.ID))
+	}
+	return true, b.reply(message, fmt.Sprintf("👀 %s проверит задачу #%d", reviewer.FullName, task.ID))
+}
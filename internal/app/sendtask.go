@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackSendTask   = "send_task_"
+	callbackSendTaskTo = "send_to_"
+)
+
+// taskSending is a target chat offered to member sharing task card.
+type taskSending struct {
+	authorTgID int64
+	taskID     int
+	projectID  int
+}
+
+func sendTaskButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("📨 Отправить в другой чат", fmt.Sprintf("%s%d", callbackSendTask, task.ID))
+}
+
+// sendTaskCallback offers member to choose one of their other projects privately,
+// so titles of those projects are not shown to this chat.
+func (b *Bot) sendTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackSendTask)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+
+	projects, err := b.projectStorage.FetchProjectsByUser(ctx, user.ID, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch projects: %w", err)
+	}
+	projects = slices.DeleteFunc(projects, func(p model.Project) bool { return p.ID == prj.ID || p.Archived })
+	if len(projects) == 0 {
+		return b.answerCallback(query.ID, "вы не состоите в других чатах с ботом")
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, target := range projects {
+		sendingID := b.taskSendings.Put(taskSending{authorTgID: query.From.ID, taskID: task.ID, projectID: target.ID})
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(target.Title, fmt.Sprintf("%s%d", callbackSendTaskTo, sendingID)),
+		))
+	}
+	msg := tgbotapi.NewMessage(query.From.ID, fmt.Sprintf("📨 куда отправить задачу #%d %s?", task.ID, task.Title))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err = b.sendMessage(msg); err != nil {
+		log.Printf("WARN could not offer chats to user id=%d privately: %s", user.ID, err)
+		return b.answerCallback(query.ID, "не получилось написать вам, начните личный чат с ботом и попробуйте снова")
+	}
+	return b.answerCallback(query.ID, "выберите чат в личных сообщениях с ботом")
+}
+
+// sendTaskToCallback posts read-only card of the task to chosen project chat.
+func (b *Bot) sendTaskToCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	sendingID, err := parseCallbackID(query.Data, callbackSendTaskTo)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	sending, ok := b.taskSendings.Get(sendingID)
+	if !ok {
+		return b.answerCallback(query.ID, "выбор устарел, нажмите кнопку на карточке задачи снова")
+	}
+	if sending.authorTgID != query.From.ID {
+		return b.answerCallback(query.ID, "выбрать чат может только тот, кто отправляет задачу")
+	}
+	b.taskSendings.Delete(sendingID)
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, sending.taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	source, err := b.projectStorage.FetchProjectByID(ctx, task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	target, err := b.projectStorage.FetchProjectByID(ctx, sending.projectID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.answerCallback(query.ID, "чат больше не подключён к боту")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	user, err := b.userStorage.FetchUserByTgID(ctx, query.From.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+	// Membership could change since chats were offered
+	projects, err := b.projectStorage.FetchProjectsByUser(ctx, user.ID, "")
+	if err != nil {
+		return fmt.Errorf("could not fetch projects: %w", err)
+	}
+	member := func(projectID int) bool {
+		return slices.ContainsFunc(projects, func(p model.Project) bool { return p.ID == projectID })
+	}
+	if !member(source.ID) || !member(target.ID) {
+		return b.answerCallback(query.ID, "вы больше не состоите в одном из проектов")
+	}
+
+	card, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("📨 %s делится задачей из проекта «%s», только для просмотра:\n\n%s", user.FullName, source.Title, card)
+	if _, err = b.sendMessage(tgbotapi.NewMessage(target.TgChatID, text)); err != nil {
+		return fmt.Errorf("could not send task card: %w", err)
+	}
+	log.Printf("DEBUG user id=%d sent task id=%d to project id=%d", user.ID, task.ID, target.ID)
+
+	if err = b.editCallbackMessage(query, fmt.Sprintf("📨 задача #%d отправлена в «%s»", task.ID, target.Title)); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "")
+}
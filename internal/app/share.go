@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/publicboard"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// shareBoardCommand issues public read-only link to project board, "/share_board off" revokes it.
+func (b *Bot) shareBoardCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+	if b.cfg.PublicURL == "" {
+		return b.reply(update.Message, "веб-доступ не настроен на этом сервере")
+	}
+
+	if strings.TrimSpace(update.Message.CommandArguments()) == "off" {
+		prj.PublicToken = ""
+		if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+			return fmt.Errorf("could not update project: %w", err)
+		}
+		log.Printf("DEBUG project id=%d public board disabled", prj.ID)
+		if err = b.reply(update.Message, "🔒 ссылка на доску отключена"); err != nil {
+			return err
+		}
+		b.publishProjectChange(ctx, prj, user, "ссылка на доску отключена")
+		return nil
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return fmt.Errorf("could not generate token: %w", err)
+	}
+	prj.PublicToken = token
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d public board link issued", prj.ID)
+
+	link := strings.TrimSuffix(b.cfg.PublicURL, "/") + publicboard.PathPrefix + token
+	err = b.reply(update.Message, fmt.Sprintf(
+		"🌐 доска проекта только для просмотра:\n%s\n\n"+
+			"Её увидит любой, у кого есть ссылка. Повторный вызов /share_board выдаст новую ссылку, "+
+			"а /share_board off отключит доступ.",
+		link,
+	))
+	if err != nil {
+		return err
+	}
+	// Link itself is not repeated, announcement is only about access
+	b.publishProjectChange(ctx, prj, user, "выдана новая ссылка на доску для просмотра")
+	return nil
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackShiftConfirm = "shift_confirm_"
+	callbackShiftCancel  = "shift_cancel_"
+
+	maxDeadlineShiftDays = 365
+)
+
+type deadlineShift struct {
+	authorTgID int64
+	projectID  int
+	days       int
+	taskIDs    []int
+}
+
+// shiftDeadlinesCommand previews moving deadlines of listed tasks, or of all open tasks with deadline,
+// by given number of days and waits for confirmation, allowed for managers.
+func (b *Bot) shiftDeadlinesCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, _, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	usage := fmt.Sprintf("укажите число дней от -%d до %d и, если нужно, номера задач, например:\n\n"+
+		"/shift_deadlines 7 — все открытые задачи со сроком\n/shift_deadlines 3 #12 #15", maxDeadlineShiftDays, maxDeadlineShiftDays)
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		return b.reply(update.Message, usage)
+	}
+	days, err := strconv.Atoi(args[0])
+	if err != nil || days == 0 || days < -maxDeadlineShiftDays || days > maxDeadlineShiftDays {
+		return b.reply(update.Message, usage)
+	}
+
+	var tasks []model.Task
+	if len(args) == 1 {
+		all, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, OnlyOpen: true})
+		if err != nil {
+			return fmt.Errorf("could not fetch project tasks: %w", err)
+		}
+		for _, task := range all {
+			if !task.Deadline.IsZero() {
+				tasks = append(tasks, task)
+			}
+		}
+		if len(tasks) == 0 {
+			return b.reply(update.Message, "в проекте нет открытых задач со сроком")
+		}
+	}
+	for _, arg := range args[1:] {
+		taskID, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		if err != nil {
+			return b.reply(update.Message, fmt.Sprintf("не понял номер задачи %q", arg))
+		}
+		if slices.ContainsFunc(tasks, func(t model.Task) bool { return t.ID == taskID }) {
+			continue
+		}
+		task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+		if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+			return fmt.Errorf("could not fetch task: %w", err)
+		}
+		if task == nil || task.ProjectID != prj.ID {
+			return b.reply(update.Message, fmt.Sprintf("задачи #%d нет в проекте", taskID))
+		}
+		if task.Deadline.IsZero() || !task.Status.IsOpen() {
+			return b.reply(update.Message, fmt.Sprintf("задача #%d закрыта или без срока, её срок не сдвинуть", taskID))
+		}
+		tasks = append(tasks, *task)
+	}
+
+	isDayOff, err := b.projectDaysOff(ctx, prj.ID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		preview  strings.Builder
+		warnings strings.Builder
+		shift    = deadlineShift{authorTgID: update.Message.From.ID, projectID: prj.ID, days: days}
+	)
+	fmt.Fprintf(&preview, "📅 сроки задач сдвинутся на %s:\n\n", shiftDaysText(days))
+	for _, task := range tasks {
+		deadline := task.Deadline.AddDate(0, 0, days)
+		fmt.Fprintf(&preview, "#%d %s: %s → %s\n", task.ID, task.Title,
+			task.Deadline.Format(format.DateLayout), deadline.Format(format.DateLayout))
+		if isDayOff(deadline) {
+			fmt.Fprintf(&warnings, "⚠️ новый срок задачи #%d выпадает на выходной\n", task.ID)
+		}
+		shift.taskIDs = append(shift.taskIDs, task.ID)
+	}
+	if warnings.Len() > 0 {
+		preview.WriteString("\n")
+		preview.WriteString(warnings.String())
+	}
+
+	shiftID := b.deadlineShifts.Put(shift)
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, preview.String())
+	msg.ReplyToMessageID = update.Message.MessageID
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Сдвинуть", fmt.Sprintf("%s%d", callbackShiftConfirm, shiftID)),
+			tgbotapi.NewInlineKeyboardButtonData("✖️ Отмена", fmt.Sprintf("%s%d", callbackShiftCancel, shiftID)),
+		),
+	)
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+func (b *Bot) shiftConfirmCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	shift, ok, err := b.takeDeadlineShift(query, callbackShiftConfirm)
+	if err != nil || !ok {
+		return err
+	}
+	manager, err := b.userStorage.FetchUserByTgID(ctx, query.From.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch user: %w", err)
+	}
+
+	// Tasks are fetched again, so changes made since preview are kept
+	var tasks, prevs []*model.Task
+	for _, taskID := range shift.taskIDs {
+		task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+		if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("could not fetch task: %w", err)
+		}
+		if task.ProjectID != shift.projectID || task.Deadline.IsZero() || !task.Status.IsOpen() {
+			continue
+		}
+		prevs = append(prevs, task.Clone())
+		task.Deadline = task.Deadline.AddDate(0, 0, shift.days)
+		task.UpdatedBy = int64(manager.ID)
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		if err = b.editCallbackMessage(query, "📅 задачи уже закрыты или удалены, сдвигать нечего"); err != nil {
+			return err
+		}
+		return b.answerCallback(query.ID, "")
+	}
+
+	if err = b.updateWatchedTasks(ctx, prevs, tasks, manager.ID); err != nil {
+		return fmt.Errorf("could not update tasks: %w", err)
+	}
+	for i, task := range tasks {
+		// Passing of the new deadline is announced again
+		if err = b.taskStorage.SetTaskOverdueNotified(ctx, task.ID, false); err != nil {
+			return fmt.Errorf("could not reset overdue announcement: %w", err)
+		}
+		b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prevs[i], ActorID: manager.ID})
+	}
+	log.Printf("INFO user id=%d shifted deadlines of %d tasks of project id=%d by %d days",
+		manager.ID, len(tasks), shift.projectID, shift.days)
+
+	text := fmt.Sprintf("📅 сдвинуты сроки задач: %d, на %s", len(tasks), shiftDaysText(shift.days))
+	if err = b.editCallbackMessage(query, text); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "сроки сдвинуты")
+}
+
+func (b *Bot) shiftCancelCallback(update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	_, ok, err := b.takeDeadlineShift(query, callbackShiftCancel)
+	if err != nil || !ok {
+		return err
+	}
+	if err = b.editCallbackMessage(query, "✖️ сдвиг сроков отменён"); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// takeDeadlineShift removes shift from pending ones if callback is pressed by its author,
+// otherwise it answers callback with explanation.
+func (b *Bot) takeDeadlineShift(query *tgbotapi.CallbackQuery, prefix string) (deadlineShift, bool, error) {
+	shiftID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return deadlineShift{}, false, fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	shift, ok := b.deadlineShifts.Get(shiftID)
+	if !ok {
+		return deadlineShift{}, false, b.answerCallback(query.ID, "предпросмотр устарел, отправьте команду заново")
+	}
+	if shift.authorTgID != query.From.ID {
+		return deadlineShift{}, false, b.answerCallback(query.ID, "подтвердить может только автор команды")
+	}
+	b.deadlineShifts.Delete(shiftID)
+	return shift, true, nil
+}
+
+// shiftDaysText describes shift direction, e.g. "3 дня вперёд".
+func shiftDaysText(days int) string {
+	if days < 0 {
+		return daysText(-days) + " назад"
+	}
+	return daysText(days) + " вперёд"
+}
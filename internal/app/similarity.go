@@ -0,0 +1,73 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const (
+	duplicateThreshold = 0.5
+	duplicatesMaxNum   = 3
+)
+
+type taskSimilarity struct {
+	task  model.Task
+	score float64
+}
+
+// findSimilarTasks returns open tasks with titles close to given title, most similar first.
+func findSimilarTasks(title string, tasks []model.Task) []model.Task {
+	grams := trigrams(title)
+	var similar []taskSimilarity
+	for _, task := range tasks {
+		if !task.Status.IsOpen() {
+			continue
+		}
+		score := jaccard(grams, trigrams(task.Title))
+		if score >= duplicateThreshold {
+			similar = append(similar, taskSimilarity{task: task, score: score})
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].score > similar[j].score
+	})
+
+	var res []model.Task
+	for i := 0; i < len(similar) && i < duplicatesMaxNum; i++ {
+		res = append(res, similar[i].task)
+	}
+	return res
+}
+
+// trigrams splits normalized text into set of character trigrams, words are padded with spaces
+// so short words still produce grams.
+func trigrams(s string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	grams := make(map[string]struct{})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return grams
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
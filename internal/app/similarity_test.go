@@ -0,0 +1,67 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func TestTitleSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Починить сборку", "Починить сборку", 1},
+		{"Починить сборку", "починить, СБОРКУ!", 1},
+		{"Починить сборку", "сборку починить", 1},
+		{"Починить сборку", "Release notes", 0},
+		{"fix CI", "fix CI", 1},
+		{"", "fix CI", 0},
+		{"!!!", "!!!", 0},
+		// One-letter word still gives grams, so it is not ignored
+		{"a", "b", 0},
+		{"a", "a", 1},
+	}
+	for _, tt := range tests {
+		if got := jaccard(trigrams(tt.a), trigrams(tt.b)); got != tt.want {
+			t.Errorf("similarity(%q, %q): got %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestFindSimilarTasks(t *testing.T) {
+	tasks := []model.Task{
+		{ID: 1, Title: "Починить сборку на CI", Status: model.TaskStatusTODO},
+		{ID: 2, Title: "Починить сборку", Status: model.TaskStatusInProgress},
+		{ID: 3, Title: "Починить сборку", Status: model.TaskStatusDone},
+		{ID: 4, Title: "Обновить документацию", Status: model.TaskStatusTODO},
+		{ID: 5, Title: "починить сборку", Status: model.TaskStatusCancelled},
+		{ID: 6, Title: "Починить сборку релиза", Status: model.TaskStatusBacklog},
+		{ID: 7, Title: "Сборку починить", Status: model.TaskStatusOnHold},
+		{ID: 8, Title: "Починить сборку на CI снова", Status: model.TaskStatusReview},
+	}
+
+	tests := []struct {
+		name  string
+		title string
+		tasks []model.Task
+		want  []int
+	}{
+		{"most similar first, closed skipped, at most three", "Починить сборку", tasks, []int{2, 7, 1}},
+		{"nothing similar", "Купить кофе", tasks, nil},
+		{"empty title", "", tasks, nil},
+		{"no tasks", "Починить сборку", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, task := range findSimilarTasks(tt.title, tt.tasks) {
+				got = append(got, task.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// snapshotTrendDays is how far back /projects compares task counts.
+const snapshotTrendDays = 7
+
+// StartSnapshots saves counts of tasks by status of every project every interval until context is cancelled.
+// Snapshot of the day is replaced on every run, so the day keeps counts at its end.
+func (b *Bot) StartSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if !b.holdsLease(ctx, "snapshots", interval) {
+				continue
+			}
+			if err := b.taskStorage.SnapshotTaskCounts(ctx, startOfDay(time.Now())); err != nil {
+				log.Printf("ERROR snapshots: could not snapshot task counts: %s", err)
+			} else {
+				log.Printf("DEBUG snapshots: task counts saved")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// renderProjectTrend returns line with tasks closed during the last week and change of open tasks,
+// empty if project has no snapshot of the week ago yet.
+func (b *Bot) renderProjectTrend(ctx context.Context, projectID int, now time.Time) (string, error) {
+	today := startOfDay(now)
+	snapshots, err := b.taskStorage.FetchTaskSnapshots(ctx, projectID, today.AddDate(0, 0, -snapshotTrendDays), today)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch snapshots: %w", err)
+	}
+	if len(snapshots) < 2 || !snapshots[0].Day.Equal(today.AddDate(0, 0, -snapshotTrendDays)) {
+		return "", nil
+	}
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	return fmt.Sprintf("📈 за неделю закрыто %d, открытых задач %d → %d", max(last.Closed()-first.Closed(), 0), first.Open(), last.Open()), nil
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackStartTask    = "task_start_"
+	callbackFinishTask   = "task_finish_"
+	callbackHoldTask     = "task_hold_"
+	callbackAcceptReview = "review_accept_"
+	callbackReturnReview = "review_return_"
+)
+
+// statusTransition is one-tap status change offered on task card in some statuses.
+type statusTransition struct {
+	prefix string
+	from   []model.TaskStatus
+	// byReviewer transitions are made by reviewer, others by assignees.
+	byReviewer bool
+}
+
+var statusTransitions = []statusTransition{
+	{prefix: callbackStartTask, from: []model.TaskStatus{model.TaskStatusBacklog, model.TaskStatusTODO, model.TaskStatusOnHold}},
+	{prefix: callbackFinishTask, from: []model.TaskStatus{model.TaskStatusInProgress}},
+	{prefix: callbackHoldTask, from: []model.TaskStatus{model.TaskStatusInProgress}},
+	{prefix: callbackAcceptReview, from: []model.TaskStatus{model.TaskStatusReview}, byReviewer: true},
+	{prefix: callbackReturnReview, from: []model.TaskStatus{model.TaskStatusReview}, byReviewer: true},
+}
+
+// target returns status task gets, finished task goes to review if it has reviewer.
+func (t statusTransition) target(task *model.Task) model.TaskStatus {
+	switch t.prefix {
+	case callbackStartTask, callbackReturnReview:
+		return model.TaskStatusInProgress
+	case callbackHoldTask:
+		return model.TaskStatusOnHold
+	case callbackFinishTask:
+		if task.Reviewer != 0 {
+			return model.TaskStatusReview
+		}
+	}
+	return model.TaskStatusDone
+}
+
+func (t statusTransition) label(task *model.Task) string {
+	switch t.prefix {
+	case callbackStartTask:
+		return "▶️ Начать"
+	case callbackFinishTask:
+		if task.Reviewer != 0 {
+			return "👀 На проверку"
+		}
+		return "✅ Завершить"
+	case callbackHoldTask:
+		return "⏸ Отложить"
+	case callbackAcceptReview:
+		return "👍 Принять"
+	default:
+		return "↩️ Вернуть в работу"
+	}
+}
+
+// statusButtons has transitions available in current status of the task.
+func statusButtons(task *model.Task) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, t := range statusTransitions {
+		if !slices.Contains(t.from, task.Status) || (t.byReviewer && task.Reviewer == 0) {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(t.label(task), fmt.Sprintf("%s%d", t.prefix, task.ID)))
+	}
+	return row
+}
+
+// statusCallback moves task to next status and refreshes its card,
+// allowed for assignees and for reviewer when task is in review.
+func (b *Bot) statusCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	i := slices.IndexFunc(statusTransitions, func(t statusTransition) bool { return strings.HasPrefix(query.Data, t.prefix) })
+	if i < 0 {
+		return b.answerCallback(query.ID, "")
+	}
+	transition := statusTransitions[i]
+	taskID, err := parseCallbackID(query.Data, transition.prefix)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if transition.byReviewer && task.Reviewer != int64(user.ID) {
+		return b.answerCallback(query.ID, "это может сделать только ревьюер задачи")
+	}
+	if !transition.byReviewer && !task.IsAssignee(int64(user.ID)) {
+		return b.answerCallback(query.ID, "это может сделать только исполнитель задачи")
+	}
+	if !slices.Contains(transition.from, task.Status) {
+		return b.answerCallback(query.ID, fmt.Sprintf("задача уже в статусе «%s»", task.Status.StringLocalized()))
+	}
+	if transition.prefix == callbackStartTask {
+		blockers, err := b.openBlockers(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if len(blockers) > 0 {
+			return b.answerCallback(query.ID, fmt.Sprintf("⛓ задачу блокируют незакрытые задачи %s", formatTaskIDs(blockers)))
+		}
+	}
+
+	prev := task.Clone()
+	from := task.Status
+	task.Status = transition.target(task)
+	task.UpdatedBy = int64(user.ID)
+	var notifications []model.Notification
+	switch {
+	case task.Status == model.TaskStatusReview:
+		notifications = append(notifications, model.NewNotification(task.Reviewer,
+			fmt.Sprintf("👀 %s просит проверить задачу #%d %s", user.FullName, task.ID, task.Title)))
+	case from == model.TaskStatusReview:
+		notifications = append(notifications, model.NewNotification(task.Assignee, fmt.Sprintf("👀 ревьюер %s: задача #%d %s — %s %s",
+			user.FullName, task.ID, task.Title, task.Status.Emoji(), task.Status.StringLocalized())))
+	}
+	if err = b.updateWatchedTask(ctx, prev, task, user.ID, notifications...); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d moved task id=%d from %s to %s", user.ID, task.ID, from, task.Status)
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, Previous: prev, ActorID: user.ID})
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	// Empty keyboard must be sent as empty array to remove the last buttons.
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if task.Status.IsOpen() {
+		keyboard = taskCardKeyboard(task)
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, text, keyboard)
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, fmt.Sprintf("%s %s", task.Status.Emoji(), task.Status.StringLocalized()))
+}
+
+// notifyUser sends private message to user if they are known, failures are only logged.
+func (b *Bot) notifyUser(ctx context.Context, userID int64, text string) {
+	if userID == 0 {
+		return
+	}
+	user, err := b.userStorage.FetchUserByID(ctx, int(userID))
+	if err != nil {
+		log.Printf("WARN could not fetch user id=%d to notify: %s", userID, err)
+		return
+	}
+	if _, err = b.sendMessage(tgbotapi.NewMessage(user.TgUserID, text)); err != nil {
+		log.Printf("WARN could not notify user id=%d: %s", user.ID, err)
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// summarizeMaxTasks limits prompt size, the nearest deadlines go first.
+const summarizeMaxTasks = 100
+
+const summarizeSystemPrompt = "Ты помогаешь команде вести задачи. По списку открытых задач проекта напиши краткую сводку " +
+	"на русском: что горит, что скоро срок, у кого больше всего работы, что стоит обсудить. " +
+	"Не больше 10 пунктов, без вступления и без разметки Markdown."
+
+// summarizeCommand asks language model for summary of project's open tasks.
+func (b *Bot) summarizeCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	if b.cfg.Completer == nil {
+		return b.reply(message, "ассистент не настроен на этом сервере")
+	}
+	if message.Chat.IsPrivate() {
+		return b.reply(message, "команда доступна только в чате проекта")
+	}
+
+	prj, _, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.reply(message, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if summary, ok := b.commandResults.Get(commandKey{"summarize", message.Chat.ID}, b.cfg.SummarizeCooldown); ok {
+		return b.reply(message, "🤖 недавно уже считал, вот сводка из кэша\n\n"+summary)
+	}
+
+	tasks, err := b.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, OnlyOpen: true})
+	if err != nil {
+		return fmt.Errorf("could not fetch tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return b.reply(message, "🎉 открытых задач нет")
+	}
+
+	prompt, err := b.renderTasksPrompt(ctx, tasks, time.Now())
+	if err != nil {
+		return err
+	}
+	// Answer may take a while
+	if _, err = b.Request(tgbotapi.NewChatAction(message.Chat.ID, tgbotapi.ChatTyping)); err != nil {
+		log.Printf("WARN could not send chat action: %s", err)
+	}
+	summary, err := b.cfg.Completer.Complete(ctx, summarizeSystemPrompt, prompt)
+	if err != nil {
+		log.Printf("ERROR could not summarize tasks of project id=%d: %s", prj.ID, err)
+		return b.reply(message, "😔 ассистент сейчас недоступен, попробуйте позже")
+	}
+	if b.cfg.SummarizeCooldown > 0 {
+		b.commandResults.Put(commandKey{"summarize", message.Chat.ID}, summary)
+	}
+	return b.reply(message, "🤖 сводка по открытым задачам\n\n"+summary)
+}
+
+// renderTasksPrompt lists tasks one per line with status, deadline and assignee.
+func (b *Bot) renderTasksPrompt(ctx context.Context, tasks []model.Task, now time.Time) (string, error) {
+	// Tasks with deadline go first, nearest first
+	sort.SliceStable(tasks, func(i, j int) bool {
+		di, dj := tasks[i].Deadline, tasks[j].Deadline
+		if di.IsZero() || dj.IsZero() {
+			return !di.IsZero() && dj.IsZero()
+		}
+		return di.Before(dj)
+	})
+	if len(tasks) > summarizeMaxTasks {
+		tasks = tasks[:summarizeMaxTasks]
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Сегодня %s.\n\n", now.Format(format.DateLayout))
+	names := make(map[int64]string)
+	for _, task := range tasks {
+		fmt.Fprintf(&sb, "#%d %s; статус: %s", task.ID, task.Title, task.Status.StringLocalized())
+		if !task.Deadline.IsZero() {
+			fmt.Fprintf(&sb, "; срок: %s", task.Deadline.Format(format.DateLayout))
+		}
+		if task.Assignee != 0 {
+			name, ok := names[task.Assignee]
+			if !ok {
+				var err error
+				if name, err = b.userName(ctx, int(task.Assignee)); err != nil {
+					return "", err
+				}
+				names[task.Assignee] = name
+			}
+			fmt.Fprintf(&sb, "; исполнитель: %s", name)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
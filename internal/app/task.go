@@ -0,0 +1,327 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackShowTask = "show_task_"
+
+// createTask saves new task and notifies subscribers.
+func (b *Bot) createTask(ctx context.Context, task *model.Task, actorID int) error {
+	if err := b.taskStorage.CreateTask(ctx, task); err != nil {
+		return fmt.Errorf("could not create task: %w", err)
+	}
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventCreated, Task: *task, ActorID: actorID})
+	return nil
+}
+
+// showTaskCallback sends card of the task, tasks are shown only in chat of their project.
+func (b *Bot) showTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackShowTask)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, query.Message.Chat.ID)
+	if err != nil && !errors.Is(err, model.ErrProjectNotFound) {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if prj == nil || prj.ID != task.ProjectID {
+		return b.answerCallback(query.ID, "задача из другого проекта")
+	}
+
+	text, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, text)
+	if task.Status.IsOpen() {
+		msg.ReplyMarkup = taskCardKeyboard(task)
+	}
+	if _, err = b.sendMessage(msg); err != nil {
+		return fmt.Errorf("could not send task card: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// assigneeKeyboard has actions of open task available to its assignee.
+func assigneeKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⏰ Запросить перенос", fmt.Sprintf("%s%d", callbackPostponeRequest, task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🤝 Передать задачу", fmt.Sprintf("%s%d", callbackHandoverRequest, task.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👀 Ревьюер", fmt.Sprintf("%s%d", callbackSetReviewer, task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("👥 Соисполнители", fmt.Sprintf("%s%d", callbackSetCoAssignees, task.ID)),
+		),
+	)
+}
+
+// taskCardKeyboard has actions of open task shown on its card.
+func taskCardKeyboard(task *model.Task) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if task.Assignee != 0 {
+		if row := statusButtons(task); len(row) > 0 {
+			rows = append(rows, row)
+		}
+		rows = append(rows, assigneeKeyboard(task).InlineKeyboard...)
+	}
+	if row := rankButtons(task); len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(muteButton(task), priorityButton(task), estimateButton(task)),
+		tgbotapi.NewInlineKeyboardRow(checklistButton(task), labelsButton(task), blockersButton(task)),
+		tgbotapi.NewInlineKeyboardRow(commentsButton(task), attachmentsButton(task), historyButton(task)),
+		tgbotapi.NewInlineKeyboardRow(watchButton(task), sendTaskButton(task)),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (b *Bot) renderTaskCard(ctx context.Context, task *model.Task) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#%d %s\n\n", task.ID, task.Title)
+	fmt.Fprintf(&sb, "Статус: %s %s\n", task.Status.Emoji(), task.Status.StringLocalized())
+	fmt.Fprintf(&sb, "Приоритет: %s %s\n", task.Priority.Emoji(), task.Priority.StringLocalized())
+	if task.Estimate != 0 {
+		fmt.Fprintf(&sb, "Оценка: %s\n", formatEstimate(task.Estimate))
+	}
+	now := time.Now()
+	if !task.Deadline.IsZero() {
+		fmt.Fprintf(&sb, "Срок: %s", task.Deadline.Format(format.DateLayout))
+		if task.Status.IsOpen() {
+			fmt.Fprintf(&sb, ", %s", format.Days(task.Deadline, now))
+		}
+		sb.WriteString("\n")
+	}
+	if task.Assignee != 0 {
+		name, err := b.userName(ctx, int(task.Assignee))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "Исполнитель: %s\n", name)
+	}
+	if len(task.CoAssignees) > 0 {
+		names := make([]string, len(task.CoAssignees))
+		for i, userID := range task.CoAssignees {
+			name, err := b.userName(ctx, int(userID))
+			if err != nil {
+				return "", err
+			}
+			names[i] = name
+		}
+		fmt.Fprintf(&sb, "Соисполнители: %s\n", strings.Join(names, ", "))
+	}
+	if task.Reviewer != 0 {
+		name, err := b.userName(ctx, int(task.Reviewer))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "Ревьюер: %s\n", name)
+	}
+	name, err := b.userName(ctx, int(task.CreatedBy))
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&sb, "Автор: %s\n", name)
+	if task.Epic {
+		progress, err := b.renderEpicProgress(ctx, task)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "Эпик: %s\n", progress)
+	}
+	if task.ParentID != 0 {
+		epic, err := b.taskStorage.FetchTaskByID(ctx, task.ParentID)
+		if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+			return "", fmt.Errorf("could not fetch epic: %w", err)
+		}
+		if epic != nil {
+			fmt.Fprintf(&sb, "Входит в эпик: #%d %s\n", epic.ID, epic.Title)
+		}
+	}
+	if len(task.Labels) > 0 {
+		fmt.Fprintf(&sb, "Метки: %s\n", formatLabels(task.Labels))
+	}
+	rec, err := b.taskStorage.FetchTaskRecurrence(ctx, task.ID)
+	if err != nil && !errors.Is(err, model.ErrRecurrenceNotFound) {
+		return "", fmt.Errorf("could not fetch recurrence: %w", err)
+	}
+	if rec != nil {
+		fmt.Fprintf(&sb, "Повтор: 🔁 %s\n", rec.Rule.StringLocalized())
+	}
+	blockers, err := b.taskStorage.FetchTaskBlockers(ctx, task.ID)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch blockers: %w", err)
+	}
+	if len(blockers) > 0 {
+		fmt.Fprintf(&sb, "Блокеры: %s\n", formatTaskRefs(blockers))
+	}
+	blocked, err := b.taskStorage.FetchBlockedTasks(ctx, task.ID)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch blocked tasks: %w", err)
+	}
+	if blocked = openTasks(blocked); len(blocked) > 0 {
+		fmt.Fprintf(&sb, "Блокирует: %s\n", formatTaskIDs(blocked))
+	}
+	items, err := b.taskStorage.FetchChecklist(ctx, task.ID)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch checklist: %w", err)
+	}
+	if done, total := model.ChecklistProgress(items); total > 0 {
+		fmt.Fprintf(&sb, "Чек-лист: %d/%d\n", done, total)
+	}
+	if task.Muted {
+		sb.WriteString("Напоминания: 🔕 отключены\n")
+	}
+	if !task.UpdatedAt.IsZero() {
+		fmt.Fprintf(&sb, "Обновлено: %s\n", format.Ago(task.UpdatedAt, now))
+	}
+	if task.Description != "" {
+		fmt.Fprintf(&sb, "\n%s\n", task.Description)
+	}
+	return sb.String(), nil
+}
+
+func (b *Bot) userName(ctx context.Context, userID int) (string, error) {
+	user, err := b.userStorage.FetchUserByID(ctx, userID)
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return "—", nil
+	} else if err != nil {
+		return "", fmt.Errorf("could not fetch user: %w", err)
+	}
+	// Name is erased when user has left all projects
+	if user.FullName == "" {
+		return "—", nil
+	}
+	return user.FullName, nil
+}
+
+// fetchAssignedTask returns task of chat's project and its assignee who pressed the button,
+// otherwise it answers callback with explanation.
+func (b *Bot) fetchAssignedTask(ctx context.Context, query *tgbotapi.CallbackQuery, taskID int) (*model.Task, *model.User, bool, error) {
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return nil, nil, false, b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch task: %w", err)
+	}
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, false, b.answerCallback(query.ID, "это может сделать только исполнитель задачи")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if prj.ID != task.ProjectID {
+		return nil, nil, false, b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if task.Assignee != int64(user.ID) {
+		return nil, nil, false, b.answerCallback(query.ID, "это может сделать только исполнитель задачи")
+	}
+	if !task.Status.IsOpen() {
+		return nil, nil, false, b.answerCallback(query.ID, "задача уже закрыта")
+	}
+	return task, user, true, nil
+}
+
+// fetchMemberTask returns open task of chat's project and project member who pressed the button,
+// otherwise it answers callback with explanation.
+func (b *Bot) fetchMemberTask(ctx context.Context, query *tgbotapi.CallbackQuery, taskID int) (*model.Task, *model.User, bool, error) {
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, false, b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return nil, nil, false, b.answerCallback(query.ID, "задача удалена")
+	} else if err != nil {
+		return nil, nil, false, fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task.ProjectID != prj.ID {
+		return nil, nil, false, b.answerCallback(query.ID, "задача из другого проекта")
+	}
+	if !task.Status.IsOpen() {
+		return nil, nil, false, b.answerCallback(query.ID, "задача уже закрыта")
+	}
+	return task, user, true, nil
+}
+
+// fetchPromptedTask returns task and its assignee if message is assignee's reply to bot's prompt matching re,
+// task is nil if reply is not assignee's. It reports false if message is not a reply to such prompt.
+func (b *Bot) fetchPromptedTask(ctx context.Context, message *tgbotapi.Message, re *regexp.Regexp) (*model.Task, *model.User, bool, error) {
+	isAssignee := func(task *model.Task, user *model.User) bool { return task.Assignee == int64(user.ID) }
+	return b.fetchPromptedTaskFor(ctx, message, re, isAssignee)
+}
+
+// fetchPromptedTaskFor is fetchPromptedTask accepting replies of project members allowed to answer the prompt.
+func (b *Bot) fetchPromptedTaskFor(
+	ctx context.Context,
+	message *tgbotapi.Message,
+	re *regexp.Regexp,
+	allowed func(*model.Task, *model.User) bool,
+) (*model.Task, *model.User, bool, error) {
+	prompt := message.ReplyToMessage
+	if prompt == nil || prompt.From == nil || prompt.From.ID != b.Self.ID {
+		return nil, nil, false, nil
+	}
+	m := re.FindStringSubmatch(prompt.Text)
+	if m == nil {
+		return nil, nil, false, nil
+	}
+	taskID, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, nil, true, fmt.Errorf("could not parse task id %q: %w", m[1], err)
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return nil, nil, true, b.reply(message, "задача удалена")
+	} else if err != nil {
+		return nil, nil, true, fmt.Errorf("could not fetch task: %w", err)
+	}
+	prj, user, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, true, nil
+	} else if err != nil {
+		return nil, nil, true, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	// Replies of other members are ignored
+	if !allowed(task, user) {
+		return nil, nil, true, nil
+	}
+	if prj.Frozen {
+		return nil, nil, true, b.reply(message, frozenProjectText)
+	}
+	return task, user, true, nil
+}
+
+// mention returns @username of user or their full name if username is not set.
+func mention(user *model.User) string {
+	if user.Username != "" {
+		return "@" + user.Username
+	}
+	return user.FullName
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramMessageLimit is maximum message length in UTF-16 code units.
+const telegramMessageLimit = 4096
+
+type telegramErrorKind int
+
+const (
+	telegramErrorOther telegramErrorKind = iota
+	// telegramErrorUnreachable means user blocked bot, deleted account or never started private chat.
+	telegramErrorUnreachable
+	telegramErrorTooLong
+	telegramErrorBadMarkup
+	telegramErrorNotModified
+	telegramErrorMessageNotFound
+)
+
+// classifyTelegramError maps Bot API error to kind which has known corrective action.
+func classifyTelegramError(err error) telegramErrorKind {
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) {
+		return telegramErrorOther
+	}
+
+	msg := strings.ToLower(apiErr.Message)
+	switch {
+	case apiErr.Code == 403:
+		return telegramErrorUnreachable
+	case strings.Contains(msg, "message is too long"):
+		return telegramErrorTooLong
+	case strings.Contains(msg, "can't parse entities"):
+		return telegramErrorBadMarkup
+	case strings.Contains(msg, "message is not modified"):
+		return telegramErrorNotModified
+	case strings.Contains(msg, "message to edit not found"):
+		return telegramErrorMessageNotFound
+	default:
+		return telegramErrorOther
+	}
+}
+
+// sendMessage sends message and corrects common failures: markup Telegram could not parse is dropped,
+// too long text is split into several messages, private messages to users who blocked bot are skipped.
+func (b *Bot) sendMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	// Private chat ID equals user ID, group chat IDs are negative.
+	isPrivate := msg.ChatID > 0
+	if isPrivate && b.isUnreachable(context.Background(), msg.ChatID) {
+		log.Printf("DEBUG skip message to unreachable user tg_id=%d", msg.ChatID)
+		return tgbotapi.Message{}, nil
+	}
+
+	sent, err := b.Send(msg)
+	switch classifyTelegramError(err) {
+	case telegramErrorBadMarkup:
+		log.Printf("WARN could not parse message markup, sending as plain text: %s", err)
+		msg.ParseMode = ""
+		msg.Entities = nil
+		return b.sendMessage(msg)
+	case telegramErrorTooLong:
+		return b.sendLongMessage(msg)
+	case telegramErrorUnreachable:
+		log.Printf("WARN chat id=%d is unreachable: %s", msg.ChatID, err)
+		if isPrivate {
+			b.setUnreachable(context.Background(), msg.ChatID, true)
+		}
+	}
+	return sent, err
+}
+
+// sendLongMessage sends text in parts, the first part keeps reply and the last one keeps keyboard.
+func (b *Bot) sendLongMessage(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	var (
+		sent  tgbotapi.Message
+		err   error
+		parts = splitMessage(msg.Text, telegramMessageLimit)
+	)
+	for i, text := range parts {
+		part := msg
+		part.Text = text
+		// Entities offsets are not valid for parts.
+		part.Entities = nil
+		if i > 0 {
+			part.ReplyToMessageID = 0
+		}
+		if i < len(parts)-1 {
+			part.ReplyMarkup = nil
+		}
+		if sent, err = b.sendMessage(part); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// splitMessage splits text into parts not longer than limit of UTF-16 code units, preferably by lines.
+func splitMessage(text string, limit int) []string {
+	var (
+		parts []string
+		part  strings.Builder
+		size  int
+	)
+	flush := func() {
+		if part.Len() > 0 {
+			parts = append(parts, part.String())
+			part.Reset()
+			size = 0
+		}
+	}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineSize := utf16Len(line)
+		if size+lineSize > limit {
+			flush()
+		}
+		for _, r := range line {
+			if size+utf16.RuneLen(r) > limit {
+				flush()
+			}
+			part.WriteRune(r)
+			size += utf16.RuneLen(r)
+		}
+	}
+	flush()
+	return parts
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Tracker counts anonymous usage events.
+type Tracker interface {
+	Track(event string)
+}
+
+func (b *Bot) track(event string) {
+	if b.cfg.Tracker != nil {
+		b.cfg.Tracker.Track(event)
+	}
+}
+
+// trackCommand counts command by name, commands missing in menus are counted together
+// so arbitrary text typed after slash is never sent.
+func (b *Bot) trackCommand(command string) {
+	known := func(c tgbotapi.BotCommand) bool { return c.Command == command }
+	if !slices.ContainsFunc(adminCommands, known) && !slices.ContainsFunc(privateCommands, known) {
+		command = "other"
+	}
+	b.track("command." + command)
+}
+
+// trackCallback counts button by its data without ids and dates, e.g. "cal_day" for "cal_day_2024-05-01"
+// or "edit_field_labels" for "edit_field_12_labels".
+func (b *Bot) trackCallback(data string) {
+	parts := strings.Split(data, "_")
+	parts = slices.DeleteFunc(parts, func(part string) bool { return strings.Trim(part, "0123456789-") == "" })
+	if len(parts) == 0 {
+		return
+	}
+	b.track("button." + strings.Join(parts, "_"))
+}
+
+func (b *Bot) trackTaskEvent(_ context.Context, event model.TaskEvent) {
+	b.track("task." + string(event.Type))
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackListTemplates      = "tpl_list"
+	callbackCreateFromTemplate = "tpl_create_"
+
+	// maxProjectTemplates keeps templates keyboard short.
+	maxProjectTemplates = 20
+	templateNameMaxLen  = 32
+)
+
+const templateUsage = "Сохранить задачу как шаблон: /template 12 название, удалить шаблон: /template - название. " +
+	"Создать задачу из шаблона: /create_task"
+
+// createTaskCommand creates task from text after command like mention of bot does,
+// without text it offers to create task from one of project's templates.
+func (b *Bot) createTaskCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	if text := strings.TrimSpace(message.CommandArguments()); text != "" {
+		return b.captureMentionTask(ctx, message, text)
+	}
+
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return b.reply(message, "сначала создайте проект командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	templates, err := b.taskStorage.FetchTaskTemplates(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch templates: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, "напишите задачу после команды, например: /create_task сделать отчёт к пятнице")
+	if len(templates) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 Создать из шаблона", callbackListTemplates),
+		))
+	}
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// templateCommand lists project's templates, managers save task N as template with "/template N name"
+// and remove template with "/template - name".
+func (b *Bot) templateCommand(ctx context.Context, update tgbotapi.Update) error {
+	message := update.Message
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		return b.listTemplates(ctx, message)
+	}
+	if len(args) < 2 {
+		return b.reply(message, templateUsage)
+	}
+
+	prj, user, err := b.fetchManagedProject(ctx, message)
+	if err != nil || prj == nil {
+		return err
+	}
+	templates, err := b.taskStorage.FetchTaskTemplates(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch templates: %w", err)
+	}
+	name := strings.Join(args[1:], " ")
+	var existing *model.TaskTemplate
+	for i := range templates {
+		if strings.EqualFold(templates[i].Name, name) {
+			existing = &templates[i]
+			break
+		}
+	}
+
+	if args[0] == "-" {
+		if existing == nil {
+			return b.reply(message, fmt.Sprintf("шаблона «%s» нет в проекте", name))
+		}
+		if err = b.taskStorage.RemoveTaskTemplate(ctx, existing.ID); err != nil {
+			return fmt.Errorf("could not remove template: %w", err)
+		}
+		log.Printf("DEBUG user id=%d removed template id=%d", user.ID, existing.ID)
+		return b.reply(message, fmt.Sprintf("📋 шаблон «%s» удалён", existing.Name))
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return b.reply(message, templateUsage)
+	}
+	switch {
+	case utf8.RuneCountInString(name) > templateNameMaxLen:
+		return b.reply(message, fmt.Sprintf("название шаблона должно быть не длиннее %d символов", templateNameMaxLen))
+	case existing == nil && len(templates) >= maxProjectTemplates:
+		return b.reply(message, fmt.Sprintf("в проекте может быть не больше %d шаблонов, удалите ненужные: /template - название", maxProjectTemplates))
+	}
+	task, err := b.taskStorage.FetchTaskByID(ctx, id)
+	if err != nil && !errors.Is(err, model.ErrTaskNotFound) {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if task == nil || task.ProjectID != prj.ID {
+		return b.reply(message, fmt.Sprintf("задачи #%d нет в проекте", id))
+	}
+
+	tpl := model.NewTaskTemplate(task, name, int64(user.ID))
+	if err = b.taskStorage.SaveTaskTemplate(ctx, tpl); err != nil {
+		return fmt.Errorf("could not save template: %w", err)
+	}
+	log.Printf("DEBUG user id=%d saved task id=%d as template id=%d", user.ID, task.ID, tpl.ID)
+
+	text := fmt.Sprintf("📋 задача #%d сохранена как шаблон «%s»", task.ID, tpl.Name)
+	if existing != nil {
+		text = fmt.Sprintf("📋 шаблон «%s» заменён задачей #%d", tpl.Name, task.ID)
+	}
+	return b.reply(message, text+"\n\nСоздать задачу из шаблона: /create_task")
+}
+
+func (b *Bot) listTemplates(ctx context.Context, message *tgbotapi.Message) error {
+	prj, _, err := b.fetchProjectMember(ctx, message.Chat.ID, message.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.reply(message, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	templates, err := b.taskStorage.FetchTaskTemplates(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return b.reply(message, "📋 в проекте нет шаблонов задач\n\n"+templateUsage)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📋 шаблоны задач:\n\n")
+	for _, tpl := range templates {
+		fmt.Fprintf(&sb, "• %s — %s\n", tpl.Name, tpl.Title)
+	}
+	sb.WriteString("\n" + templateUsage)
+	return b.reply(message, sb.String())
+}
+
+// listTemplatesCallback replaces button under /create_task reply with buttons of project's templates.
+func (b *Bot) listTemplatesCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	prj, _, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	templates, err := b.taskStorage.FetchTaskTemplates(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return b.answerCallback(query.ID, "в проекте нет шаблонов")
+	}
+
+	rows := make([][]tgbotapi.InlineKeyboardButton, len(templates))
+	for i, tpl := range templates {
+		rows[i] = tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📋 "+tpl.Name, fmt.Sprintf("%s%d", callbackCreateFromTemplate, tpl.ID)),
+		)
+	}
+	edit := tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, tgbotapi.NewInlineKeyboardMarkup(rows...))
+	if _, err = b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// createFromTemplateCallback creates task with content of the template, its assignee is kept if they are still a member.
+func (b *Bot) createFromTemplateCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	templateID, err := parseCallbackID(query.Data, callbackCreateFromTemplate)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project member: %w", err)
+	}
+	tpl, err := b.taskStorage.FetchTaskTemplate(ctx, templateID)
+	if err != nil && errors.Is(err, model.ErrTemplateNotFound) {
+		return b.answerCallback(query.ID, "шаблон удалён")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch template: %w", err)
+	}
+	if tpl.ProjectID != prj.ID {
+		return b.answerCallback(query.ID, "шаблон из другого проекта")
+	}
+
+	task := tpl.NewTask(int64(user.ID))
+	task.Status = model.TaskStatusTODO
+	task.Deadline = defaultDeadline(prj, time.Now())
+	if task.Assignee != 0 {
+		member, err := b.isProjectMember(ctx, prj.ID, int(task.Assignee))
+		if err != nil {
+			return err
+		}
+		if !member {
+			task.Assignee = 0
+		}
+	}
+	if err = b.createTask(ctx, task, user.ID); err != nil {
+		return err
+	}
+	log.Printf("DEBUG user id=%d created task id=%d from template id=%d", user.ID, task.ID, tpl.ID)
+
+	card, err := b.renderTaskCard(ctx, task)
+	if err != nil {
+		return err
+	}
+	msg := tgbotapi.NewMessage(query.Message.Chat.ID, fmt.Sprintf("✅ задача создана из шаблона «%s»\n\n%s", tpl.Name, card))
+	msg.ReplyMarkup = taskCardKeyboard(task)
+	if _, err = b.sendMessage(msg); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "задача создана")
+}
+
+// isProjectMember reports whether user still belongs to the project.
+func (b *Bot) isProjectMember(ctx context.Context, projectID, userID int) (bool, error) {
+	user, err := b.userStorage.FetchUserByID(ctx, userID)
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not fetch user: %w", err)
+	}
+	err = b.userStorage.FetchUserRoleInProject(ctx, projectID, user)
+	if err != nil && errors.Is(err, model.ErrUserNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("could not fetch user role for project: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackKeepTitle = "keep_title_"
+	callbackFullTitle = "full_title_"
+
+	// titleMaxLen is length of the longest title kept as is, longer ones are shortened.
+	titleMaxLen = 80
+)
+
+const titleSystemPrompt = "Ты помогаешь вести список задач. Придумай короткое название задачи по её тексту, " +
+	"не длиннее 60 символов, на языке текста. Ответь только названием, без кавычек и точки в конце."
+
+// Completer generates text with language model.
+type Completer interface {
+	Complete(ctx context.Context, system, prompt string) (string, error)
+}
+
+// titleSuggestion keeps title written by user until they accept shortened one.
+type titleSuggestion struct {
+	taskID     int
+	original   string
+	authorTgID int64
+}
+
+// suggestTitle returns concise title for task text, language model is used if configured.
+func (b *Bot) suggestTitle(ctx context.Context, text string) string {
+	if b.cfg.Completer != nil {
+		title, err := b.cfg.Completer.Complete(ctx, titleSystemPrompt, text)
+		title = strings.Trim(strings.TrimSpace(title), `"«»`)
+		if err != nil {
+			log.Printf("WARN could not suggest title with language model: %s", err)
+		} else if title != "" && utf8.RuneCountInString(title) <= titleMaxLen && !strings.Contains(title, "\n") {
+			return title
+		}
+	}
+	return shortenTitle(text, titleMaxLen)
+}
+
+// shortenTitle returns first sentence of text cut by word boundary to limit runes.
+func shortenTitle(text string, limit int) string {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	for _, sep := range []string{". ", "! ", "? ", "; "} {
+		if i := strings.Index(text, sep); i > 0 {
+			text = text[:i+1]
+		}
+	}
+	text = strings.TrimSuffix(strings.TrimSpace(text), ".")
+
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-—") + "…"
+}
+
+func titleSuggestionKeyboard(task *model.Task, suggestionID int, hasOriginal bool) tgbotapi.InlineKeyboardMarkup {
+	row := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("👍 Оставить", fmt.Sprintf("%s%d", callbackKeepTitle, suggestionID)),
+	)
+	if hasOriginal {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("📝 Исходное название", fmt.Sprintf("%s%d", callbackFullTitle, suggestionID)))
+	}
+	keyboard := undoTaskKeyboard(task)
+	keyboard.InlineKeyboard = append([][]tgbotapi.InlineKeyboardButton{row}, keyboard.InlineKeyboard...)
+	return keyboard
+}
+
+// keepTitleCallback accepts shortened title leaving only undo button.
+func (b *Bot) keepTitleCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	suggestion, ok, err := b.takeTitleSuggestion(query, callbackKeepTitle)
+	if err != nil || !ok {
+		return err
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, suggestion.taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача уже удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	if err = b.editCapturedTask(query, task); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "")
+}
+
+// fullTitleCallback restores title written by user.
+func (b *Bot) fullTitleCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	suggestion, ok, err := b.takeTitleSuggestion(query, callbackFullTitle)
+	if err != nil || !ok {
+		return err
+	}
+
+	task, err := b.taskStorage.FetchTaskByID(ctx, suggestion.taskID)
+	if err != nil && errors.Is(err, model.ErrTaskNotFound) {
+		return b.answerCallback(query.ID, "задача уже удалена")
+	} else if err != nil {
+		return fmt.Errorf("could not fetch task: %w", err)
+	}
+	task.Title = suggestion.original
+	if err = b.taskStorage.UpdateTask(ctx, task); err != nil {
+		return fmt.Errorf("could not update task: %w", err)
+	}
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventUpdated, Task: *task, ActorID: int(task.UpdatedBy)})
+
+	if err = b.editCapturedTask(query, task); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, "название восстановлено")
+}
+
+// takeTitleSuggestion removes suggestion from pending ones if callback is pressed by task author,
+// otherwise it answers callback with explanation.
+func (b *Bot) takeTitleSuggestion(query *tgbotapi.CallbackQuery, prefix string) (titleSuggestion, bool, error) {
+	suggestionID, err := parseCallbackID(query.Data, prefix)
+	if err != nil {
+		return titleSuggestion{}, false, fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+
+	suggestion, ok := b.titleSuggestions.Get(suggestionID)
+	if !ok {
+		return titleSuggestion{}, false, b.answerCallback(query.ID, "предложение устарело")
+	}
+	if suggestion.authorTgID != query.From.ID {
+		return titleSuggestion{}, false, b.answerCallback(query.ID, "выбрать название может только автор задачи")
+	}
+	b.titleSuggestions.Delete(suggestionID)
+	return suggestion, true, nil
+}
+
+func (b *Bot) editCapturedTask(query *tgbotapi.CallbackQuery, task *model.Task) error {
+	if query.Message == nil {
+		return nil
+	}
+	edit := tgbotapi.NewEditMessageTextAndMarkup(
+		query.Message.Chat.ID,
+		query.Message.MessageID,
+		taskCapturedText(task),
+		undoTaskKeyboard(task),
+	)
+	if _, err := b.Send(edit); err != nil {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestShortenTitle(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"Купить молоко", 20, "Купить молоко"},
+		{"  Купить молоко.  ", 20, "Купить молоко"},
+		{"Купить молоко. И хлеб тоже", 80, "Купить молоко"},
+		{"Срочно! Позвонить клиенту", 80, "Срочно!"},
+		{"Почему падает сборка? Не знаю", 80, "Почему падает сборка?"},
+		{"Обновить зависимости; потом релиз", 80, "Обновить зависимости;"},
+		{"Первая строка\nвторая строка", 80, "Первая строка"},
+		// Dot without space after it does not end sentence
+		{"Выпустить v1.2 сегодня", 80, "Выпустить v1.2 сегодня"},
+		{"Ровно двадцать симв.", 20, "Ровно двадцать симв"},
+		{"Подготовить отчёт по продажам за квартал", 20, "Подготовить отчёт…"},
+		{"Купить молоко, хлеб и сыр сегодня", 15, "Купить молоко…"},
+		// Word is cut only when space is too far from the end
+		{"Суперкалифраджилистик", 20, "Суперкалифраджилист…"},
+		{"Я суперкалифраджилистик", 20, "Я суперкалифраджили…"},
+		{"😀😀😀😀😀😀😀😀😀😀😀😀", 10, "😀😀😀😀😀😀😀😀😀…"},
+		{"", 20, ""},
+	}
+	for _, tt := range tests {
+		got := shortenTitle(tt.text, tt.limit)
+		if got != tt.want {
+			t.Errorf("shortenTitle(%q, %d): got %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n > tt.limit {
+			t.Errorf("shortenTitle(%q, %d): got %d runes", tt.text, tt.limit, n)
+		}
+	}
+}
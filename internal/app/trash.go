@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	callbackRestoreTask = "trash_restore_"
+	callbackPurgeTask   = "trash_purge_"
+
+	// trashTTL is how long removed tasks are kept before maintenance purges them.
+	trashTTL = 30 * 24 * time.Hour
+	// shownTrash keeps trash keyboard short, the latest removed tasks are shown.
+	shownTrash = 20
+)
+
+// trashCommand shows removed tasks of project with buttons restoring and purging them, allowed for managers.
+func (b *Bot) trashCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, _, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	tasks, err := b.taskStorage.FetchDeletedTasks(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch deleted tasks: %w", err)
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, renderTrash(tasks))
+	if len(tasks) > 0 {
+		msg.ReplyMarkup = trashKeyboard(tasks)
+	}
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+func renderTrash(tasks []model.Task) string {
+	if len(tasks) == 0 {
+		return "🗑 Корзина пуста"
+	}
+	var sb strings.Builder
+	sb.WriteString("🗑 Корзина\n\n")
+	for i, task := range tasks {
+		if i == shownTrash {
+			fmt.Fprintf(&sb, "…и ещё %d\n", len(tasks)-shownTrash)
+			break
+		}
+		fmt.Fprintf(&sb, "• #%d %s — удалена %s\n", task.ID, task.Title, task.DeletedAt.Format(format.DateLayout))
+	}
+	fmt.Fprintf(&sb, "\n♻️ — восстановить, 🔥 — удалить навсегда. Задачи удаляются навсегда сами через %d дней в корзине.",
+		int(trashTTL.Hours()/24))
+	return sb.String()
+}
+
+func trashKeyboard(tasks []model.Task) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, task := range tasks[:min(len(tasks), shownTrash)] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("♻️ #%d", task.ID), fmt.Sprintf("%s%d", callbackRestoreTask, task.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔥 #%d", task.ID), fmt.Sprintf("%s%d", callbackPurgeTask, task.ID)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// restoreTaskCallback returns task from trash with everything attached to it.
+func (b *Bot) restoreTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackRestoreTask)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	prj, user, task, ok, err := b.fetchTrashedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = b.taskStorage.RestoreTask(ctx, task.ID); err != nil {
+		return fmt.Errorf("could not restore task: %w", err)
+	}
+	log.Printf("DEBUG user id=%d restored task id=%d", user.ID, task.ID)
+	// Restored task appears again for subscribers as created one
+	task.DeletedAt = time.Time{}
+	b.events.Publish(ctx, model.TaskEvent{Type: model.TaskEventCreated, Task: *task, ActorID: user.ID})
+
+	if err = b.refreshTrash(ctx, query, prj); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, fmt.Sprintf("задача #%d восстановлена", task.ID))
+}
+
+// purgeTaskCallback removes task from trash for good.
+func (b *Bot) purgeTaskCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackPurgeTask)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	prj, user, task, ok, err := b.fetchTrashedTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	if err = b.taskStorage.PurgeTask(ctx, task.ID); err != nil {
+		return fmt.Errorf("could not purge task: %w", err)
+	}
+	log.Printf("INFO user id=%d purged task id=%d", user.ID, task.ID)
+
+	if err = b.refreshTrash(ctx, query, prj); err != nil {
+		return err
+	}
+	return b.answerCallback(query.ID, fmt.Sprintf("задача #%d удалена навсегда", task.ID))
+}
+
+func (b *Bot) refreshTrash(ctx context.Context, query *tgbotapi.CallbackQuery, prj *model.Project) error {
+	tasks, err := b.taskStorage.FetchDeletedTasks(ctx, prj.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch deleted tasks: %w", err)
+	}
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, renderTrash(tasks))
+	if len(tasks) > 0 {
+		keyboard := trashKeyboard(tasks)
+		edit.ReplyMarkup = &keyboard
+	}
+	if _, err = b.Send(edit); err != nil && classifyTelegramError(err) != telegramErrorNotModified {
+		return fmt.Errorf("could not edit message: %w", err)
+	}
+	return nil
+}
+
+// fetchTrashedTask returns task from trash of chat's project if callback is pressed by manager,
+// otherwise it answers callback with explanation.
+func (b *Bot) fetchTrashedTask(ctx context.Context, query *tgbotapi.CallbackQuery, taskID int) (*model.Project, *model.User, *model.Task, bool, error) {
+	if query.Message == nil {
+		return nil, nil, nil, false, b.answerCallback(query.ID, "")
+	}
+	prj, user, err := b.fetchProjectMember(ctx, query.Message.Chat.ID, query.From.ID)
+	if err != nil && (errors.Is(err, model.ErrProjectNotFound) || errors.Is(err, model.ErrUserNotFound)) {
+		return nil, nil, nil, false, b.answerCallback(query.ID, "сначала присоединитесь к проекту командой /start")
+	} else if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("could not fetch project member: %w", err)
+	}
+	if user.Role != model.UserProjectRoleManager {
+		return nil, nil, nil, false, b.answerCallback(query.ID, "корзиной управляет менеджер проекта")
+	}
+	tasks, err := b.taskStorage.FetchDeletedTasks(ctx, prj.ID)
+	if err != nil {
+		return nil, nil, nil, false, fmt.Errorf("could not fetch deleted tasks: %w", err)
+	}
+	for i := range tasks {
+		if tasks[i].ID == taskID {
+			return prj, user, &tasks[i], true, nil
+		}
+	}
+	return nil, nil, nil, false, b.answerCallback(query.ID, "задачи уже нет в корзине")
+}
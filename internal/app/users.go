@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMembersRefresh limits getChatMember requests made to resolve single unknown username.
+const maxMembersRefresh = 50
+
+// NameFormat is how user's name is built from Telegram profile.
+type NameFormat string
+
+const (
+	NameFormatLastFirst NameFormat = "last-first"
+	NameFormatFirstLast NameFormat = "first-last"
+	NameFormatUsername  NameFormat = "username"
+)
+
+// NameFormats lists supported formats, the first one is default.
+var NameFormats = []NameFormat{NameFormatLastFirst, NameFormatFirstLast, NameFormatUsername}
+
+// displayName returns name of Telegram user in given format, it falls back to another part of profile
+// if format needs missing one, and returns empty string only if profile has no name and username.
+func displayName(from *tgbotapi.User, format NameFormat) string {
+	if format == NameFormatUsername && from.UserName != "" {
+		return from.UserName
+	}
+	name := strings.TrimSpace(from.LastName + " " + from.FirstName)
+	if format == NameFormatFirstLast || format == NameFormatUsername {
+		name = strings.TrimSpace(from.FirstName + " " + from.LastName)
+	}
+	if name == "" {
+		return from.UserName
+	}
+	return name
+}
+
+// newTgUser returns not saved user with profile data from Telegram.
+func (b *Bot) newTgUser(from *tgbotapi.User) *model.User {
+	user := model.NewUser(from.ID)
+	b.updateProfile(user, from)
+	return user
+}
+
+// updateProfile copies username and name from Telegram profile, it reports whether user changed.
+func (b *Bot) updateProfile(user *model.User, from *tgbotapi.User) bool {
+	fullName := displayName(from, b.cfg.NameFormat)
+	if fullName == "" {
+		fullName = user.FullName
+	}
+	if user.Username == from.UserName && user.FullName == fullName {
+		return false
+	}
+	user.Username = from.UserName
+	user.FullName = fullName
+	return true
+}
+
+// refreshProfile saves changed username and name of known user who sent update.
+func (b *Bot) refreshProfile(ctx context.Context, update tgbotapi.Update) {
+	from := update.SentFrom()
+	if from == nil || from.IsBot {
+		return
+	}
+	user, err := b.userStorage.FetchUserByTgID(ctx, from.ID)
+	if err != nil {
+		if !errors.Is(err, model.ErrUserNotFound) {
+			log.Printf("ERROR could not fetch user: %s", err)
+		}
+		return
+	}
+	b.saveProfile(ctx, user, from)
+}
+
+func (b *Bot) saveProfile(ctx context.Context, user *model.User, from *tgbotapi.User) {
+	if !b.updateProfile(user, from) {
+		return
+	}
+	if err := b.userStorage.UpdateUser(ctx, user); err != nil {
+		log.Printf("ERROR could not update profile of user id=%d: %s", user.ID, err)
+		return
+	}
+	log.Printf("DEBUG user id=%d profile refreshed", user.ID)
+}
+
+// fetchProjectUserByUsername finds project member by current or previous username. Members who changed
+// username and have not talked to bot since are found by refreshing profiles of project chat members.
+func (b *Bot) fetchProjectUserByUsername(ctx context.Context, projectID int, username string) (*model.User, error) {
+	user, err := b.userStorage.FetchProjectUserByUsername(ctx, projectID, username)
+	if err == nil || !errors.Is(err, model.ErrUserNotFound) {
+		return user, err
+	}
+	user, err = b.userStorage.FetchProjectUserByPreviousUsername(ctx, projectID, username)
+	if err == nil || !errors.Is(err, model.ErrUserNotFound) {
+		return user, err
+	}
+
+	prj, err := b.projectStorage.FetchProjectByID(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch project: %w", err)
+	}
+	members, err := b.userStorage.FetchProjectUsers(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch project users: %w", err)
+	}
+	if len(members) > maxMembersRefresh {
+		members = members[:maxMembersRefresh]
+	}
+	for _, member := range members {
+		chatMember, err := b.GetChatMember(tgbotapi.GetChatMemberConfig{
+			ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: prj.TgChatID, UserID: member.TgUserID},
+		})
+		if err != nil {
+			log.Printf("WARN could not fetch chat member tg_id=%d: %s", member.TgUserID, err)
+			continue
+		}
+		if chatMember.User == nil || chatMember.HasLeft() || chatMember.WasKicked() {
+			continue
+		}
+		b.saveProfile(ctx, &member, chatMember.User)
+		if strings.EqualFold(member.Username, username) {
+			return &member, nil
+		}
+	}
+	return nil, model.ErrUserNotFound
+}
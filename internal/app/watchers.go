@@ -0,0 +1,133 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const callbackToggleWatch = "toggle_watch_"
+
+// watchButton subscribes member who pressed it to changes of the task or unsubscribes them.
+func watchButton(task *model.Task) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🔔 Следить", fmt.Sprintf("%s%d", callbackToggleWatch, task.ID))
+}
+
+// toggleWatchCallback adds project member to watchers of the task or removes them if they already watch it.
+func (b *Bot) toggleWatchCallback(ctx context.Context, update tgbotapi.Update) error {
+	query := update.CallbackQuery
+	taskID, err := parseCallbackID(query.Data, callbackToggleWatch)
+	if err != nil {
+		return fmt.Errorf("could not parse callback data %q: %w", query.Data, err)
+	}
+	if query.Message == nil {
+		return b.answerCallback(query.ID, "")
+	}
+	task, user, ok, err := b.fetchMemberTask(ctx, query, taskID)
+	if err != nil || !ok {
+		return err
+	}
+
+	watchers, err := b.taskStorage.FetchTaskWatchers(ctx, task.ID)
+	if err != nil {
+		return fmt.Errorf("could not fetch watchers: %w", err)
+	}
+	watching := !slices.Contains(watchers, int64(user.ID))
+	if watching && user.Unreachable {
+		return b.answerCallback(query.ID, "уведомления приходят в личные сообщения: сначала напишите боту /start в личном чате")
+	}
+	if err = b.taskStorage.SetTaskWatcher(ctx, task.ID, int64(user.ID), watching); err != nil {
+		return fmt.Errorf("could not set watcher: %w", err)
+	}
+	log.Printf("DEBUG user id=%d set watching of task id=%d to %t", user.ID, task.ID, watching)
+
+	if watching {
+		return b.answerCallback(query.ID, "🔔 вы следите за задачей: об изменении статуса, срока и исполнителя бот напишет в личные сообщения")
+	}
+	return b.answerCallback(query.ID, "🔕 вы больше не следите за задачей")
+}
+
+// updateWatchedTask saves task changed from prev by user with actorID, see updateWatchedTasks.
+func (b *Bot) updateWatchedTask(ctx context.Context, prev, task *model.Task, actorID int, notifications ...model.Notification) error {
+	return b.updateWatchedTasks(ctx, []*model.Task{prev}, []*model.Task{task}, actorID, notifications...)
+}
+
+// updateWatchedTasks saves tasks changed from prevs by user with actorID along with notifications for watchers
+// about changes of status, deadline and assignee, so they are committed together with the change.
+// Watcher who made the change is not notified.
+func (b *Bot) updateWatchedTasks(ctx context.Context, prevs, tasks []*model.Task, actorID int, notifications ...model.Notification) error {
+	for i, task := range tasks {
+		watched, err := b.watcherNotifications(ctx, prevs[i], task, actorID)
+		if err != nil {
+			// Change itself matters more than notifications about it
+			log.Printf("WARN could not notify watchers of task id=%d: %s", task.ID, err)
+		}
+		notifications = append(notifications, watched...)
+	}
+	return b.updateTasksNotifying(ctx, tasks, notifications...)
+}
+
+func (b *Bot) watcherNotifications(ctx context.Context, prev, task *model.Task, actorID int) ([]model.Notification, error) {
+	changes, err := b.watchedChanges(ctx, prev, task)
+	if err != nil {
+		return nil, fmt.Errorf("could not describe changes: %w", err)
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	watchers, err := b.taskStorage.FetchTaskWatchers(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch watchers: %w", err)
+	}
+	watchers = slices.DeleteFunc(watchers, func(userID int64) bool { return userID == int64(actorID) })
+	if len(watchers) == 0 {
+		return nil, nil
+	}
+
+	actor, err := b.userName(ctx, actorID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch author of change: %w", err)
+	}
+	text := fmt.Sprintf("🔔 задача #%d %s (%s):\n%s", task.ID, task.Title, actor, strings.Join(changes, "\n"))
+	notifications := make([]model.Notification, len(watchers))
+	for i, userID := range watchers {
+		notifications[i] = model.NewNotification(userID, text)
+	}
+	return notifications, nil
+}
+
+// watchedChanges describes changes of the task watchers are notified about.
+func (b *Bot) watchedChanges(ctx context.Context, prev, task *model.Task) ([]string, error) {
+	var changes []string
+	if prev.Status != task.Status {
+		changes = append(changes, fmt.Sprintf("• статус: %s %s → %s %s",
+			prev.Status.Emoji(), prev.Status.StringLocalized(), task.Status.Emoji(), task.Status.StringLocalized()))
+	}
+	if !prev.Deadline.Equal(task.Deadline) {
+		changes = append(changes, fmt.Sprintf("• срок: %s → %s", deadlineText(prev.Deadline), deadlineText(task.Deadline)))
+	}
+	if prev.Assignee != task.Assignee {
+		from, err := b.assigneeName(ctx, prev.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		to, err := b.assigneeName(ctx, task.Assignee)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, fmt.Sprintf("• исполнитель: %s → %s", from, to))
+	}
+	return changes, nil
+}
+
+func (b *Bot) assigneeName(ctx context.Context, userID int64) (string, error) {
+	if userID == 0 {
+		return "не назначен", nil
+	}
+	return b.userName(ctx, int(userID))
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxWelcomeLen leaves room for greeting and onboarding hints within one Telegram message.
+const maxWelcomeLen = 2000
+
+const welcomeUsage = "Задать приветствие: /welcome текст правил, отключить: /welcome off\n" +
+	"Отправлять в личные сообщения: /welcome dm, в чат проекта: /welcome chat"
+
+// welcomeCommand shows or changes message greeting new members of project chat, allowed for managers.
+func (b *Bot) welcomeCommand(ctx context.Context, update tgbotapi.Update) error {
+	prj, user, err := b.fetchManagedProject(ctx, update.Message)
+	if err != nil || prj == nil {
+		return err
+	}
+
+	args := strings.TrimSpace(update.Message.CommandArguments())
+	var text, change string
+	switch strings.ToLower(args) {
+	case "":
+		if prj.WelcomeText == "" {
+			return b.reply(update.Message, "👋 приветствие новых участников отключено\n\n"+welcomeUsage)
+		}
+		return b.reply(update.Message, fmt.Sprintf("👋 приветствие новых участников, отправляется %s:\n\n%s\n\n%s",
+			welcomeDelivery(prj), prj.WelcomeText, welcomeUsage))
+	case "off":
+		if prj.WelcomeText == "" {
+			return b.reply(update.Message, "приветствие уже отключено")
+		}
+		prj.WelcomeText = ""
+		text, change = "👋 приветствие новых участников отключено", "приветствие отключено"
+	case "dm", "chat":
+		prj.WelcomePrivately = strings.EqualFold(args, "dm")
+		text = "👋 приветствие будет отправляться " + welcomeDelivery(prj)
+		change = "приветствие отправляется " + welcomeDelivery(prj)
+		if prj.WelcomeText == "" {
+			text += "\n\nТекст приветствия ещё не задан: /welcome текст правил"
+		}
+	default:
+		if utf8.RuneCountInString(args) > maxWelcomeLen {
+			return b.reply(update.Message, fmt.Sprintf("приветствие должно быть не длиннее %d символов", maxWelcomeLen))
+		}
+		prj.WelcomeText = args
+		text = "👋 приветствие сохранено, новые участники получат его " + welcomeDelivery(prj)
+		change = "приветствие новых участников изменено"
+	}
+
+	if err = b.projectStorage.UpdateProject(ctx, prj); err != nil {
+		return fmt.Errorf("could not update project: %w", err)
+	}
+	log.Printf("DEBUG project id=%d welcome changed, privately=%t", prj.ID, prj.WelcomePrivately)
+
+	if err = b.reply(update.Message, text); err != nil {
+		return err
+	}
+	b.publishProjectChange(ctx, prj, user, change)
+	return nil
+}
+
+func welcomeDelivery(prj *model.Project) string {
+	if prj.WelcomePrivately {
+		return "в личные сообщения"
+	}
+	return "в чате проекта"
+}
+
+// welcomeNewMembers greets people who joined project chat, bots are skipped. Private welcome falls back
+// to project chat for members who never started private chat with bot.
+func (b *Bot) welcomeNewMembers(ctx context.Context, message *tgbotapi.Message) error {
+	var members []tgbotapi.User
+	for _, member := range message.NewChatMembers {
+		if !member.IsBot {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	prj, err := b.projectStorage.FetchProjectByChatID(ctx, message.Chat.ID)
+	if err != nil && errors.Is(err, model.ErrProjectNotFound) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not fetch project: %w", err)
+	}
+	if prj.WelcomeText == "" || prj.Archived {
+		return nil
+	}
+
+	if !prj.WelcomePrivately {
+		names := make([]string, len(members))
+		for i := range members {
+			names[i] = displayName(&members[i], NameFormatFirstLast)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.renderWelcome(prj, strings.Join(names, ", ")))
+		msg.ReplyToMessageID = message.MessageID
+		_, err = b.sendMessage(msg)
+		return err
+	}
+
+	var unreachable []string
+	for i := range members {
+		name := displayName(&members[i], NameFormatFirstLast)
+		sent, err := b.sendMessage(tgbotapi.NewMessage(members[i].ID, b.renderWelcome(prj, name)))
+		// Skipped message to known unreachable user comes back empty without error
+		if classifyTelegramError(err) == telegramErrorUnreachable || (err == nil && sent.MessageID == 0) {
+			unreachable = append(unreachable, name)
+		} else if err != nil {
+			return fmt.Errorf("could not send welcome: %w", err)
+		}
+	}
+	log.Printf("DEBUG welcomed %d new members of project id=%d", len(members), prj.ID)
+	if len(unreachable) == 0 {
+		return nil
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.renderWelcome(prj, strings.Join(unreachable, ", ")))
+	msg.ReplyToMessageID = message.MessageID
+	_, err = b.sendMessage(msg)
+	return err
+}
+
+// renderWelcome returns project's welcome followed by hints how to start working with tasks.
+func (b *Bot) renderWelcome(prj *model.Project, names string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "👋 %s, добро пожаловать в проект «%s»!\n\n", names, prj.Title)
+	sb.WriteString(prj.WelcomeText + "\n\n")
+	sb.WriteString("Как работать с задачами:\n")
+	sb.WriteString("• присоединиться к проекту — /start в чате проекта\n")
+	fmt.Fprintf(&sb, "• создать задачу — @%s текст задачи или /create_task\n", b.Self.UserName)
+	sb.WriteString("• ресурсы проекта — /links\n")
+	sb.WriteString("• все команды — /help")
+	return sb.String()
+}
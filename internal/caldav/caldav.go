@@ -0,0 +1,256 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// PathPrefix is the root of all CalDAV collections, each user has own collection at PathPrefix/{token}/.
+const PathPrefix = "/caldav/"
+
+// Handler serves tasks assigned to a user as read-only CalDAV collection of VTODO items.
+type Handler struct {
+	mux         *http.ServeMux
+	userStorage model.UserRepository
+	taskStorage model.TaskRepository
+}
+
+func NewHandler(userStorage model.UserRepository, taskStorage model.TaskRepository) *Handler {
+	h := &Handler{
+		mux:         http.NewServeMux(),
+		userStorage: userStorage,
+		taskStorage: taskStorage,
+	}
+	h.mux.HandleFunc("OPTIONS "+PathPrefix+"{token}/", h.options)
+	h.mux.HandleFunc("PROPFIND "+PathPrefix+"{token}/{$}", h.propfindCollection)
+	h.mux.HandleFunc("REPORT "+PathPrefix+"{token}/{$}", h.report)
+	h.mux.HandleFunc("PROPFIND "+PathPrefix+"{token}/{file}", h.propfindItem)
+	h.mux.HandleFunc("GET "+PathPrefix+"{token}/{file}", h.get)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("DAV", "1, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) propfindCollection(w http.ResponseWriter, r *http.Request) {
+	user, tasks, ok := h.fetchTasks(w, r)
+	if !ok {
+		return
+	}
+
+	collection := response{
+		Href: collectionHref(user),
+		Propstat: propstat{
+			Prop: prop{
+				DisplayName: "Telegram Tasks",
+				ResourceType: &resourceType{
+					Collection: &struct{}{},
+					Calendar:   &struct{}{},
+				},
+				SupportedComponents: &supportedComponents{Comp: component{Name: "VTODO"}},
+				CTag:                collectionCTag(tasks),
+			},
+			Status: statusOK,
+		},
+	}
+	resp := multistatus{Responses: []response{collection}}
+	if r.Header.Get("Depth") != "0" {
+		for _, task := range tasks {
+			resp.Responses = append(resp.Responses, itemResponse(user, task, ""))
+		}
+	}
+	writeMultistatus(w, resp)
+}
+
+func (h *Handler) propfindItem(w http.ResponseWriter, r *http.Request) {
+	user, task, ok := h.fetchTask(w, r)
+	if !ok {
+		return
+	}
+	writeMultistatus(w, multistatus{Responses: []response{itemResponse(user, *task, "")}})
+}
+
+// report answers calendar-query and calendar-multiget with every task in collection,
+// clients filter the result on their side.
+func (h *Handler) report(w http.ResponseWriter, r *http.Request) {
+	user, tasks, ok := h.fetchTasks(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	var resp multistatus
+	for _, task := range tasks {
+		resp.Responses = append(resp.Responses, itemResponse(user, task, renderCalendar(task, now)))
+	}
+	writeMultistatus(w, resp)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	_, task, ok := h.fetchTask(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", taskETag(*task))
+	fmt.Fprint(w, renderCalendar(*task, time.Now()))
+}
+
+func (h *Handler) fetchUser(w http.ResponseWriter, r *http.Request) (*model.User, bool) {
+	user, err := h.userStorage.FetchUserByCalDAVToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			http.NotFound(w, r)
+			return nil, false
+		}
+		log.Printf("ERROR caldav: could not fetch user: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *Handler) fetchTasks(w http.ResponseWriter, r *http.Request) (*model.User, []model.Task, bool) {
+	user, ok := h.fetchUser(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	tasks, err := h.taskStorage.FilterTasks(r.Context(), model.TaskFilter{Assignee: int64(user.ID)})
+	if err != nil {
+		log.Printf("ERROR caldav: could not fetch tasks: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return user, tasks, true
+}
+
+func (h *Handler) fetchTask(w http.ResponseWriter, r *http.Request) (*model.User, *model.Task, bool) {
+	user, ok := h.fetchUser(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	id, err := strconv.Atoi(strings.TrimSuffix(r.PathValue("file"), ".ics"))
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, nil, false
+	}
+	task, err := h.taskStorage.FetchTaskByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, model.ErrTaskNotFound) {
+			http.NotFound(w, r)
+			return nil, nil, false
+		}
+		log.Printf("ERROR caldav: could not fetch task: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	// Do not reveal tasks of other users.
+	if !task.IsAssignee(int64(user.ID)) {
+		http.NotFound(w, r)
+		return nil, nil, false
+	}
+	return user, task, true
+}
+
+func collectionHref(user *model.User) string {
+	return PathPrefix + user.CalDAVToken + "/"
+}
+
+func itemHref(user *model.User, task model.Task) string {
+	return fmt.Sprintf("%s%d.ics", collectionHref(user), task.ID)
+}
+
+func itemResponse(user *model.User, task model.Task, calendarData string) response {
+	return response{
+		Href: itemHref(user, task),
+		Propstat: propstat{
+			Prop: prop{
+				ETag:         taskETag(task),
+				ContentType:  "text/calendar; charset=utf-8; component=VTODO",
+				CalendarData: calendarData,
+			},
+			Status: statusOK,
+		},
+	}
+}
+
+func collectionCTag(tasks []model.Task) string {
+	h := fnv.New64a()
+	for _, task := range tasks {
+		fmt.Fprint(h, taskETag(task))
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+func writeMultistatus(w http.ResponseWriter, resp multistatus) {
+	resp.XmlnsD = "DAV:"
+	resp.XmlnsC = "urn:ietf:params:xml:ns:caldav"
+	resp.XmlnsCS = "http://calendarserver.org/ns/"
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	fmt.Fprint(w, xml.Header)
+	if err := xml.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("ERROR caldav: could not encode response: %s", err)
+	}
+}
+
+const statusOK = "HTTP/1.1 200 OK"
+
+type multistatus struct {
+	XMLName   xml.Name   `xml:"D:multistatus"`
+	XmlnsD    string     `xml:"xmlns:D,attr"`
+	XmlnsC    string     `xml:"xmlns:C,attr"`
+	XmlnsCS   string     `xml:"xmlns:CS,attr"`
+	Responses []response `xml:"D:response"`
+}
+
+type response struct {
+	Href     string   `xml:"D:href"`
+	Propstat propstat `xml:"D:propstat"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"D:prop"`
+	Status string `xml:"D:status"`
+}
+
+type prop struct {
+	DisplayName         string               `xml:"D:displayname,omitempty"`
+	ResourceType        *resourceType        `xml:"D:resourcetype,omitempty"`
+	SupportedComponents *supportedComponents `xml:"C:supported-calendar-component-set,omitempty"`
+	CTag                string               `xml:"CS:getctag,omitempty"`
+	ETag                string               `xml:"D:getetag,omitempty"`
+	ContentType         string               `xml:"D:getcontenttype,omitempty"`
+	CalendarData        string               `xml:"C:calendar-data,omitempty"`
+}
+
+type resourceType struct {
+	Collection *struct{} `xml:"D:collection,omitempty"`
+	Calendar   *struct{} `xml:"C:calendar,omitempty"`
+}
+
+type supportedComponents struct {
+	Comp component `xml:"C:comp"`
+}
+
+type component struct {
+	Name string `xml:"name,attr"`
+}
@@ -0,0 +1,108 @@
+package caldav
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const (
+	prodID        = "-//agalitsyn//telegram-tasks-bot//RU"
+	icalTimestamp = "20060102T150405Z"
+	// RFC 5545 recommends folding lines longer than 75 octets.
+	icalLineLimit = 75
+)
+
+func renderCalendar(task model.Task, now time.Time) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+prodID)
+	writeLine(&b, "BEGIN:VTODO")
+	writeLine(&b, "UID:"+taskUID(task))
+	writeLine(&b, "DTSTAMP:"+now.UTC().Format(icalTimestamp))
+	writeLine(&b, "SUMMARY:"+escapeText(task.Title))
+	if task.Description != "" {
+		writeLine(&b, "DESCRIPTION:"+escapeText(task.Description))
+	}
+	writeLine(&b, "STATUS:"+todoStatus(task.Status))
+	if priority := todoPriority(task.Priority); priority != 0 {
+		writeLine(&b, fmt.Sprintf("PRIORITY:%d", priority))
+	}
+	if !task.Deadline.IsZero() {
+		writeLine(&b, "DUE:"+task.Deadline.UTC().Format(icalTimestamp))
+	}
+	writeLine(&b, "END:VTODO")
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func taskUID(task model.Task) string {
+	return fmt.Sprintf("task-%d@telegram-tasks-bot", task.ID)
+}
+
+func taskETag(task model.Task) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s|%s|%s|%d|%s", task.ID, task.Title, task.Description, task.Status, task.Deadline.Unix(), task.Priority)
+	return fmt.Sprintf(`"%x"`, h.Sum64())
+}
+
+// todoPriority maps priority to RFC 5545 scale where 1 is the highest, 0 means undefined.
+func todoPriority(priority model.TaskPriority) int {
+	switch priority {
+	case model.TaskPriorityUrgent:
+		return 1
+	case model.TaskPriorityHigh:
+		return 3
+	case model.TaskPriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+func todoStatus(status model.TaskStatus) string {
+	switch status {
+	case model.TaskStatusInProgress, model.TaskStatusReview:
+		return "IN-PROCESS"
+	case model.TaskStatusDone:
+		return "COMPLETED"
+	case model.TaskStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+func writeLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		// Do not split multi-byte UTF-8 sequences.
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation line starts with space which counts towards the limit.
+		limit = icalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
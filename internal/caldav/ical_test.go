@@ -0,0 +1,81 @@
+package caldav
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Купить молоко", "Купить молоко"},
+		{"молоко, хлеб; сыр", `молоко\, хлеб\; сыр`},
+		{`C:\temp`, `C:\\temp`},
+		{`\,`, `\\\,`},
+		{"первая\nвторая", `первая\nвторая`},
+		{"первая\r\nвторая", `первая\nвторая`},
+		{"первая\rвторая", `первая\nвторая`},
+		{"a:b\"c", "a:b\"c"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.text); got != tt.want {
+			t.Errorf("escapeText(%q): got %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "SUMMARY:Купить молоко", "SUMMARY:Купить молоко\r\n"},
+		{"exactly limit", strings.Repeat("a", 75), strings.Repeat("a", 75) + "\r\n"},
+		{"one over limit", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a\r\n"},
+		{
+			"continuation counts leading space",
+			strings.Repeat("a", 75+74+1),
+			strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n",
+		},
+		// "ж" takes two octets, the one crossing the limit moves to the next line whole
+		{"two-octet rune on limit", "a" + strings.Repeat("ж", 40), "a" + strings.Repeat("ж", 37) + "\r\n " + strings.Repeat("ж", 3) + "\r\n"},
+		{"four-octet rune on limit", "aaa" + strings.Repeat("😀", 20), "aaa" + strings.Repeat("😀", 18) + "\r\n " + strings.Repeat("😀", 2) + "\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeLine(&b, tt.line)
+			if got := b.String(); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteLineUnfolds(t *testing.T) {
+	lines := []string{
+		"DESCRIPTION:" + escapeText(strings.Repeat("Починить сборку, обновить зависимости; проверить релиз\n", 10)),
+		"SUMMARY:" + strings.Repeat("😀ж€a", 50),
+	}
+	for _, line := range lines {
+		var b strings.Builder
+		writeLine(&b, line)
+		folded := strings.TrimSuffix(b.String(), "\r\n")
+		for _, physical := range strings.Split(folded, "\r\n") {
+			if len(physical) > icalLineLimit {
+				t.Errorf("got line of %d octets, limit %d", len(physical), icalLineLimit)
+			}
+			if !utf8.ValidString(physical) {
+				t.Errorf("got line with split rune %q", physical)
+			}
+		}
+		if got := strings.ReplaceAll(folded, "\r\n ", ""); got != line {
+			t.Errorf("unfolded: got %q, want %q", got, line)
+		}
+	}
+}
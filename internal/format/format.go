@@ -0,0 +1,20 @@
+package format
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	DateLayout     = "02.01.2006"
+	DateTimeLayout = "02.01.2006 15:04"
+)
+
+// Capitalize makes first letter of s upper case, unlike strings.Title it keeps other words intact.
+func Capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Plural returns Russian form of noun for n, forms are given for 1, 2 and 5: "задача", "задачи", "задач".
+func Plural(n int, one, few, many string) string {
+	if n < 0 {
+		n = -n
+	}
+	n %= 100
+	switch {
+	case n%10 == 1 && n != 11:
+		return one
+	case n%10 >= 2 && n%10 <= 4 && (n < 12 || n > 14):
+		return few
+	default:
+		return many
+	}
+}
+
+// Count returns n followed by Russian form of noun, e.g. "3 задачи".
+func Count(n int, one, few, many string) string {
+	return fmt.Sprintf("%d %s", n, Plural(n, one, few, many))
+}
+
+// Days describes calendar day of t relative to now: "сегодня", "завтра", "через 3 дня", "вчера", "2 дня назад".
+func Days(t, now time.Time) string {
+	t = t.In(now.Location())
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	to := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+	// Days around DST switch are not exactly 24 hours long
+	days := int(math.Round(to.Sub(from).Hours() / 24))
+	switch {
+	case days == 0:
+		return "сегодня"
+	case days == 1:
+		return "завтра"
+	case days == -1:
+		return "вчера"
+	case days > 0:
+		return "через " + Count(days, "день", "дня", "дней")
+	default:
+		return Count(-days, "день", "дня", "дней") + " назад"
+	}
+}
+
+// Ago describes how long ago t was: "только что", "5 минут назад", "3 часа назад", then in days.
+func Ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "только что"
+	case d < time.Hour:
+		return Count(int(d/time.Minute), "минуту", "минуты", "минут") + " назад"
+	case d < 24*time.Hour:
+		return Count(int(d/time.Hour), "час", "часа", "часов") + " назад"
+	default:
+		return Days(t, now)
+	}
+}
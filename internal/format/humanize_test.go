@@ -0,0 +1,105 @@
+package format
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "задач"},
+		{1, "задача"},
+		{2, "задачи"},
+		{4, "задачи"},
+		{5, "задач"},
+		{11, "задач"},
+		{12, "задач"},
+		{13, "задач"},
+		{14, "задач"},
+		{15, "задач"},
+		{21, "задача"},
+		{22, "задачи"},
+		{101, "задача"},
+		{111, "задач"},
+		{112, "задач"},
+		{1004, "задачи"},
+		{-1, "задача"},
+		{-3, "задачи"},
+		{-11, "задач"},
+		{-21, "задача"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.n, "задача", "задачи", "задач"); got != tt.want {
+			t.Errorf("Plural(%d): got %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.March, 10, 15, 0, 0, 0, berlin)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		now  time.Time
+		want string
+	}{
+		{"earlier today", time.Date(2026, time.March, 10, 0, 0, 0, 0, berlin), now, "сегодня"},
+		{"later today", time.Date(2026, time.March, 10, 23, 59, 0, 0, berlin), now, "сегодня"},
+		{"tomorrow", time.Date(2026, time.March, 11, 0, 0, 0, 0, berlin), now, "завтра"},
+		{"yesterday", time.Date(2026, time.March, 9, 23, 59, 0, 0, berlin), now, "вчера"},
+		{"in 3 days", time.Date(2026, time.March, 13, 9, 0, 0, 0, berlin), now, "через 3 дня"},
+		{"in 11 days", time.Date(2026, time.March, 21, 9, 0, 0, 0, berlin), now, "через 11 дней"},
+		{"in 21 days", time.Date(2026, time.March, 31, 9, 0, 0, 0, berlin), now, "через 21 день"},
+		{"5 days ago", time.Date(2026, time.March, 5, 9, 0, 0, 0, berlin), now, "5 дней назад"},
+		{"12 days ago", time.Date(2026, time.February, 26, 9, 0, 0, 0, berlin), now, "12 дней назад"},
+		// Day of switch to summer time is 23 hours long, day of switch back is 25 hours long
+		{"over spring DST", time.Date(2026, time.March, 30, 0, 30, 0, 0, berlin), time.Date(2026, time.March, 28, 23, 30, 0, 0, berlin), "через 2 дня"},
+		{"spring DST day", time.Date(2026, time.March, 29, 23, 0, 0, 0, berlin), time.Date(2026, time.March, 29, 0, 30, 0, 0, berlin), "сегодня"},
+		{"over autumn DST", time.Date(2026, time.October, 24, 23, 30, 0, 0, berlin), time.Date(2026, time.October, 26, 0, 30, 0, 0, berlin), "2 дня назад"},
+		{"autumn DST day", time.Date(2026, time.October, 25, 0, 0, 0, 0, berlin), time.Date(2026, time.October, 26, 0, 0, 0, 0, berlin), "вчера"},
+		// Time is compared in location of now
+		{"other location", time.Date(2026, time.March, 10, 23, 30, 0, 0, time.UTC), now, "завтра"},
+	}
+	for _, tt := range tests {
+		if got := Days(tt.t, tt.now); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAgo(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "только что"},
+		{59 * time.Second, "только что"},
+		{time.Minute, "1 минуту назад"},
+		{11 * time.Minute, "11 минут назад"},
+		{21 * time.Minute, "21 минуту назад"},
+		{59 * time.Minute, "59 минут назад"},
+		{time.Hour, "1 час назад"},
+		{3 * time.Hour, "3 часа назад"},
+		{12 * time.Hour, "12 часов назад"},
+		{23 * time.Hour, "23 часа назад"},
+		{24 * time.Hour, "вчера"},
+		{40 * time.Hour, "2 дня назад"},
+		{14 * 24 * time.Hour, "14 дней назад"},
+	}
+	for _, tt := range tests {
+		if got := Ago(now.Add(-tt.d), now); got != tt.want {
+			t.Errorf("Ago(now-%s): got %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
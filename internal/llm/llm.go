@@ -0,0 +1,100 @@
+// Package llm is client of OpenAI-compatible chat completions API used by optional assistant features.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+type Client struct {
+	endpoint string
+	apiKey   string
+	model    string
+	client   *http.Client
+}
+
+// NewClient creates client of API with base URL endpoint, e.g. "https://api.openai.com/v1".
+func NewClient(endpoint, apiKey, model string) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		apiKey:   apiKey,
+		model:    model,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type completionRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+}
+
+type completionResponse struct {
+	Choices []struct {
+		Message message `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete returns model's answer to prompt following system instructions.
+func (c *Client) Complete(ctx context.Context, system, prompt string) (string, error) {
+	body, err := json.Marshal(completionRequest{
+		Model: c.model,
+		Messages: []message{
+			{Role: "system", Content: system},
+			{Role: "user", Content: prompt},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read response: %w", err)
+	}
+	var res completionResponse
+	if err = json.Unmarshal(raw, &res); err != nil {
+		return "", fmt.Errorf("could not decode response with status %d: %w", resp.StatusCode, err)
+	}
+	if res.Error != nil {
+		return "", fmt.Errorf("api error: %s", res.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	if len(res.Choices) == 0 {
+		return "", errors.New("empty response")
+	}
+	return strings.TrimSpace(res.Choices[0].Message.Content), nil
+}
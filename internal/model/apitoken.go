@@ -0,0 +1,60 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+type APITokenScope string
+
+const (
+	APITokenScopeRead      APITokenScope = "read"
+	APITokenScopeReadWrite APITokenScope = "read_write"
+)
+
+// Allows reports whether token with this scope may be used for requests needing required scope.
+func (s APITokenScope) Allows(required APITokenScope) bool {
+	return s == required || s == APITokenScopeReadWrite
+}
+
+func (s APITokenScope) StringLocalized() string {
+	if s == APITokenScopeReadWrite {
+		return "чтение и запись"
+	}
+	return "только чтение"
+}
+
+// APIToken gives automation access to project, token itself is shown once when issued and only its hash is kept.
+type APIToken struct {
+	ID        int
+	ProjectID int
+	Hash      string
+	// Prefix is the beginning of token, it tells tokens apart in lists.
+	Prefix    string
+	Scope     APITokenScope
+	CreatedBy int64
+	CreatedAt time.Time
+	// LastUsedAt is zero if token was never used.
+	LastUsedAt time.Time
+}
+
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type APITokenRepository interface {
+	CreateAPIToken(ctx context.Context, token *APIToken) error
+	// ListAPITokens returns tokens of project, the oldest first.
+	ListAPITokens(ctx context.Context, projectID int) ([]APIToken, error)
+	FetchAPITokenByHash(ctx context.Context, hash string) (*APIToken, error)
+	// TouchAPIToken records that token was used at t.
+	TouchAPIToken(ctx context.Context, id int, t time.Time) error
+	// RevokeAPIToken deletes token of project, ErrAPITokenNotFound is returned if project has no such token.
+	RevokeAPIToken(ctx context.Context, projectID, id int) error
+}
+
+var ErrAPITokenNotFound = errors.New("api token not found")
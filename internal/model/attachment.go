@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type AttachmentKind string
+
+const (
+	AttachmentKindPhoto    AttachmentKind = "photo"
+	AttachmentKindDocument AttachmentKind = "document"
+)
+
+// TaskAttachment is a file sent to Telegram, it is kept as file ID which bot sends again on request.
+type TaskAttachment struct {
+	ID     int
+	TaskID int
+	Kind   AttachmentKind
+	FileID string
+	// FileName is set for documents.
+	FileName string
+	AddedBy  int64
+	// CreatedAt is set by storage.
+	CreatedAt time.Time
+}
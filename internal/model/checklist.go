@@ -0,0 +1,19 @@
+package model
+
+// ChecklistItem is a step of the task which is checked off when done.
+type ChecklistItem struct {
+	ID     int
+	TaskID int
+	Text   string
+	Done   bool
+}
+
+// ChecklistProgress returns number of done items and number of all items.
+func ChecklistProgress(items []ChecklistItem) (done, total int) {
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	return done, len(items)
+}
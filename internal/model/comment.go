@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// TaskComment is a message in discussion of the task.
+type TaskComment struct {
+	ID       int
+	TaskID   int
+	AuthorID int64
+	Text     string
+	// CreatedAt is set by storage.
+	CreatedAt time.Time
+}
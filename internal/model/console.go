@@ -0,0 +1,16 @@
+package model
+
+import "context"
+
+// QueryResult is table returned by ad-hoc query, values are formatted as text.
+type QueryResult struct {
+	Columns []string
+	Rows    [][]string
+	// Truncated is set when query returned more rows than requested limit.
+	Truncated bool
+}
+
+type ConsoleRepository interface {
+	// Query runs read-only query for diagnostics and returns at most limit rows.
+	Query(ctx context.Context, query string, limit int) (*QueryResult, error)
+}
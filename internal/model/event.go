@@ -0,0 +1,49 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type TaskEventType string
+
+const (
+	TaskEventCreated TaskEventType = "created"
+	TaskEventUpdated TaskEventType = "updated"
+	TaskEventRemoved TaskEventType = "removed"
+)
+
+// TaskEvent describes change of a task made by user with ActorID.
+type TaskEvent struct {
+	Type TaskEventType
+	Task Task
+	// Previous is the task before update, it is set by changes of status, deadline and assignee.
+	Previous *Task
+	ActorID  int
+}
+
+// ProjectEvent describes change of project settings made by user with ActorID,
+// Change is short human readable description of it.
+type ProjectEvent struct {
+	Project Project
+	ActorID int
+	Change  string
+}
+
+// LoggedTaskEvent is task event kept in TaskEventLogRepository, Task has only fields shown on public board.
+type LoggedTaskEvent struct {
+	ID    int
+	Event TaskEvent
+}
+
+// TaskEventLogRepository keeps task events for processes other than the one making changes,
+// e.g. event streams of the bot show changes made by worker.
+type TaskEventLogRepository interface {
+	AppendTaskEvent(ctx context.Context, event TaskEvent) error
+	// FetchTaskEvents returns up to limit events logged after event with afterID, the oldest first.
+	FetchTaskEvents(ctx context.Context, afterID, limit int) ([]LoggedTaskEvent, error)
+	// LastTaskEventID returns id of the latest logged event, 0 if there are none.
+	LastTaskEventID(ctx context.Context) (int, error)
+	// DeleteTaskEvents removes events logged before given time and returns their number.
+	DeleteTaskEvents(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskField names field of task in its history.
+type TaskField string
+
+const (
+	TaskFieldTitle       TaskField = "title"
+	TaskFieldDescription TaskField = "description"
+	TaskFieldStatus      TaskField = "status"
+	TaskFieldDeadline    TaskField = "deadline"
+	TaskFieldAssignee    TaskField = "assignee"
+	TaskFieldCoAssignees TaskField = "co_assignees"
+	TaskFieldReviewer    TaskField = "reviewer"
+	TaskFieldMuted       TaskField = "muted"
+	TaskFieldEpic        TaskField = "epic"
+	TaskFieldParent      TaskField = "parent"
+	TaskFieldPriority    TaskField = "priority"
+	TaskFieldLabels      TaskField = "labels"
+	TaskFieldEstimate    TaskField = "estimate"
+)
+
+// TaskChange is change of one task field saved to its history. Values are empty if field was not set,
+// users and tasks are kept as ids, deadline in RFC 3339, co-assignees and labels are joined by comma.
+type TaskChange struct {
+	ID     int
+	TaskID int
+	// UserID is user who saved the task.
+	UserID   int64
+	Field    TaskField
+	OldValue string
+	NewValue string
+	// ChangedAt is set by storage.
+	ChangedAt time.Time
+}
+
+// DiffTasks returns changes of fields between saved task and its new version made by next.UpdatedBy.
+func DiffTasks(prev, next *Task) []TaskChange {
+	var changes []TaskChange
+	add := func(field TaskField, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, TaskChange{
+				TaskID:   next.ID,
+				UserID:   next.UpdatedBy,
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+	add(TaskFieldTitle, prev.Title, next.Title)
+	add(TaskFieldDescription, prev.Description, next.Description)
+	add(TaskFieldStatus, string(prev.Status), string(next.Status))
+	// Deadlines are compared as instants, saved ones are read in UTC
+	if !prev.Deadline.Equal(next.Deadline) {
+		add(TaskFieldDeadline, formatChangeTime(prev.Deadline), formatChangeTime(next.Deadline))
+	}
+	add(TaskFieldAssignee, formatChangeID(prev.Assignee), formatChangeID(next.Assignee))
+	add(TaskFieldCoAssignees, formatChangeIDs(prev.CoAssignees), formatChangeIDs(next.CoAssignees))
+	add(TaskFieldReviewer, formatChangeID(prev.Reviewer), formatChangeID(next.Reviewer))
+	add(TaskFieldMuted, strconv.FormatBool(prev.Muted), strconv.FormatBool(next.Muted))
+	add(TaskFieldEpic, strconv.FormatBool(prev.Epic), strconv.FormatBool(next.Epic))
+	add(TaskFieldParent, formatChangeID(int64(prev.ParentID)), formatChangeID(int64(next.ParentID)))
+	add(TaskFieldPriority, string(prev.Priority), string(next.Priority))
+	add(TaskFieldLabels, formatChangeLabels(prev.Labels), formatChangeLabels(next.Labels))
+	add(TaskFieldEstimate, formatChangeID(int64(prev.Estimate)), formatChangeID(int64(next.Estimate)))
+	return changes
+}
+
+func formatChangeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatChangeID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func formatChangeIDs(ids []int64) string {
+	sorted := slices.Clone(ids)
+	slices.Sort(sorted)
+	values := make([]string, len(sorted))
+	for i, id := range sorted {
+		values[i] = strconv.FormatInt(id, 10)
+	}
+	return strings.Join(values, ",")
+}
+
+func formatChangeLabels(labels []string) string {
+	sorted := slices.Clone(labels)
+	slices.Sort(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,13 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// LeaseRepository keeps leases of background jobs, so only one of processes sharing database runs each job.
+type LeaseRepository interface {
+	// AcquireLease takes or extends lease of job for ttl and reports whether holder owns it,
+	// lease of another holder is taken only after it expires.
+	AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type MaintenanceRepository interface {
+	// DeleteOrphanUsers removes personal data of users who are not members of any project since before
+	// and returns their number. Users left without projects are only marked, so removed members who
+	// come back within grace period keep their data. Users referenced by tasks are anonymized,
+	// so tasks keep their ids, others are deleted.
+	DeleteOrphanUsers(ctx context.Context, before time.Time) (int, error)
+	// Compact reclaims unused space of database file.
+	Compact(ctx context.Context) error
+}
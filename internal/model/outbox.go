@@ -0,0 +1,42 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Notification is private message to user kept in outbox until it is delivered.
+type Notification struct {
+	ID     int
+	UserID int64
+	Text   string
+
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	// Dead is set when delivery is given up, such notifications are kept for inspection.
+	Dead      bool
+	CreatedAt time.Time
+}
+
+func NewNotification(userID int64, text string) Notification {
+	return Notification{UserID: userID, Text: text}
+}
+
+type OutboxRepository interface {
+	// EnqueueNotifications saves notifications due right away, it is used when there is no change to save them with.
+	EnqueueNotifications(ctx context.Context, notifications []Notification) error
+	// FetchDueNotifications returns not dead notifications due to be sent at now, the oldest first.
+	FetchDueNotifications(ctx context.Context, now time.Time, limit int) ([]Notification, error)
+	// UpdateNotification saves delivery state of notification.
+	UpdateNotification(ctx context.Context, n *Notification) error
+	DeleteNotification(ctx context.Context, id int) error
+	// ListDeadNotifications returns notifications delivery of which was given up, the oldest first.
+	ListDeadNotifications(ctx context.Context) ([]Notification, error)
+	// RequeueDeadNotification makes dead notification due now with attempts counted anew,
+	// ErrNotificationNotFound is returned if there is no such dead notification.
+	RequeueDeadNotification(ctx context.Context, id int, now time.Time) error
+}
+
+var ErrNotificationNotFound = errors.New("notification not found")
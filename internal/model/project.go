@@ -3,6 +3,7 @@ package model
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 type Project struct {
@@ -10,6 +11,22 @@ type Project struct {
 	TgChatID int64
 	Title    string
 	Archived bool
+	// QuickCapture turns chat messages starting with "todo:" or "задача:" into tasks.
+	QuickCapture bool
+	// BoardMessageID is pinned message with live board summary, 0 if board is not pinned.
+	BoardMessageID int
+	// PublicToken grants read-only web access to project board, empty if sharing is disabled.
+	PublicToken string
+	// DefaultDeadlineDays is deadline given to new tasks created without one, 0 disables it.
+	DefaultDeadlineDays int
+	// EscalationDays raises priority of open tasks once their deadline is this number of days away, 0 disables it.
+	EscalationDays int
+	// Frozen blocks changes of project and its tasks, viewing still works.
+	Frozen bool
+	// WelcomeText greets members joining project chat, empty disables welcome.
+	WelcomeText string
+	// WelcomePrivately sends welcome to private chat of new member instead of project chat.
+	WelcomePrivately bool
 }
 
 func NewProject(title string, tgChatID int64) *Project {
@@ -23,9 +40,45 @@ var (
 	ErrProjectNotFound = errors.New("project not found")
 )
 
+// ProjectContents counts what is removed along with the project.
+type ProjectContents struct {
+	// Tasks includes tasks in trash.
+	Tasks    int
+	Members  int
+	Comments int
+}
+
 type ProjectRepository interface {
+	FetchProjectByID(ctx context.Context, id int) (*Project, error)
 	FetchProjectByChatID(ctx context.Context, tgChatID int64) (*Project, error)
+	FetchProjectByPublicToken(ctx context.Context, token string) (*Project, error)
+	// FetchProjectsByUser returns projects where user has given role, any role if it is empty.
+	FetchProjectsByUser(ctx context.Context, userID int, role UserProjectRole) ([]Project, error)
+	// ListProjects returns all projects ordered by id.
+	ListProjects(ctx context.Context) ([]Project, error)
 	CreateProject(ctx context.Context, project *Project) error
 	UpdateProject(ctx context.Context, project *Project) error
+	// DeleteProject removes project with memberships of its users.
 	DeleteProject(ctx context.Context, id int) error
+	// CountProjectContents counts tasks, members and comments of the project, e.g. to preview deletion.
+	CountProjectContents(ctx context.Context, id int) (ProjectContents, error)
+	// FetchProjectHolidays returns project's days off besides weekends, as midnights in local time ordered by date.
+	FetchProjectHolidays(ctx context.Context, projectID int) ([]time.Time, error)
+	// SetProjectHolidays replaces project's days off.
+	SetProjectHolidays(ctx context.Context, projectID int, days []time.Time) error
+	// FetchProjectLinks returns project's links ordered by title.
+	FetchProjectLinks(ctx context.Context, projectID int) ([]ProjectLink, error)
+	// SaveProjectLink adds link to project, link with the same title ignoring case is replaced.
+	SaveProjectLink(ctx context.Context, link *ProjectLink) error
+	RemoveProjectLink(ctx context.Context, id int) error
+}
+
+// ProjectLink is named resource of project, like design doc, repository or staging.
+type ProjectLink struct {
+	ID        int
+	ProjectID int
+	// Title is unique in project ignoring case.
+	Title     string
+	URL       string
+	CreatedBy int64
 }
@@ -0,0 +1,198 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrRecurrenceNotFound = errors.New("recurrence not found")
+
+// TaskRecurrence re-creates task by rule. TaskID is the latest instance of the task,
+// NextAt ends its period: the next instance is created then or as soon as the latest one is closed.
+type TaskRecurrence struct {
+	TaskID int
+	Rule   RecurrenceRule
+	NextAt time.Time
+}
+
+// RecurrenceRule is "daily", "weekly", "monthly" or cron expression "minute hour day month weekday".
+// Instances of daily, weekly and monthly tasks are due at the end of the day.
+type RecurrenceRule string
+
+const (
+	RecurrenceDaily   RecurrenceRule = "daily"
+	RecurrenceWeekly  RecurrenceRule = "weekly"
+	RecurrenceMonthly RecurrenceRule = "monthly"
+)
+
+var recurrenceAliases = map[string]RecurrenceRule{
+	"daily":       RecurrenceDaily,
+	"ежедневно":   RecurrenceDaily,
+	"weekly":      RecurrenceWeekly,
+	"еженедельно": RecurrenceWeekly,
+	"monthly":     RecurrenceMonthly,
+	"ежемесячно":  RecurrenceMonthly,
+}
+
+// ParseRecurrenceRule accepts rule names in English or Russian and cron expressions,
+// cron expression must match some time in the next years.
+func ParseRecurrenceRule(s string) (RecurrenceRule, error) {
+	s = strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	if rule, ok := recurrenceAliases[s]; ok {
+		return rule, nil
+	}
+	cron, err := parseCron(s)
+	if err != nil {
+		return "", err
+	}
+	if cron.next(time.Now()).IsZero() {
+		return "", fmt.Errorf("cron expression %q never matches", s)
+	}
+	return RecurrenceRule(s), nil
+}
+
+// Next returns the first time of the rule after t in t's location, zero time if rule never matches.
+func (r RecurrenceRule) Next(t time.Time) time.Time {
+	endOfDay := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 23, 59, 59, 0, t.Location())
+	}
+	switch r {
+	case RecurrenceDaily:
+		return endOfDay(t.Year(), t.Month(), t.Day()+1)
+	case RecurrenceWeekly:
+		return endOfDay(t.Year(), t.Month(), t.Day()+7)
+	case RecurrenceMonthly:
+		// 31st is followed by the last day of shorter month
+		day := min(t.Day(), time.Date(t.Year(), t.Month()+2, 0, 0, 0, 0, 0, t.Location()).Day())
+		return endOfDay(t.Year(), t.Month()+1, day)
+	}
+	cron, err := parseCron(string(r))
+	if err != nil {
+		return time.Time{}
+	}
+	return cron.next(t)
+}
+
+func (r RecurrenceRule) StringLocalized() string {
+	switch r {
+	case RecurrenceDaily:
+		return "каждый день"
+	case RecurrenceWeekly:
+		return "каждую неделю"
+	case RecurrenceMonthly:
+		return "каждый месяц"
+	}
+	return fmt.Sprintf("по расписанию «%s»", string(r))
+}
+
+// cronSchedule keeps allowed values of every field as bits.
+type cronSchedule struct {
+	minute, hour, day, month, weekday uint64
+	// Restricted day and weekday match either of them, like in cron.
+	anyDay, anyWeekday bool
+}
+
+func parseCron(s string) (*cronSchedule, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected daily, weekly, monthly or cron expression of 5 fields, got %q", s)
+	}
+	var (
+		c   cronSchedule
+		err error
+	)
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.day, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("day: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if c.weekday, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("weekday: %w", err)
+	}
+	// Both 0 and 7 are Sunday
+	if c.weekday&(1<<7) != 0 {
+		c.weekday |= 1
+	}
+	c.anyDay, c.anyWeekday = fields[2] == "*", fields[4] == "*"
+	return &c, nil
+}
+
+// parseCronField parses comma separated values, ranges "a-b" and steps "*/n" or "a-b/n".
+func parseCronField(s string, minValue, maxValue int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rng, rawStep, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(rawStep); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", rawStep)
+			}
+		}
+
+		from, to := minValue, maxValue
+		if rng != "*" {
+			rawFrom, rawTo, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = strconv.Atoi(rawFrom); err != nil {
+				return 0, fmt.Errorf("invalid value %q", rawFrom)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(rawTo); err != nil {
+					return 0, fmt.Errorf("invalid value %q", rawTo)
+				}
+			} else if hasStep {
+				to = maxValue
+			}
+		}
+		if from < minValue || to > maxValue || from > to {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, minValue, maxValue)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *cronSchedule) next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, loc)
+	// Any valid expression matches within 4 years, e.g. February 29
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cronSchedule) matchesDay(t time.Time) bool {
+	day := c.day&(1<<t.Day()) != 0
+	weekday := c.weekday&(1<<int(t.Weekday())) != 0
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// TaskSnapshot is number of project's tasks in every status at the end of the day,
+// it lets charts and digests look back without scanning tasks.
+type TaskSnapshot struct {
+	ProjectID int
+	// Day is midnight of the date in local time.
+	Day    time.Time
+	Counts map[TaskStatus]int
+}
+
+// Open returns number of tasks which are not done or cancelled.
+func (s TaskSnapshot) Open() int {
+	open := 0
+	for status, count := range s.Counts {
+		if status.IsOpen() {
+			open += count
+		}
+	}
+	return open
+}
+
+// Closed returns number of done and cancelled tasks.
+func (s TaskSnapshot) Closed() int {
+	return s.Counts[TaskStatusDone] + s.Counts[TaskStatusCancelled]
+}
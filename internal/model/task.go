@@ -2,6 +2,9 @@ package model
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 )
 
@@ -14,40 +17,339 @@ type Task struct {
 	Deadline    time.Time
 	CreatedBy   int64
 	UpdatedBy   int64
+	// UpdatedAt is set by storage on every save, zero for tasks not changed since it is tracked.
+	UpdatedAt time.Time
+	// Assignee is primary assignee responsible for the task, CoAssignees share the work with them.
 	Assignee    int64
+	CoAssignees []int64
+	// Reviewer checks result of the task, 0 if not set.
+	Reviewer int64
+	// OverdueNotified is set once chat is told that deadline passed, it is not saved by UpdateTask.
+	OverdueNotified bool
+	// Escalated is set once priority is raised for approaching deadline, it is not saved by UpdateTask.
+	Escalated bool
+	// Muted tasks are skipped by scheduled reminders and escalations.
+	Muted bool
+	// Epic owns child tasks, which refer to it by ParentID, 0 if task has no epic.
+	Epic     bool
+	ParentID int
+	Priority TaskPriority
+	// Estimate is story points of the task, one of TaskEstimates or 0 if task is not estimated.
+	Estimate int
+	// Labels are sorted names of project labels attached to the task.
+	Labels []string
+	// DeletedAt is set by storage when task is moved to trash, tasks in trash are found only by FetchDeletedTasks.
+	DeletedAt time.Time
 }
 
 func NewTask(projectID int, title string, createdBy int64) *Task {
 	return &Task{
 		ProjectID: projectID,
 		Title:     title,
+		Status:    TaskStatusBacklog,
+		Priority:  TaskPriorityNormal,
 		CreatedBy: createdBy,
 		UpdatedBy: createdBy,
 	}
 }
 
+// Clone returns copy of the task which doesn't share co-assignees and labels with it.
+func (t *Task) Clone() *Task {
+	clone := *t
+	clone.CoAssignees = slices.Clone(t.CoAssignees)
+	clone.Labels = slices.Clone(t.Labels)
+	return &clone
+}
+
+// IsAssignee reports whether user is primary assignee or co-assignee of the task.
+func (t *Task) IsAssignee(userID int64) bool {
+	return userID != 0 && (t.Assignee == userID || slices.Contains(t.CoAssignees, userID))
+}
+
+// EpicProgress counts done tasks and all tasks except cancelled among children of epic found in tasks.
+func EpicProgress(epicID int, tasks []Task) (done, total int) {
+	for _, task := range tasks {
+		if task.ParentID != epicID || task.Status == TaskStatusCancelled {
+			continue
+		}
+		total++
+		if task.Status == TaskStatusDone {
+			done++
+		}
+	}
+	return done, total
+}
+
 type TaskStatus string
 
 const (
 	TaskStatusBacklog    TaskStatus = "backlog"
 	TaskStatusTODO       TaskStatus = "todo"
 	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusReview     TaskStatus = "review"
 	TaskStatusDone       TaskStatus = "done"
 	TaskStatusCancelled  TaskStatus = "cancelled"
 	TaskStatusOnHold     TaskStatus = "on_hold"
 )
 
+// TaskStatuses lists all statuses in workflow order.
+var TaskStatuses = []TaskStatus{
+	TaskStatusBacklog,
+	TaskStatusTODO,
+	TaskStatusInProgress,
+	TaskStatusReview,
+	TaskStatusOnHold,
+	TaskStatusDone,
+	TaskStatusCancelled,
+}
+
+// StatusLabel is how status is shown to users.
+type StatusLabel struct {
+	Emoji string
+	Name  string
+}
+
+var statusLabels = map[TaskStatus]StatusLabel{
+	TaskStatusBacklog:    {Emoji: "📥", Name: "бэклог"},
+	TaskStatusTODO:       {Emoji: "📋", Name: "к выполнению"},
+	TaskStatusInProgress: {Emoji: "🔄", Name: "в работе"},
+	TaskStatusReview:     {Emoji: "👀", Name: "на проверке"},
+	TaskStatusDone:       {Emoji: "✅", Name: "готово"},
+	TaskStatusCancelled:  {Emoji: "❌", Name: "отменено"},
+	TaskStatusOnHold:     {Emoji: "⏸", Name: "отложено"},
+}
+
+// OverrideStatusLabels changes how statuses are shown, empty fields keep defaults.
+// It is not safe for concurrent use and must be called on startup.
+func OverrideStatusLabels(labels map[TaskStatus]StatusLabel) error {
+	for status, label := range labels {
+		current, ok := statusLabels[status]
+		if !ok {
+			return fmt.Errorf("unknown status %q", status)
+		}
+		if label.Emoji != "" {
+			current.Emoji = label.Emoji
+		}
+		if label.Name != "" {
+			current.Name = label.Name
+		}
+		statusLabels[status] = current
+	}
+	return nil
+}
+
+func (s TaskStatus) StringLocalized() string {
+	label, ok := statusLabels[s]
+	if !ok {
+		panic(fmt.Sprintf("missing localization for %s", s))
+	}
+	return label.Name
+}
+
+func (s TaskStatus) Emoji() string {
+	label, ok := statusLabels[s]
+	if !ok {
+		return "❔"
+	}
+	return label.Emoji
+}
+
+// IsOpen reports whether work on task with this status is not finished.
+func (s TaskStatus) IsOpen() bool {
+	return s != TaskStatusDone && s != TaskStatusCancelled
+}
+
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "low"
+	TaskPriorityNormal TaskPriority = "normal"
+	TaskPriorityHigh   TaskPriority = "high"
+	TaskPriorityUrgent TaskPriority = "urgent"
+)
+
+// TaskPriorities lists all priorities from the lowest.
+var TaskPriorities = []TaskPriority{
+	TaskPriorityLow,
+	TaskPriorityNormal,
+	TaskPriorityHigh,
+	TaskPriorityUrgent,
+}
+
+var priorityLabels = map[TaskPriority]StatusLabel{
+	TaskPriorityLow:    {Emoji: "🔽", Name: "низкий"},
+	TaskPriorityNormal: {Emoji: "▫️", Name: "обычный"},
+	TaskPriorityHigh:   {Emoji: "🔺", Name: "высокий"},
+	TaskPriorityUrgent: {Emoji: "🔥", Name: "срочный"},
+}
+
+func (p TaskPriority) StringLocalized() string {
+	label, ok := priorityLabels[p]
+	if !ok {
+		panic(fmt.Sprintf("missing localization for %s", p))
+	}
+	return label.Name
+}
+
+func (p TaskPriority) Emoji() string {
+	label, ok := priorityLabels[p]
+	if !ok {
+		return "❔"
+	}
+	return label.Emoji
+}
+
+// Marker is shown before title in task lists, tasks of normal priority are not marked.
+func (p TaskPriority) Marker() string {
+	if p == TaskPriorityNormal || p == "" {
+		return ""
+	}
+	return p.Emoji() + " "
+}
+
+// TaskEstimates lists story points task may be estimated in.
+var TaskEstimates = []int{1, 2, 3, 5, 8, 13}
+
+// TaskMove changes place of task in lists among project's tasks with the same status.
+type TaskMove string
+
+const (
+	TaskMoveUp   TaskMove = "up"
+	TaskMoveDown TaskMove = "down"
+	TaskMoveTop  TaskMove = "top"
+)
+
 type TaskFilter struct {
 	ProjectID int
 	Status    TaskStatus
 	CreatedBy int64
-	Assignee  int64
-	Deadline  time.Time
+	// Assignee selects tasks where user is primary assignee or co-assignee.
+	Assignee int64
+	// Deadline selects tasks due not later than it, DeadlineFrom selects tasks due not earlier than it.
+	Deadline     time.Time
+	DeadlineFrom time.Time
+	// WithoutDeadline selects only tasks with no deadline set.
+	WithoutDeadline bool
+	// OnlyOpen excludes done and cancelled tasks.
+	OnlyOpen bool
+	// OverdueNotNotified selects tasks which chat was not told about passed deadline.
+	OverdueNotNotified bool
+	// NotEscalated selects tasks which priority was not raised for approaching deadline.
+	NotEscalated bool
+	// WithoutMuted excludes tasks muted for reminders.
+	WithoutMuted bool
+	// ParentID selects child tasks of epic, OnlyEpics selects epics.
+	ParentID  int
+	OnlyEpics bool
+	// Label selects tasks having label with this name, case is ignored.
+	Label string
+}
+
+var (
+	ErrTaskNotFound          = errors.New("task not found")
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrAttachmentNotFound    = errors.New("attachment not found")
+	ErrAlertTaskExists       = errors.New("alert already has open task")
+)
+
+// LabelCount is label with number of open tasks having it.
+type LabelCount struct {
+	Name  string
+	Tasks int
+}
+
+// TaskCounters is summary of project's work in progress.
+type TaskCounters struct {
+	InProgress int
+	Overdue    int
+	Queued     int
+	// OpenPoints is sum of estimates of open tasks.
+	OpenPoints int
 }
 
 type TaskRepository interface {
+	FetchTaskByID(ctx context.Context, id int) (*Task, error)
 	FilterTasks(ctx context.Context, filter TaskFilter) ([]Task, error)
 	CreateTask(ctx context.Context, task *Task) error
+	// CreateTasks saves all tasks atomically.
+	CreateTasks(ctx context.Context, tasks []*Task) error
 	UpdateTask(ctx context.Context, task *Task) error
+	// UpdateTasks saves all tasks atomically.
+	UpdateTasks(ctx context.Context, tasks []*Task) error
+	// UpdateTasksWithNotifications saves tasks and puts notifications about the change into outbox atomically.
+	UpdateTasksWithNotifications(ctx context.Context, tasks []*Task, notifications []Notification) error
+	SetTaskOverdueNotified(ctx context.Context, id int, notified bool) error
+	SetTaskEscalated(ctx context.Context, id int, escalated bool) error
+	// RemoveTask moves task to trash, from where it is restored by RestoreTask or removed for good by PurgeTask.
 	RemoveTask(ctx context.Context, id int) error
+	// RestoreTask returns task from trash, ErrTaskNotFound if it is not there.
+	RestoreTask(ctx context.Context, id int) error
+	// FetchDeletedTasks returns project's tasks in trash, the latest removed first.
+	FetchDeletedTasks(ctx context.Context, projectID int) ([]Task, error)
+	// PurgeTask removes task with everything attached to it, unlike RemoveTask it can't be undone.
+	PurgeTask(ctx context.Context, id int) error
+	// PurgeDeletedTasks purges tasks moved to trash before given time and returns their number.
+	PurgeDeletedTasks(ctx context.Context, before time.Time) (int, error)
+	// MoveTask changes rank of task, which orders lists of tasks. Task moved up or down swaps with
+	// the nearest task of its project having the same status, it stays if there is none.
+	MoveTask(ctx context.Context, id int, move TaskMove) error
+	CountTasksByStatus(ctx context.Context, projectID int) (map[TaskStatus]int, error)
+	FetchTaskCounters(ctx context.Context, projectID int, now time.Time) (TaskCounters, error)
+	FetchUpcomingDeadlines(ctx context.Context, projectID int, limit int) ([]Task, error)
+	// FetchChecklist returns checklist items of the task in order they were added.
+	FetchChecklist(ctx context.Context, taskID int) ([]ChecklistItem, error)
+	FetchChecklistItem(ctx context.Context, id int) (*ChecklistItem, error)
+	// AddChecklistItems saves all items atomically.
+	AddChecklistItems(ctx context.Context, items []*ChecklistItem) error
+	SetChecklistItemDone(ctx context.Context, id int, done bool) error
+	RemoveChecklistItem(ctx context.Context, id int) error
+	AddTaskComment(ctx context.Context, comment *TaskComment) error
+	// FetchTaskComments returns the latest comments of the task up to limit, the oldest first,
+	// and number of all its comments.
+	FetchTaskComments(ctx context.Context, taskID int, limit int) ([]TaskComment, int, error)
+	// FetchTaskHistory returns the latest changes of the task up to limit, the oldest first,
+	// and number of all its changes. Changes are saved by UpdateTask and other updates of tasks.
+	FetchTaskHistory(ctx context.Context, taskID int, limit int) ([]TaskChange, int, error)
+	AddTaskAttachment(ctx context.Context, attachment *TaskAttachment) error
+	// FetchTaskAttachments returns attachments of the task in order they were added.
+	FetchTaskAttachments(ctx context.Context, taskID int) ([]TaskAttachment, error)
+	FetchTaskAttachment(ctx context.Context, id int) (*TaskAttachment, error)
+	SetTaskWatcher(ctx context.Context, taskID int, userID int64, watching bool) error
+	// FetchTaskWatchers returns ids of users watching the task who are still members of its project.
+	FetchTaskWatchers(ctx context.Context, taskID int) ([]int64, error)
+	// CountTaskLabels returns labels of project's open tasks ordered by name.
+	CountTaskLabels(ctx context.Context, projectID int) ([]LabelCount, error)
+	// CreateAlertTask saves task created for monitoring alert with fingerprint and links it to the alert atomically,
+	// it replaces previous task of the alert. ErrAlertTaskExists is returned if the alert already has open task.
+	CreateAlertTask(ctx context.Context, task *Task, fingerprint string) error
+	// FetchAlertTask returns the last task linked to alert, ErrTaskNotFound is returned if there is none.
+	FetchAlertTask(ctx context.Context, projectID int, fingerprint string) (*Task, error)
+	// SetTaskBlockers replaces tasks blocking the task.
+	SetTaskBlockers(ctx context.Context, taskID int, blockerIDs []int) error
+	// FetchTaskBlockers returns tasks blocking the task, FetchBlockedTasks returns tasks blocked by it.
+	FetchTaskBlockers(ctx context.Context, taskID int) ([]Task, error)
+	FetchBlockedTasks(ctx context.Context, blockerID int) ([]Task, error)
+	// SetTaskRecurrence creates or replaces recurrence of its task.
+	SetTaskRecurrence(ctx context.Context, rec *TaskRecurrence) error
+	RemoveTaskRecurrence(ctx context.Context, taskID int) error
+	// FetchTaskRecurrence returns ErrRecurrenceNotFound if task doesn't recur.
+	FetchTaskRecurrence(ctx context.Context, taskID int) (*TaskRecurrence, error)
+	// FetchProjectRecurrences returns recurrences of project's tasks ordered by task id.
+	FetchProjectRecurrences(ctx context.Context, projectID int) ([]TaskRecurrence, error)
+	// FetchDueRecurrences returns recurrences whose period ended by now or whose latest instance is closed.
+	FetchDueRecurrences(ctx context.Context, now time.Time) ([]TaskRecurrence, error)
+	// RenewTaskRecurrence creates next instance of recurring task and moves recurrence to it atomically.
+	RenewTaskRecurrence(ctx context.Context, rec *TaskRecurrence, next *Task) error
+	// SaveTaskTemplate creates template or replaces project's template with the same name, ID is set in both cases.
+	SaveTaskTemplate(ctx context.Context, tpl *TaskTemplate) error
+	// FetchTaskTemplates returns project's templates ordered by name.
+	FetchTaskTemplates(ctx context.Context, projectID int) ([]TaskTemplate, error)
+	// FetchTaskTemplate returns ErrTemplateNotFound if template doesn't exist.
+	FetchTaskTemplate(ctx context.Context, id int) (*TaskTemplate, error)
+	RemoveTaskTemplate(ctx context.Context, id int) error
+	// SnapshotTaskCounts replaces snapshots of the day with current counts of tasks of all projects.
+	SnapshotTaskCounts(ctx context.Context, day time.Time) error
+	// FetchTaskSnapshots returns project's snapshots of days from and to inclusive ordered by day,
+	// days without snapshot are skipped.
+	FetchTaskSnapshots(ctx context.Context, projectID int, from, to time.Time) ([]TaskSnapshot, error)
 }
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TaskTemplate keeps content of typical task, so members create such tasks in one tap.
+type TaskTemplate struct {
+	ID        int
+	ProjectID int
+	// Name is unique in project ignoring case.
+	Name        string
+	Title       string
+	Description string
+	// Assignee is 0 if template has no assignee.
+	Assignee  int64
+	Labels    []string
+	CreatedBy int64
+}
+
+// NewTaskTemplate saves title, description, assignee and labels of the task as template.
+func NewTaskTemplate(task *Task, name string, createdBy int64) *TaskTemplate {
+	return &TaskTemplate{
+		ProjectID:   task.ProjectID,
+		Name:        name,
+		Title:       task.Title,
+		Description: task.Description,
+		Assignee:    task.Assignee,
+		Labels:      slices.Clone(task.Labels),
+		CreatedBy:   createdBy,
+	}
+}
+
+// NewTask returns task with content of the template, it is not saved.
+func (t *TaskTemplate) NewTask(createdBy int64) *Task {
+	task := NewTask(t.ProjectID, t.Title, createdBy)
+	task.Description = t.Description
+	task.Assignee = t.Assignee
+	task.Labels = slices.Clone(t.Labels)
+	return task
+}
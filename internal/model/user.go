@@ -9,9 +9,14 @@ import (
 type User struct {
 	ID       int
 	TgUserID int64
+	Username string
 	FullName string
 	Role     UserProjectRole
 	IsActive bool
+
+	CalDAVToken string
+	// Unreachable is set when user blocked bot, so private messages can not be delivered.
+	Unreachable bool
 }
 
 func NewUser(tgUserID int64) *User {
@@ -44,9 +49,20 @@ var (
 )
 
 type UserRepository interface {
+	FetchUserByID(ctx context.Context, id int) (*User, error)
 	FetchUserByTgID(ctx context.Context, tgUserID int64) (*User, error)
+	FetchUserByCalDAVToken(ctx context.Context, token string) (*User, error)
+	UpdateUserCalDAVToken(ctx context.Context, userID int, token string) error
+	SetUserUnreachable(ctx context.Context, tgUserID int64, unreachable bool) error
+	FetchProjectUserByUsername(ctx context.Context, projectID int, username string) (*User, error)
+	// FetchProjectUserByPreviousUsername finds project member by username they had before.
+	FetchProjectUserByPreviousUsername(ctx context.Context, projectID int, username string) (*User, error)
 	CreateUser(ctx context.Context, user *User) error
+	UpdateUser(ctx context.Context, user *User) error
 	AddUserToProject(ctx context.Context, projectID int, userID int, role UserProjectRole) error
 	FetchUserRoleInProject(ctx context.Context, projectID int, user *User) error
+	// SetUserRoleInProject changes role of project member, ErrUserNotFound if user is not a member.
+	SetUserRoleInProject(ctx context.Context, projectID int, userID int, role UserProjectRole) error
 	CountUsersInProject(ctx context.Context, projectID int) (int, error)
+	FetchProjectUsers(ctx context.Context, projectID int) ([]User, error)
 }
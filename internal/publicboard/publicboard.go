@@ -0,0 +1,235 @@
+package publicboard
+
+import (
+	_ "embed"
+	"errors"
+	"hash/fnv"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/format"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// PathPrefix is the root of shared boards, each project is served at PathPrefix/{token}.
+const PathPrefix = "/board/"
+
+//go:embed board.html
+var boardHTML string
+
+var boardTemplate = template.Must(template.New("board").Parse(boardHTML))
+
+// labelColors is number of label-N classes in board.html.
+const labelColors = 8
+
+// Handler renders read-only snapshot of project board for people without access to project chat.
+type Handler struct {
+	mux            *http.ServeMux
+	projectStorage model.ProjectRepository
+	taskStorage    model.TaskRepository
+	userStorage    model.UserRepository
+}
+
+func NewHandler(
+	projectStorage model.ProjectRepository,
+	taskStorage model.TaskRepository,
+	userStorage model.UserRepository,
+) *Handler {
+	h := &Handler{
+		mux:            http.NewServeMux(),
+		projectStorage: projectStorage,
+		taskStorage:    taskStorage,
+		userStorage:    userStorage,
+	}
+	h.mux.HandleFunc("GET "+PathPrefix+"{token}", h.board)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type boardView struct {
+	Title       string
+	GeneratedAt string
+	// Path is board URL without filters, Epic is epic selected with "?epic=ID" or nil.
+	Path  string
+	Epics []epicView
+	Epic  *epicView
+	// Labels is legend of labels of open tasks, Label is label selected with "?label=NAME".
+	Labels  []labelView
+	Label   string
+	Columns []columnView
+}
+
+type labelView struct {
+	Name string
+	// Tasks is number of open tasks with the label, 0 for labels of cards.
+	Tasks int
+	// Color is number of label-N class, it is the same for the name across boards.
+	Color int
+}
+
+type epicView struct {
+	ID    int
+	Title string
+	Done  int
+	Total int
+}
+
+type columnView struct {
+	Emoji string
+	Name  string
+	Tasks []taskView
+}
+
+type taskView struct {
+	ID       int
+	Title    string
+	Assignee string
+	Deadline string
+	Overdue  bool
+	Epic     bool
+	// Priority is emoji marker, empty for normal priority.
+	Priority string
+	Labels   []labelView
+}
+
+func (h *Handler) board(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	prj, err := h.projectStorage.FetchProjectByPublicToken(ctx, r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, model.ErrProjectNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		h.internalError(w, "could not fetch project", err)
+		return
+	}
+
+	tasks, err := h.taskStorage.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID})
+	if err != nil {
+		h.internalError(w, "could not fetch tasks", err)
+		return
+	}
+
+	now := time.Now()
+	view := boardView{Title: prj.Title, GeneratedAt: now.Format(format.DateTimeLayout), Path: r.URL.Path}
+	epicID, _ := strconv.Atoi(r.URL.Query().Get("epic"))
+	for _, task := range tasks {
+		if !task.Epic {
+			continue
+		}
+		done, total := model.EpicProgress(task.ID, tasks)
+		view.Epics = append(view.Epics, epicView{ID: task.ID, Title: task.Title, Done: done, Total: total})
+		if task.ID == epicID {
+			view.Epic = &view.Epics[len(view.Epics)-1]
+		}
+	}
+	if epicID != 0 {
+		if view.Epic == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var children []model.Task
+		for _, task := range tasks {
+			if task.ParentID == epicID {
+				children = append(children, task)
+			}
+		}
+		tasks = children
+	}
+
+	counts, err := h.taskStorage.CountTaskLabels(ctx, prj.ID)
+	if err != nil {
+		h.internalError(w, "could not count labels", err)
+		return
+	}
+	for _, c := range counts {
+		view.Labels = append(view.Labels, labelView{Name: c.Name, Tasks: c.Tasks, Color: labelColor(c.Name)})
+	}
+	if view.Label = strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("label"), "#")); view.Label != "" {
+		var labeled []model.Task
+		for _, task := range tasks {
+			if slices.Contains(task.Labels, view.Label) {
+				labeled = append(labeled, task)
+			}
+		}
+		tasks = labeled
+	}
+	assignees := make(map[int64]string)
+	for _, status := range model.TaskStatuses {
+		if status == model.TaskStatusCancelled {
+			continue
+		}
+
+		col := columnView{Emoji: status.Emoji(), Name: format.Capitalize(status.StringLocalized())}
+		for _, task := range tasks {
+			if task.Status != status {
+				continue
+			}
+
+			tv := taskView{ID: task.ID, Title: task.Title, Epic: task.Epic, Priority: task.Priority.Marker()}
+			for _, label := range task.Labels {
+				tv.Labels = append(tv.Labels, labelView{Name: label, Color: labelColor(label)})
+			}
+			if task.Assignee != 0 {
+				name, ok := assignees[task.Assignee]
+				if !ok {
+					name, err = h.userName(r, int(task.Assignee))
+					if err != nil {
+						h.internalError(w, "could not fetch assignee", err)
+						return
+					}
+					assignees[task.Assignee] = name
+				}
+				tv.Assignee = name
+			}
+			if !task.Deadline.IsZero() {
+				tv.Deadline = task.Deadline.Format(format.DateLayout)
+				tv.Overdue = status.IsOpen() && task.Deadline.Before(now)
+				if status.IsOpen() {
+					tv.Deadline += " · " + format.Days(task.Deadline, now)
+				}
+			}
+			col.Tasks = append(col.Tasks, tv)
+		}
+		view.Columns = append(view.Columns, col)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	// Token is a part of URL, do not leak it to linked sites
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err = boardTemplate.Execute(w, view); err != nil {
+		log.Printf("ERROR public board: could not render template: %s", err)
+	}
+}
+
+// labelColor picks color of label by its name, so label keeps color without storing it.
+func labelColor(name string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return int(hash.Sum32() % labelColors)
+}
+
+func (h *Handler) userName(r *http.Request, userID int) (string, error) {
+	user, err := h.userStorage.FetchUserByID(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return user.FullName, nil
+}
+
+func (h *Handler) internalError(w http.ResponseWriter, msg string, err error) {
+	log.Printf("ERROR public board: %s: %s", msg, err)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
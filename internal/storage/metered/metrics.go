@@ -0,0 +1,120 @@
+// Package metered wraps repositories to record per-method latency, calls and rows returned,
+// and logs calls slower than threshold.
+package metered
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+type methodStats struct {
+	calls  int
+	errors int
+	rows   int
+	total  time.Duration
+	max    time.Duration
+}
+
+// Metrics accumulates statistics of repository calls since start.
+type Metrics struct {
+	slowThreshold time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*methodStats
+}
+
+// NewMetrics creates metrics logging calls slower than slowThreshold, 0 disables logging.
+func NewMetrics(slowThreshold time.Duration) *Metrics {
+	return &Metrics{
+		slowThreshold: slowThreshold,
+		methods:       make(map[string]*methodStats),
+	}
+}
+
+// observe records call which started at start and returned rows.
+func (m *Metrics) observe(method string, start time.Time, rows int, err error) {
+	d := time.Since(start)
+	if m.slowThreshold > 0 && d >= m.slowThreshold {
+		log.Printf("WARN slow storage call %s took %s, rows=%d", method, d.Round(time.Millisecond), rows)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.methods[method]
+	if !ok {
+		s = &methodStats{}
+		m.methods[method] = s
+	}
+	s.calls++
+	if err != nil {
+		s.errors++
+	}
+	s.rows += rows
+	s.total += d
+	s.max = max(s.max, d)
+}
+
+// Report writes table of methods ordered by total time spent, the hottest first.
+func (m *Metrics) Report(w io.Writer) error {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.methods))
+	stats := make(map[string]methodStats, len(m.methods))
+	for name, s := range m.methods {
+		names = append(names, name)
+		stats[name] = *s
+	}
+	m.mu.Unlock()
+
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Compare(stats[b].total, stats[a].total)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "method\tcalls\terrors\trows\ttotal\tavg\tmax\t")
+	for _, name := range names {
+		s := stats[name]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\t\n",
+			name,
+			s.calls,
+			s.errors,
+			s.rows,
+			s.total.Round(time.Millisecond),
+			(s.total / time.Duration(s.calls)).Round(time.Microsecond),
+			s.max.Round(time.Microsecond),
+		)
+	}
+	return tw.Flush()
+}
+
+// StartReporting logs report every interval until context is cancelled.
+func (m *Metrics) StartReporting(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			log.Printf("INFO storage calls since start:")
+			if err := m.Report(log.Writer()); err != nil {
+				log.Printf("ERROR could not write storage report: %s", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// found returns 1 row for fetched item and 0 if it is missing.
+func found[T any](item *T) int {
+	if item == nil {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,557 @@
+package metered
+
+import (
+	"context"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+type Projects struct {
+	model.ProjectRepository
+	metrics *Metrics
+}
+
+func NewProjects(repo model.ProjectRepository, metrics *Metrics) Projects {
+	return Projects{ProjectRepository: repo, metrics: metrics}
+}
+
+func (s Projects) FetchProjectByID(ctx context.Context, id int) (prj *model.Project, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectByID", start, found(prj), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectByID(ctx, id)
+}
+
+func (s Projects) FetchProjectByChatID(ctx context.Context, tgChatID int64) (prj *model.Project, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectByChatID", start, found(prj), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectByChatID(ctx, tgChatID)
+}
+
+func (s Projects) FetchProjectByPublicToken(ctx context.Context, token string) (prj *model.Project, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectByPublicToken", start, found(prj), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectByPublicToken(ctx, token)
+}
+
+func (s Projects) FetchProjectsByUser(ctx context.Context, userID int, role model.UserProjectRole) (prjs []model.Project, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectsByUser", start, len(prjs), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectsByUser(ctx, userID, role)
+}
+
+func (s Projects) ListProjects(ctx context.Context) (prjs []model.Project, err error) {
+	defer func(start time.Time) { s.metrics.observe("ListProjects", start, len(prjs), err) }(time.Now())
+	return s.ProjectRepository.ListProjects(ctx)
+}
+
+func (s Projects) CreateProject(ctx context.Context, project *model.Project) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateProject", start, 0, err) }(time.Now())
+	return s.ProjectRepository.CreateProject(ctx, project)
+}
+
+func (s Projects) UpdateProject(ctx context.Context, project *model.Project) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateProject", start, 0, err) }(time.Now())
+	return s.ProjectRepository.UpdateProject(ctx, project)
+}
+
+func (s Projects) DeleteProject(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("DeleteProject", start, 0, err) }(time.Now())
+	return s.ProjectRepository.DeleteProject(ctx, id)
+}
+
+func (s Projects) FetchProjectHolidays(ctx context.Context, projectID int) (days []time.Time, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectHolidays", start, len(days), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectHolidays(ctx, projectID)
+}
+
+func (s Projects) SetProjectHolidays(ctx context.Context, projectID int, days []time.Time) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetProjectHolidays", start, 0, err) }(time.Now())
+	return s.ProjectRepository.SetProjectHolidays(ctx, projectID, days)
+}
+
+func (s Projects) FetchProjectLinks(ctx context.Context, projectID int) (links []model.ProjectLink, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectLinks", start, len(links), err) }(time.Now())
+	return s.ProjectRepository.FetchProjectLinks(ctx, projectID)
+}
+
+func (s Projects) SaveProjectLink(ctx context.Context, link *model.ProjectLink) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SaveProjectLink", start, 0, err) }(time.Now())
+	return s.ProjectRepository.SaveProjectLink(ctx, link)
+}
+
+func (s Projects) RemoveProjectLink(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RemoveProjectLink", start, 0, err) }(time.Now())
+	return s.ProjectRepository.RemoveProjectLink(ctx, id)
+}
+
+func (s Projects) CountProjectContents(ctx context.Context, id int) (contents model.ProjectContents, err error) {
+	defer func(start time.Time) { s.metrics.observe("CountProjectContents", start, 0, err) }(time.Now())
+	return s.ProjectRepository.CountProjectContents(ctx, id)
+}
+
+type Users struct {
+	model.UserRepository
+	metrics *Metrics
+}
+
+func NewUsers(repo model.UserRepository, metrics *Metrics) Users {
+	return Users{UserRepository: repo, metrics: metrics}
+}
+
+func (s Users) FetchUserByID(ctx context.Context, id int) (user *model.User, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchUserByID", start, found(user), err) }(time.Now())
+	return s.UserRepository.FetchUserByID(ctx, id)
+}
+
+func (s Users) FetchUserByTgID(ctx context.Context, tgUserID int64) (user *model.User, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchUserByTgID", start, found(user), err) }(time.Now())
+	return s.UserRepository.FetchUserByTgID(ctx, tgUserID)
+}
+
+func (s Users) FetchUserByCalDAVToken(ctx context.Context, token string) (user *model.User, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchUserByCalDAVToken", start, found(user), err) }(time.Now())
+	return s.UserRepository.FetchUserByCalDAVToken(ctx, token)
+}
+
+func (s Users) UpdateUserCalDAVToken(ctx context.Context, userID int, token string) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateUserCalDAVToken", start, 0, err) }(time.Now())
+	return s.UserRepository.UpdateUserCalDAVToken(ctx, userID, token)
+}
+
+func (s Users) SetUserUnreachable(ctx context.Context, tgUserID int64, unreachable bool) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetUserUnreachable", start, 0, err) }(time.Now())
+	return s.UserRepository.SetUserUnreachable(ctx, tgUserID, unreachable)
+}
+
+func (s Users) FetchProjectUserByUsername(ctx context.Context, projectID int, username string) (user *model.User, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectUserByUsername", start, found(user), err) }(time.Now())
+	return s.UserRepository.FetchProjectUserByUsername(ctx, projectID, username)
+}
+
+func (s Users) FetchProjectUserByPreviousUsername(ctx context.Context, projectID int, username string) (user *model.User, err error) {
+	defer func(start time.Time) {
+		s.metrics.observe("FetchProjectUserByPreviousUsername", start, found(user), err)
+	}(time.Now())
+	return s.UserRepository.FetchProjectUserByPreviousUsername(ctx, projectID, username)
+}
+
+func (s Users) CreateUser(ctx context.Context, user *model.User) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateUser", start, 0, err) }(time.Now())
+	return s.UserRepository.CreateUser(ctx, user)
+}
+
+func (s Users) UpdateUser(ctx context.Context, user *model.User) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateUser", start, 0, err) }(time.Now())
+	return s.UserRepository.UpdateUser(ctx, user)
+}
+
+func (s Users) AddUserToProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) (err error) {
+	defer func(start time.Time) { s.metrics.observe("AddUserToProject", start, 0, err) }(time.Now())
+	return s.UserRepository.AddUserToProject(ctx, projectID, userID, role)
+}
+
+func (s Users) SetUserRoleInProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetUserRoleInProject", start, 0, err) }(time.Now())
+	return s.UserRepository.SetUserRoleInProject(ctx, projectID, userID, role)
+}
+
+func (s Users) FetchUserRoleInProject(ctx context.Context, projectID int, user *model.User) (err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchUserRoleInProject", start, 0, err) }(time.Now())
+	return s.UserRepository.FetchUserRoleInProject(ctx, projectID, user)
+}
+
+func (s Users) CountUsersInProject(ctx context.Context, projectID int) (n int, err error) {
+	defer func(start time.Time) { s.metrics.observe("CountUsersInProject", start, 1, err) }(time.Now())
+	return s.UserRepository.CountUsersInProject(ctx, projectID)
+}
+
+func (s Users) FetchProjectUsers(ctx context.Context, projectID int) (users []model.User, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectUsers", start, len(users), err) }(time.Now())
+	return s.UserRepository.FetchProjectUsers(ctx, projectID)
+}
+
+type Tasks struct {
+	model.TaskRepository
+	metrics *Metrics
+}
+
+func NewTasks(repo model.TaskRepository, metrics *Metrics) Tasks {
+	return Tasks{TaskRepository: repo, metrics: metrics}
+}
+
+func (s Tasks) FetchTaskByID(ctx context.Context, id int) (task *model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskByID", start, found(task), err) }(time.Now())
+	return s.TaskRepository.FetchTaskByID(ctx, id)
+}
+
+func (s Tasks) FilterTasks(ctx context.Context, filter model.TaskFilter) (tasks []model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FilterTasks", start, len(tasks), err) }(time.Now())
+	return s.TaskRepository.FilterTasks(ctx, filter)
+}
+
+func (s Tasks) CreateTask(ctx context.Context, task *model.Task) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.CreateTask(ctx, task)
+}
+
+func (s Tasks) CreateTasks(ctx context.Context, tasks []*model.Task) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateTasks", start, 0, err) }(time.Now())
+	return s.TaskRepository.CreateTasks(ctx, tasks)
+}
+
+func (s Tasks) UpdateTask(ctx context.Context, task *model.Task) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.UpdateTask(ctx, task)
+}
+
+func (s Tasks) UpdateTasks(ctx context.Context, tasks []*model.Task) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateTasks", start, 0, err) }(time.Now())
+	return s.TaskRepository.UpdateTasks(ctx, tasks)
+}
+
+func (s Tasks) UpdateTasksWithNotifications(ctx context.Context, tasks []*model.Task, notifications []model.Notification) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateTasksWithNotifications", start, 0, err) }(time.Now())
+	return s.TaskRepository.UpdateTasksWithNotifications(ctx, tasks, notifications)
+}
+
+func (s Tasks) SetTaskOverdueNotified(ctx context.Context, id int, notified bool) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetTaskOverdueNotified", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetTaskOverdueNotified(ctx, id, notified)
+}
+
+func (s Tasks) SetTaskEscalated(ctx context.Context, id int, escalated bool) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetTaskEscalated", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetTaskEscalated(ctx, id, escalated)
+}
+
+func (s Tasks) RemoveTask(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RemoveTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.RemoveTask(ctx, id)
+}
+
+func (s Tasks) RestoreTask(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RestoreTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.RestoreTask(ctx, id)
+}
+
+func (s Tasks) FetchDeletedTasks(ctx context.Context, projectID int) (tasks []model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchDeletedTasks", start, len(tasks), err) }(time.Now())
+	return s.TaskRepository.FetchDeletedTasks(ctx, projectID)
+}
+
+func (s Tasks) PurgeTask(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("PurgeTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.PurgeTask(ctx, id)
+}
+
+func (s Tasks) PurgeDeletedTasks(ctx context.Context, before time.Time) (purged int, err error) {
+	defer func(start time.Time) { s.metrics.observe("PurgeDeletedTasks", start, purged, err) }(time.Now())
+	return s.TaskRepository.PurgeDeletedTasks(ctx, before)
+}
+
+func (s Tasks) CountTasksByStatus(ctx context.Context, projectID int) (counts map[model.TaskStatus]int, err error) {
+	defer func(start time.Time) { s.metrics.observe("CountTasksByStatus", start, len(counts), err) }(time.Now())
+	return s.TaskRepository.CountTasksByStatus(ctx, projectID)
+}
+
+func (s Tasks) FetchTaskCounters(ctx context.Context, projectID int, now time.Time) (counters model.TaskCounters, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskCounters", start, 1, err) }(time.Now())
+	return s.TaskRepository.FetchTaskCounters(ctx, projectID, now)
+}
+
+func (s Tasks) FetchUpcomingDeadlines(ctx context.Context, projectID int, limit int) (tasks []model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchUpcomingDeadlines", start, len(tasks), err) }(time.Now())
+	return s.TaskRepository.FetchUpcomingDeadlines(ctx, projectID, limit)
+}
+
+func (s Tasks) FetchChecklist(ctx context.Context, taskID int) (items []model.ChecklistItem, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchChecklist", start, len(items), err) }(time.Now())
+	return s.TaskRepository.FetchChecklist(ctx, taskID)
+}
+
+func (s Tasks) FetchChecklistItem(ctx context.Context, id int) (item *model.ChecklistItem, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchChecklistItem", start, found(item), err) }(time.Now())
+	return s.TaskRepository.FetchChecklistItem(ctx, id)
+}
+
+func (s Tasks) AddChecklistItems(ctx context.Context, items []*model.ChecklistItem) (err error) {
+	defer func(start time.Time) { s.metrics.observe("AddChecklistItems", start, 0, err) }(time.Now())
+	return s.TaskRepository.AddChecklistItems(ctx, items)
+}
+
+func (s Tasks) SetChecklistItemDone(ctx context.Context, id int, done bool) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetChecklistItemDone", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetChecklistItemDone(ctx, id, done)
+}
+
+func (s Tasks) RemoveChecklistItem(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RemoveChecklistItem", start, 0, err) }(time.Now())
+	return s.TaskRepository.RemoveChecklistItem(ctx, id)
+}
+
+func (s Tasks) AddTaskComment(ctx context.Context, comment *model.TaskComment) (err error) {
+	defer func(start time.Time) { s.metrics.observe("AddTaskComment", start, 0, err) }(time.Now())
+	return s.TaskRepository.AddTaskComment(ctx, comment)
+}
+
+func (s Tasks) FetchTaskComments(ctx context.Context, taskID int, limit int) (comments []model.TaskComment, total int, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskComments", start, len(comments), err) }(time.Now())
+	return s.TaskRepository.FetchTaskComments(ctx, taskID, limit)
+}
+
+func (s Tasks) AddTaskAttachment(ctx context.Context, attachment *model.TaskAttachment) (err error) {
+	defer func(start time.Time) { s.metrics.observe("AddTaskAttachment", start, 0, err) }(time.Now())
+	return s.TaskRepository.AddTaskAttachment(ctx, attachment)
+}
+
+func (s Tasks) FetchTaskAttachments(ctx context.Context, taskID int) (attachments []model.TaskAttachment, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskAttachments", start, len(attachments), err) }(time.Now())
+	return s.TaskRepository.FetchTaskAttachments(ctx, taskID)
+}
+
+func (s Tasks) FetchTaskAttachment(ctx context.Context, id int) (attachment *model.TaskAttachment, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskAttachment", start, found(attachment), err) }(time.Now())
+	return s.TaskRepository.FetchTaskAttachment(ctx, id)
+}
+
+func (s Tasks) SetTaskWatcher(ctx context.Context, taskID int, userID int64, watching bool) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetTaskWatcher", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetTaskWatcher(ctx, taskID, userID, watching)
+}
+
+func (s Tasks) FetchTaskWatchers(ctx context.Context, taskID int) (watchers []int64, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskWatchers", start, len(watchers), err) }(time.Now())
+	return s.TaskRepository.FetchTaskWatchers(ctx, taskID)
+}
+
+func (s Tasks) CountTaskLabels(ctx context.Context, projectID int) (counts []model.LabelCount, err error) {
+	defer func(start time.Time) { s.metrics.observe("CountTaskLabels", start, len(counts), err) }(time.Now())
+	return s.TaskRepository.CountTaskLabels(ctx, projectID)
+}
+
+func (s Tasks) CreateAlertTask(ctx context.Context, task *model.Task, fingerprint string) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateAlertTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.CreateAlertTask(ctx, task, fingerprint)
+}
+
+func (s Tasks) FetchAlertTask(ctx context.Context, projectID int, fingerprint string) (task *model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchAlertTask", start, found(task), err) }(time.Now())
+	return s.TaskRepository.FetchAlertTask(ctx, projectID, fingerprint)
+}
+
+func (s Tasks) SetTaskBlockers(ctx context.Context, taskID int, blockerIDs []int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetTaskBlockers", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetTaskBlockers(ctx, taskID, blockerIDs)
+}
+
+func (s Tasks) FetchTaskBlockers(ctx context.Context, taskID int) (tasks []model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskBlockers", start, len(tasks), err) }(time.Now())
+	return s.TaskRepository.FetchTaskBlockers(ctx, taskID)
+}
+
+func (s Tasks) FetchBlockedTasks(ctx context.Context, blockerID int) (tasks []model.Task, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchBlockedTasks", start, len(tasks), err) }(time.Now())
+	return s.TaskRepository.FetchBlockedTasks(ctx, blockerID)
+}
+
+func (s Tasks) SetTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SetTaskRecurrence", start, 0, err) }(time.Now())
+	return s.TaskRepository.SetTaskRecurrence(ctx, rec)
+}
+
+func (s Tasks) RemoveTaskRecurrence(ctx context.Context, taskID int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RemoveTaskRecurrence", start, 0, err) }(time.Now())
+	return s.TaskRepository.RemoveTaskRecurrence(ctx, taskID)
+}
+
+func (s Tasks) FetchTaskRecurrence(ctx context.Context, taskID int) (rec *model.TaskRecurrence, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskRecurrence", start, found(rec), err) }(time.Now())
+	return s.TaskRepository.FetchTaskRecurrence(ctx, taskID)
+}
+
+func (s Tasks) FetchProjectRecurrences(ctx context.Context, projectID int) (recs []model.TaskRecurrence, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchProjectRecurrences", start, len(recs), err) }(time.Now())
+	return s.TaskRepository.FetchProjectRecurrences(ctx, projectID)
+}
+
+func (s Tasks) FetchDueRecurrences(ctx context.Context, now time.Time) (recs []model.TaskRecurrence, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchDueRecurrences", start, len(recs), err) }(time.Now())
+	return s.TaskRepository.FetchDueRecurrences(ctx, now)
+}
+
+func (s Tasks) RenewTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence, next *model.Task) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RenewTaskRecurrence", start, 0, err) }(time.Now())
+	return s.TaskRepository.RenewTaskRecurrence(ctx, rec, next)
+}
+
+func (s Tasks) SaveTaskTemplate(ctx context.Context, tpl *model.TaskTemplate) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SaveTaskTemplate", start, 0, err) }(time.Now())
+	return s.TaskRepository.SaveTaskTemplate(ctx, tpl)
+}
+
+func (s Tasks) FetchTaskTemplates(ctx context.Context, projectID int) (templates []model.TaskTemplate, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskTemplates", start, len(templates), err) }(time.Now())
+	return s.TaskRepository.FetchTaskTemplates(ctx, projectID)
+}
+
+func (s Tasks) FetchTaskTemplate(ctx context.Context, id int) (tpl *model.TaskTemplate, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskTemplate", start, found(tpl), err) }(time.Now())
+	return s.TaskRepository.FetchTaskTemplate(ctx, id)
+}
+
+func (s Tasks) RemoveTaskTemplate(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RemoveTaskTemplate", start, 0, err) }(time.Now())
+	return s.TaskRepository.RemoveTaskTemplate(ctx, id)
+}
+
+func (s Tasks) SnapshotTaskCounts(ctx context.Context, day time.Time) (err error) {
+	defer func(start time.Time) { s.metrics.observe("SnapshotTaskCounts", start, 0, err) }(time.Now())
+	return s.TaskRepository.SnapshotTaskCounts(ctx, day)
+}
+
+func (s Tasks) FetchTaskSnapshots(ctx context.Context, projectID int, from, to time.Time) (snapshots []model.TaskSnapshot, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskSnapshots", start, len(snapshots), err) }(time.Now())
+	return s.TaskRepository.FetchTaskSnapshots(ctx, projectID, from, to)
+}
+
+func (s Tasks) FetchTaskHistory(ctx context.Context, taskID int, limit int) (changes []model.TaskChange, total int, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskHistory", start, len(changes), err) }(time.Now())
+	return s.TaskRepository.FetchTaskHistory(ctx, taskID, limit)
+}
+
+func (s Tasks) MoveTask(ctx context.Context, id int, move model.TaskMove) (err error) {
+	defer func(start time.Time) { s.metrics.observe("MoveTask", start, 0, err) }(time.Now())
+	return s.TaskRepository.MoveTask(ctx, id, move)
+}
+
+type Outbox struct {
+	model.OutboxRepository
+	metrics *Metrics
+}
+
+func NewOutbox(repo model.OutboxRepository, metrics *Metrics) Outbox {
+	return Outbox{OutboxRepository: repo, metrics: metrics}
+}
+
+func (s Outbox) EnqueueNotifications(ctx context.Context, notifications []model.Notification) (err error) {
+	defer func(start time.Time) { s.metrics.observe("EnqueueNotifications", start, 0, err) }(time.Now())
+	return s.OutboxRepository.EnqueueNotifications(ctx, notifications)
+}
+
+func (s Outbox) FetchDueNotifications(ctx context.Context, now time.Time, limit int) (notifications []model.Notification, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchDueNotifications", start, len(notifications), err) }(time.Now())
+	return s.OutboxRepository.FetchDueNotifications(ctx, now, limit)
+}
+
+func (s Outbox) UpdateNotification(ctx context.Context, n *model.Notification) (err error) {
+	defer func(start time.Time) { s.metrics.observe("UpdateNotification", start, 0, err) }(time.Now())
+	return s.OutboxRepository.UpdateNotification(ctx, n)
+}
+
+func (s Outbox) DeleteNotification(ctx context.Context, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("DeleteNotification", start, 0, err) }(time.Now())
+	return s.OutboxRepository.DeleteNotification(ctx, id)
+}
+
+func (s Outbox) ListDeadNotifications(ctx context.Context) (notifications []model.Notification, err error) {
+	defer func(start time.Time) { s.metrics.observe("ListDeadNotifications", start, len(notifications), err) }(time.Now())
+	return s.OutboxRepository.ListDeadNotifications(ctx)
+}
+
+func (s Outbox) RequeueDeadNotification(ctx context.Context, id int, now time.Time) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RequeueDeadNotification", start, 0, err) }(time.Now())
+	return s.OutboxRepository.RequeueDeadNotification(ctx, id, now)
+}
+
+type APITokens struct {
+	model.APITokenRepository
+	metrics *Metrics
+}
+
+func NewAPITokens(repo model.APITokenRepository, metrics *Metrics) APITokens {
+	return APITokens{APITokenRepository: repo, metrics: metrics}
+}
+
+func (s APITokens) CreateAPIToken(ctx context.Context, token *model.APIToken) (err error) {
+	defer func(start time.Time) { s.metrics.observe("CreateAPIToken", start, 0, err) }(time.Now())
+	return s.APITokenRepository.CreateAPIToken(ctx, token)
+}
+
+func (s APITokens) ListAPITokens(ctx context.Context, projectID int) (tokens []model.APIToken, err error) {
+	defer func(start time.Time) { s.metrics.observe("ListAPITokens", start, len(tokens), err) }(time.Now())
+	return s.APITokenRepository.ListAPITokens(ctx, projectID)
+}
+
+func (s APITokens) FetchAPITokenByHash(ctx context.Context, hash string) (token *model.APIToken, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchAPITokenByHash", start, found(token), err) }(time.Now())
+	return s.APITokenRepository.FetchAPITokenByHash(ctx, hash)
+}
+
+func (s APITokens) TouchAPIToken(ctx context.Context, id int, t time.Time) (err error) {
+	defer func(start time.Time) { s.metrics.observe("TouchAPIToken", start, 0, err) }(time.Now())
+	return s.APITokenRepository.TouchAPIToken(ctx, id, t)
+}
+
+func (s APITokens) RevokeAPIToken(ctx context.Context, projectID, id int) (err error) {
+	defer func(start time.Time) { s.metrics.observe("RevokeAPIToken", start, 0, err) }(time.Now())
+	return s.APITokenRepository.RevokeAPIToken(ctx, projectID, id)
+}
+
+type Leases struct {
+	model.LeaseRepository
+	metrics *Metrics
+}
+
+func NewLeases(repo model.LeaseRepository, metrics *Metrics) Leases {
+	return Leases{LeaseRepository: repo, metrics: metrics}
+}
+
+func (s Leases) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (ok bool, err error) {
+	defer func(start time.Time) { s.metrics.observe("AcquireLease", start, 0, err) }(time.Now())
+	return s.LeaseRepository.AcquireLease(ctx, name, holder, ttl)
+}
+
+type Maintenance struct {
+	model.MaintenanceRepository
+	metrics *Metrics
+}
+
+func NewMaintenance(repo model.MaintenanceRepository, metrics *Metrics) Maintenance {
+	return Maintenance{MaintenanceRepository: repo, metrics: metrics}
+}
+
+func (s Maintenance) DeleteOrphanUsers(ctx context.Context, before time.Time) (deleted int, err error) {
+	defer func(start time.Time) { s.metrics.observe("DeleteOrphanUsers", start, deleted, err) }(time.Now())
+	return s.MaintenanceRepository.DeleteOrphanUsers(ctx, before)
+}
+
+func (s Maintenance) Compact(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.metrics.observe("Compact", start, 0, err) }(time.Now())
+	return s.MaintenanceRepository.Compact(ctx)
+}
+
+type TaskEventLog struct {
+	model.TaskEventLogRepository
+	metrics *Metrics
+}
+
+func NewTaskEventLog(repo model.TaskEventLogRepository, metrics *Metrics) TaskEventLog {
+	return TaskEventLog{TaskEventLogRepository: repo, metrics: metrics}
+}
+
+func (s TaskEventLog) AppendTaskEvent(ctx context.Context, event model.TaskEvent) (err error) {
+	defer func(start time.Time) { s.metrics.observe("AppendTaskEvent", start, 0, err) }(time.Now())
+	return s.TaskEventLogRepository.AppendTaskEvent(ctx, event)
+}
+
+func (s TaskEventLog) FetchTaskEvents(ctx context.Context, afterID, limit int) (events []model.LoggedTaskEvent, err error) {
+	defer func(start time.Time) { s.metrics.observe("FetchTaskEvents", start, len(events), err) }(time.Now())
+	return s.TaskEventLogRepository.FetchTaskEvents(ctx, afterID, limit)
+}
+
+func (s TaskEventLog) LastTaskEventID(ctx context.Context) (id int, err error) {
+	defer func(start time.Time) { s.metrics.observe("LastTaskEventID", start, 0, err) }(time.Now())
+	return s.TaskEventLogRepository.LastTaskEventID(ctx)
+}
+
+func (s TaskEventLog) DeleteTaskEvents(ctx context.Context, before time.Time) (deleted int, err error) {
+	defer func(start time.Time) { s.metrics.observe("DeleteTaskEvents", start, deleted, err) }(time.Now())
+	return s.TaskEventLogRepository.DeleteTaskEvents(ctx, before)
+}
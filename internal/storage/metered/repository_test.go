@@ -0,0 +1,68 @@
+package metered
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// Wrappers embed repository interfaces, so method without explicit wrapper still compiles
+// but goes unmeasured.
+func TestWrappersCoverRepositories(t *testing.T) {
+	wrappers := map[string]reflect.Type{
+		"Projects":     reflect.TypeFor[model.ProjectRepository](),
+		"Users":        reflect.TypeFor[model.UserRepository](),
+		"Tasks":        reflect.TypeFor[model.TaskRepository](),
+		"Outbox":       reflect.TypeFor[model.OutboxRepository](),
+		"APITokens":    reflect.TypeFor[model.APITokenRepository](),
+		"Leases":       reflect.TypeFor[model.LeaseRepository](),
+		"Maintenance":  reflect.TypeFor[model.MaintenanceRepository](),
+		"TaskEventLog": reflect.TypeFor[model.TaskEventLogRepository](),
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "repository.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := make(map[string]map[string]bool)
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec, ok := spec.(*ast.TypeSpec); ok {
+					if _, ok := wrappers[spec.Name.Name]; !ok {
+						t.Errorf("type %s is not checked", spec.Name.Name)
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				continue
+			}
+			recv := decl.Recv.List[0].Type.(*ast.Ident).Name
+			if declared[recv] == nil {
+				declared[recv] = make(map[string]bool)
+			}
+			declared[recv][decl.Name.Name] = true
+		}
+	}
+
+	methods := make(map[string]string)
+	for name, iface := range wrappers {
+		for i := 0; i < iface.NumMethod(); i++ {
+			method := iface.Method(i).Name
+			if !declared[name][method] {
+				t.Errorf("%s.%s has no wrapper", name, method)
+			}
+			// Metrics are keyed by method name
+			if other, ok := methods[method]; ok {
+				t.Errorf("%s.%s is measured together with %s.%s", name, method, other, method)
+			}
+			methods[method] = name
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// CreateAlertTask checks open task of the alert in the same transaction, so alert delivered by concurrent
+// requests gets single task.
+func (s *TaskStorage) CreateAlertTask(ctx context.Context, task *model.Task, fingerprint string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const openQ = `SELECT COUNT(*) FROM alert_tasks JOIN tasks ON tasks.id = alert_tasks.task_id
+	WHERE alert_tasks.project_id = ? AND fingerprint = ? AND status NOT IN (?, ?) AND deleted_at IS NULL`
+	var open int
+	err = tx.QueryRowContext(ctx, openQ, task.ProjectID, fingerprint, model.TaskStatusDone, model.TaskStatusCancelled).Scan(&open)
+	if err != nil {
+		return err
+	}
+	if open > 0 {
+		return model.ErrAlertTaskExists
+	}
+
+	if err = createTask(ctx, tx, task); err != nil {
+		return err
+	}
+	const linkQ = `INSERT INTO alert_tasks (project_id, fingerprint, task_id) VALUES (?, ?, ?)
+	ON CONFLICT (project_id, fingerprint) DO UPDATE SET task_id = excluded.task_id`
+	if _, err = tx.ExecContext(ctx, linkQ, task.ProjectID, fingerprint, task.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *TaskStorage) FetchAlertTask(ctx context.Context, projectID int, fingerprint string) (*model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks
+	WHERE id = (SELECT task_id FROM alert_tasks WHERE project_id = ? AND fingerprint = ?) AND deleted_at IS NULL`
+	task, err := scanTask(s.reader.QueryRowContext(ctx, q, projectID, fingerprint))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+type APITokenStorage struct {
+	db *sql.DB
+}
+
+func NewAPITokenStorage(db *sql.DB) *APITokenStorage {
+	return &APITokenStorage{db: db}
+}
+
+func (s *APITokenStorage) CreateAPIToken(ctx context.Context, token *model.APIToken) error {
+	const q = `INSERT INTO api_tokens (project_id, token_hash, prefix, scope, created_by, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	createdAt := now()
+	result, err := s.db.ExecContext(ctx, q,
+		token.ProjectID,
+		token.Hash,
+		token.Prefix,
+		token.Scope,
+		token.CreatedBy,
+		formatTime(createdAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	token.ID = int(id)
+	token.CreatedAt = createdAt
+	return nil
+}
+
+const apiTokenColumns = `id, project_id, token_hash, prefix, scope, created_by, created_at, last_used_at`
+
+func (s *APITokenStorage) ListAPITokens(ctx context.Context, projectID int) ([]model.APIToken, error) {
+	const q = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE project_id = ? ORDER BY id`
+	rows, err := s.db.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []model.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *APITokenStorage) FetchAPITokenByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	const q = `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+	token, err := scanAPIToken(s.db.QueryRowContext(ctx, q, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+func scanAPIToken(row rowScanner) (*model.APIToken, error) {
+	var (
+		token                 model.APIToken
+		createdAt, lastUsedAt sql.NullString
+	)
+	err := row.Scan(
+		&token.ID,
+		&token.ProjectID,
+		&token.Hash,
+		&token.Prefix,
+		&token.Scope,
+		&token.CreatedBy,
+		&createdAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if token.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if token.LastUsedAt, err = parseTime(lastUsedAt); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *APITokenStorage) TouchAPIToken(ctx context.Context, id int, t time.Time) error {
+	const q = `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q, formatTime(t), id)
+	return err
+}
+
+func (s *APITokenStorage) RevokeAPIToken(ctx context.Context, projectID, id int) error {
+	const q = `DELETE FROM api_tokens WHERE id = ? AND project_id = ?`
+	result, err := s.db.ExecContext(ctx, q, id, projectID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrAPITokenNotFound
+	}
+	return nil
+}
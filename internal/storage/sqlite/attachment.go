@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func (s *TaskStorage) AddTaskAttachment(ctx context.Context, attachment *model.TaskAttachment) error {
+	const q = `INSERT INTO task_attachments (task_id, kind, file_id, file_name, added_by, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	createdAt := now()
+	result, err := s.db.ExecContext(ctx, q,
+		attachment.TaskID,
+		attachment.Kind,
+		attachment.FileID,
+		attachment.FileName,
+		attachment.AddedBy,
+		formatTime(createdAt),
+	)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	attachment.ID = int(id)
+	attachment.CreatedAt = createdAt
+	return nil
+}
+
+const attachmentColumns = `id, task_id, kind, file_id, file_name, added_by, created_at`
+
+func (s *TaskStorage) FetchTaskAttachments(ctx context.Context, taskID int) ([]model.TaskAttachment, error) {
+	const q = `SELECT ` + attachmentColumns + ` FROM task_attachments WHERE task_id = ? ORDER BY id`
+	rows, err := s.reader.QueryContext(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []model.TaskAttachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (s *TaskStorage) FetchTaskAttachment(ctx context.Context, id int) (*model.TaskAttachment, error) {
+	const q = `SELECT ` + attachmentColumns + ` FROM task_attachments WHERE id = ?`
+	attachment, err := scanAttachment(s.reader.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func scanAttachment(row rowScanner) (*model.TaskAttachment, error) {
+	var (
+		attachment model.TaskAttachment
+		createdAt  sql.NullString
+	)
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.TaskID,
+		&attachment.Kind,
+		&attachment.FileID,
+		&attachment.FileName,
+		&attachment.AddedBy,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func (s *TaskStorage) FetchChecklist(ctx context.Context, taskID int) ([]model.ChecklistItem, error) {
+	const q = `SELECT id, task_id, text, done FROM checklist_items WHERE task_id = ? ORDER BY id`
+	rows, err := s.reader.QueryContext(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ChecklistItem
+	for rows.Next() {
+		var item model.ChecklistItem
+		if err = rows.Scan(&item.ID, &item.TaskID, &item.Text, &item.Done); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (s *TaskStorage) FetchChecklistItem(ctx context.Context, id int) (*model.ChecklistItem, error) {
+	const q = `SELECT id, task_id, text, done FROM checklist_items WHERE id = ?`
+	var item model.ChecklistItem
+	err := s.reader.QueryRowContext(ctx, q, id).Scan(&item.ID, &item.TaskID, &item.Text, &item.Done)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrChecklistItemNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (s *TaskStorage) AddChecklistItems(ctx context.Context, items []*model.ChecklistItem) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		result, err := tx.ExecContext(ctx, `INSERT INTO checklist_items (task_id, text, done) VALUES (?, ?, ?)`, item.TaskID, item.Text, item.Done)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		item.ID = int(id)
+	}
+	return tx.Commit()
+}
+
+func (s *TaskStorage) SetChecklistItemDone(ctx context.Context, id int, done bool) error {
+	return s.execChecklistItem(ctx, `UPDATE checklist_items SET done = ? WHERE id = ?`, done, id)
+}
+
+func (s *TaskStorage) RemoveChecklistItem(ctx context.Context, id int) error {
+	return s.execChecklistItem(ctx, `DELETE FROM checklist_items WHERE id = ?`, id)
+}
+
+// execChecklistItem runs statement changing one item, ErrChecklistItemNotFound is returned if nothing changed.
+func (s *TaskStorage) execChecklistItem(ctx context.Context, q string, args ...interface{}) error {
+	result, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrChecklistItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !sqlcipher
+
+package sqlite
+
+import "database/sql"
+
+func openEncrypted(string, string, bool) (*sql.DB, error) {
+	return nil, ErrEncryptionUnsupported
+}
@@ -0,0 +1,29 @@
+//go:build sqlcipher
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	// SQLCipher needs cgo, driver is registered as "sqlite3" and doesn't clash with the default one.
+	_ "github.com/mutecomm/go-sqlcipher/v4"
+)
+
+func openEncrypted(path, key string, readOnly bool) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma_key=%s&_busy_timeout=%d", path, url.QueryEscape(key), busyTimeout.Milliseconds())
+	if readOnly {
+		dsn += "&mode=ro"
+	}
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+	// Wrong key is noticed only when pages are read.
+	if _, err = db.Exec(`SELECT count(*) FROM sqlite_master`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not read encrypted database, check the key: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,56 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func (s *TaskStorage) AddTaskComment(ctx context.Context, comment *model.TaskComment) error {
+	const q = `INSERT INTO task_comments (task_id, author_id, text, created_at) VALUES (?, ?, ?, ?)`
+	createdAt := now()
+	result, err := s.db.ExecContext(ctx, q, comment.TaskID, comment.AuthorID, comment.Text, formatTime(createdAt))
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	comment.ID = int(id)
+	comment.CreatedAt = createdAt
+	return nil
+}
+
+func (s *TaskStorage) FetchTaskComments(ctx context.Context, taskID int, limit int) ([]model.TaskComment, int, error) {
+	var total int
+	if err := s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM task_comments WHERE task_id = ?`, taskID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	const q = `SELECT id, task_id, author_id, text, created_at FROM (
+		SELECT id, task_id, author_id, text, created_at FROM task_comments WHERE task_id = ? ORDER BY id DESC LIMIT ?
+	) ORDER BY id`
+	rows, err := s.reader.QueryContext(ctx, q, taskID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var comments []model.TaskComment
+	for rows.Next() {
+		var (
+			comment   model.TaskComment
+			createdAt sql.NullString
+		)
+		if err = rows.Scan(&comment.ID, &comment.TaskID, &comment.AuthorID, &comment.Text, &createdAt); err != nil {
+			return nil, 0, err
+		}
+		if comment.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, total, rows.Err()
+}
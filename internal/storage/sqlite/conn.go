@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agalitsyn/sqlite"
+)
+
+// busyTimeout is how long a statement waits for lock held by another process, e.g. replay copying database.
+const busyTimeout = 5 * time.Second
+
+// ErrEncryptionUnsupported is returned for database key when binary is built without "sqlcipher" tag.
+var ErrEncryptionUnsupported = errors.New("database encryption requires binary built with -tags sqlcipher")
+
+// Connect opens database with single connection, so concurrent handlers take turns
+// instead of failing with "database is locked". SQLite allows only one writer anyway.
+func Connect(path string) (*sql.DB, error) {
+	db, err := sqlite.Connect(fmt.Sprintf("%s?_pragma=busy_timeout(%d)", path, busyTimeout.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+	keepSingleConn(db)
+	return db, nil
+}
+
+// ConnectEncrypted opens database encrypted by SQLCipher with key, database without key is opened by Connect.
+// New database is encrypted on creation, existing plain one has to be exported first, see README.
+func ConnectEncrypted(path, key string) (*sql.DB, error) {
+	if key == "" {
+		return Connect(path)
+	}
+	db, err := openEncrypted(path, key, false)
+	if err != nil {
+		return nil, err
+	}
+	keepSingleConn(db)
+	return db, nil
+}
+
+// ConnectReader switches database opened by writer to WAL mode and opens pool of conns read-only connections to it
+// with the same key. In WAL mode readers don't wait for the writer and see changes it committed,
+// so long reports don't hold handlers changing tasks.
+func ConnectReader(writer *sql.DB, path, key string, conns int) (*sql.DB, error) {
+	// Mode is kept in database file, in-memory database stays in its own mode
+	var mode string
+	if err := writer.QueryRow(`PRAGMA journal_mode = WAL`).Scan(&mode); err != nil {
+		return nil, fmt.Errorf("could not switch database to WAL mode: %w", err)
+	}
+	if mode != "wal" {
+		return nil, fmt.Errorf("database can't be switched to WAL mode, it is in %s mode", mode)
+	}
+
+	var (
+		db  *sql.DB
+		err error
+	)
+	if key == "" {
+		db, err = sqlite.Connect(fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=query_only(1)", path, busyTimeout.Milliseconds()))
+	} else {
+		db, err = openEncrypted(path, key, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(conns)
+	return db, nil
+}
+
+func keepSingleConn(db *sql.DB) {
+	db.SetMaxOpenConns(1)
+	// Keep the connection open, pragmas are set per connection.
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+}
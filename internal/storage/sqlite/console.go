@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// ErrMultipleStatements is returned for console query containing more than one statement.
+var ErrMultipleStatements = errors.New("query must contain single statement")
+
+// ConsoleStorage runs ad-hoc queries of operators, db must be opened read-only, see ConnectReader.
+type ConsoleStorage struct {
+	db *sql.DB
+}
+
+func NewConsoleStorage(db *sql.DB) *ConsoleStorage {
+	return &ConsoleStorage{db: db}
+}
+
+// Query runs single statement query on read-only connection, so statements changing database fail.
+// Driver runs every statement of the string, so the rest is rejected instead of being executed
+// past the check of the first one.
+//
+// Driver interrupts statement on cancel of ctx only while the first row is computed, so result is
+// materialized before it is read and query running too long is stopped wherever it spends the time.
+func (s *ConsoleStorage) Query(ctx context.Context, query string, limit int) (*model.QueryResult, error) {
+	query, err := singleStatement(query)
+	if err != nil {
+		return nil, err
+	}
+	// Query may end with line comment, so it is closed on the next line
+	query = fmt.Sprintf("WITH console AS MATERIALIZED (SELECT * FROM (\n%s\n) LIMIT %d) SELECT * FROM console", query, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := &model.QueryResult{Columns: columns}
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if len(res.Rows) == limit {
+			res.Truncated = true
+			break
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			switch v := v.(type) {
+			case nil:
+				row[i] = "NULL"
+			case []byte:
+				row[i] = string(v)
+			default:
+				row[i] = fmt.Sprint(v)
+			}
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
+
+// singleStatement returns query without trailing semicolons or ErrMultipleStatements if anything but
+// whitespace and comments follows the first semicolon outside of literals, identifiers and comments.
+func singleStatement(query string) (string, error) {
+	end := -1
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		comment := strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "/*")
+		if end >= 0 && !comment && c != ';' && c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return "", ErrMultipleStatements
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			// Quote inside literal is escaped by doubling, so it reads as closing and opening one.
+			if j := strings.IndexByte(query[i+1:], c); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(query)
+			}
+		case c == '[':
+			if j := strings.IndexByte(query[i+1:], ']'); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(query)
+			}
+		case comment && c == '-':
+			if j := strings.IndexByte(query[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(query)
+			}
+		case comment:
+			if j := strings.Index(query[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(query)
+			}
+		case c == ';' && end < 0:
+			end = i
+		}
+	}
+	if end < 0 {
+		return query, nil
+	}
+	return query[:end], nil
+}
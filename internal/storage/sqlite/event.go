@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+type TaskEventLogStorage struct {
+	db *sql.DB
+	// reader runs queries outside of transactions, see ConnectReader.
+	reader *sql.DB
+}
+
+// NewTaskEventLogStorage returns storage writing through db and reading through reader, which may be db itself.
+func NewTaskEventLogStorage(db, reader *sql.DB) *TaskEventLogStorage {
+	return &TaskEventLogStorage{db: db, reader: reader}
+}
+
+func (s *TaskEventLogStorage) AppendTaskEvent(ctx context.Context, event model.TaskEvent) error {
+	const q = `INSERT INTO task_events
+	(type, task_id, project_id, title, status, priority, deadline, epic, parent_id, actor_id, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	task := event.Task
+	_, err := s.db.ExecContext(ctx, q,
+		event.Type,
+		task.ID,
+		task.ProjectID,
+		task.Title,
+		task.Status,
+		task.Priority,
+		nullTime(task.Deadline),
+		task.Epic,
+		task.ParentID,
+		event.ActorID,
+		formatTime(now()),
+	)
+	return err
+}
+
+func (s *TaskEventLogStorage) FetchTaskEvents(ctx context.Context, afterID, limit int) ([]model.LoggedTaskEvent, error) {
+	const q = `SELECT id, type, task_id, project_id, title, status, priority, deadline, epic, parent_id, actor_id
+	FROM task_events WHERE id > ? ORDER BY id LIMIT ?`
+	rows, err := s.reader.QueryContext(ctx, q, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.LoggedTaskEvent
+	for rows.Next() {
+		var (
+			e        model.LoggedTaskEvent
+			deadline sql.NullString
+		)
+		task := &e.Event.Task
+		if err = rows.Scan(&e.ID, &e.Event.Type, &task.ID, &task.ProjectID, &task.Title, &task.Status,
+			&task.Priority, &deadline, &task.Epic, &task.ParentID, &e.Event.ActorID); err != nil {
+			return nil, err
+		}
+		if task.Deadline, err = parseTime(deadline); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (s *TaskEventLogStorage) LastTaskEventID(ctx context.Context) (int, error) {
+	var id int
+	err := s.reader.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM task_events`).Scan(&id)
+	return id, err
+}
+
+func (s *TaskEventLogStorage) DeleteTaskEvents(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM task_events WHERE created_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// recordTaskHistory saves changes of task fields compared to its saved version, it must run before the update.
+func recordTaskHistory(ctx context.Context, tx *sql.Tx, task *model.Task, changedAt time.Time) error {
+	const selectQuery = `SELECT ` + taskFields + ` FROM tasks WHERE id = ?`
+	prev, err := scanTask(tx.QueryRowContext(ctx, selectQuery, task.ID))
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	const q = `INSERT INTO task_history (task_id, user_id, field, old_value, new_value, changed_at) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, change := range model.DiffTasks(prev, task) {
+		_, err = tx.ExecContext(ctx, q, change.TaskID, change.UserID, change.Field, change.OldValue, change.NewValue, formatTime(changedAt))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskStorage) FetchTaskHistory(ctx context.Context, taskID int, limit int) ([]model.TaskChange, int, error) {
+	var total int
+	if err := s.reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM task_history WHERE task_id = ?`, taskID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	const q = `SELECT id, task_id, user_id, field, old_value, new_value, changed_at FROM (
+		SELECT id, task_id, user_id, field, old_value, new_value, changed_at FROM task_history WHERE task_id = ? ORDER BY id DESC LIMIT ?
+	) ORDER BY id`
+	rows, err := s.reader.QueryContext(ctx, q, taskID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var changes []model.TaskChange
+	for rows.Next() {
+		var (
+			change    model.TaskChange
+			changedAt sql.NullString
+		)
+		err = rows.Scan(&change.ID, &change.TaskID, &change.UserID, &change.Field, &change.OldValue, &change.NewValue, &changedAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		if change.ChangedAt, err = parseTime(changedAt); err != nil {
+			return nil, 0, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, total, rows.Err()
+}
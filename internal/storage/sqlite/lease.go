@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type LeaseStorage struct {
+	db *sql.DB
+}
+
+func NewLeaseStorage(db *sql.DB) *LeaseStorage {
+	return &LeaseStorage{db: db}
+}
+
+func (s *LeaseStorage) AcquireLease(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	const q = `INSERT INTO job_leases (name, holder, expires_at) VALUES (?, ?, ?)
+	ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+	WHERE job_leases.holder = excluded.holder OR job_leases.expires_at <= ?`
+	t := now()
+	result, err := s.db.ExecContext(ctx, q, name, holder, formatTime(t.Add(ttl)), formatTime(t))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func (s *TaskStorage) SetTaskBlockers(ctx context.Context, taskID int, blockerIDs []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_links WHERE task_id = ?`, taskID); err != nil {
+		return err
+	}
+	for _, blockerID := range blockerIDs {
+		const q = `INSERT OR IGNORE INTO task_links (task_id, blocker_id) VALUES (?, ?)`
+		if _, err = tx.ExecContext(ctx, q, taskID, blockerID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *TaskStorage) FetchTaskBlockers(ctx context.Context, taskID int) ([]model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks
+	WHERE id IN (SELECT blocker_id FROM task_links WHERE task_id = ?) AND deleted_at IS NULL
+	ORDER BY rank, id`
+	return s.queryTasks(ctx, q, taskID)
+}
+
+func (s *TaskStorage) FetchBlockedTasks(ctx context.Context, blockerID int) ([]model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks
+	WHERE id IN (SELECT task_id FROM task_links WHERE blocker_id = ?) AND deleted_at IS NULL
+	ORDER BY rank, id`
+	return s.queryTasks(ctx, q, blockerID)
+}
+
+func (s *TaskStorage) queryTasks(ctx context.Context, q string, args ...interface{}) ([]model.Task, error) {
+	rows, err := s.reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTasks(rows)
+}
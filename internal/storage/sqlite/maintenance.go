@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"time"
+)
+
+type MaintenanceStorage struct {
+	db *sql.DB
+}
+
+func NewMaintenanceStorage(db *sql.DB) *MaintenanceStorage {
+	return &MaintenanceStorage{db: db}
+}
+
+// orphanUsers selects users who are not members of any project since before time given as the only argument
+// and are not anonymized yet, Telegram ids are positive, so anonymized users get negative ones.
+const orphanUsers = `SELECT id FROM users WHERE tg_user_id > 0 AND orphaned_at <= ?1
+	AND id NOT IN (SELECT user_id FROM user_projects)`
+
+func (s *MaintenanceStorage) DeleteOrphanUsers(ctx context.Context, before time.Time) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Users who joined project again get grace period from scratch when they leave
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET orphaned_at = NULL
+	WHERE orphaned_at IS NOT NULL AND id IN (SELECT user_id FROM user_projects)`); err != nil {
+		return 0, err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET orphaned_at = ?
+	WHERE orphaned_at IS NULL AND tg_user_id > 0 AND id NOT IN (SELECT user_id FROM user_projects)`, formatTime(now())); err != nil {
+		return 0, err
+	}
+
+	// Foreign keys are not enforced, so data of users is deleted explicitly
+	for _, q := range []string{
+		`DELETE FROM user_usernames WHERE user_id IN (` + orphanUsers + `)`,
+		`DELETE FROM task_watchers WHERE user_id IN (` + orphanUsers + `)`,
+		`DELETE FROM task_assignees WHERE user_id IN (` + orphanUsers + `)`,
+		`DELETE FROM outbox WHERE user_id IN (` + orphanUsers + `)`,
+		`UPDATE task_templates SET assignee = NULL WHERE assignee IN (` + orphanUsers + `)`,
+	} {
+		if _, err = tx.ExecContext(ctx, q, formatTime(before)); err != nil {
+			return 0, err
+		}
+	}
+
+	const deleteQuery = `DELETE FROM users
+	WHERE id IN (` + orphanUsers + `)
+	AND id NOT IN (SELECT created_by FROM tasks)
+	AND id NOT IN (SELECT updated_by FROM tasks)
+	AND id NOT IN (SELECT assignee FROM tasks WHERE assignee IS NOT NULL)
+	AND id NOT IN (SELECT reviewer FROM tasks WHERE reviewer IS NOT NULL)`
+	result, err := tx.ExecContext(ctx, deleteQuery, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	const anonymizeQuery = `UPDATE users SET tg_user_id = -id, username = '', full_name = '', caldav_token = NULL, is_active = 0
+	WHERE id IN (` + orphanUsers + `)`
+	if result, err = tx.ExecContext(ctx, anonymizeQuery, formatTime(before)); err != nil {
+		return 0, err
+	}
+	anonymized, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(deleted + anonymized), nil
+}
+
+func (s *MaintenanceStorage) Compact(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `VACUUM`)
+	return err
+}
+
+// CopyTo writes consistent copy of database to path, existing file is replaced.
+// Copy of encrypted database is encrypted with the same key.
+func (s *MaintenanceStorage) CopyTo(ctx context.Context, path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// SQLCipher answers with its version, VACUUM INTO would write plain copy of encrypted database.
+	var cipherVersion string
+	err := s.db.QueryRowContext(ctx, `PRAGMA cipher_version`).Scan(&cipherVersion)
+	if err == sql.ErrNoRows {
+		_, err = s.db.ExecContext(ctx, `VACUUM INTO ?`, path)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Attached database without KEY is encrypted with key of the main one.
+	if _, err = conn.ExecContext(ctx, `ATTACH DATABASE ? AS backup`, path); err != nil {
+		return err
+	}
+	if _, err = conn.ExecContext(ctx, `SELECT sqlcipher_export('backup')`); err != nil {
+		conn.ExecContext(ctx, `DETACH DATABASE backup`)
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `DETACH DATABASE backup`)
+	return err
+}
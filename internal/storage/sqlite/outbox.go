@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+type OutboxStorage struct {
+	db *sql.DB
+}
+
+func NewOutboxStorage(db *sql.DB) *OutboxStorage {
+	return &OutboxStorage{db: db}
+}
+
+// enqueueNotification is called within transaction of the change notification is about.
+func enqueueNotification(ctx context.Context, db execer, n model.Notification, createdAt time.Time) error {
+	const q = `INSERT INTO outbox (user_id, text, next_attempt_at, created_at) VALUES (?, ?, ?, ?)`
+	_, err := db.ExecContext(ctx, q, n.UserID, n.Text, formatTime(createdAt), formatTime(createdAt))
+	return err
+}
+
+func (s *OutboxStorage) EnqueueNotifications(ctx context.Context, notifications []model.Notification) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	createdAt := now()
+	for _, n := range notifications {
+		if err = enqueueNotification(ctx, tx, n, createdAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const notificationColumns = `id, user_id, text, attempts, next_attempt_at, last_error, dead, created_at`
+
+func (s *OutboxStorage) FetchDueNotifications(ctx context.Context, now time.Time, limit int) ([]model.Notification, error) {
+	const q = `SELECT ` + notificationColumns + ` FROM outbox
+	WHERE NOT dead AND next_attempt_at <= ?
+	ORDER BY id
+	LIMIT ?`
+	return s.queryNotifications(ctx, q, formatTime(now), limit)
+}
+
+func (s *OutboxStorage) ListDeadNotifications(ctx context.Context) ([]model.Notification, error) {
+	const q = `SELECT ` + notificationColumns + ` FROM outbox WHERE dead ORDER BY id`
+	return s.queryNotifications(ctx, q)
+}
+
+func (s *OutboxStorage) queryNotifications(ctx context.Context, q string, args ...interface{}) ([]model.Notification, error) {
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []model.Notification
+	for rows.Next() {
+		var (
+			n                        model.Notification
+			nextAttemptAt, createdAt sql.NullString
+		)
+		if err = rows.Scan(&n.ID, &n.UserID, &n.Text, &n.Attempts, &nextAttemptAt, &n.LastError, &n.Dead, &createdAt); err != nil {
+			return nil, err
+		}
+		if n.NextAttemptAt, err = parseTime(nextAttemptAt); err != nil {
+			return nil, err
+		}
+		if n.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (s *OutboxStorage) UpdateNotification(ctx context.Context, n *model.Notification) error {
+	const q = `UPDATE outbox SET attempts = ?, next_attempt_at = ?, last_error = ?, dead = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q, n.Attempts, formatTime(n.NextAttemptAt), n.LastError, n.Dead, n.ID)
+	return err
+}
+
+func (s *OutboxStorage) RequeueDeadNotification(ctx context.Context, id int, now time.Time) error {
+	const q = `UPDATE outbox SET attempts = 0, next_attempt_at = ?, dead = FALSE WHERE id = ? AND dead`
+	result, err := s.db.ExecContext(ctx, q, formatTime(now), id)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *OutboxStorage) DeleteNotification(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, id)
+	return err
+}
@@ -3,21 +3,41 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
 )
 
 type ProjectStorage struct {
 	db *sql.DB
+	// reader runs queries outside of transactions, see ConnectReader.
+	reader *sql.DB
 }
 
-func NewProjectStorage(db *sql.DB) *ProjectStorage {
-	return &ProjectStorage{db: db}
+// NewProjectStorage returns storage writing through db and reading through reader, which may be db itself.
+func NewProjectStorage(db, reader *sql.DB) *ProjectStorage {
+	return &ProjectStorage{db: db, reader: reader}
 }
 
 func (s *ProjectStorage) CreateProject(ctx context.Context, project *model.Project) error {
-	const q = `INSERT INTO projects (tg_chat_id, title, archived) VALUES (?, ?, ?)`
-	result, err := s.db.ExecContext(ctx, q, project.TgChatID, project.Title, project.Archived)
+	const q = `INSERT INTO projects
+	(tg_chat_id, title, archived, quick_capture, board_message_id, public_token, default_deadline_days, frozen,
+	welcome_text, welcome_privately, escalation_days)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := s.db.ExecContext(ctx, q,
+		project.TgChatID,
+		project.Title,
+		project.Archived,
+		project.QuickCapture,
+		project.BoardMessageID,
+		nullString(project.PublicToken),
+		project.DefaultDeadlineDays,
+		project.Frozen,
+		project.WelcomeText,
+		project.WelcomePrivately,
+		project.EscalationDays,
+	)
 	if err != nil {
 		return err
 	}
@@ -31,185 +51,261 @@ func (s *ProjectStorage) CreateProject(ctx context.Context, project *model.Proje
 	return nil
 }
 
-func (s *ProjectStorage) GetProjectByID(ctx context.Context, id int) (*model.Project, error) {
-	const q = `SELECT id, tg_chat_id, title, archived FROM projects WHERE id = ?`
-	var project model.Project
-	err := s.db.QueryRowContext(ctx, q, id).Scan(
-		&project.ID,
-		&project.TgChatID,
-		&project.Title,
-		&project.Archived,
-	)
+const projectColumns = `id, tg_chat_id, title, archived, quick_capture, board_message_id, public_token,
+	default_deadline_days, frozen, welcome_text, welcome_privately, escalation_days`
+
+func (s *ProjectStorage) FetchProjectByID(ctx context.Context, id int) (*model.Project, error) {
+	const q = `SELECT ` + projectColumns + ` FROM projects WHERE id = ?`
+	return s.fetchProject(ctx, q, id)
+}
+
+func (s *ProjectStorage) FetchProjectByChatID(ctx context.Context, tgChatID int64) (*model.Project, error) {
+	const q = `SELECT ` + projectColumns + ` FROM projects WHERE tg_chat_id = ?`
+	return s.fetchProject(ctx, q, tgChatID)
+}
+
+func (s *ProjectStorage) FetchProjectByPublicToken(ctx context.Context, token string) (*model.Project, error) {
+	const q = `SELECT ` + projectColumns + ` FROM projects WHERE public_token = ?`
+	return s.fetchProject(ctx, q, token)
+}
+
+func (s *ProjectStorage) FetchProjectsByUser(ctx context.Context, userID int, role model.UserProjectRole) ([]model.Project, error) {
+	q := `SELECT ` + projectColumns + ` FROM projects p
+	JOIN user_projects up ON up.project_id = p.id
+	WHERE up.user_id = ?`
+	args := []interface{}{userID}
+	if role != "" {
+		q += ` AND up.user_role = ?`
+		args = append(args, role)
+	}
+	q += ` ORDER BY p.id`
+
+	rows, err := s.reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []model.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (s *ProjectStorage) fetchProject(ctx context.Context, q string, args ...interface{}) (*model.Project, error) {
+	project, err := scanProject(s.reader.QueryRowContext(ctx, q, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, model.ErrProjectNotFound
 		}
 		return nil, err
 	}
-	return &project, nil
+	return project, nil
 }
 
-func (s *ProjectStorage) FetchProjectByChatID(ctx context.Context, tgChatID int64) (*model.Project, error) {
-	const q = `SELECT id, tg_chat_id, title, archived FROM projects WHERE tg_chat_id = ?`
-	var project model.Project
-	err := s.db.QueryRowContext(ctx, q, tgChatID).Scan(
+func scanProject(row rowScanner) (*model.Project, error) {
+	var (
+		project     model.Project
+		publicToken sql.NullString
+	)
+	err := row.Scan(
 		&project.ID,
 		&project.TgChatID,
 		&project.Title,
 		&project.Archived,
+		&project.QuickCapture,
+		&project.BoardMessageID,
+		&publicToken,
+		&project.DefaultDeadlineDays,
+		&project.Frozen,
+		&project.WelcomeText,
+		&project.WelcomePrivately,
+		&project.EscalationDays,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, model.ErrProjectNotFound
-		}
 		return nil, err
 	}
+	project.PublicToken = publicToken.String
 	return &project, nil
 }
 
 func (s *ProjectStorage) UpdateProject(ctx context.Context, project *model.Project) error {
-	const q = `UPDATE projects SET title = ?, archived = ? WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, q, project.Title, project.Archived, project.ID)
+	const q = `UPDATE projects
+	SET title = ?, archived = ?, quick_capture = ?, board_message_id = ?, public_token = ?,
+		default_deadline_days = ?, frozen = ?, welcome_text = ?, welcome_privately = ?, escalation_days = ?
+	WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q,
+		project.Title,
+		project.Archived,
+		project.QuickCapture,
+		project.BoardMessageID,
+		nullString(project.PublicToken),
+		project.DefaultDeadlineDays,
+		project.Frozen,
+		project.WelcomeText,
+		project.WelcomePrivately,
+		project.EscalationDays,
+		project.ID,
+	)
 	return err
 }
 
 func (s *ProjectStorage) DeleteProject(ctx context.Context, id int) error {
-	const q = `DELETE FROM projects WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, q, id)
-	return err
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Foreign keys are not enforced, so everything owned by project is removed explicitly.
+	// Members are detached and ones left without projects are cleaned up by maintenance
+	const projectTasks = `(SELECT id FROM tasks WHERE project_id = ?1)`
+	for _, q := range []string{
+		`DELETE FROM task_assignees WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_labels WHERE task_id IN ` + projectTasks,
+		`DELETE FROM checklist_items WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_comments WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_history WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_attachments WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_watchers WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_recurrences WHERE task_id IN ` + projectTasks,
+		`DELETE FROM task_links WHERE task_id IN ` + projectTasks + ` OR blocker_id IN ` + projectTasks,
+		`DELETE FROM tasks WHERE project_id = ?`,
+		`DELETE FROM labels WHERE project_id = ?`,
+		`DELETE FROM alert_tasks WHERE project_id = ?`,
+		`DELETE FROM api_tokens WHERE project_id = ?`,
+		`DELETE FROM user_projects WHERE project_id = ?`,
+		`DELETE FROM project_holidays WHERE project_id = ?`,
+		`DELETE FROM task_templates WHERE project_id = ?`,
+		`DELETE FROM task_snapshots WHERE project_id = ?`,
+		`DELETE FROM project_links WHERE project_id = ?`,
+	} {
+		if _, err = tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM projects WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *ProjectStorage) CountProjectContents(ctx context.Context, id int) (model.ProjectContents, error) {
+	const q = `SELECT
+		(SELECT COUNT(*) FROM tasks WHERE project_id = ?1),
+		(SELECT COUNT(*) FROM user_projects WHERE project_id = ?1),
+		(SELECT COUNT(*) FROM task_comments WHERE task_id IN (SELECT id FROM tasks WHERE project_id = ?1))`
+	var contents model.ProjectContents
+	err := s.reader.QueryRowContext(ctx, q, id).Scan(&contents.Tasks, &contents.Members, &contents.Comments)
+	return contents, err
 }
 
-// func (s *ProjectStorage) ListProjects(ctx context.Context) ([]model.Project, error) {
-// 	query := `
-// 		SELECT id, tg_chat_id, title, archived
-// 		FROM projects
-// 		ORDER BY id
-// 	`
-// 	rows, err := s.db.QueryContext(ctx, query)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	defer rows.Close()
-
-// 	var projects []model.Project
-// 	for rows.Next() {
-// 		var project model.Project
-// 		err := rows.Scan(
-// 			&project.ID,
-// 			&project.TgChatID,
-// 			&project.Title,
-// 			&project.Archived,
-// 		)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		projects = append(projects, project)
-// 	}
-
-// 	if err = rows.Err(); err != nil {
-// 		return nil, err
-// 	}
-
-// 	return projects, nil
-// }
-
-// // Implement TaskRepository methods
-
-// func (s *ProjectStorage) FilterTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error) {
-// 	query := `
-// 		SELECT id, project_id, title, description, status, deadline, created_by, updated_by, assignee
-// 		FROM tasks
-// 		WHERE project_id = ?
-// 	`
-// 	args := []interface{}{filter.ProjectID}
-
-// 	if filter.Status != "" {
-// 		query += " AND status = ?"
-// 		args = append(args, filter.Status)
-// 	}
-// 	if filter.CreatedBy != 0 {
-// 		query += " AND created_by = ?"
-// 		args = append(args, filter.CreatedBy)
-// 	}
-// 	if filter.Assignee != 0 {
-// 		query += " AND assignee = ?"
-// 		args = append(args, filter.Assignee)
-// 	}
-// 	if !filter.Deadline.IsZero() {
-// 		query += " AND deadline <= ?"
-// 		args = append(args, filter.Deadline)
-// 	}
-
-// 	rows, err := s.db.QueryContext(ctx, query, args...)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	defer rows.Close()
-
-// 	var tasks []model.Task
-// 	for rows.Next() {
-// 		var task model.Task
-// 		var deadlineNull sql.NullTime
-// 		err := rows.Scan(
-// 			&task.ID,
-// 			&task.ProjectID,
-// 			&task.Title,
-// 			&task.Description,
-// 			&task.Status,
-// 			&deadlineNull,
-// 			&task.CreatedBy,
-// 			&task.UpdatedBy,
-// 			&task.Assignee,
-// 		)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		if deadlineNull.Valid {
-// 			task.Deadline = deadlineNull.Time
-// 		}
-// 		tasks = append(tasks, task)
-// 	}
-
-// 	if err = rows.Err(); err != nil {
-// 		return nil, err
-// 	}
-
-// 	return tasks, nil
-// }
-
-// func (s *ProjectStorage) CreateTask(ctx context.Context, task *model.Task) error {
-// 	query := `
-// 		INSERT INTO tasks (project_id, title, description, status, deadline, created_by, updated_by, assignee)
-// 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-// 	`
-// 	result, err := s.db.ExecContext(ctx, query,
-// 		task.ProjectID, task.Title, task.Description, task.Status, task.Deadline, task.CreatedBy, task.UpdatedBy, task.Assignee)
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	id, err := result.LastInsertId()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	task.ID = int(id)
-// 	return nil
-// }
-
-// func (s *ProjectStorage) UpdateTask(ctx context.Context, task *model.Task) error {
-// 	query := `
-// 		UPDATE tasks
-// 		SET title = ?, description = ?, status = ?, deadline = ?, updated_by = ?, assignee = ?
-// 		WHERE id = ?
-// 	`
-// 	_, err := s.db.ExecContext(ctx, query,
-// 		task.Title, task.Description, task.Status, task.Deadline, task.UpdatedBy, task.Assignee, task.ID)
-// 	return err
-// }
-
-// func (s *ProjectStorage) RemoveTask(ctx context.Context, id int) error {
-// 	query := `DELETE FROM tasks WHERE id = ?`
-// 	_, err := s.db.ExecContext(ctx, query, id)
-// 	return err
-// }
+// dayLayout keeps dates of holidays and snapshots, they are in bot's time zone.
+const dayLayout = "2006-01-02"
+
+func (s *ProjectStorage) FetchProjectHolidays(ctx context.Context, projectID int) ([]time.Time, error) {
+	const q = `SELECT day FROM project_holidays WHERE project_id = ? ORDER BY day`
+	rows, err := s.reader.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var raw string
+		if err = rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		day, err := time.ParseInLocation(dayLayout, raw, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+func (s *ProjectStorage) SetProjectHolidays(ctx context.Context, projectID int, days []time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM project_holidays WHERE project_id = ?`, projectID); err != nil {
+		return err
+	}
+	for _, day := range days {
+		const q = `INSERT OR IGNORE INTO project_holidays (project_id, day) VALUES (?, ?)`
+		if _, err = tx.ExecContext(ctx, q, projectID, day.Format(dayLayout)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *ProjectStorage) ListProjects(ctx context.Context) ([]model.Project, error) {
+	const q = `SELECT ` + projectColumns + ` FROM projects ORDER BY id`
+	rows, err := s.reader.QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []model.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (s *ProjectStorage) FetchProjectLinks(ctx context.Context, projectID int) ([]model.ProjectLink, error) {
+	const q = `SELECT id, project_id, title, url, created_by FROM project_links WHERE project_id = ? ORDER BY title_key`
+	rows, err := s.reader.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []model.ProjectLink
+	for rows.Next() {
+		var link model.ProjectLink
+		if err = rows.Scan(&link.ID, &link.ProjectID, &link.Title, &link.URL, &link.CreatedBy); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+func (s *ProjectStorage) SaveProjectLink(ctx context.Context, link *model.ProjectLink) error {
+	const q = `INSERT INTO project_links (project_id, title, title_key, url, created_by) VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT (project_id, title_key) DO UPDATE SET title = excluded.title, url = excluded.url, created_by = excluded.created_by
+	RETURNING id`
+	row := s.db.QueryRowContext(ctx, q, link.ProjectID, link.Title, strings.ToLower(link.Title), link.URL, link.CreatedBy)
+	return row.Scan(&link.ID)
+}
+
+func (s *ProjectStorage) RemoveProjectLink(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM project_links WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// Ranks are unique in project: new task gets the largest rank of all tasks and task moved to the top
+// the smallest one in its project.
+
+func (s *TaskStorage) MoveTask(ctx context.Context, id int, move model.TaskMove) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		projectID, rank int
+		status          model.TaskStatus
+	)
+	err = tx.QueryRowContext(ctx, `SELECT project_id, status, rank FROM tasks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&projectID, &status, &rank)
+	if err == sql.ErrNoRows {
+		return model.ErrTaskNotFound
+	} else if err != nil {
+		return err
+	}
+
+	var neighbourQuery string
+	switch move {
+	case model.TaskMoveTop:
+		const q = `UPDATE tasks SET rank = (SELECT MIN(rank) - 1 FROM tasks WHERE project_id = ?) WHERE id = ?`
+		if _, err = tx.ExecContext(ctx, q, projectID, id); err != nil {
+			return err
+		}
+		return tx.Commit()
+	case model.TaskMoveUp:
+		neighbourQuery = `SELECT id, rank FROM tasks
+		WHERE project_id = ? AND status = ? AND deleted_at IS NULL AND (rank < ? OR rank = ? AND id < ?)
+		ORDER BY rank DESC, id DESC LIMIT 1`
+	case model.TaskMoveDown:
+		neighbourQuery = `SELECT id, rank FROM tasks
+		WHERE project_id = ? AND status = ? AND deleted_at IS NULL AND (rank > ? OR rank = ? AND id > ?)
+		ORDER BY rank, id LIMIT 1`
+	default:
+		return fmt.Errorf("unknown move %q", move)
+	}
+
+	var neighbourID, neighbourRank int
+	err = tx.QueryRowContext(ctx, neighbourQuery, projectID, status, rank, rank, id).Scan(&neighbourID, &neighbourRank)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE tasks SET rank = ? WHERE id = ?`, neighbourRank, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE tasks SET rank = ? WHERE id = ?`, rank, neighbourID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,107 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const recurrenceFields = `r.task_id, r.rule, r.next_at`
+
+func (s *TaskStorage) SetTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence) error {
+	const q = `INSERT INTO task_recurrences (task_id, rule, next_at) VALUES (?, ?, ?)
+	ON CONFLICT (task_id) DO UPDATE SET rule = excluded.rule, next_at = excluded.next_at`
+	_, err := s.db.ExecContext(ctx, q, rec.TaskID, string(rec.Rule), formatTime(rec.NextAt))
+	return err
+}
+
+func (s *TaskStorage) RemoveTaskRecurrence(ctx context.Context, taskID int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_recurrences WHERE task_id = ?`, taskID)
+	return err
+}
+
+func (s *TaskStorage) FetchTaskRecurrence(ctx context.Context, taskID int) (*model.TaskRecurrence, error) {
+	const q = `SELECT ` + recurrenceFields + ` FROM task_recurrences r WHERE r.task_id = ?`
+	rec, err := scanRecurrence(s.reader.QueryRowContext(ctx, q, taskID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrRecurrenceNotFound
+		}
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (s *TaskStorage) FetchProjectRecurrences(ctx context.Context, projectID int) ([]model.TaskRecurrence, error) {
+	const q = `SELECT ` + recurrenceFields + ` FROM task_recurrences r
+	JOIN tasks t ON t.id = r.task_id
+	WHERE t.project_id = ? AND t.deleted_at IS NULL
+	ORDER BY r.task_id`
+	return s.queryRecurrences(ctx, q, projectID)
+}
+
+func (s *TaskStorage) FetchDueRecurrences(ctx context.Context, now time.Time) ([]model.TaskRecurrence, error) {
+	const q = `SELECT ` + recurrenceFields + ` FROM task_recurrences r
+	JOIN tasks t ON t.id = r.task_id
+	WHERE (r.next_at <= ? OR t.status IN (?, ?)) AND t.deleted_at IS NULL
+	ORDER BY r.task_id`
+	return s.queryRecurrences(ctx, q, formatTime(now), model.TaskStatusDone, model.TaskStatusCancelled)
+}
+
+func (s *TaskStorage) RenewTaskRecurrence(ctx context.Context, rec *model.TaskRecurrence, next *model.Task) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = createTask(ctx, tx, next); err != nil {
+		return err
+	}
+	const q = `UPDATE task_recurrences SET task_id = ?, rule = ?, next_at = ? WHERE task_id = ?`
+	if _, err = tx.ExecContext(ctx, q, next.ID, string(rec.Rule), formatTime(rec.NextAt), rec.TaskID); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	rec.TaskID = next.ID
+	return nil
+}
+
+func (s *TaskStorage) queryRecurrences(ctx context.Context, q string, args ...interface{}) ([]model.TaskRecurrence, error) {
+	rows, err := s.reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recs []model.TaskRecurrence
+	for rows.Next() {
+		rec, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, *rec)
+	}
+	return recs, rows.Err()
+}
+
+func scanRecurrence(row rowScanner) (*model.TaskRecurrence, error) {
+	var (
+		rec    model.TaskRecurrence
+		rule   string
+		nextAt sql.NullString
+	)
+	if err := row.Scan(&rec.TaskID, &rule, &nextAt); err != nil {
+		return nil, err
+	}
+	rec.Rule = model.RecurrenceRule(rule)
+	var err error
+	if rec.NextAt, err = parseTime(nextAt); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+)
+
+// expectedSchema lists tables and columns queried by storages.
+var expectedSchema = map[string][]string{
+	"projects":         strings.Split(strings.Join(strings.Fields(projectColumns), ""), ","),
+	"tasks":            append(strings.Split(strings.Join(strings.Fields(taskColumns), ""), ","), "rank"),
+	"users":            {"id", "tg_user_id", "username", "full_name", "is_active", "caldav_token", "unreachable", "orphaned_at"},
+	"user_projects":    {"user_id", "project_id", "user_role"},
+	"project_holidays": {"project_id", "day"},
+	"project_links":    {"id", "project_id", "title", "title_key", "url", "created_by"},
+	"task_assignees":   {"task_id", "user_id"},
+	"user_usernames":   {"user_id", "username", "changed_at"},
+	"job_leases":       {"name", "holder", "expires_at"},
+	"labels":           {"id", "project_id", "name"},
+	"task_labels":      {"task_id", "label_id"},
+	"checklist_items":  {"id", "task_id", "text", "done"},
+	"task_comments":    {"id", "task_id", "author_id", "text", "created_at"},
+	"task_watchers":    {"task_id", "user_id"},
+	"alert_tasks":      {"project_id", "fingerprint", "task_id"},
+	"task_links":       {"task_id", "blocker_id"},
+	"task_recurrences": {"task_id", "rule", "next_at"},
+	"task_history":     {"id", "task_id", "user_id", "field", "old_value", "new_value", "changed_at"},
+	"task_snapshots":   {"project_id", "day", "status", "count"},
+	"task_templates":   {"id", "project_id", "name", "name_key", "title", "description", "assignee", "labels", "created_by"},
+	"task_attachments": strings.Split(strings.Join(strings.Fields(attachmentColumns), ""), ","),
+	"outbox":           {"id", "user_id", "text", "attempts", "next_attempt_at", "last_error", "dead", "created_at"},
+	"task_events":      {"id", "type", "task_id", "project_id", "title", "status", "priority", "deadline", "epic", "parent_id", "actor_id", "created_at"},
+	"api_tokens":       {"id", "project_id", "token_hash", "prefix", "scope", "created_by", "created_at", "last_used_at"},
+}
+
+// ValidateSchema checks that database has no migrations unknown to this build, which means it was
+// migrated by newer version, and that tables and columns used by storages exist.
+func ValidateSchema(ctx context.Context, db *sql.DB, migrations fs.FS) error {
+	files, err := fs.Glob(migrations, "*.sql")
+	if err != nil {
+		return fmt.Errorf("could not list migrations: %w", err)
+	}
+	known := make(map[string]bool, len(files))
+	for _, name := range files {
+		known[strings.TrimSuffix(name, ".sql")] = true
+	}
+
+	applied, err := queryStrings(ctx, db, `SELECT version FROM schema_version ORDER BY version`)
+	if err != nil {
+		return fmt.Errorf("could not fetch applied migrations: %w", err)
+	}
+	var unknown []string
+	for _, version := range applied {
+		if !known[version] {
+			unknown = append(unknown, version)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("database has migrations unknown to this version: %s, upgrade the bot", strings.Join(unknown, ", "))
+	}
+
+	tables := make([]string, 0, len(expectedSchema))
+	for table := range expectedSchema {
+		tables = append(tables, table)
+	}
+	slices.Sort(tables)
+	for _, table := range tables {
+		columns, err := queryStrings(ctx, db, `SELECT name FROM pragma_table_info(?)`, table)
+		if err != nil {
+			return fmt.Errorf("could not fetch columns of table %s: %w", table, err)
+		}
+		if len(columns) == 0 {
+			return fmt.Errorf("table %s is missing", table)
+		}
+		for _, column := range expectedSchema[table] {
+			if !slices.Contains(columns, column) {
+				return fmt.Errorf("column %s.%s is missing", table, column)
+			}
+		}
+	}
+	return nil
+}
+
+func queryStrings(ctx context.Context, db *sql.DB, q string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []string
+	for rows.Next() {
+		var s string
+		if err = rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+func (s *TaskStorage) SnapshotTaskCounts(ctx context.Context, day time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Statuses left without tasks since the previous snapshot of the day must not keep their counts
+	raw := day.Format(dayLayout)
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_snapshots WHERE day = ?`, raw); err != nil {
+		return err
+	}
+	const q = `INSERT INTO task_snapshots (project_id, day, status, count)
+	SELECT project_id, ?, status, COUNT(*) FROM tasks WHERE deleted_at IS NULL GROUP BY project_id, status`
+	if _, err = tx.ExecContext(ctx, q, raw); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *TaskStorage) FetchTaskSnapshots(ctx context.Context, projectID int, from, to time.Time) ([]model.TaskSnapshot, error) {
+	const q = `SELECT day, status, count FROM task_snapshots
+	WHERE project_id = ? AND day BETWEEN ? AND ?
+	ORDER BY day`
+	rows, err := s.reader.QueryContext(ctx, q, projectID, from.Format(dayLayout), to.Format(dayLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []model.TaskSnapshot
+	for rows.Next() {
+		var (
+			raw    string
+			status model.TaskStatus
+			count  int
+		)
+		if err = rows.Scan(&raw, &status, &count); err != nil {
+			return nil, err
+		}
+		day, err := time.ParseInLocation(dayLayout, raw, time.Local)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(snapshots); n == 0 || !snapshots[n-1].Day.Equal(day) {
+			snapshots = append(snapshots, model.TaskSnapshot{ProjectID: projectID, Day: day, Counts: make(map[model.TaskStatus]int)})
+		}
+		snapshots[len(snapshots)-1].Counts[status] = count
+	}
+	return snapshots, rows.Err()
+}
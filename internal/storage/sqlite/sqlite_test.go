@@ -0,0 +1,204 @@
+package sqlite_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agalitsyn/sqlite"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+	sqliteStorage "github.com/agalitsyn/telegram-tasks-bot/internal/storage/sqlite"
+	"github.com/agalitsyn/telegram-tasks-bot/internal/storage/storagetest"
+	"github.com/agalitsyn/telegram-tasks-bot/migrations"
+)
+
+func TestRepositories(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Repositories {
+		path := filepath.Join(t.TempDir(), "db.sqlite3")
+		db, err := sqliteStorage.Connect(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+			t.Fatal(err)
+		}
+		// Reads go through separate pool like in the bot, so they must see committed writes
+		reader, err := sqliteStorage.ConnectReader(db, path, "", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { reader.Close() })
+		return storagetest.Repositories{
+			Projects: sqliteStorage.NewProjectStorage(db, reader),
+			Users:    sqliteStorage.NewUserStorage(db, reader),
+			Tasks:    sqliteStorage.NewTaskStorage(db, reader),
+			Leases:   sqliteStorage.NewLeaseStorage(db),
+			Outbox:   sqliteStorage.NewOutboxStorage(db),
+			Tokens:   sqliteStorage.NewAPITokenStorage(db),
+
+			Maintenance: sqliteStorage.NewMaintenanceStorage(db),
+			EventLog:    sqliteStorage.NewTaskEventLogStorage(db, reader),
+		}
+	})
+}
+
+func TestValidateSchema(t *testing.T) {
+	ctx := context.Background()
+	db, err := sqliteStorage.Connect(filepath.Join(t.TempDir(), "db.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+	if err = sqliteStorage.ValidateSchema(ctx, db, migrations.FS); err != nil {
+		t.Fatalf("migrated schema: %s", err)
+	}
+
+	if _, err = db.ExecContext(ctx, `ALTER TABLE tasks DROP COLUMN reviewer`); err != nil {
+		t.Fatal(err)
+	}
+	if err = sqliteStorage.ValidateSchema(ctx, db, migrations.FS); err == nil || !strings.Contains(err.Error(), "tasks.reviewer") {
+		t.Fatalf("missing column: got %v", err)
+	}
+
+	if _, err = db.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES ('9999_future')`); err != nil {
+		t.Fatal(err)
+	}
+	if err = sqliteStorage.ValidateSchema(ctx, db, migrations.FS); err == nil || !strings.Contains(err.Error(), "9999_future") {
+		t.Fatalf("unknown migration: got %v", err)
+	}
+}
+
+func TestConsoleQuery(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := sqliteStorage.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+	for i, title := range []string{"a", "b", "c"} {
+		if _, err = db.ExecContext(ctx, `INSERT INTO projects (tg_chat_id, title) VALUES (?, ?)`, -100-i, title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader, err := sqliteStorage.ConnectReader(db, path, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reader.Close() })
+	console := sqliteStorage.NewConsoleStorage(reader)
+
+	res, err := console.Query(ctx, `SELECT title, NULL AS empty FROM projects ORDER BY id`, 2)
+	if err != nil {
+		t.Fatalf("query: %s", err)
+	}
+	want := &model.QueryResult{Columns: []string{"title", "empty"}, Rows: [][]string{{"a", "NULL"}, {"b", "NULL"}}, Truncated: true}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("query: got %+v, want %+v", res, want)
+	}
+
+	res, err = console.Query(ctx, `SELECT ';' AS "a;b" FROM projects AS [p;] -- last;
+		LIMIT 1; /* done */ ;`, 10)
+	if err != nil {
+		t.Fatalf("query with semicolons: %s", err)
+	}
+	want = &model.QueryResult{Columns: []string{"a;b"}, Rows: [][]string{{";"}}}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("query with semicolons: got %+v, want %+v", res, want)
+	}
+
+	if _, err = console.Query(ctx, `DELETE FROM projects`, 10); err == nil {
+		t.Fatal("delete: got no error")
+	}
+	for _, query := range []string{
+		`SELECT 1; PRAGMA query_only = OFF; DELETE FROM projects`,
+		`SELECT 1; 'x'`,
+		`SELECT 1 /* ; */; DELETE FROM projects`,
+	} {
+		if _, err = console.Query(ctx, query, 10); !errors.Is(err, sqliteStorage.ErrMultipleStatements) {
+			t.Fatalf("%q: got %v, want %v", query, err, sqliteStorage.ErrMultipleStatements)
+		}
+	}
+
+	// Runaway queries spend the time before the first row and between rows
+	for _, query := range []string{
+		`WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c`,
+		`WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT x FROM c WHERE x = 1 OR x < 0`,
+	} {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		start := time.Now()
+		_, err = console.Query(timeoutCtx, query, 10)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("%q: got %v, want %v", query, err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("%q: stopped after %s", query, elapsed)
+		}
+	}
+	if _, err = console.Query(ctx, `SELECT 1 AS one`, 10); err != nil {
+		t.Fatalf("query after timeout: %s", err)
+	}
+
+	var count int
+	if err = db.QueryRowContext(ctx, `SELECT count(*) FROM projects`).Scan(&count); err != nil || count != 3 {
+		t.Fatalf("projects after queries: got %d, %v", count, err)
+	}
+}
+
+func TestConnectReader(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := sqliteStorage.Connect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err = sqlite.MigrateUp(db, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := sqliteStorage.ConnectReader(db, path, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reader.Close() })
+
+	// Reader sees changes of open writer transaction only after commit
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, `INSERT INTO projects (tg_chat_id, title) VALUES (-100, 'a')`); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err = reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects`).Scan(&count); err != nil || count != 0 {
+		t.Fatalf("count during write: got %d, %v", count, err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err = reader.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects`).Scan(&count); err != nil || count != 1 {
+		t.Fatalf("count after commit: got %d, %v", count, err)
+	}
+
+	if _, err = reader.ExecContext(ctx, `DELETE FROM projects`); err == nil {
+		t.Fatal("delete through reader: got no error")
+	}
+}
@@ -1 +1,582 @@
 package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+type TaskStorage struct {
+	db *sql.DB
+	// reader runs queries outside of transactions, see ConnectReader.
+	reader *sql.DB
+}
+
+// NewTaskStorage returns storage writing through db and reading through reader, which may be db itself.
+func NewTaskStorage(db, reader *sql.DB) *TaskStorage {
+	return &TaskStorage{db: db, reader: reader}
+}
+
+const taskColumns = `id, project_id, title, description, status, deadline, created_by, updated_by, updated_at, assignee, reviewer,
+	overdue_notified, escalated, muted, epic, parent_id, priority, estimate, deleted_at`
+
+// taskFields are columns of tasks table queried with co-assignees joined by comma and labels joined by newline.
+const taskFields = taskColumns + `, (SELECT group_concat(user_id) FROM task_assignees WHERE task_id = tasks.id),
+	(SELECT group_concat(l.name, char(10)) FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = tasks.id)`
+
+func (s *TaskStorage) FetchTaskByID(ctx context.Context, id int) (*model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks WHERE id = ? AND deleted_at IS NULL`
+	task, err := scanTask(s.reader.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *TaskStorage) FilterTasks(ctx context.Context, filter model.TaskFilter) ([]model.Task, error) {
+	var (
+		conds = []string{"deleted_at IS NULL"}
+		args  []interface{}
+	)
+	if filter.ProjectID != 0 {
+		conds = append(conds, "project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+	if filter.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.CreatedBy != 0 {
+		conds = append(conds, "created_by = ?")
+		args = append(args, filter.CreatedBy)
+	}
+	if filter.Assignee != 0 {
+		conds = append(conds, "(assignee = ? OR id IN (SELECT task_id FROM task_assignees WHERE user_id = ?))")
+		args = append(args, filter.Assignee, filter.Assignee)
+	}
+	if !filter.Deadline.IsZero() {
+		conds = append(conds, "deadline <= ?")
+		args = append(args, formatTime(filter.Deadline))
+	}
+	if !filter.DeadlineFrom.IsZero() {
+		conds = append(conds, "deadline >= ?")
+		args = append(args, formatTime(filter.DeadlineFrom))
+	}
+	if filter.WithoutDeadline {
+		conds = append(conds, "deadline IS NULL")
+	}
+	if filter.OverdueNotNotified {
+		conds = append(conds, "overdue_notified = 0")
+	}
+	if filter.NotEscalated {
+		conds = append(conds, "escalated = 0")
+	}
+	if filter.WithoutMuted {
+		conds = append(conds, "muted = 0")
+	}
+	if filter.ParentID != 0 {
+		conds = append(conds, "parent_id = ?")
+		args = append(args, filter.ParentID)
+	}
+	if filter.OnlyEpics {
+		conds = append(conds, "epic = 1")
+	}
+	if filter.Label != "" {
+		conds = append(conds, "id IN (SELECT tl.task_id FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE l.name = ?)")
+		args = append(args, filter.Label)
+	}
+	if filter.OnlyOpen {
+		conds = append(conds, "status NOT IN (?, ?)")
+		args = append(args, model.TaskStatusDone, model.TaskStatusCancelled)
+	}
+
+	q := `SELECT ` + taskFields + ` FROM tasks WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY rank, id`
+
+	rows, err := s.reader.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTasks(rows)
+}
+
+func (s *TaskStorage) CreateTask(ctx context.Context, task *model.Task) error {
+	return s.CreateTasks(ctx, []*model.Task{task})
+}
+
+func (s *TaskStorage) CreateTasks(ctx context.Context, tasks []*model.Task) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, task := range tasks {
+		if err = createTask(ctx, tx, task); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func createTask(ctx context.Context, db execer, task *model.Task) error {
+	// New task goes to the end of lists, ranks keep order of creation across projects
+	const q = `INSERT INTO tasks (project_id, title, description, status, deadline, created_by, updated_by, updated_at, assignee, reviewer, muted,
+		epic, parent_id, priority, estimate, rank)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT COALESCE(MAX(rank), 0) + 1 FROM tasks))`
+	if task.Priority == "" {
+		task.Priority = model.TaskPriorityNormal
+	}
+	updatedAt := now()
+	result, err := db.ExecContext(ctx, q,
+		task.ProjectID,
+		task.Title,
+		nullString(task.Description),
+		task.Status,
+		nullTime(task.Deadline),
+		task.CreatedBy,
+		task.UpdatedBy,
+		formatTime(updatedAt),
+		nullInt64(task.Assignee),
+		nullInt64(task.Reviewer),
+		task.Muted,
+		task.Epic,
+		nullInt64(int64(task.ParentID)),
+		task.Priority,
+		task.Estimate,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	task.ID = int(id)
+	task.UpdatedAt = updatedAt
+	if err = setCoAssignees(ctx, db, task); err != nil {
+		return err
+	}
+	return setLabels(ctx, db, task)
+}
+
+// setCoAssignees replaces co-assignees of saved task.
+func setCoAssignees(ctx context.Context, db execer, task *model.Task) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM task_assignees WHERE task_id = ?`, task.ID); err != nil {
+		return err
+	}
+	for _, userID := range task.CoAssignees {
+		const q = `INSERT OR IGNORE INTO task_assignees (task_id, user_id) VALUES (?, ?)`
+		if _, err := db.ExecContext(ctx, q, task.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setLabels replaces labels of saved task, labels missing in project are created
+// and ones no longer attached to its tasks are removed.
+func setLabels(ctx context.Context, db execer, task *model.Task) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM task_labels WHERE task_id = ?`, task.ID); err != nil {
+		return err
+	}
+	for _, name := range task.Labels {
+		if _, err := db.ExecContext(ctx, `INSERT OR IGNORE INTO labels (project_id, name) VALUES (?, ?)`, task.ProjectID, name); err != nil {
+			return err
+		}
+		const q = `INSERT OR IGNORE INTO task_labels (task_id, label_id) SELECT ?, id FROM labels WHERE project_id = ? AND name = ?`
+		if _, err := db.ExecContext(ctx, q, task.ID, task.ProjectID, name); err != nil {
+			return err
+		}
+	}
+	const q = `DELETE FROM labels WHERE project_id = ? AND id NOT IN (SELECT label_id FROM task_labels)`
+	_, err := db.ExecContext(ctx, q, task.ProjectID)
+	return err
+}
+
+func (s *TaskStorage) CountTaskLabels(ctx context.Context, projectID int) ([]model.LabelCount, error) {
+	const q = `SELECT l.name, COUNT(*) FROM task_labels tl
+	JOIN labels l ON l.id = tl.label_id
+	JOIN tasks t ON t.id = tl.task_id
+	WHERE t.project_id = ? AND t.status NOT IN (?, ?) AND t.deleted_at IS NULL
+	GROUP BY l.name ORDER BY l.name`
+	rows, err := s.reader.QueryContext(ctx, q, projectID, model.TaskStatusDone, model.TaskStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []model.LabelCount
+	for rows.Next() {
+		var c model.LabelCount
+		if err = rows.Scan(&c.Name, &c.Tasks); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (s *TaskStorage) UpdateTask(ctx context.Context, task *model.Task) error {
+	return s.UpdateTasks(ctx, []*model.Task{task})
+}
+
+func (s *TaskStorage) UpdateTasks(ctx context.Context, tasks []*model.Task) error {
+	return s.updateTasks(ctx, tasks, nil)
+}
+
+func (s *TaskStorage) UpdateTasksWithNotifications(ctx context.Context, tasks []*model.Task, notifications []model.Notification) error {
+	return s.updateTasks(ctx, tasks, notifications)
+}
+
+func (s *TaskStorage) updateTasks(ctx context.Context, tasks []*model.Task, notifications []model.Notification) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	updatedAt := now()
+	for _, task := range tasks {
+		if err = recordTaskHistory(ctx, tx, task, updatedAt); err != nil {
+			return err
+		}
+		if err = updateTask(ctx, tx, task, updatedAt); err != nil {
+			return err
+		}
+	}
+	for _, n := range notifications {
+		if err = enqueueNotification(ctx, tx, n, updatedAt); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		task.UpdatedAt = updatedAt
+	}
+	return nil
+}
+
+func updateTask(ctx context.Context, db execer, task *model.Task, updatedAt time.Time) error {
+	const q = `UPDATE tasks
+	SET title = ?, description = ?, status = ?, deadline = ?, updated_by = ?, updated_at = ?, assignee = ?, reviewer = ?, muted = ?,
+		epic = ?, parent_id = ?, priority = ?, estimate = ?
+	WHERE id = ?`
+	_, err := db.ExecContext(ctx, q,
+		task.Title,
+		nullString(task.Description),
+		task.Status,
+		nullTime(task.Deadline),
+		task.UpdatedBy,
+		formatTime(updatedAt),
+		nullInt64(task.Assignee),
+		nullInt64(task.Reviewer),
+		task.Muted,
+		task.Epic,
+		nullInt64(int64(task.ParentID)),
+		task.Priority,
+		task.Estimate,
+		task.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if err = setCoAssignees(ctx, db, task); err != nil {
+		return err
+	}
+	return setLabels(ctx, db, task)
+}
+
+func (s *TaskStorage) SetTaskOverdueNotified(ctx context.Context, id int, notified bool) error {
+	const q = `UPDATE tasks SET overdue_notified = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q, notified, id)
+	return err
+}
+
+func (s *TaskStorage) SetTaskEscalated(ctx context.Context, id int, escalated bool) error {
+	const q = `UPDATE tasks SET escalated = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, q, escalated, id)
+	return err
+}
+
+func (s *TaskStorage) RemoveTask(ctx context.Context, id int) error {
+	const q = `UPDATE tasks SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	_, err := s.db.ExecContext(ctx, q, formatTime(now()), id)
+	return err
+}
+
+func (s *TaskStorage) RestoreTask(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE tasks SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	restored, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if restored == 0 {
+		return model.ErrTaskNotFound
+	}
+	return nil
+}
+
+func (s *TaskStorage) FetchDeletedTasks(ctx context.Context, projectID int) ([]model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks
+	WHERE project_id = ? AND deleted_at IS NOT NULL
+	ORDER BY deleted_at DESC, id DESC`
+	return s.queryTasks(ctx, q, projectID)
+}
+
+func (s *TaskStorage) PurgeDeletedTasks(ctx context.Context, before time.Time) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id FROM tasks WHERE deleted_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Every task is purged in its own transaction, so long cleanup doesn't block writes
+	for _, id := range ids {
+		if err = s.PurgeTask(ctx, id); err != nil {
+			return 0, fmt.Errorf("could not purge task id=%d: %w", id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+func (s *TaskStorage) PurgeTask(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Foreign keys are not enforced, so relations are removed explicitly
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_assignees WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_labels WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM checklist_items WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_comments WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_history WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_attachments WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_watchers WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM alert_tasks WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_links WHERE task_id = ? OR blocker_id = ?`, id, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM task_recurrences WHERE task_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM labels WHERE id NOT IN (SELECT label_id FROM task_labels)`); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE tasks SET parent_id = NULL WHERE parent_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *TaskStorage) CountTasksByStatus(ctx context.Context, projectID int) (map[model.TaskStatus]int, error) {
+	const q = `SELECT status, COUNT(*) FROM tasks WHERE project_id = ? AND deleted_at IS NULL GROUP BY status`
+	rows, err := s.reader.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[model.TaskStatus]int)
+	for rows.Next() {
+		var (
+			status model.TaskStatus
+			count  int
+		)
+		if err = rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (s *TaskStorage) FetchTaskCounters(ctx context.Context, projectID int, now time.Time) (model.TaskCounters, error) {
+	const q = `SELECT
+		COALESCE(SUM(status = ?), 0),
+		COALESCE(SUM(deadline < ? AND status NOT IN (?, ?)), 0),
+		COALESCE(SUM(status = ?), 0),
+		COALESCE(SUM(CASE WHEN status NOT IN (?, ?) THEN estimate END), 0)
+	FROM tasks WHERE project_id = ? AND deleted_at IS NULL`
+	var counters model.TaskCounters
+	err := s.reader.QueryRowContext(ctx, q,
+		model.TaskStatusInProgress,
+		formatTime(now),
+		model.TaskStatusDone,
+		model.TaskStatusCancelled,
+		model.TaskStatusTODO,
+		model.TaskStatusDone,
+		model.TaskStatusCancelled,
+		projectID,
+	).Scan(
+		&counters.InProgress,
+		&counters.Overdue,
+		&counters.Queued,
+		&counters.OpenPoints,
+	)
+	return counters, err
+}
+
+func (s *TaskStorage) FetchUpcomingDeadlines(ctx context.Context, projectID int, limit int) ([]model.Task, error) {
+	const q = `SELECT ` + taskFields + ` FROM tasks
+	WHERE project_id = ? AND deadline IS NOT NULL AND status NOT IN (?, ?) AND deleted_at IS NULL
+	ORDER BY deadline, rank, id LIMIT ?`
+	rows, err := s.reader.QueryContext(ctx, q, projectID, model.TaskStatusDone, model.TaskStatusCancelled, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTasks(rows)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row rowScanner) (*model.Task, error) {
+	var (
+		task        model.Task
+		description sql.NullString
+		deadline    sql.NullString
+		updatedAt   sql.NullString
+		assignee    sql.NullInt64
+		reviewer    sql.NullInt64
+		parentID    sql.NullInt64
+		coAssignees sql.NullString
+		labels      sql.NullString
+		deletedAt   sql.NullString
+	)
+	err := row.Scan(
+		&task.ID,
+		&task.ProjectID,
+		&task.Title,
+		&description,
+		&task.Status,
+		&deadline,
+		&task.CreatedBy,
+		&task.UpdatedBy,
+		&updatedAt,
+		&assignee,
+		&reviewer,
+		&task.OverdueNotified,
+		&task.Escalated,
+		&task.Muted,
+		&task.Epic,
+		&parentID,
+		&task.Priority,
+		&task.Estimate,
+		&deletedAt,
+		&coAssignees,
+		&labels,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if coAssignees.Valid {
+		for _, raw := range strings.Split(coAssignees.String, ",") {
+			userID, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse co-assignee %q: %w", raw, err)
+			}
+			task.CoAssignees = append(task.CoAssignees, userID)
+		}
+		slices.Sort(task.CoAssignees)
+	}
+	if labels.Valid {
+		task.Labels = strings.Split(labels.String, "\n")
+		slices.Sort(task.Labels)
+	}
+	task.Description = description.String
+	task.Assignee = assignee.Int64
+	task.Reviewer = reviewer.Int64
+	task.ParentID = int(parentID.Int64)
+	if task.Deadline, err = parseTime(deadline); err != nil {
+		return nil, err
+	}
+	if task.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if task.DeletedAt, err = parseTime(deletedAt); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func scanTasks(rows *sql.Rows) ([]model.Task, error) {
+	var tasks []model.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullInt64(n int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: n, Valid: n != 0}
+}
@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+const templateFields = `id, project_id, name, title, description, assignee, labels, created_by`
+
+func (s *TaskStorage) SaveTaskTemplate(ctx context.Context, tpl *model.TaskTemplate) error {
+	const q = `INSERT INTO task_templates (project_id, name, name_key, title, description, assignee, labels, created_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (project_id, name_key) DO UPDATE SET name = excluded.name, title = excluded.title,
+	description = excluded.description, assignee = excluded.assignee, labels = excluded.labels, created_by = excluded.created_by
+	RETURNING id`
+	row := s.db.QueryRowContext(ctx, q,
+		tpl.ProjectID,
+		tpl.Name,
+		strings.ToLower(tpl.Name),
+		tpl.Title,
+		tpl.Description,
+		nullInt64(tpl.Assignee),
+		strings.Join(tpl.Labels, "\n"),
+		tpl.CreatedBy,
+	)
+	return row.Scan(&tpl.ID)
+}
+
+func (s *TaskStorage) FetchTaskTemplates(ctx context.Context, projectID int) ([]model.TaskTemplate, error) {
+	const q = `SELECT ` + templateFields + ` FROM task_templates WHERE project_id = ? ORDER BY name_key`
+	rows, err := s.reader.QueryContext(ctx, q, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []model.TaskTemplate
+	for rows.Next() {
+		tpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *tpl)
+	}
+	return templates, rows.Err()
+}
+
+func (s *TaskStorage) FetchTaskTemplate(ctx context.Context, id int) (*model.TaskTemplate, error) {
+	const q = `SELECT ` + templateFields + ` FROM task_templates WHERE id = ?`
+	tpl, err := scanTemplate(s.reader.QueryRowContext(ctx, q, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return tpl, nil
+}
+
+func (s *TaskStorage) RemoveTaskTemplate(ctx context.Context, id int) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM task_templates WHERE id = ?`, id)
+	return err
+}
+
+func scanTemplate(row rowScanner) (*model.TaskTemplate, error) {
+	var (
+		tpl      model.TaskTemplate
+		assignee sql.NullInt64
+		labels   string
+	)
+	if err := row.Scan(&tpl.ID, &tpl.ProjectID, &tpl.Name, &tpl.Title, &tpl.Description, &assignee, &labels, &tpl.CreatedBy); err != nil {
+		return nil, err
+	}
+	tpl.Assignee = assignee.Int64
+	if labels != "" {
+		tpl.Labels = strings.Split(labels, "\n")
+	}
+	return &tpl, nil
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// timeLayout is the storage format of all timestamps. Times are kept in UTC without fractional seconds,
+// so stored values have fixed width and compare correctly as strings in queries.
+const timeLayout = "2006-01-02T15:04:05Z"
+
+// now returns current time with precision of stored timestamps.
+func now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTime converts zero time to NULL.
+func nullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(t), Valid: true}
+}
+
+// parseTime converts stored timestamp to local time, NULL becomes zero time.
+func parseTime(s sql.NullString) (time.Time, error) {
+	if !s.Valid || s.String == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(timeLayout, s.String)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Local(), nil
+}
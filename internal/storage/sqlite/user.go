@@ -9,10 +9,13 @@ import (
 
 type UserStorage struct {
 	db *sql.DB
+	// reader runs queries outside of transactions, see ConnectReader.
+	reader *sql.DB
 }
 
-func NewUserStorage(db *sql.DB) *UserStorage {
-	return &UserStorage{db: db}
+// NewUserStorage returns storage writing through db and reading through reader, which may be db itself.
+func NewUserStorage(db, reader *sql.DB) *UserStorage {
+	return &UserStorage{db: db, reader: reader}
 }
 
 func (s *UserStorage) FetchUserRoleInProject(ctx context.Context, projectID int, user *model.User) error {
@@ -21,7 +24,7 @@ func (s *UserStorage) FetchUserRoleInProject(ctx context.Context, projectID int,
 	WHERE up.project_id = ? AND u.id = ?`
 
 	var roleStr string
-	err := s.db.QueryRowContext(ctx, query, projectID, user.ID).Scan(&roleStr)
+	err := s.reader.QueryRowContext(ctx, query, projectID, user.ID).Scan(&roleStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return model.ErrUserNotFound
@@ -33,8 +36,8 @@ func (s *UserStorage) FetchUserRoleInProject(ctx context.Context, projectID int,
 }
 
 func (s *UserStorage) CreateUser(ctx context.Context, user *model.User) error {
-	const query = `INSERT INTO users (tg_user_id, full_name, is_active) VALUES (?, ?, ?)`
-	result, err := s.db.ExecContext(ctx, query, user.TgUserID, user.FullName, user.IsActive)
+	const query = `INSERT INTO users (tg_user_id, username, full_name, is_active) VALUES (?, ?, ?, ?)`
+	result, err := s.db.ExecContext(ctx, query, user.TgUserID, user.Username, user.FullName, user.IsActive)
 	if err != nil {
 		return err
 	}
@@ -48,14 +51,93 @@ func (s *UserStorage) CreateUser(ctx context.Context, user *model.User) error {
 	return nil
 }
 
+func (s *UserStorage) FetchUserByID(ctx context.Context, id int) (*model.User, error) {
+	const query = `SELECT id, tg_user_id, username, full_name, is_active, caldav_token, unreachable FROM users WHERE id = ?`
+	return s.fetchUser(ctx, query, id)
+}
+
 func (s *UserStorage) FetchUserByTgID(ctx context.Context, tgUserID int64) (*model.User, error) {
-	const query = `SELECT id, tg_user_id, full_name, is_active FROM users WHERE tg_user_id = ?`
-	var user model.User
-	err := s.db.QueryRowContext(ctx, query, tgUserID).Scan(
+	const query = `SELECT id, tg_user_id, username, full_name, is_active, caldav_token, unreachable FROM users WHERE tg_user_id = ?`
+	return s.fetchUser(ctx, query, tgUserID)
+}
+
+func (s *UserStorage) FetchUserByCalDAVToken(ctx context.Context, token string) (*model.User, error) {
+	const query = `SELECT id, tg_user_id, username, full_name, is_active, caldav_token, unreachable FROM users WHERE caldav_token = ?`
+	return s.fetchUser(ctx, query, token)
+}
+
+func (s *UserStorage) FetchProjectUserByUsername(ctx context.Context, projectID int, username string) (*model.User, error) {
+	const query = `SELECT u.id, u.tg_user_id, u.username, u.full_name, u.is_active, u.caldav_token, u.unreachable FROM users u
+	JOIN user_projects up ON u.id = up.user_id
+	WHERE up.project_id = ? AND u.username = ? COLLATE NOCASE`
+	return s.fetchUser(ctx, query, projectID, username)
+}
+
+// FetchProjectUserByPreviousUsername returns project member who used username before, the latest one if there are several.
+func (s *UserStorage) FetchProjectUserByPreviousUsername(ctx context.Context, projectID int, username string) (*model.User, error) {
+	const query = `SELECT u.id, u.tg_user_id, u.username, u.full_name, u.is_active, u.caldav_token, u.unreachable FROM users u
+	JOIN user_projects up ON u.id = up.user_id
+	JOIN user_usernames uu ON u.id = uu.user_id
+	WHERE up.project_id = ? AND uu.username = ? COLLATE NOCASE
+	ORDER BY uu.changed_at DESC
+	LIMIT 1`
+	return s.fetchUser(ctx, query, projectID, username)
+}
+
+// FetchProjectUsers returns members of project with their roles ordered by id.
+func (s *UserStorage) FetchProjectUsers(ctx context.Context, projectID int) ([]model.User, error) {
+	const query = `SELECT u.id, u.tg_user_id, u.username, u.full_name, u.is_active, u.caldav_token, u.unreachable, up.user_role
+	FROM users u
+	JOIN user_projects up ON u.id = up.user_id
+	WHERE up.project_id = ?
+	ORDER BY u.id`
+	rows, err := s.reader.QueryContext(ctx, query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var (
+			user        model.User
+			caldavToken sql.NullString
+		)
+		err = rows.Scan(
+			&user.ID,
+			&user.TgUserID,
+			&user.Username,
+			&user.FullName,
+			&user.IsActive,
+			&caldavToken,
+			&user.Unreachable,
+			&user.Role,
+		)
+		if err != nil {
+			return nil, err
+		}
+		user.CalDAVToken = caldavToken.String
+		users = append(users, user)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *UserStorage) fetchUser(ctx context.Context, query string, args ...interface{}) (*model.User, error) {
+	var (
+		user        model.User
+		caldavToken sql.NullString
+	)
+	err := s.reader.QueryRowContext(ctx, query, args...).Scan(
 		&user.ID,
 		&user.TgUserID,
+		&user.Username,
 		&user.FullName,
 		&user.IsActive,
+		&caldavToken,
+		&user.Unreachable,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -63,25 +145,68 @@ func (s *UserStorage) FetchUserByTgID(ctx context.Context, tgUserID int64) (*mod
 		}
 		return nil, err
 	}
+	user.CalDAVToken = caldavToken.String
 	return &user, nil
 }
 
-func (s *UserStorage) UpdateUser(ctx context.Context, user *model.User) error {
-	const query = ` UPDATE users SET full_name = ?, is_active = ? WHERE id = ?`
-	_, err := s.db.ExecContext(ctx, query, user.FullName, user.IsActive, user.ID)
+func (s *UserStorage) UpdateUserCalDAVToken(ctx context.Context, userID int, token string) error {
+	const query = `UPDATE users SET caldav_token = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, query, nullString(token), userID)
 	return err
 }
 
+func (s *UserStorage) SetUserUnreachable(ctx context.Context, tgUserID int64, unreachable bool) error {
+	const query = `UPDATE users SET unreachable = ? WHERE tg_user_id = ?`
+	_, err := s.db.ExecContext(ctx, query, unreachable, tgUserID)
+	return err
+}
+
+// UpdateUser saves user, replaced username is kept in history.
+func (s *UserStorage) UpdateUser(ctx context.Context, user *model.User) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const history = `INSERT OR REPLACE INTO user_usernames (user_id, username, changed_at)
+	SELECT id, username, ? FROM users WHERE id = ? AND username != '' AND username != ?`
+	if _, err = tx.ExecContext(ctx, history, formatTime(now()), user.ID, user.Username); err != nil {
+		return err
+	}
+	const query = `UPDATE users SET username = ?, full_name = ?, is_active = ? WHERE id = ?`
+	if _, err = tx.ExecContext(ctx, query, user.Username, user.FullName, user.IsActive, user.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (s *UserStorage) AddUserToProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) error {
 	const query = `INSERT INTO user_projects (user_id, project_id, user_role) VALUES (?, ?, ?) `
 	_, err := s.db.ExecContext(ctx, query, userID, projectID, string(role))
 	return err
 }
 
+func (s *UserStorage) SetUserRoleInProject(ctx context.Context, projectID int, userID int, role model.UserProjectRole) error {
+	const query = `UPDATE user_projects SET user_role = ? WHERE project_id = ? AND user_id = ?`
+	res, err := s.db.ExecContext(ctx, query, string(role), projectID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return model.ErrUserNotFound
+	}
+	return nil
+}
+
 func (s *UserStorage) CountUsersInProject(ctx context.Context, projectID int) (int, error) {
 	const query = `SELECT COUNT(*) FROM user_projects WHERE project_id = ?`
 	var count int
-	err := s.db.QueryRowContext(ctx, query, projectID).Scan(&count)
+	err := s.reader.QueryRowContext(ctx, query, projectID).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,35 @@
+package sqlite
+
+import "context"
+
+func (s *TaskStorage) SetTaskWatcher(ctx context.Context, taskID int, userID int64, watching bool) error {
+	q := `INSERT OR IGNORE INTO task_watchers (task_id, user_id) VALUES (?, ?)`
+	if !watching {
+		q = `DELETE FROM task_watchers WHERE task_id = ? AND user_id = ?`
+	}
+	_, err := s.db.ExecContext(ctx, q, taskID, userID)
+	return err
+}
+
+func (s *TaskStorage) FetchTaskWatchers(ctx context.Context, taskID int) ([]int64, error) {
+	const q = `SELECT w.user_id FROM task_watchers w
+	JOIN tasks t ON t.id = w.task_id
+	JOIN user_projects up ON up.user_id = w.user_id AND up.project_id = t.project_id
+	WHERE w.task_id = ?
+	ORDER BY w.user_id`
+	rows, err := s.reader.QueryContext(ctx, q, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var watchers []int64
+	for rows.Next() {
+		var userID int64
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		watchers = append(watchers, userID)
+	}
+	return watchers, rows.Err()
+}
@@ -0,0 +1,1922 @@
+// Package storagetest contains contract tests every storage backend must pass,
+// so implementations behave identically, including returned errors.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// Repositories is a set of storages of one backend sharing the same database.
+type Repositories struct {
+	Projects model.ProjectRepository
+	Users    model.UserRepository
+	Tasks    model.TaskRepository
+	Leases   model.LeaseRepository
+	Outbox   model.OutboxRepository
+	Tokens   model.APITokenRepository
+
+	Maintenance model.MaintenanceRepository
+	EventLog    model.TaskEventLogRepository
+}
+
+// Run executes all contract tests, newRepos must return repositories backed by empty migrated database.
+// It is called for every test, so tests do not depend on each other.
+func Run(t *testing.T, newRepos func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories)
+	}{
+		{"ProjectCRUD", testProjectCRUD},
+		{"DeleteProject", testDeleteProject},
+		{"ProjectNotFound", testProjectNotFound},
+		{"ProjectHolidays", testProjectHolidays},
+		{"ProjectLinks", testProjectLinks},
+		{"UserCRUD", testUserCRUD},
+		{"UserNotFound", testUserNotFound},
+		{"UserProjects", testUserProjects},
+		{"UserCalDAVToken", testUserCalDAVToken},
+		{"UserUnreachable", testUserUnreachable},
+		{"UserPreviousUsernames", testUserPreviousUsernames},
+		{"OrphanUsers", testOrphanUsers},
+		{"TaskCRUD", testTaskCRUD},
+		{"TaskDefaultPriority", testTaskDefaultPriority},
+		{"TaskLabels", testTaskLabels},
+		{"TaskNotFound", testTaskNotFound},
+		{"Checklist", testChecklist},
+		{"TaskComments", testTaskComments},
+		{"TaskAttachments", testTaskAttachments},
+		{"TaskWatchers", testTaskWatchers},
+		{"AlertTasks", testAlertTasks},
+		{"TaskBlockers", testTaskBlockers},
+		{"TaskRecurrences", testTaskRecurrences},
+		{"TaskTemplates", testTaskTemplates},
+		{"TaskSnapshots", testTaskSnapshots},
+		{"TaskHistory", testTaskHistory},
+		{"MoveTask", testMoveTask},
+		{"TaskTrash", testTaskTrash},
+		{"TaskDeadline", testTaskDeadline},
+		{"FilterTasks", testFilterTasks},
+		{"CreateTasks", testCreateTasks},
+		{"UpdateTasks", testUpdateTasks},
+		{"Outbox", testOutbox},
+		{"Epics", testEpics},
+		{"TaskCounters", testTaskCounters},
+		{"UpcomingDeadlines", testUpcomingDeadlines},
+		{"Leases", testLeases},
+		{"APITokens", testAPITokens},
+		{"TaskEventLog", testTaskEventLog},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepos(t))
+		})
+	}
+}
+
+func testProjectCRUD(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := model.NewProject("Alpha", -100123)
+	prj.PublicToken = "token"
+	prj.DefaultDeadlineDays = 7
+	prj.EscalationDays = 2
+	if err := r.Projects.CreateProject(ctx, prj); err != nil {
+		t.Fatalf("create project: %s", err)
+	}
+	if prj.ID == 0 {
+		t.Fatal("create project: id is not set")
+	}
+
+	got, err := r.Projects.FetchProjectByID(ctx, prj.ID)
+	if err != nil {
+		t.Fatalf("fetch project by id: %s", err)
+	}
+	if *got != *prj {
+		t.Fatalf("fetch project by id: got %+v, want %+v", *got, *prj)
+	}
+	if got, err = r.Projects.FetchProjectByChatID(ctx, prj.TgChatID); err != nil || got.ID != prj.ID {
+		t.Fatalf("fetch project by chat id: got %+v, %v", got, err)
+	}
+	if got, err = r.Projects.FetchProjectByPublicToken(ctx, prj.PublicToken); err != nil || got.ID != prj.ID {
+		t.Fatalf("fetch project by public token: got %+v, %v", got, err)
+	}
+
+	prj.Title = "Beta"
+	prj.Archived = true
+	prj.QuickCapture = true
+	prj.BoardMessageID = 42
+	prj.PublicToken = ""
+	prj.DefaultDeadlineDays = 0
+	prj.EscalationDays = 0
+	prj.Frozen = true
+	prj.WelcomeText = "Правила чата"
+	prj.WelcomePrivately = true
+	if err = r.Projects.UpdateProject(ctx, prj); err != nil {
+		t.Fatalf("update project: %s", err)
+	}
+	if got, err = r.Projects.FetchProjectByID(ctx, prj.ID); err != nil || *got != *prj {
+		t.Fatalf("fetch updated project: got %+v, %v, want %+v", got, err, *prj)
+	}
+
+	member := createUser(t, r, 1, "Member")
+	if err = r.Users.AddUserToProject(ctx, prj.ID, member.ID, model.UserProjectRoleMember); err != nil {
+		t.Fatalf("add user to project: %s", err)
+	}
+	if err = r.Projects.DeleteProject(ctx, prj.ID); err != nil {
+		t.Fatalf("delete project: %s", err)
+	}
+	if _, err = r.Projects.FetchProjectByID(ctx, prj.ID); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Fatalf("fetch deleted project: got %v, want %v", err, model.ErrProjectNotFound)
+	}
+	// Members are detached from deleted project.
+	if err = r.Users.FetchUserRoleInProject(ctx, prj.ID, member); !errors.Is(err, model.ErrUserNotFound) {
+		t.Fatalf("fetch role in deleted project: got %v, want %v", err, model.ErrUserNotFound)
+	}
+}
+
+func testDeleteProject(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Labels = []string{"bug"}
+	})
+	if err := r.Tasks.AddTaskComment(ctx, &model.TaskComment{TaskID: task.ID, AuthorID: int64(author.ID), Text: "note"}); err != nil {
+		t.Fatalf("add comment: %s", err)
+	}
+	token := &model.APIToken{ProjectID: prj.ID, Hash: model.HashAPIToken("secret"), Prefix: "secr", Scope: model.APITokenScopeReadWrite, CreatedBy: 1}
+	if err := r.Tokens.CreateAPIToken(ctx, token); err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	if err := r.Users.AddUserToProject(ctx, prj.ID, author.ID, model.UserProjectRoleManager); err != nil {
+		t.Fatalf("add user to project: %s", err)
+	}
+	createTask(t, r, prj.ID, author, func(*model.Task) {})
+	other := createProject(t, r, -100789)
+	createTask(t, r, other.ID, author, func(*model.Task) {})
+
+	want := model.ProjectContents{Tasks: 2, Members: 1, Comments: 1}
+	if got, err := r.Projects.CountProjectContents(ctx, prj.ID); err != nil || got != want {
+		t.Fatalf("count project contents: got %+v, %v, want %+v", got, err, want)
+	}
+
+	if err := r.Projects.DeleteProject(ctx, prj.ID); err != nil {
+		t.Fatalf("delete project: %s", err)
+	}
+	if _, err := r.Tasks.FetchTaskByID(ctx, task.ID); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch task of deleted project: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+	if comments, total, err := r.Tasks.FetchTaskComments(ctx, task.ID, 10); err != nil || total != 0 {
+		t.Fatalf("fetch comments of deleted project: got %+v, %d, %v", comments, total, err)
+	}
+	if _, err := r.Tokens.FetchAPITokenByHash(ctx, token.Hash); !errors.Is(err, model.ErrAPITokenNotFound) {
+		t.Fatalf("fetch api token of deleted project: got %v, want %v", err, model.ErrAPITokenNotFound)
+	}
+	if got, err := r.Projects.CountProjectContents(ctx, prj.ID); err != nil || got != (model.ProjectContents{}) {
+		t.Fatalf("count contents of deleted project: got %+v, %v", got, err)
+	}
+
+	// Id of deleted project is not reused, so nothing left by it could show up in the next one.
+	next := createProject(t, r, -100456)
+	if next.ID == prj.ID {
+		t.Fatalf("create project after delete: got id %d of deleted project", next.ID)
+	}
+	if tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: next.ID}); err != nil || len(tasks) != 0 {
+		t.Fatalf("filter tasks of next project: got %v, %v", taskIDs(tasks), err)
+	}
+}
+
+func testProjectNotFound(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	if _, err := r.Projects.FetchProjectByID(ctx, 1); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("fetch project by id: got %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if _, err := r.Projects.FetchProjectByChatID(ctx, 1); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("fetch project by chat id: got %v, want %v", err, model.ErrProjectNotFound)
+	}
+	if _, err := r.Projects.FetchProjectByPublicToken(ctx, "token"); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("fetch project by public token: got %v, want %v", err, model.ErrProjectNotFound)
+	}
+}
+
+func testProjectHolidays(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	newYear := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.Local)
+	christmas := time.Date(2030, time.January, 7, 0, 0, 0, 0, time.Local)
+
+	days, err := r.Projects.FetchProjectHolidays(ctx, prj.ID)
+	if err != nil || len(days) != 0 {
+		t.Fatalf("fetch holidays of new project: got %v, %v", days, err)
+	}
+
+	if err = r.Projects.SetProjectHolidays(ctx, prj.ID, []time.Time{christmas, newYear, newYear}); err != nil {
+		t.Fatalf("set holidays: %s", err)
+	}
+	if err = r.Projects.SetProjectHolidays(ctx, other.ID, []time.Time{christmas}); err != nil {
+		t.Fatalf("set holidays of other project: %s", err)
+	}
+	days, err = r.Projects.FetchProjectHolidays(ctx, prj.ID)
+	if err != nil || !slices.EqualFunc(days, []time.Time{newYear, christmas}, time.Time.Equal) {
+		t.Fatalf("fetch holidays: got %v, %v", days, err)
+	}
+
+	if err = r.Projects.SetProjectHolidays(ctx, prj.ID, nil); err != nil {
+		t.Fatalf("clear holidays: %s", err)
+	}
+	if days, err = r.Projects.FetchProjectHolidays(ctx, prj.ID); err != nil || len(days) != 0 {
+		t.Fatalf("fetch cleared holidays: got %v, %v", days, err)
+	}
+	if days, err = r.Projects.FetchProjectHolidays(ctx, other.ID); err != nil || len(days) != 1 {
+		t.Fatalf("fetch holidays of other project: got %v, %v", days, err)
+	}
+}
+
+func testProjectLinks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+
+	if links, err := r.Projects.FetchProjectLinks(ctx, prj.ID); err != nil || len(links) != 0 {
+		t.Fatalf("fetch links of new project: got %v, %v", links, err)
+	}
+
+	staging := &model.ProjectLink{ProjectID: prj.ID, Title: "Стенд", URL: "https://staging.example.com", CreatedBy: int64(author.ID)}
+	repo := &model.ProjectLink{ProjectID: prj.ID, Title: "Репозиторий", URL: "https://git.example.com", CreatedBy: int64(author.ID)}
+	for _, link := range []*model.ProjectLink{staging, repo} {
+		if err := r.Projects.SaveProjectLink(ctx, link); err != nil {
+			t.Fatalf("save link: %s", err)
+		}
+	}
+	links, err := r.Projects.FetchProjectLinks(ctx, prj.ID)
+	if err != nil || !slices.Equal(links, []model.ProjectLink{*repo, *staging}) {
+		t.Fatalf("fetch links: got %v, %v", links, err)
+	}
+
+	// Link with the same title ignoring case replaces the saved one.
+	replaced := &model.ProjectLink{ProjectID: prj.ID, Title: "стенд", URL: "https://stage.example.com", CreatedBy: int64(author.ID)}
+	if err = r.Projects.SaveProjectLink(ctx, replaced); err != nil {
+		t.Fatalf("replace link: %s", err)
+	}
+	if replaced.ID != staging.ID {
+		t.Fatalf("replace link: got id %d, want %d", replaced.ID, staging.ID)
+	}
+	if links, err = r.Projects.FetchProjectLinks(ctx, prj.ID); err != nil || !slices.Equal(links, []model.ProjectLink{*repo, *replaced}) {
+		t.Fatalf("fetch replaced links: got %v, %v", links, err)
+	}
+
+	if err = r.Projects.RemoveProjectLink(ctx, repo.ID); err != nil {
+		t.Fatalf("remove link: %s", err)
+	}
+	if links, err = r.Projects.FetchProjectLinks(ctx, prj.ID); err != nil || !slices.Equal(links, []model.ProjectLink{*replaced}) {
+		t.Fatalf("fetch links after removal: got %v, %v", links, err)
+	}
+
+	// Links are removed with their project.
+	if err = r.Projects.DeleteProject(ctx, prj.ID); err != nil {
+		t.Fatalf("delete project: %s", err)
+	}
+	if links, err = r.Projects.FetchProjectLinks(ctx, prj.ID); err != nil || len(links) != 0 {
+		t.Fatalf("fetch links of deleted project: got %v, %v", links, err)
+	}
+}
+
+func testUserCRUD(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	user := model.NewUser(123)
+	user.Username = "john"
+	user.FullName = "John Doe"
+	if err := r.Users.CreateUser(ctx, user); err != nil {
+		t.Fatalf("create user: %s", err)
+	}
+	if user.ID == 0 {
+		t.Fatal("create user: id is not set")
+	}
+
+	got, err := r.Users.FetchUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("fetch user by id: %s", err)
+	}
+	if *got != *user {
+		t.Fatalf("fetch user by id: got %+v, want %+v", *got, *user)
+	}
+	if got, err = r.Users.FetchUserByTgID(ctx, user.TgUserID); err != nil || got.ID != user.ID {
+		t.Fatalf("fetch user by tg id: got %+v, %v", got, err)
+	}
+
+	user.Username = "johnny"
+	user.FullName = "Johnny Doe"
+	user.IsActive = false
+	if err = r.Users.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("update user: %s", err)
+	}
+	if got, err = r.Users.FetchUserByID(ctx, user.ID); err != nil || *got != *user {
+		t.Fatalf("fetch updated user: got %+v, %v, want %+v", got, err, *user)
+	}
+}
+
+func testUserNotFound(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	if _, err := r.Users.FetchUserByID(ctx, 1); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch user by id: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	if _, err := r.Users.FetchUserByTgID(ctx, 1); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch user by tg id: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	if _, err := r.Users.FetchUserByCalDAVToken(ctx, "token"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch user by caldav token: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	if _, err := r.Users.FetchProjectUserByUsername(ctx, 1, "john"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch project user by username: got %v, want %v", err, model.ErrUserNotFound)
+	}
+}
+
+func testUserProjects(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	manager := createUser(t, r, 1, "Manager")
+	member := createUser(t, r, 2, "Member")
+	outsider := createUser(t, r, 3, "Outsider")
+
+	if err := r.Users.AddUserToProject(ctx, prj.ID, manager.ID, model.UserProjectRoleManager); err != nil {
+		t.Fatalf("add manager to project: %s", err)
+	}
+	if err := r.Users.AddUserToProject(ctx, prj.ID, member.ID, model.UserProjectRoleMember); err != nil {
+		t.Fatalf("add member to project: %s", err)
+	}
+
+	count, err := r.Users.CountUsersInProject(ctx, prj.ID)
+	if err != nil || count != 2 {
+		t.Fatalf("count users in project: got %d, %v, want 2", count, err)
+	}
+	users, err := r.Users.FetchProjectUsers(ctx, prj.ID)
+	if err != nil {
+		t.Fatalf("fetch project users: %s", err)
+	}
+	manager.Role, member.Role = model.UserProjectRoleManager, model.UserProjectRoleMember
+	if want := []model.User{*manager, *member}; !slices.Equal(users, want) {
+		t.Fatalf("fetch project users: got %+v, want %+v", users, want)
+	}
+
+	for _, tt := range []struct {
+		user *model.User
+		role model.UserProjectRole
+	}{
+		{manager, model.UserProjectRoleManager},
+		{member, model.UserProjectRoleMember},
+	} {
+		if err = r.Users.FetchUserRoleInProject(ctx, prj.ID, tt.user); err != nil || tt.user.Role != tt.role {
+			t.Errorf("fetch role of %s: got %q, %v, want %q", tt.user.FullName, tt.user.Role, err, tt.role)
+		}
+	}
+	if err = r.Users.FetchUserRoleInProject(ctx, prj.ID, outsider); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch role of outsider: got %v, want %v", err, model.ErrUserNotFound)
+	}
+
+	// Usernames are matched case-insensitively and only among project members.
+	got, err := r.Users.FetchProjectUserByUsername(ctx, prj.ID, "MEMBER")
+	if err != nil || got.ID != member.ID {
+		t.Errorf("fetch project user by username: got %+v, %v", got, err)
+	}
+	if _, err = r.Users.FetchProjectUserByUsername(ctx, prj.ID, "outsider"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch outsider by username: got %v, want %v", err, model.ErrUserNotFound)
+	}
+
+	other := createProject(t, r, -100456)
+	if err = r.Users.AddUserToProject(ctx, other.ID, member.ID, model.UserProjectRoleManager); err != nil {
+		t.Fatalf("add member to other project: %s", err)
+	}
+	for _, tt := range []struct {
+		user *model.User
+		role model.UserProjectRole
+		want []int
+	}{
+		{member, "", []int{prj.ID, other.ID}},
+		{member, model.UserProjectRoleManager, []int{other.ID}},
+		{manager, model.UserProjectRoleManager, []int{prj.ID}},
+		{outsider, "", nil},
+	} {
+		projects, err := r.Projects.FetchProjectsByUser(ctx, tt.user.ID, tt.role)
+		if err != nil {
+			t.Fatalf("fetch projects of %s: %s", tt.user.FullName, err)
+		}
+		var got []int
+		for _, p := range projects {
+			got = append(got, p.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("fetch %q projects of %s: got %v, want %v", tt.role, tt.user.FullName, got, tt.want)
+		}
+	}
+
+	all, err := r.Projects.ListProjects(ctx)
+	if err != nil || len(all) != 2 || all[0] != *prj || all[1] != *other {
+		t.Errorf("list projects: got %+v, %v, want %+v and %+v", all, err, *prj, *other)
+	}
+
+	if err = r.Users.SetUserRoleInProject(ctx, prj.ID, member.ID, model.UserProjectRoleManager); err != nil {
+		t.Fatalf("set role of member: %s", err)
+	}
+	if err = r.Users.FetchUserRoleInProject(ctx, prj.ID, member); err != nil || member.Role != model.UserProjectRoleManager {
+		t.Errorf("fetch changed role: got %q, %v, want %q", member.Role, err, model.UserProjectRoleManager)
+	}
+	err = r.Users.SetUserRoleInProject(ctx, prj.ID, outsider.ID, model.UserProjectRoleManager)
+	if !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("set role of outsider: got %v, want %v", err, model.ErrUserNotFound)
+	}
+}
+
+func testUserCalDAVToken(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	user := createUser(t, r, 1, "John")
+	if err := r.Users.UpdateUserCalDAVToken(ctx, user.ID, "token"); err != nil {
+		t.Fatalf("update caldav token: %s", err)
+	}
+	got, err := r.Users.FetchUserByCalDAVToken(ctx, "token")
+	if err != nil || got.ID != user.ID || got.CalDAVToken != "token" {
+		t.Fatalf("fetch user by caldav token: got %+v, %v", got, err)
+	}
+
+	if err = r.Users.UpdateUserCalDAVToken(ctx, user.ID, ""); err != nil {
+		t.Fatalf("reset caldav token: %s", err)
+	}
+	if _, err = r.Users.FetchUserByCalDAVToken(ctx, "token"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Fatalf("fetch user by revoked token: got %v, want %v", err, model.ErrUserNotFound)
+	}
+}
+
+func testUserUnreachable(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	user := createUser(t, r, 1, "John")
+	if err := r.Users.SetUserUnreachable(ctx, user.TgUserID, true); err != nil {
+		t.Fatalf("set user unreachable: %s", err)
+	}
+	got, err := r.Users.FetchUserByTgID(ctx, user.TgUserID)
+	if err != nil || !got.Unreachable {
+		t.Fatalf("fetch unreachable user: got %+v, %v", got, err)
+	}
+
+	// Profile updates must not reset reachability.
+	got.FullName = "Johnny"
+	if err = r.Users.UpdateUser(ctx, got); err != nil {
+		t.Fatalf("update user: %s", err)
+	}
+	if got, err = r.Users.FetchUserByTgID(ctx, user.TgUserID); err != nil || !got.Unreachable {
+		t.Fatalf("fetch updated user: got %+v, %v", got, err)
+	}
+
+	if err = r.Users.SetUserUnreachable(ctx, user.TgUserID, false); err != nil {
+		t.Fatalf("set user reachable: %s", err)
+	}
+	if got, err = r.Users.FetchUserByTgID(ctx, user.TgUserID); err != nil || got.Unreachable || got.FullName != "Johnny" {
+		t.Fatalf("fetch reachable user: got %+v, %v", got, err)
+	}
+}
+
+func testUserPreviousUsernames(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	member := createUser(t, r, 1, "Member")
+	outsider := createUser(t, r, 2, "Outsider")
+	if err := r.Users.AddUserToProject(ctx, prj.ID, member.ID, model.UserProjectRoleMember); err != nil {
+		t.Fatalf("add member to project: %s", err)
+	}
+
+	for _, user := range []*model.User{member, outsider} {
+		user.Username = "renamed_" + user.Username
+		if err := r.Users.UpdateUser(ctx, user); err != nil {
+			t.Fatalf("update user: %s", err)
+		}
+	}
+
+	if _, err := r.Users.FetchProjectUserByUsername(ctx, prj.ID, "member"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch by old username: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	got, err := r.Users.FetchProjectUserByPreviousUsername(ctx, prj.ID, "Member")
+	if err != nil || got.ID != member.ID || got.Username != "renamed_member" {
+		t.Errorf("fetch by previous username: got %+v, %v", got, err)
+	}
+	if _, err = r.Users.FetchProjectUserByPreviousUsername(ctx, prj.ID, "outsider"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch outsider by previous username: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	if _, err = r.Users.FetchProjectUserByPreviousUsername(ctx, prj.ID, "renamed_member"); !errors.Is(err, model.ErrUserNotFound) {
+		t.Errorf("fetch by current username: got %v, want %v", err, model.ErrUserNotFound)
+	}
+}
+
+func testOrphanUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	deleted, kept := createProject(t, r, -100), createProject(t, r, -200)
+	newMember := func(tgUserID int64, prj *model.Project) *model.User {
+		user := createUser(t, r, tgUserID, "User"+strconv.FormatInt(tgUserID, 10))
+		if err := r.Users.AddUserToProject(ctx, prj.ID, user.ID, model.UserProjectRoleMember); err != nil {
+			t.Fatalf("add user to project: %s", err)
+		}
+		return user
+	}
+	// Author leaves deleted project and task in kept one, stranger leaves nothing, member stays in kept project,
+	// newcomer never joined any project
+	author, stranger, member := newMember(1, deleted), newMember(2, deleted), newMember(3, kept)
+	newcomer := createUser(t, r, 4, "Newcomer")
+	createTask(t, r, kept.ID, author, func(*model.Task) {})
+	if err := r.Projects.DeleteProject(ctx, deleted.ID); err != nil {
+		t.Fatalf("delete project: %s", err)
+	}
+
+	// Users found without projects are kept for grace period
+	if n, err := r.Maintenance.DeleteOrphanUsers(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("delete users without projects within grace period: got %d, %v", n, err)
+	}
+	for _, user := range []*model.User{author, stranger, newcomer} {
+		if got, err := r.Users.FetchUserByID(ctx, user.ID); err != nil || got.FullName != user.FullName {
+			t.Fatalf("fetch user within grace period: got %+v, %v", got, err)
+		}
+	}
+	// Returned member is not cleaned up after grace period of the first leave
+	if err := r.Users.AddUserToProject(ctx, kept.ID, newcomer.ID, model.UserProjectRoleMember); err != nil {
+		t.Fatalf("add user to project: %s", err)
+	}
+	if n, err := r.Maintenance.DeleteOrphanUsers(ctx, time.Now().Add(time.Hour)); err != nil || n != 2 {
+		t.Fatalf("delete users without projects: got %d, %v", n, err)
+	}
+	if n, err := r.Maintenance.DeleteOrphanUsers(ctx, time.Now().Add(time.Hour)); err != nil || n != 0 {
+		t.Fatalf("delete users without projects again: got %d, %v", n, err)
+	}
+
+	if _, err := r.Users.FetchUserByID(ctx, stranger.ID); !errors.Is(err, model.ErrUserNotFound) {
+		t.Fatalf("fetch deleted user: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	got, err := r.Users.FetchUserByID(ctx, author.ID)
+	if err != nil || got.FullName != "" || got.Username != "" || got.TgUserID == author.TgUserID {
+		t.Fatalf("fetch anonymized user: got %+v, %v", got, err)
+	}
+	if _, err = r.Users.FetchUserByTgID(ctx, author.TgUserID); !errors.Is(err, model.ErrUserNotFound) {
+		t.Fatalf("fetch anonymized user by telegram id: got %v, want %v", err, model.ErrUserNotFound)
+	}
+	for _, user := range []*model.User{member, newcomer} {
+		if got, err = r.Users.FetchUserByID(ctx, user.ID); err != nil || got.FullName != user.FullName {
+			t.Fatalf("fetch member of kept project: got %+v, %v", got, err)
+		}
+	}
+}
+
+func testTaskCRUD(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	assignee := createUser(t, r, 2, "Assignee")
+	reviewer := createUser(t, r, 3, "Reviewer")
+
+	task := model.NewTask(prj.ID, "Write tests", int64(author.ID))
+	task.Description = "Contract tests for storages"
+	task.Assignee = int64(assignee.ID)
+	task.CoAssignees = []int64{int64(author.ID), int64(reviewer.ID)}
+	task.Reviewer = int64(reviewer.ID)
+	task.Muted = true
+	task.Priority = model.TaskPriorityHigh
+	task.Estimate = 3
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	if task.ID == 0 {
+		t.Fatal("create task: id is not set")
+	}
+	if task.UpdatedAt.IsZero() {
+		t.Fatal("create task: updated at is not set")
+	}
+
+	got, err := r.Tasks.FetchTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("fetch task: %s", err)
+	}
+	if !reflect.DeepEqual(got, task) {
+		t.Fatalf("fetch task: got %+v, want %+v", *got, *task)
+	}
+
+	task.Title = "Write more tests"
+	task.Description = ""
+	task.Status = model.TaskStatusInProgress
+	task.UpdatedBy = int64(assignee.ID)
+	task.Assignee = 0
+	task.CoAssignees = nil
+	task.Reviewer = int64(author.ID)
+	task.Muted = false
+	task.Priority = model.TaskPriorityUrgent
+	task.Estimate = 8
+	if err = r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("update task: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskByID(ctx, task.ID); err != nil || !reflect.DeepEqual(got, task) {
+		t.Fatalf("fetch updated task: got %+v, %v, want %+v", got, err, *task)
+	}
+
+	if err = r.Tasks.SetTaskOverdueNotified(ctx, task.ID, true); err != nil {
+		t.Fatalf("set overdue notified: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskByID(ctx, task.ID); err != nil || !got.OverdueNotified {
+		t.Fatalf("fetch overdue notified task: got %+v, %v", got, err)
+	}
+	// Flag is changed only explicitly
+	if err = r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("update task: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskByID(ctx, task.ID); err != nil || !got.OverdueNotified {
+		t.Fatalf("fetch overdue notified task after update: got %+v, %v", got, err)
+	}
+
+	if tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, NotEscalated: true}); err != nil || len(tasks) != 1 {
+		t.Fatalf("filter not escalated tasks: got %v, %v", taskIDs(tasks), err)
+	}
+	if err = r.Tasks.SetTaskEscalated(ctx, task.ID, true); err != nil {
+		t.Fatalf("set escalated: %s", err)
+	}
+	if err = r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("update task: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskByID(ctx, task.ID); err != nil || !got.Escalated {
+		t.Fatalf("fetch escalated task after update: got %+v, %v", got, err)
+	}
+	if tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, NotEscalated: true}); err != nil || len(tasks) != 0 {
+		t.Fatalf("filter not escalated tasks after escalation: got %v, %v", taskIDs(tasks), err)
+	}
+
+	if err = r.Tasks.RemoveTask(ctx, task.ID); err != nil {
+		t.Fatalf("remove task: %s", err)
+	}
+	if _, err = r.Tasks.FetchTaskByID(ctx, task.ID); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch removed task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+}
+
+// testTaskDefaultPriority checks tasks created without priority get normal one.
+func testTaskDefaultPriority(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	task := &model.Task{ProjectID: prj.ID, Title: "Triage", Status: model.TaskStatusTODO}
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	got, err := r.Tasks.FetchTaskByID(ctx, task.ID)
+	if err != nil || got.Priority != model.TaskPriorityNormal || task.Priority != model.TaskPriorityNormal {
+		t.Fatalf("fetch task: got %+v, %v, want %q priority", got, err, model.TaskPriorityNormal)
+	}
+}
+
+func testTaskLabels(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	author := createUser(t, r, 1, "Author")
+
+	bug := model.NewTask(prj.ID, "Crash", int64(author.ID))
+	bug.Labels = []string{"bug", "ui"}
+	plain := model.NewTask(prj.ID, "Docs", int64(author.ID))
+	foreign := model.NewTask(other.ID, "Foreign crash", int64(author.ID))
+	foreign.Labels = []string{"bug"}
+	if err := r.Tasks.CreateTasks(ctx, []*model.Task{bug, plain, foreign}); err != nil {
+		t.Fatalf("create tasks: %s", err)
+	}
+	if got, err := r.Tasks.FetchTaskByID(ctx, bug.ID); err != nil || !reflect.DeepEqual(got.Labels, []string{"bug", "ui"}) {
+		t.Fatalf("fetch labeled task: got %+v, %v", got, err)
+	}
+	if got, err := r.Tasks.FetchTaskByID(ctx, plain.ID); err != nil || len(got.Labels) != 0 {
+		t.Fatalf("fetch task without labels: got %+v, %v", got, err)
+	}
+
+	got, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, Label: "BUG"})
+	if err != nil || len(got) != 1 || got[0].ID != bug.ID {
+		t.Fatalf("filter by label: got %+v, %v", got, err)
+	}
+
+	done := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusDone
+		task.Labels = []string{"bug"}
+	})
+	wantCounts := []model.LabelCount{{Name: "bug", Tasks: 1}, {Name: "ui", Tasks: 1}}
+	if counts, err := r.Tasks.CountTaskLabels(ctx, prj.ID); err != nil || !reflect.DeepEqual(counts, wantCounts) {
+		t.Fatalf("count labels: got %+v, %v, want %+v", counts, err, wantCounts)
+	}
+	if err = r.Tasks.PurgeTask(ctx, done.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+
+	bug.Labels = []string{"ui"}
+	plain.Labels = []string{"bug", "docs"}
+	if err = r.Tasks.UpdateTasks(ctx, []*model.Task{bug, plain}); err != nil {
+		t.Fatalf("update tasks: %s", err)
+	}
+	got, err = r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, Label: "bug"})
+	if err != nil || len(got) != 1 || got[0].ID != plain.ID || !reflect.DeepEqual(got[0].Labels, []string{"bug", "docs"}) {
+		t.Fatalf("filter by label after update: got %+v, %v", got, err)
+	}
+
+	if err = r.Tasks.RemoveTask(ctx, plain.ID); err != nil {
+		t.Fatalf("remove task: %s", err)
+	}
+	if got, err = r.Tasks.FilterTasks(ctx, model.TaskFilter{Label: "bug"}); err != nil || len(got) != 1 || got[0].ID != foreign.ID {
+		t.Fatalf("filter by label after remove: got %+v, %v", got, err)
+	}
+	wantCounts = []model.LabelCount{{Name: "ui", Tasks: 1}}
+	if counts, err := r.Tasks.CountTaskLabels(ctx, prj.ID); err != nil || !reflect.DeepEqual(counts, wantCounts) {
+		t.Fatalf("count labels after remove: got %+v, %v, want %+v", counts, err, wantCounts)
+	}
+}
+
+func testChecklist(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := model.NewTask(prj.ID, "Release", int64(author.ID))
+	other := model.NewTask(prj.ID, "Other", int64(author.ID))
+	if err := r.Tasks.CreateTasks(ctx, []*model.Task{task, other}); err != nil {
+		t.Fatalf("create tasks: %s", err)
+	}
+
+	items := []*model.ChecklistItem{
+		{TaskID: task.ID, Text: "Changelog"},
+		{TaskID: task.ID, Text: "Tag"},
+		{TaskID: other.ID, Text: "Other step"},
+	}
+	if err := r.Tasks.AddChecklistItems(ctx, items); err != nil {
+		t.Fatalf("add checklist items: %s", err)
+	}
+	if err := r.Tasks.SetChecklistItemDone(ctx, items[1].ID, true); err != nil {
+		t.Fatalf("set checklist item done: %s", err)
+	}
+	got, err := r.Tasks.FetchChecklist(ctx, task.ID)
+	want := []model.ChecklistItem{*items[0], {ID: items[1].ID, TaskID: task.ID, Text: "Tag", Done: true}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("fetch checklist: got %+v, %v, want %+v", got, err, want)
+	}
+	if item, err := r.Tasks.FetchChecklistItem(ctx, items[1].ID); err != nil || !reflect.DeepEqual(*item, want[1]) {
+		t.Fatalf("fetch checklist item: got %+v, %v", item, err)
+	}
+
+	if err = r.Tasks.RemoveChecklistItem(ctx, items[0].ID); err != nil {
+		t.Fatalf("remove checklist item: %s", err)
+	}
+	if err = r.Tasks.RemoveChecklistItem(ctx, items[0].ID); !errors.Is(err, model.ErrChecklistItemNotFound) {
+		t.Fatalf("remove removed item: got %v, want %v", err, model.ErrChecklistItemNotFound)
+	}
+	if err = r.Tasks.SetChecklistItemDone(ctx, items[0].ID, true); !errors.Is(err, model.ErrChecklistItemNotFound) {
+		t.Fatalf("check removed item: got %v, want %v", err, model.ErrChecklistItemNotFound)
+	}
+	if _, err = r.Tasks.FetchChecklistItem(ctx, items[0].ID); !errors.Is(err, model.ErrChecklistItemNotFound) {
+		t.Fatalf("fetch removed item: got %v, want %v", err, model.ErrChecklistItemNotFound)
+	}
+
+	// Items are removed with their task.
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if got, err = r.Tasks.FetchChecklist(ctx, task.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch checklist of purged task: got %+v, %v", got, err)
+	}
+	if got, err = r.Tasks.FetchChecklist(ctx, other.ID); err != nil || len(got) != 1 {
+		t.Fatalf("fetch checklist of other task: got %+v, %v", got, err)
+	}
+}
+
+func testTaskComments(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := model.NewTask(prj.ID, "Discuss", int64(author.ID))
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+
+	for _, text := range []string{"first", "second", "third"} {
+		comment := &model.TaskComment{TaskID: task.ID, AuthorID: int64(author.ID), Text: text}
+		if err := r.Tasks.AddTaskComment(ctx, comment); err != nil {
+			t.Fatalf("add comment: %s", err)
+		}
+		if comment.ID == 0 || comment.CreatedAt.IsZero() {
+			t.Fatalf("add comment: got %+v", comment)
+		}
+	}
+
+	got, total, err := r.Tasks.FetchTaskComments(ctx, task.ID, 2)
+	if err != nil || total != 3 || len(got) != 2 || got[0].Text != "second" || got[1].Text != "third" {
+		t.Fatalf("fetch latest comments: got %+v, %d, %v", got, total, err)
+	}
+	if got[0].AuthorID != int64(author.ID) || got[0].TaskID != task.ID || got[0].CreatedAt.IsZero() {
+		t.Fatalf("fetch latest comments: got %+v", got[0])
+	}
+
+	// Comments are removed with their task.
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if got, total, err = r.Tasks.FetchTaskComments(ctx, task.ID, 10); err != nil || total != 0 || len(got) != 0 {
+		t.Fatalf("fetch comments of purged task: got %+v, %d, %v", got, total, err)
+	}
+}
+
+func testTaskAttachments(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := model.NewTask(prj.ID, "Screenshots", int64(author.ID))
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+
+	photo := &model.TaskAttachment{TaskID: task.ID, Kind: model.AttachmentKindPhoto, FileID: "photo-id", AddedBy: int64(author.ID)}
+	doc := &model.TaskAttachment{
+		TaskID:   task.ID,
+		Kind:     model.AttachmentKindDocument,
+		FileID:   "doc-id",
+		FileName: "report.pdf",
+		AddedBy:  int64(author.ID),
+	}
+	for _, attachment := range []*model.TaskAttachment{photo, doc} {
+		if err := r.Tasks.AddTaskAttachment(ctx, attachment); err != nil {
+			t.Fatalf("add attachment: %s", err)
+		}
+		if attachment.ID == 0 || attachment.CreatedAt.IsZero() {
+			t.Fatalf("add attachment: got %+v", attachment)
+		}
+	}
+
+	got, err := r.Tasks.FetchTaskAttachments(ctx, task.ID)
+	if err != nil || len(got) != 2 || got[0].ID != photo.ID || got[1].FileName != "report.pdf" {
+		t.Fatalf("fetch attachments: got %+v, %v", got, err)
+	}
+	one, err := r.Tasks.FetchTaskAttachment(ctx, doc.ID)
+	if err != nil || one.TaskID != task.ID || one.Kind != model.AttachmentKindDocument || one.FileID != "doc-id" ||
+		one.AddedBy != int64(author.ID) {
+		t.Fatalf("fetch attachment: got %+v, %v", one, err)
+	}
+
+	// Attachments are removed with their task.
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if _, err = r.Tasks.FetchTaskAttachment(ctx, doc.ID); !errors.Is(err, model.ErrAttachmentNotFound) {
+		t.Fatalf("fetch attachment of purged task: got %v, want %v", err, model.ErrAttachmentNotFound)
+	}
+	if got, err = r.Tasks.FetchTaskAttachments(ctx, task.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch attachments of removed task: got %+v, %v", got, err)
+	}
+}
+
+func testTaskWatchers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	watcher := createUser(t, r, 2, "Watcher")
+	stranger := createUser(t, r, 3, "Stranger")
+	for _, user := range []*model.User{author, watcher} {
+		if err := r.Users.AddUserToProject(ctx, prj.ID, user.ID, model.UserProjectRoleMember); err != nil {
+			t.Fatalf("add user to project: %s", err)
+		}
+	}
+	task := createTask(t, r, prj.ID, author, func(*model.Task) {})
+
+	for _, user := range []*model.User{watcher, author, stranger, watcher} {
+		if err := r.Tasks.SetTaskWatcher(ctx, task.ID, int64(user.ID), true); err != nil {
+			t.Fatalf("watch task: %s", err)
+		}
+	}
+	// User who left the project is not notified.
+	got, err := r.Tasks.FetchTaskWatchers(ctx, task.ID)
+	if err != nil || !slices.Equal(got, []int64{int64(author.ID), int64(watcher.ID)}) {
+		t.Fatalf("fetch watchers: got %v, %v", got, err)
+	}
+
+	if err = r.Tasks.SetTaskWatcher(ctx, task.ID, int64(author.ID), false); err != nil {
+		t.Fatalf("unwatch task: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskWatchers(ctx, task.ID); err != nil || !slices.Equal(got, []int64{int64(watcher.ID)}) {
+		t.Fatalf("fetch watchers after unwatch: got %v, %v", got, err)
+	}
+
+	// Watchers are removed with their task.
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskWatchers(ctx, task.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch watchers of purged task: got %v, %v", got, err)
+	}
+}
+
+func testAlertTasks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	author := createUser(t, r, 1, "Author")
+	first := model.NewTask(prj.ID, "first", int64(author.ID))
+	second := model.NewTask(prj.ID, "second", int64(author.ID))
+
+	if _, err := r.Tasks.FetchAlertTask(ctx, prj.ID, "abc"); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch task of unknown alert: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+	if err := r.Tasks.CreateAlertTask(ctx, first, "abc"); err != nil {
+		t.Fatalf("create alert task: %s", err)
+	}
+	if got, err := r.Tasks.FetchAlertTask(ctx, prj.ID, "abc"); err != nil || got.ID != first.ID {
+		t.Fatalf("fetch alert task: got %+v, %v", got, err)
+	}
+	// Fingerprints are scoped by project.
+	if _, err := r.Tasks.FetchAlertTask(ctx, other.ID, "abc"); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch alert task of other project: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+
+	// Alert with open task gets no other task.
+	if err := r.Tasks.CreateAlertTask(ctx, second, "abc"); !errors.Is(err, model.ErrAlertTaskExists) {
+		t.Fatalf("create task of alert with open task: got %v, want %v", err, model.ErrAlertTaskExists)
+	}
+	if _, err := r.Tasks.FetchTaskByID(ctx, first.ID+1); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch task of alert with open task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+
+	first.Status = model.TaskStatusDone
+	if err := r.Tasks.UpdateTask(ctx, first); err != nil {
+		t.Fatalf("close alert task: %s", err)
+	}
+	if err := r.Tasks.CreateAlertTask(ctx, second, "abc"); err != nil {
+		t.Fatalf("recreate alert task: %s", err)
+	}
+	if got, err := r.Tasks.FetchAlertTask(ctx, prj.ID, "abc"); err != nil || got.ID != second.ID {
+		t.Fatalf("fetch relinked alert task: got %+v, %v", got, err)
+	}
+
+	// Link is removed with its task.
+	if err := r.Tasks.PurgeTask(ctx, second.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if _, err := r.Tasks.FetchAlertTask(ctx, prj.ID, "abc"); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch purged alert task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+}
+
+func testTaskBlockers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := createTask(t, r, prj.ID, author, func(*model.Task) {})
+	first := createTask(t, r, prj.ID, author, func(*model.Task) {})
+	second := createTask(t, r, prj.ID, author, func(*model.Task) {})
+
+	if err := r.Tasks.SetTaskBlockers(ctx, task.ID, []int{second.ID, first.ID}); err != nil {
+		t.Fatalf("set blockers: %s", err)
+	}
+	got, err := r.Tasks.FetchTaskBlockers(ctx, task.ID)
+	if err != nil || len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+		t.Fatalf("fetch blockers: got %+v, %v", got, err)
+	}
+	if got, err = r.Tasks.FetchBlockedTasks(ctx, first.ID); err != nil || len(got) != 1 || got[0].ID != task.ID {
+		t.Fatalf("fetch blocked tasks: got %+v, %v", got, err)
+	}
+
+	// Blockers are replaced.
+	if err = r.Tasks.SetTaskBlockers(ctx, task.ID, []int{second.ID}); err != nil {
+		t.Fatalf("replace blockers: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskBlockers(ctx, task.ID); err != nil || len(got) != 1 || got[0].ID != second.ID {
+		t.Fatalf("fetch replaced blockers: got %+v, %v", got, err)
+	}
+	if got, err = r.Tasks.FetchBlockedTasks(ctx, first.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch tasks blocked by removed blocker: got %+v, %v", got, err)
+	}
+
+	// Links are removed with either of their tasks.
+	if err = r.Tasks.PurgeTask(ctx, second.ID); err != nil {
+		t.Fatalf("purge blocker: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskBlockers(ctx, task.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch blockers after purging blocker: got %+v, %v", got, err)
+	}
+	if err = r.Tasks.SetTaskBlockers(ctx, task.ID, []int{first.ID}); err != nil {
+		t.Fatalf("set blockers: %s", err)
+	}
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge blocked task: %s", err)
+	}
+	if got, err = r.Tasks.FetchBlockedTasks(ctx, first.ID); err != nil || len(got) != 0 {
+		t.Fatalf("fetch tasks blocked by blocker of purged task: got %+v, %v", got, err)
+	}
+}
+
+func testTaskRecurrences(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+	other := createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+
+	if _, err := r.Tasks.FetchTaskRecurrence(ctx, task.ID); !errors.Is(err, model.ErrRecurrenceNotFound) {
+		t.Fatalf("fetch missing recurrence: got %v, want %v", err, model.ErrRecurrenceNotFound)
+	}
+
+	now := time.Date(2024, 12, 2, 10, 0, 0, 0, time.UTC)
+	rec := &model.TaskRecurrence{TaskID: task.ID, Rule: model.RecurrenceWeekly, NextAt: now.Add(time.Hour)}
+	if err := r.Tasks.SetTaskRecurrence(ctx, rec); err != nil {
+		t.Fatalf("set recurrence: %s", err)
+	}
+	otherRec := &model.TaskRecurrence{TaskID: other.ID, Rule: "0 9 * * 1", NextAt: now.Add(-time.Hour)}
+	if err := r.Tasks.SetTaskRecurrence(ctx, otherRec); err != nil {
+		t.Fatalf("set other recurrence: %s", err)
+	}
+	got, err := r.Tasks.FetchTaskRecurrence(ctx, task.ID)
+	if err != nil || got.Rule != rec.Rule || !got.NextAt.Equal(rec.NextAt) {
+		t.Fatalf("fetch recurrence: got %+v, %v", got, err)
+	}
+	if recs, err := r.Tasks.FetchProjectRecurrences(ctx, prj.ID); err != nil || len(recs) != 2 || recs[0].TaskID != task.ID {
+		t.Fatalf("fetch project recurrences: got %+v, %v", recs, err)
+	}
+
+	// Recurrence is due when its period ends or its task is closed.
+	if recs, err := r.Tasks.FetchDueRecurrences(ctx, now); err != nil || len(recs) != 1 || recs[0].TaskID != other.ID {
+		t.Fatalf("fetch due recurrences: got %+v, %v", recs, err)
+	}
+	task.Status = model.TaskStatusDone
+	if err = r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("close task: %s", err)
+	}
+	if recs, err := r.Tasks.FetchDueRecurrences(ctx, now); err != nil || len(recs) != 2 {
+		t.Fatalf("fetch due recurrences after closing task: got %+v, %v", recs, err)
+	}
+
+	next := model.NewTask(prj.ID, task.Title, int64(author.ID))
+	rec.NextAt = now.AddDate(0, 0, 7)
+	if err = r.Tasks.RenewTaskRecurrence(ctx, rec, next); err != nil {
+		t.Fatalf("renew recurrence: %s", err)
+	}
+	if next.ID == 0 || rec.TaskID != next.ID {
+		t.Fatalf("renew recurrence: next task id=%d, recurrence task id=%d", next.ID, rec.TaskID)
+	}
+	if _, err = r.Tasks.FetchTaskRecurrence(ctx, task.ID); !errors.Is(err, model.ErrRecurrenceNotFound) {
+		t.Fatalf("fetch recurrence of previous instance: got %v, want %v", err, model.ErrRecurrenceNotFound)
+	}
+	if got, err = r.Tasks.FetchTaskRecurrence(ctx, next.ID); err != nil || !got.NextAt.Equal(rec.NextAt) {
+		t.Fatalf("fetch renewed recurrence: got %+v, %v", got, err)
+	}
+
+	if err = r.Tasks.RemoveTaskRecurrence(ctx, next.ID); err != nil {
+		t.Fatalf("remove recurrence: %s", err)
+	}
+	if _, err = r.Tasks.FetchTaskRecurrence(ctx, next.ID); !errors.Is(err, model.ErrRecurrenceNotFound) {
+		t.Fatalf("fetch removed recurrence: got %v, want %v", err, model.ErrRecurrenceNotFound)
+	}
+
+	// Recurrence is removed with its task.
+	if err = r.Tasks.PurgeTask(ctx, other.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if recs, err := r.Tasks.FetchProjectRecurrences(ctx, prj.ID); err != nil || len(recs) != 0 {
+		t.Fatalf("fetch recurrences after purging task: got %+v, %v", recs, err)
+	}
+}
+
+func testTaskTemplates(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	author := createUser(t, r, 1, "Author")
+
+	if _, err := r.Tasks.FetchTaskTemplate(ctx, 1); !errors.Is(err, model.ErrTemplateNotFound) {
+		t.Fatalf("fetch missing template: got %v, want %v", err, model.ErrTemplateNotFound)
+	}
+
+	tpl := &model.TaskTemplate{
+		ProjectID:   prj.ID,
+		Name:        "Релиз",
+		Title:       "Release new version",
+		Description: "Tag and deploy",
+		Assignee:    int64(author.ID),
+		Labels:      []string{"backend", "ops"},
+		CreatedBy:   int64(author.ID),
+	}
+	if err := r.Tasks.SaveTaskTemplate(ctx, tpl); err != nil {
+		t.Fatalf("save template: %s", err)
+	}
+	if tpl.ID == 0 {
+		t.Fatal("save template: id is not set")
+	}
+	bare := &model.TaskTemplate{ProjectID: prj.ID, Name: "bug", Title: "Fix bug", CreatedBy: int64(author.ID)}
+	if err := r.Tasks.SaveTaskTemplate(ctx, bare); err != nil {
+		t.Fatalf("save template without assignee: %s", err)
+	}
+	if err := r.Tasks.SaveTaskTemplate(ctx, &model.TaskTemplate{ProjectID: other.ID, Name: "Релиз", Title: "Other", CreatedBy: int64(author.ID)}); err != nil {
+		t.Fatalf("save template of other project: %s", err)
+	}
+
+	got, err := r.Tasks.FetchTaskTemplate(ctx, tpl.ID)
+	if err != nil || !reflect.DeepEqual(got, tpl) {
+		t.Fatalf("fetch template: got %+v, %v, want %+v", got, err, tpl)
+	}
+	if got, err = r.Tasks.FetchTaskTemplate(ctx, bare.ID); err != nil || got.Assignee != 0 || got.Labels != nil {
+		t.Fatalf("fetch template without assignee: got %+v, %v", got, err)
+	}
+	templates, err := r.Tasks.FetchTaskTemplates(ctx, prj.ID)
+	if err != nil || len(templates) != 2 || templates[0].ID != bare.ID || templates[1].ID != tpl.ID {
+		t.Fatalf("fetch templates: got %+v, %v", templates, err)
+	}
+
+	// Template with the same name ignoring case is replaced.
+	replaced := &model.TaskTemplate{ProjectID: prj.ID, Name: "релиз", Title: "Release hotfix", CreatedBy: int64(author.ID)}
+	if err = r.Tasks.SaveTaskTemplate(ctx, replaced); err != nil {
+		t.Fatalf("replace template: %s", err)
+	}
+	if replaced.ID != tpl.ID {
+		t.Fatalf("replace template: got id=%d, want %d", replaced.ID, tpl.ID)
+	}
+	if got, err = r.Tasks.FetchTaskTemplate(ctx, tpl.ID); err != nil || !reflect.DeepEqual(got, replaced) {
+		t.Fatalf("fetch replaced template: got %+v, %v, want %+v", got, err, replaced)
+	}
+
+	if err = r.Tasks.RemoveTaskTemplate(ctx, bare.ID); err != nil {
+		t.Fatalf("remove template: %s", err)
+	}
+	if _, err = r.Tasks.FetchTaskTemplate(ctx, bare.ID); !errors.Is(err, model.ErrTemplateNotFound) {
+		t.Fatalf("fetch removed template: got %v, want %v", err, model.ErrTemplateNotFound)
+	}
+	if templates, err = r.Tasks.FetchTaskTemplates(ctx, prj.ID); err != nil || len(templates) != 1 {
+		t.Fatalf("fetch templates after removing: got %+v, %v", templates, err)
+	}
+}
+
+func testTaskSnapshots(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	author := createUser(t, r, 1, "Author")
+	todo := createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+	createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+	createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusDone })
+	createTask(t, r, other.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+
+	monday := time.Date(2024, 12, 2, 0, 0, 0, 0, time.Local)
+	tuesday := monday.AddDate(0, 0, 1)
+	if err := r.Tasks.SnapshotTaskCounts(ctx, monday); err != nil {
+		t.Fatalf("snapshot task counts: %s", err)
+	}
+	todo.Status = model.TaskStatusDone
+	if err := r.Tasks.UpdateTask(ctx, todo); err != nil {
+		t.Fatalf("close task: %s", err)
+	}
+	if err := r.Tasks.SnapshotTaskCounts(ctx, tuesday); err != nil {
+		t.Fatalf("snapshot task counts of the next day: %s", err)
+	}
+
+	snapshots, err := r.Tasks.FetchTaskSnapshots(ctx, prj.ID, monday, tuesday)
+	if err != nil {
+		t.Fatalf("fetch snapshots: %s", err)
+	}
+	want := []model.TaskSnapshot{
+		{ProjectID: prj.ID, Day: monday, Counts: map[model.TaskStatus]int{model.TaskStatusTODO: 2, model.TaskStatusDone: 1}},
+		{ProjectID: prj.ID, Day: tuesday, Counts: map[model.TaskStatus]int{model.TaskStatusTODO: 1, model.TaskStatusDone: 2}},
+	}
+	if !reflect.DeepEqual(snapshots, want) {
+		t.Fatalf("fetch snapshots: got %+v, want %+v", snapshots, want)
+	}
+	if snapshots[1].Open() != 1 || snapshots[1].Closed() != 2 {
+		t.Fatalf("snapshot counts: got %d open and %d closed, want 1 and 2", snapshots[1].Open(), snapshots[1].Closed())
+	}
+
+	// Snapshot of the same day is replaced, statuses without tasks are dropped.
+	tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, Status: model.TaskStatusTODO})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("fetch todo tasks: got %+v, %v", tasks, err)
+	}
+	tasks[0].Status = model.TaskStatusCancelled
+	if err = r.Tasks.UpdateTask(ctx, &tasks[0]); err != nil {
+		t.Fatalf("cancel task: %s", err)
+	}
+	if err = r.Tasks.SnapshotTaskCounts(ctx, tuesday); err != nil {
+		t.Fatalf("snapshot task counts again: %s", err)
+	}
+	if snapshots, err = r.Tasks.FetchTaskSnapshots(ctx, prj.ID, tuesday, tuesday); err != nil || len(snapshots) != 1 {
+		t.Fatalf("fetch replaced snapshot: got %+v, %v", snapshots, err)
+	}
+	wantCounts := map[model.TaskStatus]int{model.TaskStatusDone: 2, model.TaskStatusCancelled: 1}
+	if !reflect.DeepEqual(snapshots[0].Counts, wantCounts) {
+		t.Fatalf("fetch replaced snapshot: got %v, want %v", snapshots[0].Counts, wantCounts)
+	}
+
+	if snapshots, err = r.Tasks.FetchTaskSnapshots(ctx, prj.ID, tuesday.AddDate(0, 0, 1), tuesday.AddDate(0, 0, 7)); err != nil || len(snapshots) != 0 {
+		t.Fatalf("fetch snapshots of days without them: got %+v, %v", snapshots, err)
+	}
+}
+
+func testTaskHistory(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	editor := createUser(t, r, 2, "Editor")
+	task := createTask(t, r, prj.ID, author, func(task *model.Task) { task.Status = model.TaskStatusTODO })
+
+	if changes, total, err := r.Tasks.FetchTaskHistory(ctx, task.ID, 10); err != nil || total != 0 || len(changes) != 0 {
+		t.Fatalf("fetch history of new task: got %+v, %d, %v", changes, total, err)
+	}
+
+	task.Title = "Renamed"
+	task.Status = model.TaskStatusInProgress
+	task.Assignee = int64(editor.ID)
+	task.Labels = []string{"ops", "backend"}
+	task.UpdatedBy = int64(editor.ID)
+	if err := r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("update task: %s", err)
+	}
+	// Saving unchanged task adds nothing.
+	if err := r.Tasks.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("update unchanged task: %s", err)
+	}
+
+	changes, total, err := r.Tasks.FetchTaskHistory(ctx, task.ID, 10)
+	if err != nil || total != 4 || len(changes) != 4 {
+		t.Fatalf("fetch history: got %+v, %d, %v", changes, total, err)
+	}
+	want := []model.TaskChange{
+		{Field: model.TaskFieldTitle, OldValue: "Task", NewValue: "Renamed"},
+		{Field: model.TaskFieldStatus, OldValue: string(model.TaskStatusTODO), NewValue: string(model.TaskStatusInProgress)},
+		{Field: model.TaskFieldAssignee, OldValue: "", NewValue: strconv.Itoa(editor.ID)},
+		{Field: model.TaskFieldLabels, OldValue: "", NewValue: "backend,ops"},
+	}
+	for i, change := range changes {
+		if change.TaskID != task.ID || change.UserID != int64(editor.ID) || change.ChangedAt.IsZero() ||
+			change.Field != want[i].Field || change.OldValue != want[i].OldValue || change.NewValue != want[i].NewValue {
+			t.Fatalf("change %d: got %+v, want %+v", i, change, want[i])
+		}
+	}
+
+	task.Status = model.TaskStatusDone
+	if err = r.Tasks.UpdateTasks(ctx, []*model.Task{task}); err != nil {
+		t.Fatalf("update tasks: %s", err)
+	}
+	changes, total, err = r.Tasks.FetchTaskHistory(ctx, task.ID, 1)
+	if err != nil || total != 5 || len(changes) != 1 || changes[0].NewValue != string(model.TaskStatusDone) {
+		t.Fatalf("fetch the latest change: got %+v, %d, %v", changes, total, err)
+	}
+
+	// History is removed with its task.
+	if err = r.Tasks.PurgeTask(ctx, task.ID); err != nil {
+		t.Fatalf("purge task: %s", err)
+	}
+	if changes, total, err = r.Tasks.FetchTaskHistory(ctx, task.ID, 10); err != nil || total != 0 || len(changes) != 0 {
+		t.Fatalf("fetch history of purged task: got %+v, %d, %v", changes, total, err)
+	}
+}
+
+func testMoveTask(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	first := createTask(t, r, prj.ID, author, func(task *model.Task) {})
+	started := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusInProgress
+	})
+	second := createTask(t, r, prj.ID, author, func(task *model.Task) {})
+	third := createTask(t, r, prj.ID, author, func(task *model.Task) {})
+
+	check := func(name string, want ...int) {
+		t.Helper()
+		tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID})
+		if err != nil {
+			t.Fatalf("filter tasks: %s", err)
+		}
+		if got := taskIDs(tasks); !slices.Equal(got, want) {
+			t.Fatalf("%s: got %v, want %v", name, got, want)
+		}
+	}
+	check("order of creation", first.ID, started.ID, second.ID, third.ID)
+
+	// Task swaps with the nearest one of the same status.
+	if err := r.Tasks.MoveTask(ctx, second.ID, model.TaskMoveUp); err != nil {
+		t.Fatalf("move task up: %s", err)
+	}
+	check("move up", second.ID, started.ID, first.ID, third.ID)
+	if err := r.Tasks.MoveTask(ctx, second.ID, model.TaskMoveUp); err != nil {
+		t.Fatalf("move first task up: %s", err)
+	}
+	check("move first task up", second.ID, started.ID, first.ID, third.ID)
+	if err := r.Tasks.MoveTask(ctx, second.ID, model.TaskMoveDown); err != nil {
+		t.Fatalf("move task down: %s", err)
+	}
+	check("move down", first.ID, started.ID, second.ID, third.ID)
+	if err := r.Tasks.MoveTask(ctx, third.ID, model.TaskMoveTop); err != nil {
+		t.Fatalf("move task to top: %s", err)
+	}
+	check("move to top", third.ID, first.ID, started.ID, second.ID)
+
+	if err := r.Tasks.MoveTask(ctx, 100, model.TaskMoveUp); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("move missing task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+}
+
+func testTaskTrash(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	kept := createTask(t, r, prj.ID, author, func(task *model.Task) {})
+	removed := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Labels = []string{"bug"}
+	})
+	if err := r.Tasks.AddTaskComment(ctx, &model.TaskComment{TaskID: removed.ID, AuthorID: int64(author.ID), Text: "note"}); err != nil {
+		t.Fatalf("add comment: %s", err)
+	}
+
+	if err := r.Tasks.RemoveTask(ctx, removed.ID); err != nil {
+		t.Fatalf("remove task: %s", err)
+	}
+	if _, err := r.Tasks.FetchTaskByID(ctx, removed.ID); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch task in trash: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+	if tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID}); err != nil || !slices.Equal(taskIDs(tasks), []int{kept.ID}) {
+		t.Fatalf("filter tasks with one in trash: got %v, %v", taskIDs(tasks), err)
+	}
+	if counts, err := r.Tasks.CountTasksByStatus(ctx, prj.ID); err != nil || counts[model.TaskStatusBacklog] != 1 {
+		t.Fatalf("count tasks with one in trash: got %v, %v", counts, err)
+	}
+	deleted, err := r.Tasks.FetchDeletedTasks(ctx, prj.ID)
+	if err != nil || !slices.Equal(taskIDs(deleted), []int{removed.ID}) || deleted[0].DeletedAt.IsZero() {
+		t.Fatalf("fetch deleted tasks: got %+v, %v", deleted, err)
+	}
+
+	// Restored task comes back with everything attached to it.
+	if err = r.Tasks.RestoreTask(ctx, removed.ID); err != nil {
+		t.Fatalf("restore task: %s", err)
+	}
+	got, err := r.Tasks.FetchTaskByID(ctx, removed.ID)
+	if err != nil || !slices.Equal(got.Labels, []string{"bug"}) || !got.DeletedAt.IsZero() {
+		t.Fatalf("fetch restored task: got %+v, %v", got, err)
+	}
+	if comments, total, err := r.Tasks.FetchTaskComments(ctx, removed.ID, 10); err != nil || total != 1 {
+		t.Fatalf("fetch comments of restored task: got %+v, %d, %v", comments, total, err)
+	}
+	if err = r.Tasks.RestoreTask(ctx, kept.ID); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("restore task not in trash: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+
+	// Only tasks in trash since before given time are purged.
+	if err = r.Tasks.RemoveTask(ctx, removed.ID); err != nil {
+		t.Fatalf("remove task again: %s", err)
+	}
+	if purged, err := r.Tasks.PurgeDeletedTasks(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Fatalf("purge tasks removed an hour ago: got %d, %v", purged, err)
+	}
+	if purged, err := r.Tasks.PurgeDeletedTasks(ctx, time.Now().Add(time.Hour)); err != nil || purged != 1 {
+		t.Fatalf("purge deleted tasks: got %d, %v", purged, err)
+	}
+	if deleted, err = r.Tasks.FetchDeletedTasks(ctx, prj.ID); err != nil || len(deleted) != 0 {
+		t.Fatalf("fetch deleted tasks after purge: got %+v, %v", deleted, err)
+	}
+	if err = r.Tasks.RestoreTask(ctx, removed.ID); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("restore purged task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+	if _, err = r.Tasks.FetchTaskByID(ctx, kept.ID); err != nil {
+		t.Fatalf("fetch kept task: %s", err)
+	}
+	// Buttons of purged task are left in chats, so its id is never given to another task.
+	if next := createTask(t, r, prj.ID, author, func(*model.Task) {}); next.ID == removed.ID {
+		t.Fatalf("create task after purge: got id %d of purged task", next.ID)
+	}
+}
+
+func testTaskNotFound(t *testing.T, r Repositories) {
+	if _, err := r.Tasks.FetchTaskByID(context.Background(), 1); !errors.Is(err, model.ErrTaskNotFound) {
+		t.Fatalf("fetch task: got %v, want %v", err, model.ErrTaskNotFound)
+	}
+}
+
+// testTaskDeadline checks deadline keeps the same instant regardless of time zone it was set in.
+func testTaskDeadline(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+
+	deadline := time.Date(2024, 12, 1, 23, 59, 59, 0, time.FixedZone("MSK", 3*60*60))
+	task := model.NewTask(prj.ID, "Deadline", int64(author.ID))
+	task.Deadline = deadline
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+
+	got, err := r.Tasks.FetchTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("fetch task: %s", err)
+	}
+	if !got.Deadline.Equal(deadline) {
+		t.Fatalf("deadline: got %s, want %s", got.Deadline, deadline)
+	}
+
+	// Same instant in UTC must match the filter bound exactly.
+	tasks, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{Deadline: deadline.UTC()})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("filter by deadline: got %d tasks, %v, want 1", len(tasks), err)
+	}
+	tasks, err = r.Tasks.FilterTasks(ctx, model.TaskFilter{Deadline: deadline.Add(-time.Second)})
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("filter by earlier deadline: got %d tasks, %v, want 0", len(tasks), err)
+	}
+}
+
+func testFilterTasks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	author := createUser(t, r, 1, "Author")
+	assignee := createUser(t, r, 2, "Assignee")
+	now := time.Now()
+
+	backlog := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.CoAssignees = []int64{int64(assignee.ID)}
+	})
+	assigned := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusInProgress
+		task.Assignee = int64(assignee.ID)
+		task.Deadline = now.Add(time.Hour)
+		task.Muted = true
+	})
+	done := createTask(t, r, prj.ID, assignee, func(task *model.Task) {
+		task.Status = model.TaskStatusDone
+		task.Deadline = now.Add(-time.Hour)
+	})
+	if err := r.Tasks.SetTaskOverdueNotified(ctx, done.ID, true); err != nil {
+		t.Fatalf("set overdue notified: %s", err)
+	}
+	foreign := createTask(t, r, other.ID, author, func(task *model.Task) {})
+
+	tests := []struct {
+		name   string
+		filter model.TaskFilter
+		want   []int
+	}{
+		{"all", model.TaskFilter{}, []int{backlog.ID, assigned.ID, done.ID, foreign.ID}},
+		{"project", model.TaskFilter{ProjectID: prj.ID}, []int{backlog.ID, assigned.ID, done.ID}},
+		{"status", model.TaskFilter{Status: model.TaskStatusDone}, []int{done.ID}},
+		{"created by", model.TaskFilter{ProjectID: prj.ID, CreatedBy: int64(author.ID)}, []int{backlog.ID, assigned.ID}},
+		{"assignee", model.TaskFilter{Assignee: int64(assignee.ID)}, []int{backlog.ID, assigned.ID}},
+		{"deadline", model.TaskFilter{Deadline: now}, []int{done.ID}},
+		{"deadline from", model.TaskFilter{DeadlineFrom: now}, []int{assigned.ID}},
+		{"deadline range", model.TaskFilter{DeadlineFrom: now.Add(-2 * time.Hour), Deadline: now}, []int{done.ID}},
+		{"without deadline", model.TaskFilter{ProjectID: prj.ID, WithoutDeadline: true}, []int{backlog.ID}},
+		{"only open", model.TaskFilter{ProjectID: prj.ID, OnlyOpen: true}, []int{backlog.ID, assigned.ID}},
+		{"overdue not notified", model.TaskFilter{ProjectID: prj.ID, OverdueNotNotified: true}, []int{backlog.ID, assigned.ID}},
+		{"without muted", model.TaskFilter{ProjectID: prj.ID, WithoutMuted: true}, []int{backlog.ID, done.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := r.Tasks.FilterTasks(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("filter tasks: %s", err)
+			}
+			if got := taskIDs(tasks); !slices.Equal(got, tt.want) {
+				t.Fatalf("filter tasks: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testCreateTasks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+
+	tasks := []*model.Task{
+		model.NewTask(prj.ID, "First", int64(author.ID)),
+		model.NewTask(prj.ID, "Second", int64(author.ID)),
+	}
+	if err := r.Tasks.CreateTasks(ctx, tasks); err != nil {
+		t.Fatalf("create tasks: %s", err)
+	}
+	for _, task := range tasks {
+		got, err := r.Tasks.FetchTaskByID(ctx, task.ID)
+		if err != nil || got.Title != task.Title {
+			t.Fatalf("fetch created task %d: got %+v, %v", task.ID, got, err)
+		}
+	}
+}
+
+func testUpdateTasks(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+
+	tasks := []*model.Task{
+		model.NewTask(prj.ID, "First", int64(author.ID)),
+		model.NewTask(prj.ID, "Second", int64(author.ID)),
+	}
+	if err := r.Tasks.CreateTasks(ctx, tasks); err != nil {
+		t.Fatalf("create tasks: %s", err)
+	}
+	deadline := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	for _, task := range tasks {
+		task.Deadline = deadline
+	}
+	if err := r.Tasks.UpdateTasks(ctx, tasks); err != nil {
+		t.Fatalf("update tasks: %s", err)
+	}
+	for _, task := range tasks {
+		got, err := r.Tasks.FetchTaskByID(ctx, task.ID)
+		if err != nil || !got.Deadline.Equal(deadline) {
+			t.Fatalf("fetch updated task %d: got %+v, %v", task.ID, got, err)
+		}
+	}
+}
+
+func testOutbox(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	task := model.NewTask(prj.ID, "Review", int64(author.ID))
+	if err := r.Tasks.CreateTask(ctx, task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	task.Status = model.TaskStatusReview
+	notifications := []model.Notification{model.NewNotification(2, "first"), model.NewNotification(3, "second")}
+	if err := r.Tasks.UpdateTasksWithNotifications(ctx, []*model.Task{task}, notifications); err != nil {
+		t.Fatalf("update task with notifications: %s", err)
+	}
+	if got, err := r.Tasks.FetchTaskByID(ctx, task.ID); err != nil || got.Status != model.TaskStatusReview {
+		t.Fatalf("fetch updated task: got %+v, %v", got, err)
+	}
+
+	due, err := r.Outbox.FetchDueNotifications(ctx, time.Now(), 10)
+	if err != nil || len(due) != 2 || due[0].Text != "first" || due[0].UserID != 2 || due[1].Text != "second" {
+		t.Fatalf("fetch due notifications: got %+v, %v", due, err)
+	}
+	if due[0].CreatedAt.IsZero() || due[0].Attempts != 0 || due[0].Dead {
+		t.Fatalf("new notification: got %+v", due[0])
+	}
+
+	retried := due[0]
+	retried.Attempts = 1
+	retried.NextAttemptAt = time.Now().Add(time.Hour)
+	retried.LastError = "timeout"
+	if err = r.Outbox.UpdateNotification(ctx, &retried); err != nil {
+		t.Fatalf("update notification: %s", err)
+	}
+	dead := due[1]
+	dead.Dead = true
+	if err = r.Outbox.UpdateNotification(ctx, &dead); err != nil {
+		t.Fatalf("bury notification: %s", err)
+	}
+	if due, err = r.Outbox.FetchDueNotifications(ctx, time.Now(), 10); err != nil || len(due) != 0 {
+		t.Fatalf("fetch due notifications after update: got %+v, %v", due, err)
+	}
+	due, err = r.Outbox.FetchDueNotifications(ctx, time.Now().Add(2*time.Hour), 10)
+	if err != nil || len(due) != 1 || due[0].ID != retried.ID || due[0].Attempts != 1 || due[0].LastError != "timeout" {
+		t.Fatalf("fetch retried notification: got %+v, %v", due, err)
+	}
+
+	if got, err := r.Outbox.ListDeadNotifications(ctx); err != nil || len(got) != 1 || got[0].ID != dead.ID || !got[0].Dead {
+		t.Fatalf("list dead notifications: got %+v, %v", got, err)
+	}
+	if err = r.Outbox.RequeueDeadNotification(ctx, retried.ID, time.Now()); !errors.Is(err, model.ErrNotificationNotFound) {
+		t.Fatalf("requeue live notification: got %v, want %v", err, model.ErrNotificationNotFound)
+	}
+	if err = r.Outbox.RequeueDeadNotification(ctx, dead.ID, time.Now()); err != nil {
+		t.Fatalf("requeue dead notification: %s", err)
+	}
+	if got, err := r.Outbox.ListDeadNotifications(ctx); err != nil || len(got) != 0 {
+		t.Fatalf("list dead notifications after requeue: got %+v, %v", got, err)
+	}
+	due, err = r.Outbox.FetchDueNotifications(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 || due[0].ID != dead.ID || due[0].Attempts != 0 {
+		t.Fatalf("fetch requeued notification: got %+v, %v", due, err)
+	}
+	if err = r.Outbox.DeleteNotification(ctx, dead.ID); err != nil {
+		t.Fatalf("delete requeued notification: %s", err)
+	}
+
+	if err = r.Outbox.DeleteNotification(ctx, retried.ID); err != nil {
+		t.Fatalf("delete notification: %s", err)
+	}
+	if due, err = r.Outbox.FetchDueNotifications(ctx, time.Now().Add(2*time.Hour), 10); err != nil || len(due) != 0 {
+		t.Fatalf("fetch deleted notification: got %+v, %v", due, err)
+	}
+
+	// Notifications not tied to a change are due right away.
+	if err = r.Outbox.EnqueueNotifications(ctx, []model.Notification{model.NewNotification(4, "watched")}); err != nil {
+		t.Fatalf("enqueue notifications: %s", err)
+	}
+	due, err = r.Outbox.FetchDueNotifications(ctx, time.Now(), 10)
+	if err != nil || len(due) != 1 || due[0].UserID != 4 || due[0].Text != "watched" {
+		t.Fatalf("fetch enqueued notification: got %+v, %v", due, err)
+	}
+}
+
+func testEpics(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+
+	epic := model.NewTask(prj.ID, "Release", int64(author.ID))
+	epic.Epic = true
+	if err := r.Tasks.CreateTask(ctx, epic); err != nil {
+		t.Fatalf("create epic: %s", err)
+	}
+	child := model.NewTask(prj.ID, "Changelog", int64(author.ID))
+	child.ParentID = epic.ID
+	other := model.NewTask(prj.ID, "Other", int64(author.ID))
+	if err := r.Tasks.CreateTasks(ctx, []*model.Task{child, other}); err != nil {
+		t.Fatalf("create tasks: %s", err)
+	}
+
+	got, err := r.Tasks.FetchTaskByID(ctx, child.ID)
+	if err != nil || got.ParentID != epic.ID {
+		t.Fatalf("fetch child: got %+v, %v", got, err)
+	}
+	children, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ParentID: epic.ID})
+	if err != nil || len(children) != 1 || children[0].ID != child.ID {
+		t.Fatalf("filter children: got %+v, %v", children, err)
+	}
+	epics, err := r.Tasks.FilterTasks(ctx, model.TaskFilter{ProjectID: prj.ID, OnlyEpics: true})
+	if err != nil || len(epics) != 1 || epics[0].ID != epic.ID || !epics[0].Epic {
+		t.Fatalf("filter epics: got %+v, %v", epics, err)
+	}
+
+	// Children are kept without epic
+	if err = r.Tasks.PurgeTask(ctx, epic.ID); err != nil {
+		t.Fatalf("purge epic: %s", err)
+	}
+	if got, err = r.Tasks.FetchTaskByID(ctx, child.ID); err != nil || got.ParentID != 0 {
+		t.Fatalf("fetch child of purged epic: got %+v, %v", got, err)
+	}
+}
+
+func testTaskCounters(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	now := time.Now()
+
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusInProgress
+		task.Deadline = now.Add(-time.Hour)
+		task.Estimate = 5
+	})
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusTODO
+		task.Estimate = 3
+	})
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusTODO
+		task.Deadline = now.Add(time.Hour)
+	})
+	// Finished tasks are never overdue and their points are done.
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusDone
+		task.Deadline = now.Add(-time.Hour)
+		task.Estimate = 8
+	})
+
+	counters, err := r.Tasks.FetchTaskCounters(ctx, prj.ID, now)
+	if err != nil {
+		t.Fatalf("fetch task counters: %s", err)
+	}
+	want := model.TaskCounters{InProgress: 1, Overdue: 1, Queued: 2, OpenPoints: 8}
+	if counters != want {
+		t.Fatalf("fetch task counters: got %+v, want %+v", counters, want)
+	}
+
+	counts, err := r.Tasks.CountTasksByStatus(ctx, prj.ID)
+	if err != nil {
+		t.Fatalf("count tasks by status: %s", err)
+	}
+	wantCounts := map[model.TaskStatus]int{
+		model.TaskStatusInProgress: 1,
+		model.TaskStatusTODO:       2,
+		model.TaskStatusDone:       1,
+	}
+	for _, status := range model.TaskStatuses {
+		if counts[status] != wantCounts[status] {
+			t.Errorf("count of %s tasks: got %d, want %d", status, counts[status], wantCounts[status])
+		}
+	}
+}
+
+func testUpcomingDeadlines(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	author := createUser(t, r, 1, "Author")
+	now := time.Now()
+
+	later := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Deadline = now.Add(48 * time.Hour)
+	})
+	sooner := createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Deadline = now.Add(time.Hour)
+	})
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Deadline = now.Add(72 * time.Hour)
+	})
+	createTask(t, r, prj.ID, author, func(task *model.Task) {
+		task.Status = model.TaskStatusCancelled
+		task.Deadline = now
+	})
+	createTask(t, r, prj.ID, author, func(task *model.Task) {})
+
+	tasks, err := r.Tasks.FetchUpcomingDeadlines(ctx, prj.ID, 2)
+	if err != nil {
+		t.Fatalf("fetch upcoming deadlines: %s", err)
+	}
+	if got, want := taskIDs(tasks), []int{sooner.ID, later.ID}; !slices.Equal(got, want) {
+		t.Fatalf("fetch upcoming deadlines: got %v, want %v", got, want)
+	}
+}
+
+func createProject(t *testing.T, r Repositories, tgChatID int64) *model.Project {
+	t.Helper()
+	prj := model.NewProject("Project", tgChatID)
+	if err := r.Projects.CreateProject(context.Background(), prj); err != nil {
+		t.Fatalf("create project: %s", err)
+	}
+	return prj
+}
+
+// createUser uses lowercased full name as username.
+func createUser(t *testing.T, r Repositories, tgUserID int64, fullName string) *model.User {
+	t.Helper()
+	user := model.NewUser(tgUserID)
+	user.FullName = fullName
+	user.Username = strings.ToLower(fullName)
+	if err := r.Users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("create user: %s", err)
+	}
+	return user
+}
+
+func createTask(t *testing.T, r Repositories, projectID int, author *model.User, setup func(task *model.Task)) *model.Task {
+	t.Helper()
+	task := model.NewTask(projectID, "Task", int64(author.ID))
+	setup(task)
+	if err := r.Tasks.CreateTask(context.Background(), task); err != nil {
+		t.Fatalf("create task: %s", err)
+	}
+	return task
+}
+
+func taskIDs(tasks []model.Task) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func testLeases(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	acquire := func(name, holder string, ttl time.Duration, want bool) {
+		t.Helper()
+		got, err := r.Leases.AcquireLease(ctx, name, holder, ttl)
+		if err != nil || got != want {
+			t.Fatalf("acquire %s by %s: got %v, %v, want %v", name, holder, got, err, want)
+		}
+	}
+	acquire("grooming", "a", time.Hour, true)
+	acquire("grooming", "a", time.Hour, true)
+	acquire("grooming", "b", time.Hour, false)
+	// Leases of different jobs are independent.
+	acquire("overdue", "b", time.Hour, true)
+
+	// Expired lease is taken by another holder.
+	acquire("grooming", "a", 0, true)
+	acquire("grooming", "b", time.Hour, true)
+	acquire("grooming", "a", time.Hour, false)
+}
+
+func testAPITokens(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	prj := createProject(t, r, -100123)
+	other := createProject(t, r, -100456)
+	token := &model.APIToken{
+		ProjectID: prj.ID,
+		Hash:      model.HashAPIToken("secret"),
+		Prefix:    "secr",
+		Scope:     model.APITokenScopeRead,
+		CreatedBy: 1,
+	}
+	if err := r.Tokens.CreateAPIToken(ctx, token); err != nil {
+		t.Fatalf("create api token: %s", err)
+	}
+	if token.ID == 0 || token.CreatedAt.IsZero() {
+		t.Fatalf("create api token: got %+v", token)
+	}
+
+	got, err := r.Tokens.FetchAPITokenByHash(ctx, model.HashAPIToken("secret"))
+	if err != nil || got.ID != token.ID || got.ProjectID != prj.ID || got.Scope != model.APITokenScopeRead ||
+		got.Prefix != "secr" || !got.LastUsedAt.IsZero() {
+		t.Fatalf("fetch api token: got %+v, %v", got, err)
+	}
+	if _, err = r.Tokens.FetchAPITokenByHash(ctx, model.HashAPIToken("other")); !errors.Is(err, model.ErrAPITokenNotFound) {
+		t.Fatalf("fetch unknown api token: got %v, want %v", err, model.ErrAPITokenNotFound)
+	}
+
+	usedAt := time.Now().Truncate(time.Second)
+	if err = r.Tokens.TouchAPIToken(ctx, token.ID, usedAt); err != nil {
+		t.Fatalf("touch api token: %s", err)
+	}
+	tokens, err := r.Tokens.ListAPITokens(ctx, prj.ID)
+	if err != nil || len(tokens) != 1 || tokens[0].ID != token.ID || !tokens[0].LastUsedAt.Equal(usedAt) {
+		t.Fatalf("list api tokens: got %+v, %v", tokens, err)
+	}
+	if tokens, err = r.Tokens.ListAPITokens(ctx, other.ID); err != nil || len(tokens) != 0 {
+		t.Fatalf("list api tokens of other project: got %+v, %v", tokens, err)
+	}
+
+	if err = r.Tokens.RevokeAPIToken(ctx, other.ID, token.ID); !errors.Is(err, model.ErrAPITokenNotFound) {
+		t.Fatalf("revoke api token of other project: got %v, want %v", err, model.ErrAPITokenNotFound)
+	}
+	if err = r.Tokens.RevokeAPIToken(ctx, prj.ID, token.ID); err != nil {
+		t.Fatalf("revoke api token: %s", err)
+	}
+	if _, err = r.Tokens.FetchAPITokenByHash(ctx, token.Hash); !errors.Is(err, model.ErrAPITokenNotFound) {
+		t.Fatalf("fetch revoked api token: got %v, want %v", err, model.ErrAPITokenNotFound)
+	}
+}
+
+func testTaskEventLog(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	if id, err := r.EventLog.LastTaskEventID(ctx); err != nil || id != 0 {
+		t.Fatalf("last id of empty log: got %d, %v", id, err)
+	}
+	deadline := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	logged := []model.TaskEvent{
+		{Type: model.TaskEventCreated, ActorID: 1, Task: model.Task{ID: 1, ProjectID: 10, Title: "a",
+			Status: model.TaskStatusBacklog, Priority: model.TaskPriorityNormal}},
+		{Type: model.TaskEventUpdated, ActorID: 2, Task: model.Task{ID: 2, ProjectID: 10, Title: "b",
+			Status: model.TaskStatusDone, Priority: model.TaskPriorityHigh, Deadline: deadline, Epic: true, ParentID: 3}},
+		{Type: model.TaskEventRemoved, ActorID: 1, Task: model.Task{ID: 1, ProjectID: 10, Title: "a",
+			Status: model.TaskStatusBacklog, Priority: model.TaskPriorityNormal}},
+	}
+	for _, event := range logged {
+		if err := r.EventLog.AppendTaskEvent(ctx, event); err != nil {
+			t.Fatalf("append task event: %s", err)
+		}
+	}
+
+	events, err := r.EventLog.FetchTaskEvents(ctx, 0, 2)
+	if err != nil || len(events) != 2 {
+		t.Fatalf("fetch task events: got %+v, %v", events, err)
+	}
+	for i, e := range events {
+		if !reflect.DeepEqual(e.Event, logged[i]) {
+			t.Fatalf("fetch task events: got %+v, want %+v", e.Event, logged[i])
+		}
+	}
+	rest, err := r.EventLog.FetchTaskEvents(ctx, events[1].ID, 10)
+	if err != nil || len(rest) != 1 || rest[0].Event.Type != model.TaskEventRemoved {
+		t.Fatalf("fetch task events after id: got %+v, %v", rest, err)
+	}
+	if id, err := r.EventLog.LastTaskEventID(ctx); err != nil || id != rest[0].ID {
+		t.Fatalf("last id: got %d, %v, want %d", id, err, rest[0].ID)
+	}
+
+	if n, err := r.EventLog.DeleteTaskEvents(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("delete events logged an hour ago: got %d, %v", n, err)
+	}
+	if n, err := r.EventLog.DeleteTaskEvents(ctx, time.Now().Add(time.Hour)); err != nil || n != 3 {
+		t.Fatalf("delete task events: got %d, %v", n, err)
+	}
+}
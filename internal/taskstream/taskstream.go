@@ -0,0 +1,210 @@
+// Package taskstream streams changes of project tasks as server-sent events, e.g. for live dashboards.
+package taskstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/agalitsyn/telegram-tasks-bot/internal/model"
+)
+
+// PathPrefix is the root of streams, each project is streamed at PathPrefix/{token} with public board token.
+const PathPrefix = "/events/"
+
+const (
+	keepAliveInterval = 30 * time.Second
+	// pollInterval is how often task event log is read, events are logged by every process changing tasks.
+	pollInterval = time.Second
+	pollBatch    = 100
+	// subscriberBuffer is how many events may wait for slow client before it is disconnected.
+	subscriberBuffer = 64
+)
+
+// Handler serves streams of task events read from event log.
+type Handler struct {
+	mux            *http.ServeMux
+	projectStorage model.ProjectRepository
+	events         model.TaskEventLogRepository
+
+	mu          sync.Mutex
+	subscribers map[int]map[*subscriber]struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+type subscriber struct {
+	events chan []byte
+	// dropped is closed when client falls behind.
+	dropped chan struct{}
+}
+
+func NewHandler(projectStorage model.ProjectRepository, events model.TaskEventLogRepository) *Handler {
+	h := &Handler{
+		mux:            http.NewServeMux(),
+		projectStorage: projectStorage,
+		events:         events,
+		subscribers:    make(map[int]map[*subscriber]struct{}),
+		closed:         make(chan struct{}),
+	}
+	h.mux.HandleFunc("GET "+PathPrefix+"{token}", h.stream)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Close ends open streams, so server can shut down.
+func (h *Handler) Close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+type taskView struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority string `json:"priority"`
+	Deadline string `json:"deadline,omitempty"`
+	Epic     bool   `json:"epic,omitempty"`
+	ParentID int    `json:"parent_id,omitempty"`
+}
+
+// Start sends events logged since start to clients until ctx is cancelled.
+func (h *Handler) Start(ctx context.Context) {
+	lastID, err := h.events.LastTaskEventID(ctx)
+	if err != nil {
+		log.Printf("ERROR could not fetch last task event: %s", err)
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		// Log is read even without clients, so those connecting later don't get old events
+		for {
+			events, err := h.events.FetchTaskEvents(ctx, lastID, pollBatch)
+			if err != nil {
+				log.Printf("ERROR could not fetch task events: %s", err)
+				break
+			}
+			for _, e := range events {
+				h.send(e.Event)
+				lastID = e.ID
+			}
+			if len(events) < pollBatch {
+				break
+			}
+		}
+	}
+}
+
+// send sends event to clients of task's project, only fields shown on public board are included.
+func (h *Handler) send(event model.TaskEvent) {
+	task := taskView{
+		ID:       event.Task.ID,
+		Title:    event.Task.Title,
+		Status:   string(event.Task.Status),
+		Priority: string(event.Task.Priority),
+		Epic:     event.Task.Epic,
+		ParentID: event.Task.ParentID,
+	}
+	if !event.Task.Deadline.IsZero() {
+		task.Deadline = event.Task.Deadline.Format(time.DateOnly)
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		log.Printf("ERROR could not encode task event: %s", err)
+		return
+	}
+	msg := []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, data))
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers[event.Task.ProjectID] {
+		select {
+		case sub.events <- msg:
+		default:
+			h.unsubscribe(event.Task.ProjectID, sub)
+			close(sub.dropped)
+		}
+	}
+}
+
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request) {
+	prj, err := h.projectStorage.FetchProjectByPublicToken(r.Context(), r.PathValue("token"))
+	if err != nil {
+		if errors.Is(err, model.ErrProjectNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("ERROR could not fetch project: %s", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := &subscriber{events: make(chan []byte, subscriberBuffer), dropped: make(chan struct{})}
+	h.mu.Lock()
+	if h.subscribers[prj.ID] == nil {
+		h.subscribers[prj.ID] = make(map[*subscriber]struct{})
+	}
+	h.subscribers[prj.ID][sub] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.unsubscribe(prj.ID, sub)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case msg := <-sub.events:
+			_, err = w.Write(msg)
+		case <-keepAlive.C:
+			// Stream ends when link is revoked or reissued
+			if _, err = h.projectStorage.FetchProjectByPublicToken(r.Context(), r.PathValue("token")); err != nil {
+				return
+			}
+			_, err = w.Write([]byte(": keep-alive\n\n"))
+		case <-sub.dropped:
+			log.Printf("WARN task stream client of project id=%d fell behind and was disconnected", prj.ID)
+			return
+		case <-h.closed:
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// unsubscribe must be called with mu held.
+func (h *Handler) unsubscribe(projectID int, sub *subscriber) {
+	delete(h.subscribers[projectID], sub)
+	if len(h.subscribers[projectID]) == 0 {
+		delete(h.subscribers, projectID)
+	}
+}
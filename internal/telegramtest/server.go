@@ -0,0 +1,126 @@
+// Package telegramtest provides fake Telegram Bot API server for running bot without Telegram.
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Server answers every method with success and remembers inline buttons sent to chats,
+// so callbacks can refer to pending items which really exist in bot.
+type Server struct {
+	*httptest.Server
+
+	// Username of bot returned by getMe, OnRequest is called for every other request.
+	// Both must be set before bot is created.
+	Username  string
+	OnRequest func(method string, params url.Values)
+	// Administrators are returned by getChatAdministrators for every chat, must be set before bot is created.
+	Administrators []tgbotapi.ChatMember
+
+	mu        sync.Mutex
+	messageID int
+	requests  map[string]int
+	buttons   map[int64][]tgbotapi.InlineKeyboardButton
+}
+
+func NewServer() *Server {
+	s := &Server{
+		Username: "test_bot",
+		requests: make(map[string]int),
+		buttons:  make(map[int64][]tgbotapi.InlineKeyboardButton),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Endpoint returns API endpoint format for bot config.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := path.Base(r.URL.Path)
+
+	var result interface{}
+	if method == "getMe" {
+		result = tgbotapi.User{ID: 1, IsBot: true, FirstName: "Test", UserName: s.Username}
+	} else {
+		if s.OnRequest != nil {
+			s.OnRequest(method, r.PostForm)
+		}
+		// Message suits both Send and Request callers, the latter do not decode result.
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+		result = s.handleMessage(method, chatID, r.PostForm.Get("reply_markup"))
+		if method == "getChatAdministrators" {
+			result = append([]tgbotapi.ChatMember{}, s.Administrators...)
+		}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+func (s *Server) handleMessage(method string, chatID int64, replyMarkup string) tgbotapi.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests[method]++
+	s.messageID++
+
+	var markup tgbotapi.InlineKeyboardMarkup
+	if replyMarkup != "" && json.Unmarshal([]byte(replyMarkup), &markup) == nil {
+		var buttons []tgbotapi.InlineKeyboardButton
+		for _, row := range markup.InlineKeyboard {
+			for _, button := range row {
+				if button.CallbackData != nil {
+					buttons = append(buttons, button)
+				}
+			}
+		}
+		s.buttons[chatID] = buttons
+	}
+
+	return tgbotapi.Message{
+		MessageID: s.messageID,
+		Date:      int(time.Now().Unix()),
+		Chat:      &tgbotapi.Chat{ID: chatID},
+	}
+}
+
+// ChatButtons returns inline buttons of latest keyboard sent to chat.
+func (s *Server) ChatButtons(chatID int64) []tgbotapi.InlineKeyboardButton {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.buttons[chatID]
+}
+
+// RequestCounts returns number of calls of every method except getMe.
+func (s *Server) RequestCounts() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(s.requests))
+	for method, n := range s.requests {
+		counts[method] = n
+	}
+	return counts
+}
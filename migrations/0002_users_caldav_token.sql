@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN caldav_token TEXT;
+CREATE UNIQUE INDEX idx_users_caldav_token ON users(caldav_token);
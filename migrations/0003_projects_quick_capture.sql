@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN quick_capture BOOLEAN NOT NULL DEFAULT 0;
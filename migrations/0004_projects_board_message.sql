@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN board_message_id INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN username TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_users_username ON users(username COLLATE NOCASE);
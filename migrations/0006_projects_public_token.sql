@@ -0,0 +1,2 @@
+ALTER TABLE projects ADD COLUMN public_token TEXT;
+CREATE UNIQUE INDEX idx_projects_public_token ON projects(public_token);
@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN default_deadline_days INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+-- Deadlines were stored in Go time.String() format like "2024-12-01 23:59:59 +0300 MSK",
+-- convert them to UTC "2024-12-01T20:59:59Z". The offset follows the first space after seconds.
+UPDATE tasks
+SET deadline = strftime(
+    '%Y-%m-%dT%H:%M:%SZ',
+    substr(deadline, 1, 19)
+        || substr(deadline, 20 + instr(substr(deadline, 20), ' '), 3)
+        || ':'
+        || substr(deadline, 23 + instr(substr(deadline, 20), ' '), 2)
+)
+WHERE deadline IS NOT NULL AND deadline NOT LIKE '____-__-__T__:__:__Z';
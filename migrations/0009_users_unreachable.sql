@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN unreachable BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN reviewer INTEGER REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+CREATE TABLE project_holidays (
+    project_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    PRIMARY KEY (project_id, day),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN updated_at TEXT;
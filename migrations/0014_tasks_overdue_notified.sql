@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN overdue_notified INTEGER NOT NULL DEFAULT 0;
+
+-- Tasks overdue before announcements were introduced are not announced.
+UPDATE tasks SET overdue_notified = 1 WHERE deadline < strftime('%Y-%m-%dT%H:%M:%SZ', 'now');
@@ -0,0 +1 @@
+ALTER TABLE tasks ADD COLUMN muted INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,9 @@
+CREATE TABLE user_usernames (
+    user_id INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    changed_at TEXT NOT NULL,
+    PRIMARY KEY (user_id, username),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_usernames_username ON user_usernames(username COLLATE NOCASE);
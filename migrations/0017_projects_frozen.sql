@@ -0,0 +1 @@
+ALTER TABLE projects ADD COLUMN frozen INTEGER NOT NULL DEFAULT 0;
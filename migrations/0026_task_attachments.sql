@@ -0,0 +1,11 @@
+CREATE TABLE task_attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    file_id TEXT NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    added_by INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX task_attachments_task_id ON task_attachments (task_id);
@@ -0,0 +1,8 @@
+CREATE TABLE alert_tasks (
+    project_id INTEGER NOT NULL,
+    fingerprint TEXT NOT NULL,
+    task_id INTEGER NOT NULL,
+    PRIMARY KEY (project_id, fingerprint)
+);
+
+CREATE INDEX alert_tasks_task_id ON alert_tasks (task_id);
@@ -0,0 +1,9 @@
+CREATE TABLE task_links (
+    task_id INTEGER NOT NULL,
+    blocker_id INTEGER NOT NULL,
+    PRIMARY KEY (task_id, blocker_id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocker_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX task_links_blocker_id ON task_links (blocker_id);
@@ -0,0 +1,8 @@
+CREATE TABLE task_recurrences (
+    task_id INTEGER PRIMARY KEY,
+    rule TEXT NOT NULL,
+    next_at TEXT NOT NULL,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX task_recurrences_next_at ON task_recurrences (next_at);
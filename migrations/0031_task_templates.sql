@@ -0,0 +1,14 @@
+CREATE TABLE task_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    -- name_key is lowercased name, SQLite ignores case of ASCII letters only
+    name_key TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    assignee INTEGER,
+    labels TEXT NOT NULL DEFAULT '',
+    created_by INTEGER NOT NULL,
+    UNIQUE (project_id, name_key),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+CREATE TABLE task_snapshots (
+    project_id INTEGER NOT NULL,
+    day TEXT NOT NULL,
+    status TEXT NOT NULL,
+    count INTEGER NOT NULL,
+    PRIMARY KEY (project_id, day, status),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+ALTER TABLE tasks ADD COLUMN rank INTEGER NOT NULL DEFAULT 0;
+
+-- Existing tasks keep order of creation.
+UPDATE tasks SET rank = id;
+
+CREATE INDEX tasks_project_id_rank ON tasks (project_id, rank);
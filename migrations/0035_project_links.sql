@@ -0,0 +1,11 @@
+CREATE TABLE project_links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    -- title_key is lowercased title, SQLite ignores case of ASCII letters only
+    title_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    created_by INTEGER NOT NULL,
+    UNIQUE (project_id, title_key),
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE
+);
@@ -0,0 +1,4 @@
+-- Removed tasks stay in trash until purged, deleted_at is NULL for other tasks.
+ALTER TABLE tasks ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX tasks_deleted_at ON tasks (deleted_at);
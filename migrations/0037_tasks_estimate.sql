@@ -0,0 +1,2 @@
+-- Story points of task, 0 if it is not estimated.
+ALTER TABLE tasks ADD COLUMN estimate INTEGER NOT NULL DEFAULT 0;
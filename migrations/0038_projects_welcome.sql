@@ -0,0 +1,3 @@
+-- Message greeting new chat members, empty if welcome is disabled.
+ALTER TABLE projects ADD COLUMN welcome_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN welcome_privately INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,42 @@
+-- Ids of deleted projects are not reused, so rows and API tokens left by them never show up in new projects.
+CREATE TABLE projects_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tg_chat_id INTEGER NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    archived BOOLEAN NOT NULL DEFAULT 0,
+    quick_capture BOOLEAN NOT NULL DEFAULT 0,
+    board_message_id INTEGER NOT NULL DEFAULT 0,
+    public_token TEXT,
+    default_deadline_days INTEGER NOT NULL DEFAULT 0,
+    frozen INTEGER NOT NULL DEFAULT 0,
+    welcome_text TEXT NOT NULL DEFAULT '',
+    welcome_privately INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO projects_new (id, tg_chat_id, title, archived, quick_capture, board_message_id, public_token,
+    default_deadline_days, frozen, welcome_text, welcome_privately)
+SELECT id, tg_chat_id, title, archived, quick_capture, board_message_id, public_token,
+    default_deadline_days, frozen, welcome_text, welcome_privately
+FROM projects;
+
+DROP TABLE projects;
+ALTER TABLE projects_new RENAME TO projects;
+
+CREATE INDEX idx_projects_tg_chat_id ON projects(tg_chat_id);
+CREATE UNIQUE INDEX idx_projects_public_token ON projects(public_token);
+
+-- Rows of projects deleted earlier may be left, their ids are skipped too.
+DELETE FROM sqlite_sequence WHERE name = 'projects';
+INSERT INTO sqlite_sequence (name, seq)
+SELECT 'projects', COALESCE(MAX(id), 0) FROM (
+    SELECT id FROM projects
+    UNION ALL SELECT project_id FROM tasks
+    UNION ALL SELECT project_id FROM user_projects
+    UNION ALL SELECT project_id FROM api_tokens
+    UNION ALL SELECT project_id FROM labels
+    UNION ALL SELECT project_id FROM alert_tasks
+    UNION ALL SELECT project_id FROM project_holidays
+    UNION ALL SELECT project_id FROM project_links
+    UNION ALL SELECT project_id FROM task_templates
+    UNION ALL SELECT project_id FROM task_snapshots
+);
@@ -0,0 +1,59 @@
+-- Ids of purged tasks are not reused, so buttons left in chats never act on another task.
+CREATE TABLE tasks_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    status TEXT NOT NULL,
+    deadline DATETIME,
+    created_by INTEGER NOT NULL,
+    updated_by INTEGER NOT NULL,
+    assignee INTEGER,
+    reviewer INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TEXT,
+    overdue_notified INTEGER NOT NULL DEFAULT 0,
+    muted INTEGER NOT NULL DEFAULT 0,
+    epic INTEGER NOT NULL DEFAULT 0,
+    parent_id INTEGER,
+    priority TEXT NOT NULL DEFAULT 'normal',
+    rank INTEGER NOT NULL DEFAULT 0,
+    deleted_at TEXT,
+    estimate INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (project_id) REFERENCES projects(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (updated_by) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (assignee) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO tasks_new (id, project_id, title, description, status, deadline, created_by, updated_by, assignee,
+    reviewer, updated_at, overdue_notified, muted, epic, parent_id, priority, rank, deleted_at, estimate)
+SELECT id, project_id, title, description, status, deadline, created_by, updated_by, assignee,
+    reviewer, updated_at, overdue_notified, muted, epic, parent_id, priority, rank, deleted_at, estimate
+FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+CREATE INDEX idx_tasks_project_id ON tasks(project_id);
+CREATE INDEX idx_tasks_assignee ON tasks(assignee);
+CREATE INDEX idx_tasks_parent_id ON tasks(parent_id);
+CREATE INDEX tasks_project_id_rank ON tasks (project_id, rank);
+CREATE INDEX tasks_deleted_at ON tasks (deleted_at);
+
+-- Tasks purged earlier may be still referenced by rows left behind, their ids are skipped too.
+DELETE FROM sqlite_sequence WHERE name = 'tasks';
+INSERT INTO sqlite_sequence (name, seq)
+SELECT 'tasks', COALESCE(MAX(id), 0) FROM (
+    SELECT id FROM tasks
+    UNION ALL SELECT task_id FROM task_assignees
+    UNION ALL SELECT task_id FROM task_labels
+    UNION ALL SELECT task_id FROM checklist_items
+    UNION ALL SELECT task_id FROM task_comments
+    UNION ALL SELECT task_id FROM task_attachments
+    UNION ALL SELECT task_id FROM task_watchers
+    UNION ALL SELECT task_id FROM alert_tasks
+    UNION ALL SELECT task_id FROM task_links
+    UNION ALL SELECT blocker_id FROM task_links
+    UNION ALL SELECT task_id FROM task_recurrences
+    UNION ALL SELECT task_id FROM task_history
+);
@@ -0,0 +1,3 @@
+-- Time maintenance found user without projects, personal data is removed only after grace period,
+-- NULL for members of projects.
+ALTER TABLE users ADD COLUMN orphaned_at TEXT;
@@ -0,0 +1,18 @@
+-- Task events are logged, so event streams served by one process see changes made by another, e.g. by worker.
+-- Only fields shown on public board are kept, maintenance prunes old events.
+CREATE TABLE task_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL,
+    task_id INTEGER NOT NULL,
+    project_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL,
+    priority TEXT NOT NULL,
+    deadline TEXT,
+    epic BOOLEAN NOT NULL DEFAULT FALSE,
+    parent_id INTEGER NOT NULL DEFAULT 0,
+    actor_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX task_events_created_at ON task_events (created_at);
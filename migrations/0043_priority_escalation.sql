@@ -0,0 +1,2 @@
+ALTER TABLE projects ADD COLUMN escalation_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN escalated INTEGER NOT NULL DEFAULT 0;